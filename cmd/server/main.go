@@ -19,7 +19,9 @@ import (
 	"penny-assesment/internal/config"
 	"penny-assesment/internal/events"
 	natspub "penny-assesment/internal/events/nats"
+	"penny-assesment/internal/repo/memory"
 	"penny-assesment/internal/repo/postgres"
+	"penny-assesment/internal/repo/sqlite"
 	"penny-assesment/internal/service"
 	"penny-assesment/internal/transport/grpcapi"
 	"penny-assesment/internal/transport/httpapi"
@@ -37,20 +39,22 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	pool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	store, closeStore, err := openStore(ctx, cfg)
 	if err != nil {
-		log.Fatalf("db error: %v", err)
+		log.Fatalf("store error: %v", err)
 	}
-	defer pool.Close()
+	defer closeStore()
 
-	if cfg.MigrateOnStart {
-		if err := postgres.ApplyMigrations(ctx, pool, "migrations"); err != nil {
-			log.Fatalf("migration error: %v", err)
-		}
-	}
-
-	store := postgres.NewStore(pool)
 	svc := service.New(store, cfg.DroneSpeedMPS)
+	svc.SetIdempotencyTTL(cfg.IdempotencyTTL)
+	svc.SetDwellTimes(cfg.PickupDwell, cfg.DropoffDwell)
+	svc.SetSpeedWindow(cfg.SpeedFixes, cfg.SpeedWindow)
+	svc.SetTelemetryRetention(cfg.TelemetryTTL)
+	svc.SetServiceInterval(service.ServiceInterval{FlightTime: cfg.ServiceTime, Meters: cfg.ServiceKM * 1000})
+	svc.SetLowBattery(cfg.LowBattery)
+	svc.SetDroneRange(cfg.DroneRangeKM * 1000)
+	svc.SetBatching(cfg.DroneCapacity, cfg.BatchRadiusM)
+	svc.SetDeadlineWindow(cfg.DeadlineWindow)
 	authenticator := auth.New(cfg.JWTSecret, cfg.JWTTTL)
 
 	var publisher events.Publisher = events.NoopPublisher{}
@@ -123,6 +127,11 @@ func main() {
 		})
 	}
 
+	g.Go(func() error {
+		pruneExpired(ctx, svc)
+		return nil
+	})
+
 	g.Go(func() error {
 		<-ctx.Done()
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -137,3 +146,89 @@ func main() {
 		log.Fatalf("server error: %v", err)
 	}
 }
+
+// pruneInterval is how often expired heartbeat telemetry and idempotency
+// keys are deleted.
+const pruneInterval = time.Hour
+
+// pruneExpired deletes expired telemetry and idempotency keys now and then
+// every pruneInterval until ctx is done. Failures are logged and retried on
+// the next tick.
+func pruneExpired(ctx context.Context, svc *service.Service) {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+	for {
+		pruned, err := svc.PruneTelemetry(ctx)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			log.Printf("telemetry prune error: %v", err)
+		case pruned > 0:
+			log.Printf("pruned %d telemetry samples", pruned)
+		}
+		pruned, err = svc.PruneIdempotencyRecords(ctx)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			log.Printf("idempotency key prune error: %v", err)
+		case pruned > 0:
+			log.Printf("pruned %d expired idempotency keys", pruned)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// store is what the server needs from a backend: the service store plus the
+// outbox the in-process worker drains.
+type store interface {
+	service.Store
+	events.OutboxRepository
+}
+
+func openStore(ctx context.Context, cfg config.Config) (store, func(), error) {
+	switch cfg.StoreBackend {
+	case config.StoreMemory:
+		log.Printf("using in-memory store; data is lost on exit")
+		return memory.NewStore(), func() {}, nil
+	case config.StoreSQLite:
+		db, err := sqlite.Open(cfg.SQLitePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		if cfg.MigrateOnStart {
+			if err := sqlite.ApplyMigrations(ctx, db); err != nil {
+				db.Close()
+				return nil, nil, err
+			}
+		}
+		log.Printf("using sqlite store at %s", cfg.SQLitePath)
+		return sqlite.NewStore(db), func() { db.Close() }, nil
+	}
+
+	pool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg.MigrateOnStart {
+		if err := postgres.ApplyMigrations(ctx, pool, "migrations"); err != nil {
+			pool.Close()
+			return nil, nil, err
+		}
+	}
+	pgStore := postgres.NewStore(pool)
+	if cfg.PostGIS {
+		enabled, err := postgres.SetupPostGIS(ctx, pool, "migrations/postgis", cfg.MigrateOnStart)
+		switch {
+		case err != nil:
+			log.Printf("postgis setup failed, using haversine fallback: %v", err)
+		case enabled:
+			pgStore.EnablePostGIS()
+			log.Printf("using postgis for spatial queries")
+		default:
+			log.Printf("postgis not available, using haversine fallback")
+		}
+	}
+	return pgStore, pool.Close, nil
+}
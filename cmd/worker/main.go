@@ -12,6 +12,7 @@ import (
 	"penny-assesment/internal/events"
 	natspub "penny-assesment/internal/events/nats"
 	"penny-assesment/internal/repo/postgres"
+	"penny-assesment/internal/repo/sqlite"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -21,6 +22,9 @@ func main() {
 	if err != nil {
 		log.Fatalf("config error: %v", err)
 	}
+	if cfg.StoreBackend == config.StoreMemory {
+		log.Fatalf("worker needs a shared outbox; the memory store only works with the server's in-process worker")
+	}
 	if !cfg.OutboxEnabled {
 		log.Printf("outbox disabled; exiting")
 		return
@@ -29,17 +33,11 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	pool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	repo, closeRepo, err := openOutbox(ctx, cfg)
 	if err != nil {
-		log.Fatalf("db error: %v", err)
-	}
-	defer pool.Close()
-
-	if cfg.MigrateOnStart {
-		if err := postgres.ApplyMigrations(ctx, pool, "migrations"); err != nil {
-			log.Fatalf("migration error: %v", err)
-		}
+		log.Fatalf("store error: %v", err)
 	}
+	defer closeRepo()
 
 	publisher, err := natspub.New(cfg.NATSURL, cfg.NATSSubject)
 	if err != nil {
@@ -47,9 +45,8 @@ func main() {
 	}
 	defer publisher.Close()
 
-	store := postgres.NewStore(pool)
 	worker := &events.OutboxWorker{
-		Repo:         store,
+		Repo:         repo,
 		Publisher:    publisher,
 		PollInterval: cfg.OutboxInterval,
 		BatchSize:    cfg.OutboxBatch,
@@ -63,3 +60,31 @@ func main() {
 		log.Fatalf("worker error: %v", err)
 	}
 }
+
+func openOutbox(ctx context.Context, cfg config.Config) (events.OutboxRepository, func(), error) {
+	if cfg.StoreBackend == config.StoreSQLite {
+		db, err := sqlite.Open(cfg.SQLitePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		if cfg.MigrateOnStart {
+			if err := sqlite.ApplyMigrations(ctx, db); err != nil {
+				db.Close()
+				return nil, nil, err
+			}
+		}
+		return sqlite.NewStore(db), func() { db.Close() }, nil
+	}
+
+	pool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg.MigrateOnStart {
+		if err := postgres.ApplyMigrations(ctx, pool, "migrations"); err != nil {
+			pool.Close()
+			return nil, nil, err
+		}
+	}
+	return postgres.NewStore(pool), pool.Close, nil
+}
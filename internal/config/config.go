@@ -7,20 +7,43 @@ import (
 	"time"
 )
 
+// Store backends selectable via STORE_BACKEND.
+const (
+	StorePostgres = "postgres"
+	StoreMemory   = "memory"
+	StoreSQLite   = "sqlite"
+)
+
 type Config struct {
-	DatabaseURL   string
-	JWTSecret     string
-	JWTTTL        time.Duration
-	HTTPAddr      string
-	GRPCAddr      string
-	ThriftAddr    string
-	DroneSpeedMPS float64
+	StoreBackend   string
+	DatabaseURL    string
+	SQLitePath     string
+	JWTSecret      string
+	JWTTTL         time.Duration
+	HTTPAddr       string
+	GRPCAddr       string
+	ThriftAddr     string
+	DroneSpeedMPS  float64
+	PickupDwell    time.Duration
+	DropoffDwell   time.Duration
+	SpeedFixes     int
+	SpeedWindow    time.Duration
 	MigrateOnStart bool
 	NATSURL        string
 	NATSSubject    string
 	OutboxEnabled  bool
 	OutboxInterval time.Duration
 	OutboxBatch    int
+	IdempotencyTTL time.Duration
+	TelemetryTTL   time.Duration
+	ServiceTime    time.Duration
+	ServiceKM      float64
+	LowBattery     float64
+	DroneRangeKM   float64
+	DroneCapacity  int
+	BatchRadiusM   float64
+	DeadlineWindow time.Duration
+	PostGIS        bool
 }
 
 func Load() (Config, error) {
@@ -33,10 +56,19 @@ func LoadWorker() (Config, error) {
 
 func load(requireJWT bool) (Config, error) {
 	var cfg Config
+	cfg.StoreBackend = getString("STORE_BACKEND", StorePostgres)
+	switch cfg.StoreBackend {
+	case StorePostgres:
+	case StoreMemory:
+	case StoreSQLite:
+	default:
+		return cfg, fmt.Errorf("STORE_BACKEND must be one of %s, %s, %s", StorePostgres, StoreMemory, StoreSQLite)
+	}
 	cfg.DatabaseURL = os.Getenv("DATABASE_URL")
-	if cfg.DatabaseURL == "" {
+	if cfg.StoreBackend == StorePostgres && cfg.DatabaseURL == "" {
 		return cfg, fmt.Errorf("DATABASE_URL is required")
 	}
+	cfg.SQLitePath = getString("SQLITE_PATH", "drone.db")
 	cfg.JWTSecret = os.Getenv("JWT_SECRET")
 	if requireJWT && cfg.JWTSecret == "" {
 		return cfg, fmt.Errorf("JWT_SECRET is required")
@@ -46,12 +78,26 @@ func load(requireJWT bool) (Config, error) {
 	cfg.GRPCAddr = getString("GRPC_ADDR", ":9090")
 	cfg.ThriftAddr = getString("THRIFT_ADDR", ":9091")
 	cfg.DroneSpeedMPS = getFloat("DRONE_SPEED_MPS", 15.0)
+	cfg.PickupDwell = getDuration("PICKUP_DWELL", 0)
+	cfg.DropoffDwell = getDuration("DROPOFF_DWELL", 0)
+	cfg.SpeedFixes = getInt("SPEED_WINDOW_FIXES", 10)
+	cfg.SpeedWindow = getDuration("SPEED_WINDOW", 2*time.Minute)
 	cfg.MigrateOnStart = getBool("MIGRATE_ON_START", true)
 	cfg.NATSURL = getString("NATS_URL", "nats://127.0.0.1:4222")
 	cfg.NATSSubject = getString("NATS_SUBJECT", "drone.events")
 	cfg.OutboxEnabled = getBool("OUTBOX_ENABLED", true)
 	cfg.OutboxInterval = getDuration("OUTBOX_POLL_INTERVAL", time.Second)
 	cfg.OutboxBatch = getInt("OUTBOX_BATCH_SIZE", 50)
+	cfg.IdempotencyTTL = getDuration("IDEMPOTENCY_TTL", 24*time.Hour)
+	cfg.TelemetryTTL = getDuration("TELEMETRY_RETENTION", 30*24*time.Hour)
+	cfg.ServiceTime = getDuration("SERVICE_INTERVAL_FLIGHT_TIME", 0)
+	cfg.ServiceKM = getFloat("SERVICE_INTERVAL_KM", 0)
+	cfg.LowBattery = getFloat("LOW_BATTERY_PCT", 20)
+	cfg.DroneRangeKM = getFloat("DRONE_RANGE_KM", 0)
+	cfg.DroneCapacity = getInt("DRONE_CAPACITY", 1)
+	cfg.BatchRadiusM = getFloat("BATCH_RADIUS_M", 1000)
+	cfg.DeadlineWindow = getDuration("DEADLINE_WINDOW", 30*time.Minute)
+	cfg.PostGIS = getBool("POSTGIS", true)
 	return cfg, nil
 }
 
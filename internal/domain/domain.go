@@ -22,31 +22,174 @@ const (
 
 type DroneStatus string
 
+// Only ACTIVE drones are dispatched. CHARGING, MAINTENANCE, OFFLINE and
+// BROKEN take a drone out of dispatch until it is brought back; RETIRED takes
+// it out for good.
 const (
-	DroneStatusActive DroneStatus = "ACTIVE"
-	DroneStatusBroken DroneStatus = "BROKEN"
+	DroneStatusActive      DroneStatus = "ACTIVE"
+	DroneStatusBroken      DroneStatus = "BROKEN"
+	DroneStatusCharging    DroneStatus = "CHARGING"
+	DroneStatusMaintenance DroneStatus = "MAINTENANCE"
+	DroneStatusOffline     DroneStatus = "OFFLINE"
+	DroneStatusRetired     DroneStatus = "RETIRED"
 )
 
+// AssignableDroneStatuses are the statuses of drones that may be given a job.
+var AssignableDroneStatuses = []DroneStatus{DroneStatusActive}
+
+// Assignable reports whether a drone in status may be given a job.
+func (status DroneStatus) Assignable() bool {
+	for _, s := range AssignableDroneStatuses {
+		if status == s {
+			return true
+		}
+	}
+	return false
+}
+
+// droneTransitions lists, for each status, the statuses a drone may move to
+// and the roles allowed to move it there. Drones report their own charging
+// and connectivity and can ground themselves; taking a drone in or out of
+// maintenance, repairing it and retiring it are for admins. RETIRED is final.
+var droneTransitions = map[DroneStatus]map[DroneStatus][]string{
+	DroneStatusActive: {
+		DroneStatusBroken:      {RoleDrone, RoleAdmin},
+		DroneStatusCharging:    {RoleDrone, RoleAdmin},
+		DroneStatusOffline:     {RoleDrone, RoleAdmin},
+		DroneStatusMaintenance: {RoleAdmin},
+		DroneStatusRetired:     {RoleAdmin},
+	},
+	DroneStatusCharging: {
+		DroneStatusActive:      {RoleDrone, RoleAdmin},
+		DroneStatusBroken:      {RoleDrone, RoleAdmin},
+		DroneStatusOffline:     {RoleDrone, RoleAdmin},
+		DroneStatusMaintenance: {RoleAdmin},
+		DroneStatusRetired:     {RoleAdmin},
+	},
+	DroneStatusOffline: {
+		DroneStatusActive:      {RoleDrone, RoleAdmin},
+		DroneStatusCharging:    {RoleDrone, RoleAdmin},
+		DroneStatusBroken:      {RoleDrone, RoleAdmin},
+		DroneStatusMaintenance: {RoleAdmin},
+		DroneStatusRetired:     {RoleAdmin},
+	},
+	DroneStatusBroken: {
+		DroneStatusActive:      {RoleAdmin},
+		DroneStatusMaintenance: {RoleAdmin},
+		DroneStatusRetired:     {RoleAdmin},
+	},
+	DroneStatusMaintenance: {
+		DroneStatusActive:  {RoleAdmin},
+		DroneStatusBroken:  {RoleAdmin},
+		DroneStatusRetired: {RoleAdmin},
+	},
+}
+
+// CanTransitionDrone reports whether role may move a drone from one status to
+// another. Staying in the same status is allowed to any role that could have
+// moved the drone there, so that repeated requests are harmless.
+func CanTransitionDrone(from, to DroneStatus, role string) bool {
+	if from == to {
+		for _, targets := range droneTransitions {
+			for _, r := range targets[to] {
+				if r == role {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	for _, r := range droneTransitions[from][to] {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
 type Location struct {
 	Lat float64
 	Lng float64
 }
 
+// BoundingBox is a lat/lng rectangle. A box whose MinLng is greater than its
+// MaxLng wraps across the antimeridian.
+type BoundingBox struct {
+	MinLat float64
+	MinLng float64
+	MaxLat float64
+	MaxLng float64
+}
+
+func (b BoundingBox) CrossesAntimeridian() bool {
+	return b.MinLng > b.MaxLng
+}
+
+func (b BoundingBox) Contains(loc Location) bool {
+	if loc.Lat < b.MinLat || loc.Lat > b.MaxLat {
+		return false
+	}
+	if b.CrossesAntimeridian() {
+		return loc.Lng >= b.MinLng || loc.Lng <= b.MaxLng
+	}
+	return loc.Lng >= b.MinLng && loc.Lng <= b.MaxLng
+}
+
 type Order struct {
-	ID               string
-	UserID           string
-	Origin           Location
-	Destination      Location
-	Status           OrderStatus
-	AssignedDroneID  *string
-	HandoffOrigin    *Location
-	CreatedAt        time.Time
-	UpdatedAt        time.Time
-	ReservedAt       *time.Time
-	PickedUpAt       *time.Time
-	DeliveredAt      *time.Time
-	FailedAt         *time.Time
-	FailureReason    *string
+	ID              string
+	UserID          string
+	Origin          Location
+	Destination     Location
+	Status          OrderStatus
+	AssignedDroneID *string
+	HandoffOrigin   *Location
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	ReservedAt      *time.Time
+	PickedUpAt      *time.Time
+	DeliveredAt     *time.Time
+	FailedAt        *time.Time
+	FailureReason   *string
+	// Route is the path planned when the order was last reserved, from its
+	// route start to Destination with any waypoints between; nil until then.
+	Route []Location
+	// Legs splits an order beyond a single drone's range into hops between
+	// relay points, flown in turn by different drones; nil for orders one
+	// drone flies end to end.
+	Legs []RelayLeg
+	// PickupNotBefore and DeliverBy are the order's optional delivery window:
+	// it is not reserved before PickupNotBefore, and is due at its
+	// destination by DeliverBy.
+	PickupNotBefore *time.Time
+	DeliverBy       *time.Time
+	// SLAAtRiskAt is when the order's ETA was first found to miss DeliverBy.
+	SLAAtRiskAt *time.Time
+	// Version starts at 1 and is incremented by the store on every update.
+	Version int64
+}
+
+// RelayLeg is one hop of a relayed order. It starts where the previous leg
+// ended, or at the order's origin, and ends at To: a relay point for every leg
+// but the last, which ends at the destination.
+type RelayLeg struct {
+	To Location
+	// RelayPointID is the depot or charging station at To; empty on the last
+	// leg.
+	RelayPointID string
+	// DroneID is the drone that reserved the leg, kept once it is completed.
+	DroneID     *string
+	CompletedAt *time.Time
+}
+
+// CurrentLeg returns the index of the first leg not yet completed, or -1 for
+// an order without legs.
+func (o *Order) CurrentLeg() int {
+	for i, leg := range o.Legs {
+		if leg.CompletedAt == nil {
+			return i
+		}
+	}
+	return -1
 }
 
 type Drone struct {
@@ -54,9 +197,250 @@ type Drone struct {
 	Status          DroneStatus
 	LastLocation    *Location
 	LastHeartbeatAt *time.Time
-	CurrentOrderID  *string
-	CreatedAt       time.Time
-	UpdatedAt       time.Time
+	// CurrentOrderID is the order of the drone's next stop.
+	CurrentOrderID *string
+	// Stops are the pickups and dropoffs of the drone's current trip in the
+	// order it flies them. Completed stops are kept until the trip ends, when
+	// the list is cleared.
+	Stops []Stop
+	// HomeDepotID is the depot the drone is based at and returns to between
+	// orders; nil if it has none.
+	HomeDepotID *string
+	// RecentFixes are the positions of the latest heartbeats, oldest first,
+	// kept to estimate the drone's observed speed.
+	RecentFixes []Fix
+	// Vitals are the readings of the latest heartbeat.
+	Vitals Vitals
+	// Usage is the flying the drone has done on the orders it completed;
+	// SinceService is the part of it since a maintenance record was last
+	// closed.
+	Usage        FlightUsage
+	SinceService FlightUsage
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Version      int64
+}
+
+// FlightUsage is time spent in the air and distance flown.
+type FlightUsage struct {
+	Seconds float64
+	Meters  float64
+}
+
+func (u FlightUsage) Add(other FlightUsage) FlightUsage {
+	return FlightUsage{Seconds: u.Seconds + other.Seconds, Meters: u.Meters + other.Meters}
+}
+
+type MaintenanceReason string
+
+const (
+	// MaintenanceReasonBroken records a drone marked BROKEN.
+	MaintenanceReasonBroken MaintenanceReason = "BROKEN"
+	// MaintenanceReasonScheduled records an admin taking a drone into
+	// MAINTENANCE.
+	MaintenanceReasonScheduled MaintenanceReason = "SCHEDULED"
+	// MaintenanceReasonServiceInterval records a drone taken into MAINTENANCE
+	// for flying past its service interval.
+	MaintenanceReasonServiceInterval MaintenanceReason = "SERVICE_INTERVAL"
+)
+
+// MaintenanceRecord covers one spell of a drone being out of service for
+// repair or servicing. It is opened when the drone goes BROKEN or into
+// MAINTENANCE and closed when it leaves both, with the admin's report of the
+// work done when the drone is marked fixed. A drone has at most one open
+// record.
+type MaintenanceRecord struct {
+	ID      string
+	DroneID string
+	Reason  MaintenanceReason
+	// FaultCodes are those of the drone's latest heartbeat when the record
+	// was opened.
+	FaultCodes []string
+	// Usage is the drone's usage when the record was opened.
+	Usage      FlightUsage
+	OpenedAt   time.Time
+	ClosedAt   *time.Time
+	Technician *string
+	Notes      *string
+	Parts      []string
+}
+
+// Open reports whether the record has not been closed yet.
+func (r *MaintenanceRecord) Open() bool {
+	return r.ClosedAt == nil
+}
+
+// MaintenanceReport is the work done on a drone, recorded when it is marked
+// fixed. Every field is optional.
+type MaintenanceReport struct {
+	Technician string
+	Notes      string
+	Parts      []string
+}
+
+// Vitals are the flight and health readings a drone reports with a heartbeat
+// besides its position. Every reading is optional: drones that only report
+// their position leave them nil.
+type Vitals struct {
+	AltitudeMeters *float64
+	// HeadingDegrees is clockwise from true north, in [0, 360).
+	HeadingDegrees *float64
+	GroundSpeedMPS *float64
+	BatteryPercent *float64
+	FaultCodes     []string
+}
+
+// Fault codes that ground a drone: reporting any of them marks it broken.
+const (
+	FaultMotorFailure            = "MOTOR_FAILURE"
+	FaultBatteryCritical         = "BATTERY_CRITICAL"
+	FaultFlightControllerFailure = "FLIGHT_CONTROLLER_FAILURE"
+	FaultGPSLost                 = "GPS_LOST"
+	FaultStructuralDamage        = "STRUCTURAL_DAMAGE"
+)
+
+// IsCriticalFault reports whether code grounds the drone reporting it.
+func IsCriticalFault(code string) bool {
+	switch code {
+	case FaultMotorFailure, FaultBatteryCritical, FaultFlightControllerFailure, FaultGPSLost, FaultStructuralDamage:
+		return true
+	default:
+		return false
+	}
+}
+
+// CriticalFaults returns the codes in v that ground the drone, in order.
+func (v Vitals) CriticalFaults() []string {
+	var critical []string
+	for _, code := range v.FaultCodes {
+		if IsCriticalFault(code) {
+			critical = append(critical, code)
+		}
+	}
+	return critical
+}
+
+// Fix is a position a drone reported and when it reported it.
+type Fix struct {
+	Location Location
+	At       time.Time
+}
+
+// IdempotencyRecord remembers the result of a mutation made under a
+// client-supplied idempotency key so that a retry can be answered with the
+// original result instead of being applied twice. Keys are scoped to the
+// caller (Scope), and RequestHash identifies the request the key was first
+// used for.
+type IdempotencyRecord struct {
+	Scope       string
+	Key         string
+	RequestHash string
+	Response    []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+// ServiceArea is an admin-managed region orders may be placed in. Once any
+// service area exists, order origins and destinations must lie inside an
+// active one.
+type ServiceArea struct {
+	ID        string
+	Name      string
+	Boundary  Polygon
+	Active    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Version   int64
+}
+
+// NoFlyZone is an admin-managed restricted region, e.g. around an airport. A
+// zone with no window is always in force; otherwise it is in force from
+// ActiveFrom (inclusive) until ActiveUntil (exclusive), either end optional.
+type NoFlyZone struct {
+	ID          string
+	Name        string
+	Boundary    Polygon
+	ActiveFrom  *time.Time
+	ActiveUntil *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Version     int64
+}
+
+// ActiveAt reports whether the zone is in force at t.
+func (z *NoFlyZone) ActiveAt(t time.Time) bool {
+	if z.ActiveFrom != nil && t.Before(*z.ActiveFrom) {
+		return false
+	}
+	return z.ActiveUntil == nil || t.Before(*z.ActiveUntil)
+}
+
+// ExpiredAt reports whether the zone's window has ended by t.
+func (z *NoFlyZone) ExpiredAt(t time.Time) bool {
+	return z.ActiveUntil != nil && !t.Before(*z.ActiveUntil)
+}
+
+// Depot is an admin-managed base drones are homed at. Capacity bounds how
+// many drones may call it home.
+type Depot struct {
+	ID        string
+	Name      string
+	Location  Location
+	Capacity  int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Version   int64
+}
+
+// ChargingStation is an admin-managed place drones charge at. It has Slots
+// chargers, numbered from 1.
+type ChargingStation struct {
+	ID        string
+	Name      string
+	Location  Location
+	Slots     int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Version   int64
+}
+
+// ChargingSlot is one charger at a station. DroneID and ReservedAt are set
+// while a drone holds it; a drone holds at most one slot.
+type ChargingSlot struct {
+	StationID  string
+	Number     int
+	DroneID    *string
+	ReservedAt *time.Time
+}
+
+// Free reports whether no drone holds the slot.
+func (s *ChargingSlot) Free() bool {
+	return s.DroneID == nil
+}
+
+// StopKind says what a drone does at a stop.
+type StopKind string
+
+const (
+	StopPickup  StopKind = "pickup"
+	StopDropoff StopKind = "dropoff"
+)
+
+// Stop is a place a drone picks up or drops off an order on its trip.
+type Stop struct {
+	OrderID     string
+	Kind        StopKind
+	Location    Location
+	CompletedAt *time.Time
+}
+
+// TelemetrySample is one heartbeat in a drone's flight history, with the
+// order the drone was carrying out at the time, if any.
+type TelemetrySample struct {
+	DroneID    string
+	OrderID    *string
+	Location   Location
+	RecordedAt time.Time
 }
 
 func IsTerminal(status OrderStatus) bool {
@@ -67,4 +451,3 @@ func IsTerminal(status OrderStatus) bool {
 		return false
 	}
 }
-
@@ -1,6 +1,9 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrNotFound      = errors.New("not found")
@@ -12,3 +15,55 @@ var (
 	ErrPrecondition  = errors.New("precondition failed")
 )
 
+// ErrVersionMismatch is returned when a caller's expected version (If-Match)
+// is stale, or a store update lost an optimistic-concurrency race. It is an
+// ErrPrecondition.
+var ErrVersionMismatch = fmt.Errorf("version mismatch: %w", ErrPrecondition)
+
+
+// ErrIdempotencyKeyReused is returned when an idempotency key is replayed with
+// a request that differs from the one it was first used for. It is an
+// ErrInvalid.
+var ErrIdempotencyKeyReused = fmt.Errorf("idempotency key reused for a different request: %w", ErrInvalid)
+
+// ErrOutsideServiceArea is returned when an order location lies outside every
+// active service area. It is an ErrInvalid; the concrete error is an
+// *OutsideServiceAreaError naming the location.
+var ErrOutsideServiceArea = fmt.Errorf("outside service area: %w", ErrInvalid)
+
+type OutsideServiceAreaError struct {
+	// Field is "origin" or "destination".
+	Field    string
+	Location Location
+}
+
+func (e *OutsideServiceAreaError) Error() string {
+	return fmt.Sprintf("%s (%g, %g) is outside every active service area", e.Field, e.Location.Lat, e.Location.Lng)
+}
+
+func (e *OutsideServiceAreaError) Unwrap() error {
+	return ErrOutsideServiceArea
+}
+
+// ErrRouteBlocked is returned when an order's straight-line route crosses a
+// no-fly zone in force. It is an ErrInvalid; the concrete error is a
+// *RouteBlockedError naming the zone.
+var ErrRouteBlocked = fmt.Errorf("route blocked: %w", ErrInvalid)
+
+type RouteBlockedError struct {
+	ZoneID   string
+	ZoneName string
+}
+
+func (e *RouteBlockedError) Error() string {
+	return fmt.Sprintf("route crosses no-fly zone %q (%s)", e.ZoneName, e.ZoneID)
+}
+
+func (e *RouteBlockedError) Unwrap() error {
+	return ErrRouteBlocked
+}
+
+// ErrOutOfRange is returned when an order is longer than a drone's range and
+// no chain of relay points within range links its origin to its destination.
+// It is an ErrInvalid.
+var ErrOutOfRange = fmt.Errorf("out of range: %w", ErrInvalid)
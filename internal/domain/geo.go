@@ -0,0 +1,362 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+)
+
+const earthRadiusMeters = 6371000.0
+
+// DistanceMeters is the great-circle (haversine) distance between a and b.
+func DistanceMeters(a, b Location) float64 {
+	lat1 := degreesToRadians(a.Lat)
+	lat2 := degreesToRadians(b.Lat)
+	dLat := degreesToRadians(b.Lat - a.Lat)
+	dLng := degreesToRadians(b.Lng - a.Lng)
+
+	sinLat := math.Sin(dLat / 2)
+	sinLng := math.Sin(dLng / 2)
+
+	h := sinLat*sinLat + math.Cos(lat1)*math.Cos(lat2)*sinLng*sinLng
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// BoundingBoxAround returns a box containing every point within radiusMeters
+// of center. It wraps across the antimeridian when needed and widens to every
+// longitude when the circle reaches a pole.
+func BoundingBoxAround(center Location, radiusMeters float64) BoundingBox {
+	dLat := radiansToDegrees(radiusMeters / earthRadiusMeters)
+	box := BoundingBox{
+		MinLat: math.Max(-90, center.Lat-dLat),
+		MaxLat: math.Min(90, center.Lat+dLat),
+		MinLng: -180,
+		MaxLng: 180,
+	}
+	if box.MinLat == -90 || box.MaxLat == 90 {
+		return box
+	}
+	// The widest longitude span of the circle is at the latitude where a
+	// meridian is tangent to it.
+	ratio := math.Sin(radiusMeters/earthRadiusMeters) / math.Cos(degreesToRadians(center.Lat))
+	if ratio >= 1 {
+		return box
+	}
+	dLng := radiansToDegrees(math.Asin(ratio))
+	box.MinLng = normalizeLng(center.Lng - dLng)
+	box.MaxLng = normalizeLng(center.Lng + dLng)
+	return box
+}
+
+func normalizeLng(lng float64) float64 {
+	if lng < -180 {
+		return lng + 360
+	}
+	if lng > 180 {
+		return lng - 360
+	}
+	return lng
+}
+
+func degreesToRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func radiansToDegrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
+
+// Polygon is a GeoJSON-style polygon: the first ring is the exterior and any
+// further rings are holes. Each ring is closed (its last point repeats the
+// first). Edges are straight lines in lat/lng space; a ring may cross the
+// antimeridian but must not enclose a pole.
+type Polygon [][]Location
+
+// Contains reports whether loc lies inside the exterior ring and outside every
+// hole. Points on any edge, a hole's included, count as inside.
+func (p Polygon) Contains(loc Location) bool {
+	if len(p) == 0 || !ringContains(p[0], loc) {
+		return false
+	}
+	for _, hole := range p[1:] {
+		if ringContains(hole, loc) && !onRingEdge(hole, loc) {
+			return false
+		}
+	}
+	return true
+}
+
+// GeoJSONCoordinates returns p as GeoJSON Polygon coordinates, i.e. rings of
+// [lng, lat] positions.
+func (p Polygon) GeoJSONCoordinates() [][][]float64 {
+	coords := make([][][]float64, 0, len(p))
+	for _, ring := range p {
+		positions := make([][]float64, 0, len(ring))
+		for _, loc := range ring {
+			positions = append(positions, []float64{loc.Lng, loc.Lat})
+		}
+		coords = append(coords, positions)
+	}
+	return coords
+}
+
+// PolygonFromGeoJSON converts GeoJSON Polygon coordinates. Positions need at
+// least a longitude and a latitude; any altitude is ignored. The result is
+// not validated; see ValidatePolygon.
+func PolygonFromGeoJSON(coords [][][]float64) (Polygon, error) {
+	polygon := make(Polygon, 0, len(coords))
+	for _, positions := range coords {
+		ring := make([]Location, 0, len(positions))
+		for _, pos := range positions {
+			if len(pos) < 2 {
+				return nil, fmt.Errorf("position needs longitude and latitude")
+			}
+			ring = append(ring, Location{Lat: pos[1], Lng: pos[0]})
+		}
+		polygon = append(polygon, ring)
+	}
+	return polygon, nil
+}
+
+// CrossesPath reports whether the great-circle path from a to b enters or
+// touches p. A path that stays inside a hole does not cross p.
+func (p Polygon) CrossesPath(a, b Location) bool {
+	if len(p) == 0 {
+		return false
+	}
+	if p.Contains(a) || p.Contains(b) {
+		return true
+	}
+	// With neither end inside, the path can only reach p by crossing one of
+	// its edges.
+	path := unwrapRing(greatCirclePoints(a, b, pathStepMeters))
+	for _, ring := range p {
+		points := unwrapRing(ring)
+		for _, shift := range []float64{0, 360, -360} {
+			for i := 1; i < len(path); i++ {
+				from := Location{Lat: path[i-1].Lat, Lng: path[i-1].Lng + shift}
+				to := Location{Lat: path[i].Lat, Lng: path[i].Lng + shift}
+				for j := 1; j < len(points); j++ {
+					if segmentsIntersect(from, to, points[j-1], points[j]) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// pathStepMeters is the spacing of the points a great-circle path is
+// approximated by; over this length it strays from a straight lat/lng line by
+// a few metres at most.
+const pathStepMeters = 10_000
+
+// greatCirclePoints returns points along the great circle from a to b, both
+// included, no more than step metres apart. Antipodal points have no unique
+// great circle and yield just a and b.
+func greatCirclePoints(a, b Location, step float64) []Location {
+	angle := DistanceMeters(a, b) / earthRadiusMeters
+	sinAngle := math.Sin(angle)
+	if angle == 0 || sinAngle < 1e-12 {
+		return []Location{a, b}
+	}
+	lat1, lng1 := degreesToRadians(a.Lat), degreesToRadians(a.Lng)
+	lat2, lng2 := degreesToRadians(b.Lat), degreesToRadians(b.Lng)
+	n := int(math.Ceil(angle * earthRadiusMeters / step))
+	points := make([]Location, 0, n+1)
+	points = append(points, a)
+	for i := 1; i < n; i++ {
+		f := float64(i) / float64(n)
+		wa := math.Sin((1-f)*angle) / sinAngle
+		wb := math.Sin(f*angle) / sinAngle
+		x := wa*math.Cos(lat1)*math.Cos(lng1) + wb*math.Cos(lat2)*math.Cos(lng2)
+		y := wa*math.Cos(lat1)*math.Sin(lng1) + wb*math.Cos(lat2)*math.Sin(lng2)
+		z := wa*math.Sin(lat1) + wb*math.Sin(lat2)
+		points = append(points, Location{
+			Lat: radiansToDegrees(math.Atan2(z, math.Hypot(x, y))),
+			Lng: radiansToDegrees(math.Atan2(y, x)),
+		})
+	}
+	return append(points, b)
+}
+
+// Waypoints returns a point just outside each corner of p's exterior ring,
+// bufferMeters away from the corner along the bisector of its edges. Paths
+// around p can bend at these points without touching it. Points at reflex
+// corners may land inside p; callers should drop those.
+func (p Polygon) Waypoints(bufferMeters float64) []Location {
+	if len(p) == 0 || len(p[0]) < 4 {
+		return nil
+	}
+	points := unwrapRing(p[0])
+	points = points[:len(points)-1]
+	// Work in a local plane scaled to metres so the bisector is not skewed by
+	// longitude convergence.
+	cosLat := math.Cos(degreesToRadians(points[0].Lat))
+	orientation := 0.0
+	for i := range points {
+		a, b := points[i], points[(i+1)%len(points)]
+		orientation += (b.Lng - a.Lng) * cosLat * (b.Lat + a.Lat)
+	}
+	// orientation > 0 means the ring is clockwise, so the outward normal of an
+	// edge is on its left.
+	outward := 1.0
+	if orientation < 0 {
+		outward = -1
+	}
+	dLat := radiansToDegrees(bufferMeters / earthRadiusMeters)
+	waypoints := make([]Location, 0, len(points))
+	for i, corner := range points {
+		prev := points[(i+len(points)-1)%len(points)]
+		next := points[(i+1)%len(points)]
+		// Outward normals of the edges prev→corner and corner→next.
+		n1x, n1y := unitNormal(prev, corner, cosLat, outward)
+		n2x, n2y := unitNormal(corner, next, cosLat, outward)
+		x, y := n1x+n2x, n1y+n2y
+		length := math.Hypot(x, y)
+		if length < geoEpsilon {
+			continue
+		}
+		cornerCos := math.Cos(degreesToRadians(corner.Lat))
+		if cornerCos < geoEpsilon {
+			continue
+		}
+		waypoints = append(waypoints, Location{
+			Lat: corner.Lat + dLat*y/length,
+			Lng: normalizeLng(corner.Lng + dLat*x/length/cornerCos),
+		})
+	}
+	return waypoints
+}
+
+// unitNormal returns the unit normal of a→b in the local plane, on the left
+// of the edge when side is 1 and on the right when it is -1.
+func unitNormal(a, b Location, cosLat, side float64) (float64, float64) {
+	dx := (b.Lng - a.Lng) * cosLat
+	dy := b.Lat - a.Lat
+	length := math.Hypot(dx, dy)
+	if length < geoEpsilon {
+		return 0, 0
+	}
+	return -dy / length * side, dx / length * side
+}
+
+// PathLengthMeters is the great-circle length of the path through points.
+func PathLengthMeters(points []Location) float64 {
+	total := 0.0
+	for i := 1; i < len(points); i++ {
+		total += DistanceMeters(points[i-1], points[i])
+	}
+	return total
+}
+
+// LineStringCoordinates returns points as GeoJSON LineString coordinates.
+func LineStringCoordinates(points []Location) [][]float64 {
+	coords := make([][]float64, 0, len(points))
+	for _, loc := range points {
+		coords = append(coords, []float64{loc.Lng, loc.Lat})
+	}
+	return coords
+}
+
+// LineStringFromCoordinates converts GeoJSON LineString coordinates.
+func LineStringFromCoordinates(coords [][]float64) ([]Location, error) {
+	points := make([]Location, 0, len(coords))
+	for _, pos := range coords {
+		if len(pos) < 2 {
+			return nil, fmt.Errorf("position needs longitude and latitude")
+		}
+		points = append(points, Location{Lat: pos[1], Lng: pos[0]})
+	}
+	return points, nil
+}
+
+// ringContains runs an even-odd ray cast on the ring with its longitudes
+// unwrapped, so that a ring crossing the antimeridian is contiguous, and tries
+// the point at its own longitude and one turn either side.
+func ringContains(ring []Location, loc Location) bool {
+	points := unwrapRing(ring)
+	for _, shift := range []float64{0, 360, -360} {
+		if pointInRing(points, Location{Lat: loc.Lat, Lng: loc.Lng + shift}) {
+			return true
+		}
+	}
+	return false
+}
+
+func onRingEdge(ring []Location, loc Location) bool {
+	points := unwrapRing(ring)
+	for _, shift := range []float64{0, 360, -360} {
+		p := Location{Lat: loc.Lat, Lng: loc.Lng + shift}
+		for i := 1; i < len(points); i++ {
+			if onSegment(points[i-1], points[i], p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func pointInRing(points []Location, p Location) bool {
+	inside := false
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		if onSegment(a, b, p) {
+			return true
+		}
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) {
+			x := a.Lng + (p.Lat-a.Lat)*(b.Lng-a.Lng)/(b.Lat-a.Lat)
+			if p.Lng < x {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+const geoEpsilon = 1e-9
+
+// cross is the z component of (b-a)×(p-a) in lng/lat space: positive when p
+// is left of the line a→b.
+func cross(a, b, p Location) float64 {
+	return (b.Lng-a.Lng)*(p.Lat-a.Lat) - (b.Lat-a.Lat)*(p.Lng-a.Lng)
+}
+
+func onSegment(a, b, p Location) bool {
+	if math.Abs(cross(a, b, p)) > geoEpsilon {
+		return false
+	}
+	return p.Lng >= math.Min(a.Lng, b.Lng)-geoEpsilon && p.Lng <= math.Max(a.Lng, b.Lng)+geoEpsilon &&
+		p.Lat >= math.Min(a.Lat, b.Lat)-geoEpsilon && p.Lat <= math.Max(a.Lat, b.Lat)+geoEpsilon
+}
+
+// segmentsIntersect reports whether segments p1p2 and q1q2 share a point,
+// touching included.
+func segmentsIntersect(p1, p2, q1, q2 Location) bool {
+	d1, d2 := cross(q1, q2, p1), cross(q1, q2, p2)
+	d3, d4 := cross(p1, p2, q1), cross(p1, p2, q2)
+	if straddles(d1, d2) && straddles(d3, d4) {
+		return true
+	}
+	return onSegment(q1, q2, p1) || onSegment(q1, q2, p2) || onSegment(p1, p2, q1) || onSegment(p1, p2, q2)
+}
+
+func straddles(d1, d2 float64) bool {
+	return (d1 > geoEpsilon && d2 < -geoEpsilon) || (d1 < -geoEpsilon && d2 > geoEpsilon)
+}
+
+// unwrapRing shifts longitudes by whole turns so that consecutive points are
+// never more than 180° apart.
+func unwrapRing(ring []Location) []Location {
+	points := make([]Location, len(ring))
+	copy(points, ring)
+	for i := 1; i < len(points); i++ {
+		for points[i].Lng-points[i-1].Lng > 180 {
+			points[i].Lng -= 360
+		}
+		for points[i].Lng-points[i-1].Lng < -180 {
+			points[i].Lng += 360
+		}
+	}
+	return points
+}
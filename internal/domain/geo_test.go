@@ -0,0 +1,200 @@
+package domain_test
+
+import (
+	"testing"
+
+	"penny-assesment/internal/domain"
+)
+
+func ring(points ...[2]float64) []domain.Location {
+	locs := make([]domain.Location, 0, len(points))
+	for _, p := range points {
+		locs = append(locs, domain.Location{Lat: p[0], Lng: p[1]})
+	}
+	return locs
+}
+
+func TestPolygonContainsConcave(t *testing.T) {
+	// A "U" opening north: the notch between the arms is outside.
+	u := domain.Polygon{ring(
+		[2]float64{0, 0}, [2]float64{0, 3}, [2]float64{3, 3}, [2]float64{3, 2},
+		[2]float64{1, 2}, [2]float64{1, 1}, [2]float64{3, 1}, [2]float64{3, 0}, [2]float64{0, 0},
+	)}
+	if err := domain.ValidatePolygon(u); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	cases := []struct {
+		name string
+		loc  domain.Location
+		want bool
+	}{
+		{"base", domain.Location{Lat: 0.5, Lng: 1.5}, true},
+		{"west arm", domain.Location{Lat: 2, Lng: 0.5}, true},
+		{"east arm", domain.Location{Lat: 2, Lng: 2.5}, true},
+		{"notch", domain.Location{Lat: 2, Lng: 1.5}, false},
+		{"ray through notch vertices", domain.Location{Lat: 1, Lng: -1}, false},
+		{"on notch edge", domain.Location{Lat: 1, Lng: 1.5}, true},
+		{"vertex", domain.Location{Lat: 3, Lng: 3}, true},
+		{"outside", domain.Location{Lat: 4, Lng: 1.5}, false},
+	}
+	for _, tc := range cases {
+		if got := u.Contains(tc.loc); got != tc.want {
+			t.Errorf("%s: Contains(%v) = %v, want %v", tc.name, tc.loc, got, tc.want)
+		}
+	}
+}
+
+func TestPolygonContainsHole(t *testing.T) {
+	p := domain.Polygon{
+		ring([2]float64{0, 0}, [2]float64{0, 10}, [2]float64{10, 10}, [2]float64{10, 0}, [2]float64{0, 0}),
+		ring([2]float64{4, 4}, [2]float64{4, 6}, [2]float64{6, 6}, [2]float64{6, 4}, [2]float64{4, 4}),
+	}
+	if p.Contains(domain.Location{Lat: 5, Lng: 5}) {
+		t.Fatal("expected point in hole to be outside")
+	}
+	if !p.Contains(domain.Location{Lat: 2, Lng: 5}) {
+		t.Fatal("expected point between rings to be inside")
+	}
+	if !p.Contains(domain.Location{Lat: 4, Lng: 5}) {
+		t.Fatal("expected point on hole edge to be inside")
+	}
+}
+
+func TestPolygonContainsAcrossAntimeridian(t *testing.T) {
+	// Fiji-like box from 177°E to 178°W, written with raw GeoJSON longitudes.
+	p := domain.Polygon{ring(
+		[2]float64{-20, 177}, [2]float64{-20, -178}, [2]float64{-15, -178}, [2]float64{-15, 177}, [2]float64{-20, 177},
+	)}
+	if err := domain.ValidatePolygon(p); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	cases := []struct {
+		name string
+		loc  domain.Location
+		want bool
+	}{
+		{"east of antimeridian", domain.Location{Lat: -17, Lng: 179}, true},
+		{"west of antimeridian", domain.Location{Lat: -17, Lng: -179}, true},
+		{"on antimeridian", domain.Location{Lat: -17, Lng: 180}, true},
+		{"on antimeridian negative", domain.Location{Lat: -17, Lng: -180}, true},
+		{"west of box", domain.Location{Lat: -17, Lng: 176}, false},
+		{"east of box", domain.Location{Lat: -17, Lng: -177}, false},
+		{"far side of globe", domain.Location{Lat: -17, Lng: 0}, false},
+	}
+	for _, tc := range cases {
+		if got := p.Contains(tc.loc); got != tc.want {
+			t.Errorf("%s: Contains(%v) = %v, want %v", tc.name, tc.loc, got, tc.want)
+		}
+	}
+}
+
+func TestValidatePolygon(t *testing.T) {
+	cases := []struct {
+		name string
+		p    domain.Polygon
+	}{
+		{"no rings", domain.Polygon{}},
+		{"too few positions", domain.Polygon{ring([2]float64{0, 0}, [2]float64{0, 1}, [2]float64{0, 0})}},
+		{"not closed", domain.Polygon{ring([2]float64{0, 0}, [2]float64{0, 1}, [2]float64{1, 1}, [2]float64{1, 0})}},
+		{"out of range", domain.Polygon{ring([2]float64{0, 0}, [2]float64{0, 181}, [2]float64{1, 1}, [2]float64{0, 0})}},
+		{"encloses pole", domain.Polygon{ring(
+			[2]float64{80, 0}, [2]float64{80, 120}, [2]float64{80, -120}, [2]float64{80, 0},
+		)}},
+	}
+	for _, tc := range cases {
+		if err := domain.ValidatePolygon(tc.p); err == nil {
+			t.Errorf("%s: expected an error", tc.name)
+		}
+	}
+}
+
+func TestPolygonGeoJSONRoundTrip(t *testing.T) {
+	coords := [][][]float64{{{46.6, 24.6}, {46.8, 24.6}, {46.8, 24.8, 612}, {46.6, 24.6}}}
+	p, err := domain.PolygonFromGeoJSON(coords)
+	if err != nil {
+		t.Fatalf("from geojson: %v", err)
+	}
+	if p[0][1] != (domain.Location{Lat: 24.6, Lng: 46.8}) {
+		t.Fatalf("expected [lng, lat] positions, got %v", p[0][1])
+	}
+	back := p.GeoJSONCoordinates()
+	if len(back[0][2]) != 2 || back[0][2][0] != 46.8 || back[0][2][1] != 24.8 {
+		t.Fatalf("unexpected coordinates %v", back)
+	}
+	if _, err := domain.PolygonFromGeoJSON([][][]float64{{{46.6}}}); err == nil {
+		t.Fatal("expected a short position to be rejected")
+	}
+}
+
+func TestPolygonCrossesPath(t *testing.T) {
+	// A small zone straddling the equator between two points 1° apart.
+	zone := domain.Polygon{ring(
+		[2]float64{-0.1, 0.4}, [2]float64{-0.1, 0.6}, [2]float64{0.1, 0.6}, [2]float64{0.1, 0.4}, [2]float64{-0.1, 0.4},
+	)}
+	// The same zone across the antimeridian.
+	dateline := domain.Polygon{ring(
+		[2]float64{-0.1, 179.9}, [2]float64{-0.1, -179.9}, [2]float64{0.1, -179.9}, [2]float64{0.1, 179.9}, [2]float64{-0.1, 179.9},
+	)}
+	cases := []struct {
+		name    string
+		polygon domain.Polygon
+		a, b    domain.Location
+		want    bool
+	}{
+		{"through", zone, domain.Location{Lat: 0, Lng: 0}, domain.Location{Lat: 0, Lng: 1}, true},
+		{"passes north", zone, domain.Location{Lat: 0.2, Lng: 0}, domain.Location{Lat: 0.2, Lng: 1}, false},
+		{"ends inside", zone, domain.Location{Lat: 1, Lng: 0.5}, domain.Location{Lat: 0, Lng: 0.5}, true},
+		{"short of zone", zone, domain.Location{Lat: 0, Lng: 0}, domain.Location{Lat: 0, Lng: 0.3}, false},
+		{"across antimeridian", dateline, domain.Location{Lat: 0, Lng: 179.5}, domain.Location{Lat: 0, Lng: -179.5}, true},
+		{"antimeridian miss", dateline, domain.Location{Lat: 1, Lng: 179.5}, domain.Location{Lat: 1, Lng: -179.5}, false},
+	}
+	for _, tc := range cases {
+		if got := tc.polygon.CrossesPath(tc.a, tc.b); got != tc.want {
+			t.Errorf("%s: CrossesPath(%v, %v) = %v, want %v", tc.name, tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestPolygonCrossesPathFollowsGreatCircle(t *testing.T) {
+	// At 60°N the great circle between two points 40° of longitude apart
+	// bulges about 1.5° north of the parallel they share, so it misses a zone
+	// sitting on the parallel and crosses one to the north.
+	a := domain.Location{Lat: 60, Lng: -20}
+	b := domain.Location{Lat: 60, Lng: 20}
+	onParallel := domain.Polygon{ring(
+		[2]float64{59.5, -1}, [2]float64{59.5, 1}, [2]float64{60.5, 1}, [2]float64{60.5, -1}, [2]float64{59.5, -1},
+	)}
+	north := domain.Polygon{ring(
+		[2]float64{61, -1}, [2]float64{61, 1}, [2]float64{62, 1}, [2]float64{62, -1}, [2]float64{61, -1},
+	)}
+	if onParallel.CrossesPath(a, b) {
+		t.Error("expected great-circle path to pass north of a zone on the parallel")
+	}
+	if !north.CrossesPath(a, b) {
+		t.Error("expected great-circle path to cross a zone north of the parallel")
+	}
+}
+
+func TestPolygonWaypoints(t *testing.T) {
+	// The same square wound both ways; waypoints must land outside either way.
+	clockwise := domain.Polygon{ring(
+		[2]float64{-0.1, 0.4}, [2]float64{0.1, 0.4}, [2]float64{0.1, 0.6}, [2]float64{-0.1, 0.6}, [2]float64{-0.1, 0.4},
+	)}
+	counter := domain.Polygon{ring(
+		[2]float64{-0.1, 0.4}, [2]float64{-0.1, 0.6}, [2]float64{0.1, 0.6}, [2]float64{0.1, 0.4}, [2]float64{-0.1, 0.4},
+	)}
+	for name, polygon := range map[string]domain.Polygon{"clockwise": clockwise, "counter-clockwise": counter} {
+		waypoints := polygon.Waypoints(200)
+		if len(waypoints) != 4 {
+			t.Fatalf("%s: expected a waypoint per corner, got %v", name, waypoints)
+		}
+		for i, waypoint := range waypoints {
+			if polygon.Contains(waypoint) {
+				t.Errorf("%s: waypoint %v is inside the polygon", name, waypoint)
+			}
+			if d := domain.DistanceMeters(waypoint, polygon[0][i]); d < 190 || d > 210 {
+				t.Errorf("%s: waypoint %v is %.0fm from its corner, want 200m", name, waypoint, d)
+			}
+		}
+	}
+}
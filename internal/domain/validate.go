@@ -1,6 +1,9 @@
 package domain
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+)
 
 func ValidateLocation(loc Location) error {
 	if loc.Lat < -90 || loc.Lat > 90 {
@@ -12,6 +15,49 @@ func ValidateLocation(loc Location) error {
 	return nil
 }
 
+// Heartbeat reading limits.
+const (
+	MinAltitudeMeters  = -500
+	MaxAltitudeMeters  = 10_000
+	MaxGroundSpeedMPS  = 150
+	MaxFaultCodes      = 32
+	MaxFaultCodeLength = 64
+)
+
+// ValidateVitals checks that every reading present is a number in range and
+// that the fault codes are few, non-empty and short.
+func ValidateVitals(v Vitals) error {
+	readings := []struct {
+		name     string
+		value    *float64
+		min, max float64
+		maxOpen  bool
+	}{
+		{"altitude", v.AltitudeMeters, MinAltitudeMeters, MaxAltitudeMeters, false},
+		{"heading", v.HeadingDegrees, 0, 360, true},
+		{"ground speed", v.GroundSpeedMPS, 0, MaxGroundSpeedMPS, false},
+		{"battery", v.BatteryPercent, 0, 100, false},
+	}
+	for _, r := range readings {
+		if r.value == nil {
+			continue
+		}
+		x := *r.value
+		if math.IsNaN(x) || x < r.min || x > r.max || (r.maxOpen && x == r.max) {
+			return fmt.Errorf("%s out of range", r.name)
+		}
+	}
+	if len(v.FaultCodes) > MaxFaultCodes {
+		return fmt.Errorf("more than %d fault codes", MaxFaultCodes)
+	}
+	for _, code := range v.FaultCodes {
+		if code == "" || len(code) > MaxFaultCodeLength {
+			return fmt.Errorf("fault code %q invalid", code)
+		}
+	}
+	return nil
+}
+
 func ValidateRole(role string) bool {
 	switch role {
 	case RoleAdmin, RoleEndUser, RoleDrone:
@@ -21,3 +67,85 @@ func ValidateRole(role string) bool {
 	}
 }
 
+func ValidateOrderStatus(status OrderStatus) bool {
+	switch status {
+	case OrderStatusCreated, OrderStatusReserved, OrderStatusPickedUp, OrderStatusHandoffRequested,
+		OrderStatusDelivered, OrderStatusFailed, OrderStatusWithdrawn:
+		return true
+	default:
+		return false
+	}
+}
+
+// Maintenance report limits.
+const (
+	MaxTechnicianLength       = 128
+	MaxMaintenanceNotesLength = 4_000
+	MaxMaintenanceParts       = 64
+	MaxPartLength             = 128
+)
+
+// ValidateMaintenanceReport checks the lengths of the report's fields.
+func ValidateMaintenanceReport(r MaintenanceReport) error {
+	if len(r.Technician) > MaxTechnicianLength {
+		return fmt.Errorf("technician longer than %d characters", MaxTechnicianLength)
+	}
+	if len(r.Notes) > MaxMaintenanceNotesLength {
+		return fmt.Errorf("notes longer than %d characters", MaxMaintenanceNotesLength)
+	}
+	if len(r.Parts) > MaxMaintenanceParts {
+		return fmt.Errorf("more than %d parts", MaxMaintenanceParts)
+	}
+	for _, part := range r.Parts {
+		if part == "" || len(part) > MaxPartLength {
+			return fmt.Errorf("part %q invalid", part)
+		}
+	}
+	return nil
+}
+
+func ValidateDroneStatus(status DroneStatus) bool {
+	switch status {
+	case DroneStatusActive, DroneStatusBroken, DroneStatusCharging, DroneStatusMaintenance,
+		DroneStatusOffline, DroneStatusRetired:
+		return true
+	default:
+		return false
+	}
+}
+
+func ValidateBoundingBox(box BoundingBox) error {
+	if box.MinLat < -90 || box.MaxLat > 90 || box.MinLat > box.MaxLat {
+		return fmt.Errorf("lat range invalid")
+	}
+	if box.MinLng < -180 || box.MinLng > 180 || box.MaxLng < -180 || box.MaxLng > 180 {
+		return fmt.Errorf("lng out of range")
+	}
+	return nil
+}
+
+// ValidatePolygon checks that p has an exterior ring, that every ring is
+// closed with at least four positions of valid coordinates, and that no ring
+// winds around a pole.
+func ValidatePolygon(p Polygon) error {
+	if len(p) == 0 {
+		return fmt.Errorf("polygon has no rings")
+	}
+	for i, ring := range p {
+		if len(ring) < 4 {
+			return fmt.Errorf("ring %d needs at least 4 positions", i)
+		}
+		for _, loc := range ring {
+			if err := ValidateLocation(loc); err != nil {
+				return fmt.Errorf("ring %d: %w", i, err)
+			}
+		}
+		if ring[0] != ring[len(ring)-1] {
+			return fmt.Errorf("ring %d is not closed", i)
+		}
+		if points := unwrapRing(ring); points[0].Lng != points[len(points)-1].Lng {
+			return fmt.Errorf("ring %d encloses a pole", i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package domain_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"penny-assesment/internal/domain"
+)
+
+func TestValidateVitals(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	cases := []struct {
+		name   string
+		vitals domain.Vitals
+		ok     bool
+	}{
+		{"none reported", domain.Vitals{}, true},
+		{"all in range", domain.Vitals{AltitudeMeters: f(120), HeadingDegrees: f(359.9), GroundSpeedMPS: f(0), BatteryPercent: f(100), FaultCodes: []string{"LOW_SIGNAL"}}, true},
+		{"below sea level", domain.Vitals{AltitudeMeters: f(-20)}, true},
+		{"too high", domain.Vitals{AltitudeMeters: f(10_001)}, false},
+		{"heading wraps", domain.Vitals{HeadingDegrees: f(360)}, false},
+		{"negative heading", domain.Vitals{HeadingDegrees: f(-1)}, false},
+		{"negative speed", domain.Vitals{GroundSpeedMPS: f(-0.1)}, false},
+		{"battery over full", domain.Vitals{BatteryPercent: f(100.5)}, false},
+		{"NaN battery", domain.Vitals{BatteryPercent: f(math.NaN())}, false},
+		{"empty fault code", domain.Vitals{FaultCodes: []string{""}}, false},
+		{"long fault code", domain.Vitals{FaultCodes: []string{strings.Repeat("X", domain.MaxFaultCodeLength+1)}}, false},
+		{"too many fault codes", domain.Vitals{FaultCodes: make([]string, domain.MaxFaultCodes+1)}, false},
+	}
+	for _, tc := range cases {
+		err := domain.ValidateVitals(tc.vitals)
+		if (err == nil) != tc.ok {
+			t.Errorf("%s: expected ok=%v, got %v", tc.name, tc.ok, err)
+		}
+	}
+
+	v := domain.Vitals{FaultCodes: []string{"LOW_SIGNAL", domain.FaultGPSLost, "CAMERA_FAULT", domain.FaultMotorFailure}}
+	if got := v.CriticalFaults(); len(got) != 2 || got[0] != domain.FaultGPSLost || got[1] != domain.FaultMotorFailure {
+		t.Fatalf("expected the critical codes in order, got %v", got)
+	}
+}
+
+func TestValidateMaintenanceReport(t *testing.T) {
+	cases := []struct {
+		name   string
+		report domain.MaintenanceReport
+		ok     bool
+	}{
+		{"empty", domain.MaintenanceReport{}, true},
+		{"full", domain.MaintenanceReport{Technician: "Sam", Notes: "replaced rotor", Parts: []string{"rotor", "esc"}}, true},
+		{"long technician", domain.MaintenanceReport{Technician: strings.Repeat("x", domain.MaxTechnicianLength+1)}, false},
+		{"long notes", domain.MaintenanceReport{Notes: strings.Repeat("x", domain.MaxMaintenanceNotesLength+1)}, false},
+		{"empty part", domain.MaintenanceReport{Parts: []string{""}}, false},
+		{"long part", domain.MaintenanceReport{Parts: []string{strings.Repeat("x", domain.MaxPartLength+1)}}, false},
+		{"too many parts", domain.MaintenanceReport{Parts: make([]string, domain.MaxMaintenanceParts+1)}, false},
+	}
+	for _, tc := range cases {
+		err := domain.ValidateMaintenanceReport(tc.report)
+		if (err == nil) != tc.ok {
+			t.Errorf("%s: expected ok=%v, got %v", tc.name, tc.ok, err)
+		}
+	}
+}
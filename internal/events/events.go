@@ -10,8 +10,9 @@ import (
 )
 
 const (
-	AggregateOrder = "order"
-	AggregateDrone = "drone"
+	AggregateOrder           = "order"
+	AggregateDrone           = "drone"
+	AggregateChargingStation = "charging_station"
 )
 
 const (
@@ -23,8 +24,18 @@ const (
 	EventOrderHandoffRequested = "order.handoff_requested"
 	EventOrderWithdrawn        = "order.withdrawn"
 	EventOrderUpdated          = "order.updated"
+	EventOrderAssigned         = "order.assigned"
+	EventOrderUnassigned       = "order.unassigned"
+	EventOrderReassigned       = "order.reassigned"
+	EventOrderAdminOverride    = "order.admin_override"
+	EventOrderRouteBlocked     = "order.route_blocked"
+	EventOrderSLAAtRisk        = "order.sla_at_risk"
 	EventDroneBroken           = "drone.broken"
 	EventDroneFixed            = "drone.fixed"
+	EventDroneStatusChanged    = "drone.status_changed"
+	EventDroneRetired          = "drone.retired"
+	EventChargingSlotReserved  = "charging.slot_reserved"
+	EventChargingSlotReleased  = "charging.slot_released"
 )
 
 type Event struct {
@@ -62,12 +73,79 @@ func NewOrderEvent(eventType string, order *domain.Order, drone *domain.Drone, o
 	return NewEvent(eventType, AggregateOrder, order.ID, payload, occurredAt)
 }
 
+// NewOrderOverrideEvent records an admin forcing an order into a new status.
+// The actor, previous status and reason are kept in the payload as the audit trail.
+func NewOrderOverrideEvent(order *domain.Order, drone *domain.Drone, actor string, previous domain.OrderStatus, reason string, occurredAt time.Time) Event {
+	payload := map[string]any{
+		"order_id":        order.ID,
+		"status":          order.Status,
+		"previous_status": previous,
+		"user_id":         order.UserID,
+		"drone_id":        order.AssignedDroneID,
+		"actor":           actor,
+		"reason":          reason,
+		"occurred_at":     occurredAt,
+	}
+	if drone != nil {
+		payload["released_drone_id"] = drone.ID
+		payload["drone_status"] = drone.Status
+	}
+	return NewEvent(EventOrderAdminOverride, AggregateOrder, order.ID, payload, occurredAt)
+}
+
+// NewRouteBlockedEvent reports that a no-fly zone, added or changed by an
+// admin, crosses the route of an open order. The order itself is unchanged.
+func NewRouteBlockedEvent(order *domain.Order, zone *domain.NoFlyZone, occurredAt time.Time) Event {
+	payload := map[string]any{
+		"order_id":     order.ID,
+		"status":       order.Status,
+		"user_id":      order.UserID,
+		"drone_id":     order.AssignedDroneID,
+		"zone_id":      zone.ID,
+		"zone_name":    zone.Name,
+		"active_from":  zone.ActiveFrom,
+		"active_until": zone.ActiveUntil,
+		"occurred_at":  occurredAt,
+	}
+	return NewEvent(EventOrderRouteBlocked, AggregateOrder, order.ID, payload, occurredAt)
+}
+
+// NewSLAAtRiskEvent reports that an order's ETA would deliver it at
+// estimatedAt, after its deliver_by.
+func NewSLAAtRiskEvent(order *domain.Order, estimatedAt, occurredAt time.Time) Event {
+	payload := map[string]any{
+		"order_id":              order.ID,
+		"status":                order.Status,
+		"user_id":               order.UserID,
+		"drone_id":              order.AssignedDroneID,
+		"deliver_by":            order.DeliverBy,
+		"estimated_delivery_at": estimatedAt,
+		"occurred_at":           occurredAt,
+	}
+	return NewEvent(EventOrderSLAAtRisk, AggregateOrder, order.ID, payload, occurredAt)
+}
+
 func NewDroneEvent(eventType string, drone *domain.Drone, occurredAt time.Time) Event {
 	payload := map[string]any{
 		"drone_id":    drone.ID,
 		"status":      drone.Status,
 		"occurred_at": occurredAt,
 	}
+	if len(drone.Vitals.FaultCodes) > 0 {
+		payload["fault_codes"] = drone.Vitals.FaultCodes
+	}
 	return NewEvent(eventType, AggregateDrone, drone.ID, payload, occurredAt)
 }
 
+// NewChargingSlotEvent records a drone taking or giving up a charging slot.
+// droneID is passed separately because a released slot no longer names it.
+func NewChargingSlotEvent(eventType string, slot *domain.ChargingSlot, droneID string, occurredAt time.Time) Event {
+	payload := map[string]any{
+		"station_id":  slot.StationID,
+		"slot":        slot.Number,
+		"drone_id":    droneID,
+		"occurred_at": occurredAt,
+	}
+	return NewEvent(eventType, AggregateChargingStation, slot.StationID, payload, occurredAt)
+}
+
@@ -0,0 +1,261 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"penny-assesment/internal/domain"
+)
+
+func (s *Store) GetChargingStation(ctx context.Context, id string) (*domain.ChargingStation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	station, ok := s.stations[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneChargingStation(station), nil
+}
+
+func (s *Store) ListChargingStations(ctx context.Context) ([]*domain.ChargingStation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stations := make([]*domain.ChargingStation, 0, len(s.stations))
+	for _, station := range s.stations {
+		stations = append(stations, cloneChargingStation(station))
+	}
+	sort.Slice(stations, func(i, j int) bool { return stations[i].ID < stations[j].ID })
+	return stations, nil
+}
+
+func (s *Store) ListChargingSlots(ctx context.Context, stationID string) ([]*domain.ChargingSlot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var slots []*domain.ChargingSlot
+	for _, slot := range s.slots {
+		if slot.StationID == stationID {
+			slots = append(slots, cloneChargingSlot(slot))
+		}
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i].Number < slots[j].Number })
+	return slots, nil
+}
+
+func (s *Store) CountOccupiedSlots(ctx context.Context) (map[string]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	occupied := make(map[string]int)
+	for _, slot := range s.slots {
+		if !slot.Free() {
+			occupied[slot.StationID]++
+		}
+	}
+	return occupied, nil
+}
+
+func (s *Store) GetDroneChargingSlot(ctx context.Context, droneID string) (*domain.ChargingSlot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, slot := range s.slots {
+		if slot.DroneID != nil && *slot.DroneID == droneID {
+			return cloneChargingSlot(slot), nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (t *Tx) CreateChargingStation(ctx context.Context, station *domain.ChargingStation) error {
+	if t.done {
+		return errTxDone
+	}
+	if err := t.store.lock(ctx, t, chargingStationKey(station.ID)); err != nil {
+		return err
+	}
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	if t.chargingStation(station.ID) != nil {
+		return domain.ErrConflict
+	}
+	station.Version = 1
+	t.stations[station.ID] = cloneChargingStation(station)
+	t.resizeSlots(station)
+	return nil
+}
+
+func (t *Tx) GetChargingStationForUpdate(ctx context.Context, id string) (*domain.ChargingStation, error) {
+	if t.done {
+		return nil, errTxDone
+	}
+	if err := t.store.lock(ctx, t, chargingStationKey(id)); err != nil {
+		return nil, err
+	}
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	station := t.chargingStation(id)
+	if station == nil {
+		return nil, domain.ErrNotFound
+	}
+	return cloneChargingStation(station), nil
+}
+
+func (t *Tx) UpdateChargingStation(ctx context.Context, station *domain.ChargingStation) error {
+	if t.done {
+		return errTxDone
+	}
+	if err := t.store.lock(ctx, t, chargingStationKey(station.ID)); err != nil {
+		return err
+	}
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	current := t.chargingStation(station.ID)
+	if current == nil || current.Version != station.Version {
+		return domain.ErrVersionMismatch
+	}
+	station.Version++
+	t.stations[station.ID] = cloneChargingStation(station)
+	t.resizeSlots(station)
+	return nil
+}
+
+// ReserveFreeChargingSlot skips slots locked by other transactions the way
+// ReserveOrder skips orders.
+func (t *Tx) ReserveFreeChargingSlot(ctx context.Context, stationID string) (*domain.ChargingSlot, error) {
+	if t.done {
+		return nil, errTxDone
+	}
+	s := t.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var selected *domain.ChargingSlot
+	for _, slot := range t.chargingSlots() {
+		if slot.StationID != stationID || !slot.Free() {
+			continue
+		}
+		if s.lockedByOther(t, chargingSlotKey(slot.StationID, slot.Number)) {
+			continue
+		}
+		if selected == nil || slot.Number < selected.Number {
+			selected = slot
+		}
+	}
+	if selected == nil {
+		return nil, nil
+	}
+	key := chargingSlotKey(selected.StationID, selected.Number)
+	s.locks[key] = t
+	t.held[key] = true
+	return cloneChargingSlot(selected), nil
+}
+
+func (t *Tx) GetDroneChargingSlotForUpdate(ctx context.Context, droneID string) (*domain.ChargingSlot, error) {
+	if t.done {
+		return nil, errTxDone
+	}
+	t.store.mu.Lock()
+	slot := t.droneChargingSlot(droneID)
+	t.store.mu.Unlock()
+	if slot == nil {
+		return nil, domain.ErrNotFound
+	}
+	key := chargingSlotKey(slot.StationID, slot.Number)
+	if err := t.store.lock(ctx, t, key); err != nil {
+		return nil, err
+	}
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	// The slot may have been released while we waited for its lock.
+	slot = t.chargingSlot(key)
+	if slot == nil || slot.DroneID == nil || *slot.DroneID != droneID {
+		return nil, domain.ErrNotFound
+	}
+	return cloneChargingSlot(slot), nil
+}
+
+func (t *Tx) UpdateChargingSlot(ctx context.Context, slot *domain.ChargingSlot) error {
+	if t.done {
+		return errTxDone
+	}
+	key := chargingSlotKey(slot.StationID, slot.Number)
+	if err := t.store.lock(ctx, t, key); err != nil {
+		return err
+	}
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	if t.chargingSlot(key) == nil {
+		return domain.ErrNotFound
+	}
+	if slot.DroneID != nil {
+		if held := t.droneChargingSlot(*slot.DroneID); held != nil && (held.StationID != slot.StationID || held.Number != slot.Number) {
+			return domain.ErrConflict
+		}
+	}
+	t.slots[key] = cloneChargingSlot(slot)
+	return nil
+}
+
+// chargingStation is the charging station counterpart of Tx.order. Callers
+// must hold the store mutex.
+func (t *Tx) chargingStation(id string) *domain.ChargingStation {
+	if station, ok := t.stations[id]; ok {
+		return station
+	}
+	return t.store.stations[id]
+}
+
+// chargingSlot is the charging slot counterpart of Tx.order; a slot this
+// transaction removed is nil. Callers must hold the store mutex.
+func (t *Tx) chargingSlot(key string) *domain.ChargingSlot {
+	if slot, ok := t.slots[key]; ok {
+		return slot
+	}
+	return t.store.slots[key]
+}
+
+// chargingSlots returns every slot as this transaction sees it. Callers must
+// hold the store mutex.
+func (t *Tx) chargingSlots() []*domain.ChargingSlot {
+	var slots []*domain.ChargingSlot
+	for key := range t.store.slots {
+		if _, staged := t.slots[key]; !staged {
+			slots = append(slots, t.store.slots[key])
+		}
+	}
+	for _, slot := range t.slots {
+		if slot != nil {
+			slots = append(slots, slot)
+		}
+	}
+	return slots
+}
+
+// droneChargingSlot finds the slot the drone holds as this transaction sees
+// it. Callers must hold the store mutex.
+func (t *Tx) droneChargingSlot(droneID string) *domain.ChargingSlot {
+	for _, slot := range t.chargingSlots() {
+		if slot.DroneID != nil && *slot.DroneID == droneID {
+			return slot
+		}
+	}
+	return nil
+}
+
+// resizeSlots stages free slots up to station.Slots and the removal of those
+// numbered above it. Callers must hold the store mutex.
+func (t *Tx) resizeSlots(station *domain.ChargingStation) {
+	for _, slot := range t.chargingSlots() {
+		if slot.StationID == station.ID && slot.Number > station.Slots {
+			t.slots[chargingSlotKey(slot.StationID, slot.Number)] = nil
+		}
+	}
+	for n := 1; n <= station.Slots; n++ {
+		key := chargingSlotKey(station.ID, n)
+		if t.chargingSlot(key) == nil {
+			t.slots[key] = &domain.ChargingSlot{StationID: station.ID, Number: n}
+		}
+	}
+}
+
+func chargingSlotKey(stationID string, number int) string {
+	return "charging_slot:" + stationID + "/" + strconv.Itoa(number)
+}
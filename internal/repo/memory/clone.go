@@ -0,0 +1,150 @@
+package memory
+
+import (
+	"time"
+
+	"penny-assesment/internal/domain"
+)
+
+// cloneOrder deep-copies an order so callers can never mutate stored rows
+// through shared pointers.
+func cloneOrder(order *domain.Order) *domain.Order {
+	c := *order
+	c.AssignedDroneID = cloneString(order.AssignedDroneID)
+	c.HandoffOrigin = cloneLocation(order.HandoffOrigin)
+	c.ReservedAt = cloneTime(order.ReservedAt)
+	c.PickedUpAt = cloneTime(order.PickedUpAt)
+	c.DeliveredAt = cloneTime(order.DeliveredAt)
+	c.FailedAt = cloneTime(order.FailedAt)
+	c.FailureReason = cloneString(order.FailureReason)
+	c.PickupNotBefore = cloneTime(order.PickupNotBefore)
+	c.DeliverBy = cloneTime(order.DeliverBy)
+	c.SLAAtRiskAt = cloneTime(order.SLAAtRiskAt)
+	c.Route = append([]domain.Location(nil), order.Route...)
+	c.Legs = nil
+	for _, leg := range order.Legs {
+		leg.DroneID = cloneString(leg.DroneID)
+		leg.CompletedAt = cloneTime(leg.CompletedAt)
+		c.Legs = append(c.Legs, leg)
+	}
+	return &c
+}
+
+func cloneDrone(drone *domain.Drone) *domain.Drone {
+	c := *drone
+	c.LastLocation = cloneLocation(drone.LastLocation)
+	c.LastHeartbeatAt = cloneTime(drone.LastHeartbeatAt)
+	c.CurrentOrderID = cloneString(drone.CurrentOrderID)
+	c.HomeDepotID = cloneString(drone.HomeDepotID)
+	c.RecentFixes = append([]domain.Fix(nil), drone.RecentFixes...)
+	c.Stops = nil
+	for _, stop := range drone.Stops {
+		stop.CompletedAt = cloneTime(stop.CompletedAt)
+		c.Stops = append(c.Stops, stop)
+	}
+	c.Vitals = cloneVitals(drone.Vitals)
+	return &c
+}
+
+func cloneVitals(v domain.Vitals) domain.Vitals {
+	return domain.Vitals{
+		AltitudeMeters: cloneFloat(v.AltitudeMeters),
+		HeadingDegrees: cloneFloat(v.HeadingDegrees),
+		GroundSpeedMPS: cloneFloat(v.GroundSpeedMPS),
+		BatteryPercent: cloneFloat(v.BatteryPercent),
+		FaultCodes:     append([]string(nil), v.FaultCodes...),
+	}
+}
+
+func cloneIdempotencyRecord(record *domain.IdempotencyRecord) *domain.IdempotencyRecord {
+	c := *record
+	c.Response = append([]byte(nil), record.Response...)
+	return &c
+}
+
+func cloneDepot(depot *domain.Depot) *domain.Depot {
+	c := *depot
+	return &c
+}
+
+func cloneChargingStation(station *domain.ChargingStation) *domain.ChargingStation {
+	c := *station
+	return &c
+}
+
+func cloneChargingSlot(slot *domain.ChargingSlot) *domain.ChargingSlot {
+	c := *slot
+	c.DroneID = cloneString(slot.DroneID)
+	c.ReservedAt = cloneTime(slot.ReservedAt)
+	return &c
+}
+
+func cloneServiceArea(area *domain.ServiceArea) *domain.ServiceArea {
+	c := *area
+	c.Boundary = clonePolygon(area.Boundary)
+	return &c
+}
+
+func cloneNoFlyZone(zone *domain.NoFlyZone) *domain.NoFlyZone {
+	c := *zone
+	c.Boundary = clonePolygon(zone.Boundary)
+	c.ActiveFrom = cloneTime(zone.ActiveFrom)
+	c.ActiveUntil = cloneTime(zone.ActiveUntil)
+	return &c
+}
+
+func cloneTelemetrySample(sample *domain.TelemetrySample) *domain.TelemetrySample {
+	c := *sample
+	c.OrderID = cloneString(sample.OrderID)
+	return &c
+}
+
+func cloneMaintenanceRecord(record *domain.MaintenanceRecord) *domain.MaintenanceRecord {
+	c := *record
+	c.FaultCodes = append([]string(nil), record.FaultCodes...)
+	c.ClosedAt = cloneTime(record.ClosedAt)
+	c.Technician = cloneString(record.Technician)
+	c.Notes = cloneString(record.Notes)
+	c.Parts = append([]string(nil), record.Parts...)
+	return &c
+}
+
+func clonePolygon(p domain.Polygon) domain.Polygon {
+	c := make(domain.Polygon, 0, len(p))
+	for _, ring := range p {
+		c = append(c, append([]domain.Location(nil), ring...))
+	}
+	return c
+}
+
+func cloneString(v *string) *string {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
+
+func cloneFloat(v *float64) *float64 {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
+
+func cloneTime(v *time.Time) *time.Time {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
+
+func cloneLocation(v *domain.Location) *domain.Location {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
@@ -0,0 +1,13 @@
+package memory
+
+import (
+	"testing"
+
+	"penny-assesment/internal/repo/storetest"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) storetest.Store {
+		return NewStore()
+	})
+}
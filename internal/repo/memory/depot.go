@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"penny-assesment/internal/domain"
+)
+
+func (s *Store) GetDepot(ctx context.Context, id string) (*domain.Depot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	depot, ok := s.depots[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneDepot(depot), nil
+}
+
+func (s *Store) ListDepots(ctx context.Context) ([]*domain.Depot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	depots := make([]*domain.Depot, 0, len(s.depots))
+	for _, depot := range s.depots {
+		depots = append(depots, cloneDepot(depot))
+	}
+	sort.Slice(depots, func(i, j int) bool { return depots[i].ID < depots[j].ID })
+	return depots, nil
+}
+
+func (s *Store) ListDepotDrones(ctx context.Context, depotID string) ([]*domain.Drone, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var drones []*domain.Drone
+	for _, drone := range s.drones {
+		if drone.HomeDepotID != nil && *drone.HomeDepotID == depotID {
+			drones = append(drones, cloneDrone(drone))
+		}
+	}
+	sort.Slice(drones, func(i, j int) bool { return drones[i].ID < drones[j].ID })
+	return drones, nil
+}
+
+func (t *Tx) CreateDepot(ctx context.Context, depot *domain.Depot) error {
+	if t.done {
+		return errTxDone
+	}
+	if err := t.store.lock(ctx, t, depotKey(depot.ID)); err != nil {
+		return err
+	}
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	if t.depot(depot.ID) != nil {
+		return domain.ErrConflict
+	}
+	depot.Version = 1
+	t.depots[depot.ID] = cloneDepot(depot)
+	return nil
+}
+
+func (t *Tx) GetDepotForUpdate(ctx context.Context, id string) (*domain.Depot, error) {
+	if t.done {
+		return nil, errTxDone
+	}
+	if err := t.store.lock(ctx, t, depotKey(id)); err != nil {
+		return nil, err
+	}
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	depot := t.depot(id)
+	if depot == nil {
+		return nil, domain.ErrNotFound
+	}
+	return cloneDepot(depot), nil
+}
+
+func (t *Tx) UpdateDepot(ctx context.Context, depot *domain.Depot) error {
+	if t.done {
+		return errTxDone
+	}
+	if err := t.store.lock(ctx, t, depotKey(depot.ID)); err != nil {
+		return err
+	}
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	current := t.depot(depot.ID)
+	if current == nil || current.Version != depot.Version {
+		return domain.ErrVersionMismatch
+	}
+	depot.Version++
+	t.depots[depot.ID] = cloneDepot(depot)
+	return nil
+}
+
+// depot is the depot counterpart of Tx.order. Callers must hold the store
+// mutex.
+func (t *Tx) depot(id string) *domain.Depot {
+	if depot, ok := t.depots[id]; ok {
+		return depot
+	}
+	return t.store.depots[id]
+}
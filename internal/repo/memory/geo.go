@@ -0,0 +1,36 @@
+package memory
+
+import (
+	"context"
+
+	"penny-assesment/internal/domain"
+	"penny-assesment/internal/service"
+)
+
+func (s *Store) OrdersWithinRadius(ctx context.Context, query service.RadiusQuery) ([]*domain.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	orders := make([]*domain.Order, 0, len(s.orders))
+	for _, order := range s.orders {
+		orders = append(orders, order)
+	}
+	orders = service.FilterOrdersWithinRadius(orders, query)
+	for i, order := range orders {
+		orders[i] = cloneOrder(order)
+	}
+	return orders, nil
+}
+
+func (s *Store) NearestIdleDrones(ctx context.Context, point domain.Location, limit int) ([]*domain.Drone, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	drones := make([]*domain.Drone, 0, len(s.drones))
+	for _, drone := range s.drones {
+		drones = append(drones, drone)
+	}
+	drones = service.NearestDrones(drones, point, limit)
+	for i, drone := range drones {
+		drones[i] = cloneDrone(drone)
+	}
+	return drones, nil
+}
@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"penny-assesment/internal/domain"
+)
+
+func (t *Tx) GetIdempotencyRecord(ctx context.Context, scope, key string) (*domain.IdempotencyRecord, error) {
+	if t.done {
+		return nil, errTxDone
+	}
+	k := idempotencyKey(scope, key)
+	if err := t.store.lock(ctx, t, k); err != nil {
+		return nil, err
+	}
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	record, ok := t.idempotency[k]
+	if !ok {
+		record, ok = t.store.idempotency[k]
+	}
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneIdempotencyRecord(record), nil
+}
+
+func (t *Tx) SaveIdempotencyRecord(ctx context.Context, record *domain.IdempotencyRecord) error {
+	if t.done {
+		return errTxDone
+	}
+	k := idempotencyKey(record.Scope, record.Key)
+	if err := t.store.lock(ctx, t, k); err != nil {
+		return err
+	}
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	t.idempotency[k] = cloneIdempotencyRecord(record)
+	return nil
+}
+
+func (s *Store) PruneIdempotencyRecords(ctx context.Context, now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var pruned int64
+	for k, record := range s.idempotency {
+		if !record.ExpiresAt.After(now) {
+			delete(s.idempotency, k)
+			pruned++
+		}
+	}
+	return pruned, nil
+}
@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"penny-assesment/internal/domain"
+)
+
+func (s *Store) ListMaintenanceRecords(ctx context.Context, droneID string) ([]*domain.MaintenanceRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var records []*domain.MaintenanceRecord
+	for _, record := range s.maintenance {
+		if record.DroneID == droneID {
+			records = append(records, cloneMaintenanceRecord(record))
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if !records[i].OpenedAt.Equal(records[j].OpenedAt) {
+			return records[i].OpenedAt.Before(records[j].OpenedAt)
+		}
+		return records[i].ID < records[j].ID
+	})
+	return records, nil
+}
+
+func (t *Tx) CreateMaintenanceRecord(ctx context.Context, record *domain.MaintenanceRecord) error {
+	if t.done {
+		return errTxDone
+	}
+	if err := t.store.lock(ctx, t, maintenanceKey(record.ID)); err != nil {
+		return err
+	}
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	if t.maintenanceRecord(record.ID) != nil {
+		return domain.ErrConflict
+	}
+	if record.Open() && t.openMaintenanceRecord(record.DroneID) != nil {
+		return domain.ErrConflict
+	}
+	t.maintenance[record.ID] = cloneMaintenanceRecord(record)
+	return nil
+}
+
+func (t *Tx) GetOpenMaintenanceRecordForUpdate(ctx context.Context, droneID string) (*domain.MaintenanceRecord, error) {
+	if t.done {
+		return nil, errTxDone
+	}
+	t.store.mu.Lock()
+	record := t.openMaintenanceRecord(droneID)
+	t.store.mu.Unlock()
+	if record == nil {
+		return nil, domain.ErrNotFound
+	}
+	if err := t.store.lock(ctx, t, maintenanceKey(record.ID)); err != nil {
+		return nil, err
+	}
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	// The record may have been closed while we waited for its lock.
+	record = t.maintenanceRecord(record.ID)
+	if !record.Open() {
+		return nil, domain.ErrNotFound
+	}
+	return cloneMaintenanceRecord(record), nil
+}
+
+func (t *Tx) UpdateMaintenanceRecord(ctx context.Context, record *domain.MaintenanceRecord) error {
+	if t.done {
+		return errTxDone
+	}
+	if err := t.store.lock(ctx, t, maintenanceKey(record.ID)); err != nil {
+		return err
+	}
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	if t.maintenanceRecord(record.ID) == nil {
+		return domain.ErrNotFound
+	}
+	t.maintenance[record.ID] = cloneMaintenanceRecord(record)
+	return nil
+}
+
+// maintenanceRecord is the maintenance record counterpart of Tx.order.
+// Callers must hold the store mutex.
+func (t *Tx) maintenanceRecord(id string) *domain.MaintenanceRecord {
+	if record, ok := t.maintenance[id]; ok {
+		return record
+	}
+	return t.store.maintenance[id]
+}
+
+// openMaintenanceRecord finds the drone's open record as this transaction
+// sees it. Callers must hold the store mutex.
+func (t *Tx) openMaintenanceRecord(droneID string) *domain.MaintenanceRecord {
+	for _, record := range t.maintenance {
+		if record.DroneID == droneID && record.Open() {
+			return record
+		}
+	}
+	for id, record := range t.store.maintenance {
+		if record.DroneID == droneID && t.maintenanceRecord(id).Open() {
+			return t.maintenanceRecord(id)
+		}
+	}
+	return nil
+}
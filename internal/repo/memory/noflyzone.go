@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"penny-assesment/internal/domain"
+)
+
+func (s *Store) GetNoFlyZone(ctx context.Context, id string) (*domain.NoFlyZone, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	zone, ok := s.zones[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneNoFlyZone(zone), nil
+}
+
+func (s *Store) ListNoFlyZones(ctx context.Context) ([]*domain.NoFlyZone, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	zones := make([]*domain.NoFlyZone, 0, len(s.zones))
+	for _, zone := range s.zones {
+		zones = append(zones, cloneNoFlyZone(zone))
+	}
+	sort.Slice(zones, func(i, j int) bool { return zones[i].ID < zones[j].ID })
+	return zones, nil
+}
+
+func (t *Tx) CreateNoFlyZone(ctx context.Context, zone *domain.NoFlyZone) error {
+	if t.done {
+		return errTxDone
+	}
+	if err := t.store.lock(ctx, t, noFlyZoneKey(zone.ID)); err != nil {
+		return err
+	}
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	if t.noFlyZone(zone.ID) != nil {
+		return domain.ErrConflict
+	}
+	zone.Version = 1
+	t.zones[zone.ID] = cloneNoFlyZone(zone)
+	return nil
+}
+
+func (t *Tx) GetNoFlyZoneForUpdate(ctx context.Context, id string) (*domain.NoFlyZone, error) {
+	if t.done {
+		return nil, errTxDone
+	}
+	if err := t.store.lock(ctx, t, noFlyZoneKey(id)); err != nil {
+		return nil, err
+	}
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	zone := t.noFlyZone(id)
+	if zone == nil {
+		return nil, domain.ErrNotFound
+	}
+	return cloneNoFlyZone(zone), nil
+}
+
+func (t *Tx) UpdateNoFlyZone(ctx context.Context, zone *domain.NoFlyZone) error {
+	if t.done {
+		return errTxDone
+	}
+	if err := t.store.lock(ctx, t, noFlyZoneKey(zone.ID)); err != nil {
+		return err
+	}
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	current := t.noFlyZone(zone.ID)
+	if current == nil || current.Version != zone.Version {
+		return domain.ErrVersionMismatch
+	}
+	zone.Version++
+	t.zones[zone.ID] = cloneNoFlyZone(zone)
+	return nil
+}
+
+// noFlyZone is the no-fly zone counterpart of Tx.order. Callers must hold
+// the store mutex.
+func (t *Tx) noFlyZone(id string) *domain.NoFlyZone {
+	if zone, ok := t.zones[id]; ok {
+		return zone
+	}
+	return t.store.zones[id]
+}
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"penny-assesment/internal/events"
+)
+
+type outboxEntry struct {
+	event       events.Event
+	publishedAt *time.Time
+}
+
+func (s *Store) FetchPending(ctx context.Context, limit int) ([]events.Event, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var pending []events.Event
+	for _, entry := range s.outbox {
+		if entry.publishedAt == nil {
+			pending = append(pending, entry.event)
+		}
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].OccurredAt.Before(pending[j].OccurredAt)
+	})
+	if len(pending) > limit {
+		pending = pending[:limit]
+	}
+	return pending, nil
+}
+
+func (s *Store) MarkPublished(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	marked := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		marked[id] = true
+	}
+	now := time.Now().UTC()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, entry := range s.outbox {
+		if entry.publishedAt == nil && marked[entry.event.ID] {
+			entry.publishedAt = &now
+		}
+	}
+	return nil
+}
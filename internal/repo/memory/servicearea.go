@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"penny-assesment/internal/domain"
+)
+
+func (s *Store) GetServiceArea(ctx context.Context, id string) (*domain.ServiceArea, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	area, ok := s.areas[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneServiceArea(area), nil
+}
+
+func (s *Store) ListServiceAreas(ctx context.Context) ([]*domain.ServiceArea, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	areas := make([]*domain.ServiceArea, 0, len(s.areas))
+	for _, area := range s.areas {
+		areas = append(areas, cloneServiceArea(area))
+	}
+	sort.Slice(areas, func(i, j int) bool { return areas[i].ID < areas[j].ID })
+	return areas, nil
+}
+
+func (t *Tx) CreateServiceArea(ctx context.Context, area *domain.ServiceArea) error {
+	if t.done {
+		return errTxDone
+	}
+	if err := t.store.lock(ctx, t, serviceAreaKey(area.ID)); err != nil {
+		return err
+	}
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	if t.serviceArea(area.ID) != nil {
+		return domain.ErrConflict
+	}
+	area.Version = 1
+	t.areas[area.ID] = cloneServiceArea(area)
+	return nil
+}
+
+func (t *Tx) GetServiceAreaForUpdate(ctx context.Context, id string) (*domain.ServiceArea, error) {
+	if t.done {
+		return nil, errTxDone
+	}
+	if err := t.store.lock(ctx, t, serviceAreaKey(id)); err != nil {
+		return nil, err
+	}
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	area := t.serviceArea(id)
+	if area == nil {
+		return nil, domain.ErrNotFound
+	}
+	return cloneServiceArea(area), nil
+}
+
+func (t *Tx) UpdateServiceArea(ctx context.Context, area *domain.ServiceArea) error {
+	if t.done {
+		return errTxDone
+	}
+	if err := t.store.lock(ctx, t, serviceAreaKey(area.ID)); err != nil {
+		return err
+	}
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	current := t.serviceArea(area.ID)
+	if current == nil || current.Version != area.Version {
+		return domain.ErrVersionMismatch
+	}
+	area.Version++
+	t.areas[area.ID] = cloneServiceArea(area)
+	return nil
+}
+
+// serviceArea is the service area counterpart of Tx.order. Callers must hold
+// the store mutex.
+func (t *Tx) serviceArea(id string) *domain.ServiceArea {
+	if area, ok := t.areas[id]; ok {
+		return area
+	}
+	return t.store.areas[id]
+}
@@ -0,0 +1,291 @@
+// Package memory is an in-process implementation of service.Store and
+// events.OutboxRepository. It keeps Postgres' transactional semantics closely
+// enough to run the full service without a database: writes are staged per
+// transaction and only become visible on Commit, rows are locked by the
+// *ForUpdate reads until the transaction ends, and ReserveOrder skips rows
+// locked by other transactions the way FOR UPDATE SKIP LOCKED does.
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"penny-assesment/internal/domain"
+	"penny-assesment/internal/events"
+	"penny-assesment/internal/service"
+)
+
+// ErrDeadlock is returned when waiting for a row lock would never finish
+// because the lock holder is itself waiting on the caller.
+var ErrDeadlock = errors.New("memory: deadlock detected")
+
+type Store struct {
+	mu          sync.Mutex
+	orders      map[string]*domain.Order
+	drones      map[string]*domain.Drone
+	idempotency map[string]*domain.IdempotencyRecord
+	areas       map[string]*domain.ServiceArea
+	zones       map[string]*domain.NoFlyZone
+	telemetry   []*domain.TelemetrySample
+	maintenance map[string]*domain.MaintenanceRecord
+	depots      map[string]*domain.Depot
+	stations    map[string]*domain.ChargingStation
+	slots       map[string]*domain.ChargingSlot
+	outbox      []*outboxEntry
+	locks       map[string]*Tx
+	waits       map[*Tx]*Tx
+	released    chan struct{}
+}
+
+func NewStore() *Store {
+	return &Store{
+		orders:      make(map[string]*domain.Order),
+		drones:      make(map[string]*domain.Drone),
+		idempotency: make(map[string]*domain.IdempotencyRecord),
+		areas:       make(map[string]*domain.ServiceArea),
+		zones:       make(map[string]*domain.NoFlyZone),
+		maintenance: make(map[string]*domain.MaintenanceRecord),
+		depots:      make(map[string]*domain.Depot),
+		stations:    make(map[string]*domain.ChargingStation),
+		slots:       make(map[string]*domain.ChargingSlot),
+		locks:       make(map[string]*Tx),
+		waits:       make(map[*Tx]*Tx),
+		released:    make(chan struct{}),
+	}
+}
+
+func (s *Store) BeginTx(ctx context.Context) (service.Tx, error) {
+	return &Tx{
+		store:       s,
+		orders:      make(map[string]*domain.Order),
+		drones:      make(map[string]*domain.Drone),
+		idempotency: make(map[string]*domain.IdempotencyRecord),
+		areas:       make(map[string]*domain.ServiceArea),
+		zones:       make(map[string]*domain.NoFlyZone),
+		maintenance: make(map[string]*domain.MaintenanceRecord),
+		depots:      make(map[string]*domain.Depot),
+		stations:    make(map[string]*domain.ChargingStation),
+		slots:       make(map[string]*domain.ChargingSlot),
+		held:        make(map[string]bool),
+	}, nil
+}
+
+func (s *Store) GetOrder(ctx context.Context, id string) (*domain.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	order, ok := s.orders[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneOrder(order), nil
+}
+
+func (s *Store) ListOrders(ctx context.Context, filter service.OrderFilter) ([]*domain.Order, error) {
+	if filter.Sort == "" {
+		filter.Sort = service.OrderSortCreatedAsc
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var orders []*domain.Order
+	for _, order := range s.orders {
+		if filter.Matches(order) {
+			orders = append(orders, cloneOrder(order))
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		return filter.Less(&service.OrderCursor{Key: filter.Sort.Key(orders[i]), ID: orders[i].ID}, orders[j])
+	})
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	if len(orders) > limit {
+		orders = orders[:limit]
+	}
+	return orders, nil
+}
+
+func (s *Store) CountOrders(ctx context.Context, filter service.OrderFilter) (int, error) {
+	filter.After = nil
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count := 0
+	for _, order := range s.orders {
+		if filter.Matches(order) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *Store) QueuePositions(ctx context.Context, statuses []domain.OrderStatus, ids []string) (map[string]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	filter := service.OrderFilter{Statuses: statuses}
+	var queued []time.Time
+	for _, order := range s.orders {
+		if filter.Matches(order) {
+			queued = append(queued, order.CreatedAt)
+		}
+	}
+	sort.Slice(queued, func(i, j int) bool { return queued[i].Before(queued[j]) })
+	positions := make(map[string]int, len(ids))
+	for _, id := range ids {
+		order, ok := s.orders[id]
+		if !ok || !filter.Matches(order) {
+			continue
+		}
+		positions[id] = sort.Search(len(queued), func(i int) bool { return !queued[i].Before(order.CreatedAt) })
+	}
+	return positions, nil
+}
+
+func (s *Store) CreateOrder(ctx context.Context, order *domain.Order) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.orders[order.ID]; ok {
+		return domain.ErrConflict
+	}
+	order.Version = 1
+	s.orders[order.ID] = cloneOrder(order)
+	return nil
+}
+
+func (s *Store) GetDrone(ctx context.Context, id string) (*domain.Drone, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	drone, ok := s.drones[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneDrone(drone), nil
+}
+
+func (s *Store) GetDrones(ctx context.Context, ids []string) ([]*domain.Drone, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var drones []*domain.Drone
+	for _, id := range ids {
+		if drone, ok := s.drones[id]; ok {
+			drones = append(drones, cloneDrone(drone))
+		}
+	}
+	return drones, nil
+}
+
+func (s *Store) ListDrones(ctx context.Context) ([]*domain.Drone, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	drones := make([]*domain.Drone, 0, len(s.drones))
+	for _, drone := range s.drones {
+		drones = append(drones, cloneDrone(drone))
+	}
+	sort.Slice(drones, func(i, j int) bool { return drones[i].ID < drones[j].ID })
+	return drones, nil
+}
+
+// lock acquires the row lock key for tx, blocking while another transaction
+// holds it. Callers must not hold s.mu.
+func (s *Store) lock(ctx context.Context, tx *Tx, key string) error {
+	for {
+		s.mu.Lock()
+		owner, held := s.locks[key]
+		if !held || owner == tx {
+			s.locks[key] = tx
+			tx.held[key] = true
+			delete(s.waits, tx)
+			s.mu.Unlock()
+			return nil
+		}
+		if s.wouldDeadlock(tx, owner) {
+			delete(s.waits, tx)
+			s.mu.Unlock()
+			return ErrDeadlock
+		}
+		s.waits[tx] = owner
+		wait := s.released
+		s.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			s.mu.Lock()
+			delete(s.waits, tx)
+			s.mu.Unlock()
+			return ctx.Err()
+		case <-wait:
+		}
+	}
+}
+
+// wouldDeadlock follows the wait-for chain from owner and reports whether it
+// leads back to tx. Callers must hold s.mu.
+func (s *Store) wouldDeadlock(tx, owner *Tx) bool {
+	for cur := owner; cur != nil; cur = s.waits[cur] {
+		if cur == tx {
+			return true
+		}
+	}
+	return false
+}
+
+// lockedByOther reports whether key is locked by a transaction other than tx.
+// Callers must hold s.mu.
+func (s *Store) lockedByOther(tx *Tx, key string) bool {
+	owner, held := s.locks[key]
+	return held && owner != tx
+}
+
+// releaseLocks drops every lock held by tx and wakes all waiters. Callers must
+// hold s.mu.
+func (s *Store) releaseLocks(tx *Tx) {
+	for key := range tx.held {
+		if s.locks[key] == tx {
+			delete(s.locks, key)
+		}
+	}
+	tx.held = nil
+	delete(s.waits, tx)
+	close(s.released)
+	s.released = make(chan struct{})
+}
+
+func orderKey(id string) string {
+	return "order:" + id
+}
+
+func droneKey(id string) string {
+	return "drone:" + id
+}
+
+func serviceAreaKey(id string) string {
+	return "service_area:" + id
+}
+
+func noFlyZoneKey(id string) string {
+	return "no_fly_zone:" + id
+}
+
+func depotKey(id string) string {
+	return "depot:" + id
+}
+
+func chargingStationKey(id string) string {
+	return "charging_station:" + id
+}
+
+func maintenanceKey(id string) string {
+	return "maintenance:" + id
+}
+
+// idempotencyKey keys both the stored record and its row lock. Scopes never
+// contain a NUL byte, so distinct (scope, key) pairs cannot collide.
+func idempotencyKey(scope, key string) string {
+	return "idempotency:" + scope + "\x00" + key
+}
+
+var (
+	_ service.Store           = (*Store)(nil)
+	_ events.OutboxRepository = (*Store)(nil)
+)
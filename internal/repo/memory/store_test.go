@@ -0,0 +1,222 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"penny-assesment/internal/domain"
+	"penny-assesment/internal/events"
+)
+
+func newOrder(id string, createdAt time.Time) *domain.Order {
+	return &domain.Order{
+		ID:          id,
+		UserID:      "user-1",
+		Origin:      domain.Location{Lat: 1, Lng: 1},
+		Destination: domain.Location{Lat: 2, Lng: 2},
+		Status:      domain.OrderStatusCreated,
+		CreatedAt:   createdAt,
+		UpdatedAt:   createdAt,
+	}
+}
+
+func TestRollbackDiscardsStagedWrites(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	now := time.Now().UTC()
+	if err := store.CreateOrder(ctx, newOrder("o1", now)); err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+
+	tx, _ := store.BeginTx(ctx)
+	order, err := tx.GetOrderForUpdate(ctx, "o1")
+	if err != nil {
+		t.Fatalf("get for update: %v", err)
+	}
+	order.Status = domain.OrderStatusWithdrawn
+	if err := tx.UpdateOrder(ctx, order); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := tx.CreateDrone(ctx, &domain.Drone{ID: "d1", Status: domain.DroneStatusActive}); err != nil {
+		t.Fatalf("create drone: %v", err)
+	}
+	if err := tx.EnqueueEvent(ctx, events.Event{ID: "e1", OccurredAt: now}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	// Staged writes are visible inside the transaction only.
+	if staged, _ := tx.GetOrderForUpdate(ctx, "o1"); staged.Status != domain.OrderStatusWithdrawn {
+		t.Fatalf("expected tx to read its own write")
+	}
+	if committed, _ := store.GetOrder(ctx, "o1"); committed.Status != domain.OrderStatusCreated {
+		t.Fatalf("expected uncommitted write to be invisible")
+	}
+
+	if err := tx.Rollback(ctx); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	if committed, _ := store.GetOrder(ctx, "o1"); committed.Status != domain.OrderStatusCreated {
+		t.Fatalf("expected rolled back update to be discarded")
+	}
+	if _, err := store.GetDrone(ctx, "d1"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected rolled back drone to be discarded, got %v", err)
+	}
+	if pending, _ := store.FetchPending(ctx, 10); len(pending) != 0 {
+		t.Fatalf("expected rolled back event to be discarded, got %d", len(pending))
+	}
+}
+
+func TestCommitPublishesWritesAndOutbox(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	now := time.Now().UTC()
+
+	tx, _ := store.BeginTx(ctx)
+	if err := tx.CreateDrone(ctx, &domain.Drone{ID: "d1", Status: domain.DroneStatusActive}); err != nil {
+		t.Fatalf("create drone: %v", err)
+	}
+	_ = tx.EnqueueEvent(ctx, events.Event{ID: "e2", OccurredAt: now.Add(time.Second)})
+	_ = tx.EnqueueEvent(ctx, events.Event{ID: "e1", OccurredAt: now})
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if _, err := store.GetDrone(ctx, "d1"); err != nil {
+		t.Fatalf("expected committed drone, got %v", err)
+	}
+
+	pending, _ := store.FetchPending(ctx, 10)
+	if len(pending) != 2 || pending[0].ID != "e1" || pending[1].ID != "e2" {
+		t.Fatalf("expected pending events oldest first, got %v", pending)
+	}
+	if err := store.MarkPublished(ctx, []string{"e1"}); err != nil {
+		t.Fatalf("mark published: %v", err)
+	}
+	pending, _ = store.FetchPending(ctx, 10)
+	if len(pending) != 1 || pending[0].ID != "e2" {
+		t.Fatalf("expected only e2 pending, got %v", pending)
+	}
+}
+
+func TestReserveOrderSkipsLockedRows(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	now := time.Now().UTC()
+	_ = store.CreateOrder(ctx, newOrder("o1", now))
+	_ = store.CreateOrder(ctx, newOrder("o2", now.Add(time.Second)))
+	allowed := []domain.OrderStatus{domain.OrderStatusCreated}
+
+	tx1, _ := store.BeginTx(ctx)
+	first, err := tx1.ReserveOrder(ctx, "o1", allowed, now)
+	if err != nil || first == nil || first.ID != "o1" {
+		t.Fatalf("expected o1, got %v err=%v", first, err)
+	}
+	tx2, _ := store.BeginTx(ctx)
+	queued, err := tx2.QueuedOrders(ctx, allowed, now, now, nil, 10)
+	if err != nil || len(queued) != 2 {
+		t.Fatalf("expected the unlocked read to see both orders, got %d err=%v", len(queued), err)
+	}
+	if locked, _ := tx2.ReserveOrder(ctx, "o1", allowed, now); locked != nil {
+		t.Fatalf("expected locked o1 to be skipped, got %s", locked.ID)
+	}
+	second, err := tx2.ReserveOrder(ctx, "o2", allowed, now)
+	if err != nil || second == nil || second.ID != "o2" {
+		t.Fatalf("expected o2, got %v err=%v", second, err)
+	}
+	tx3, _ := store.BeginTx(ctx)
+	if none, _ := tx3.ReserveOrder(ctx, "o2", allowed, now); none != nil {
+		t.Fatalf("expected no unlocked order, got %s", none.ID)
+	}
+	_ = tx3.Rollback(ctx)
+	_ = tx2.Rollback(ctx)
+	_ = tx1.Rollback(ctx)
+}
+
+func TestForUpdateWaitsForLockHolder(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	_ = store.CreateOrder(ctx, newOrder("o1", time.Now().UTC()))
+
+	tx1, _ := store.BeginTx(ctx)
+	order, _ := tx1.GetOrderForUpdate(ctx, "o1")
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	tx2, _ := store.BeginTx(ctx)
+	if _, err := tx2.GetOrderForUpdate(timeoutCtx, "o1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected lock wait to time out, got %v", err)
+	}
+
+	done := make(chan *domain.Order)
+	go func() {
+		locked, _ := tx2.GetOrderForUpdate(ctx, "o1")
+		done <- locked
+	}()
+	order.Status = domain.OrderStatusWithdrawn
+	_ = tx1.UpdateOrder(ctx, order)
+	if err := tx1.Commit(ctx); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if locked := <-done; locked == nil || locked.Status != domain.OrderStatusWithdrawn {
+		t.Fatalf("expected waiter to see committed write")
+	}
+	_ = tx2.Rollback(ctx)
+}
+
+func TestConcurrentCreateConflicts(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+
+	tx1, _ := store.BeginTx(ctx)
+	if err := tx1.CreateDrone(ctx, &domain.Drone{ID: "d1"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	result := make(chan error)
+	go func() {
+		tx2, _ := store.BeginTx(ctx)
+		defer tx2.Rollback(ctx)
+		result <- tx2.CreateDrone(ctx, &domain.Drone{ID: "d1"})
+	}()
+	_ = tx1.Commit(ctx)
+	if err := <-result; !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict, got %v", err)
+	}
+}
+
+func TestDeadlockDetected(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	now := time.Now().UTC()
+	_ = store.CreateOrder(ctx, newOrder("o1", now))
+	_ = store.CreateOrder(ctx, newOrder("o2", now))
+
+	tx1, _ := store.BeginTx(ctx)
+	tx2, _ := store.BeginTx(ctx)
+	_, _ = tx1.GetOrderForUpdate(ctx, "o1")
+	_, _ = tx2.GetOrderForUpdate(ctx, "o2")
+
+	blocked := make(chan error)
+	go func() {
+		_, err := tx1.GetOrderForUpdate(ctx, "o2")
+		blocked <- err
+	}()
+	// Wait until tx1 is registered as waiting on tx2.
+	for {
+		store.mu.Lock()
+		waiting := store.waits[tx1.(*Tx)] != nil
+		store.mu.Unlock()
+		if waiting {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := tx2.GetOrderForUpdate(ctx, "o1"); !errors.Is(err, ErrDeadlock) {
+		t.Fatalf("expected deadlock, got %v", err)
+	}
+	_ = tx2.Rollback(ctx)
+	if err := <-blocked; err != nil {
+		t.Fatalf("expected tx1 to proceed after tx2 rolled back, got %v", err)
+	}
+	_ = tx1.Rollback(ctx)
+}
@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"penny-assesment/internal/domain"
+	"penny-assesment/internal/service"
+)
+
+func (s *Store) ListTelemetry(ctx context.Context, query service.TelemetryQuery) ([]*domain.TelemetrySample, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var samples []*domain.TelemetrySample
+	for _, sample := range s.telemetry {
+		if query.Matches(sample) {
+			samples = append(samples, cloneTelemetrySample(sample))
+		}
+	}
+	sort.SliceStable(samples, func(i, j int) bool { return samples[i].RecordedAt.Before(samples[j].RecordedAt) })
+	if query.Limit > 0 && len(samples) > query.Limit {
+		samples = samples[:query.Limit]
+	}
+	return samples, nil
+}
+
+func (s *Store) PruneTelemetry(ctx context.Context, before time.Time) (int64, error) {
+	if before.IsZero() {
+		return 0, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.telemetry[:0]
+	for _, sample := range s.telemetry {
+		if !sample.RecordedAt.Before(before) {
+			kept = append(kept, sample)
+		}
+	}
+	pruned := int64(len(s.telemetry) - len(kept))
+	clear(s.telemetry[len(kept):])
+	s.telemetry = kept
+	return pruned, nil
+}
+
+func (t *Tx) AppendTelemetry(ctx context.Context, sample *domain.TelemetrySample) error {
+	if t.done {
+		return errTxDone
+	}
+	t.telemetry = append(t.telemetry, cloneTelemetrySample(sample))
+	return nil
+}
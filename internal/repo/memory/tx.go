@@ -0,0 +1,301 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"penny-assesment/internal/domain"
+	"penny-assesment/internal/events"
+	"penny-assesment/internal/service"
+)
+
+var errTxDone = errors.New("memory: transaction already committed or rolled back")
+
+// Tx stages its writes locally; nothing is visible to other readers until
+// Commit. Rollback (or a Commit error) discards the staged writes.
+type Tx struct {
+	store       *Store
+	orders      map[string]*domain.Order
+	drones      map[string]*domain.Drone
+	idempotency map[string]*domain.IdempotencyRecord
+	areas       map[string]*domain.ServiceArea
+	zones       map[string]*domain.NoFlyZone
+	telemetry   []*domain.TelemetrySample
+	maintenance map[string]*domain.MaintenanceRecord
+	depots      map[string]*domain.Depot
+	stations    map[string]*domain.ChargingStation
+	// slots are keyed by chargingSlotKey; a nil slot has been removed.
+	slots  map[string]*domain.ChargingSlot
+	events []events.Event
+	held   map[string]bool
+	done   bool
+}
+
+func (t *Tx) Commit(ctx context.Context) error {
+	if t.done {
+		return errTxDone
+	}
+	t.done = true
+	s := t.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.releaseLocks(t)
+	for id, order := range t.orders {
+		s.orders[id] = order
+	}
+	for id, drone := range t.drones {
+		s.drones[id] = drone
+	}
+	for key, record := range t.idempotency {
+		s.idempotency[key] = record
+	}
+	for id, area := range t.areas {
+		s.areas[id] = area
+	}
+	for id, zone := range t.zones {
+		s.zones[id] = zone
+	}
+	s.telemetry = append(s.telemetry, t.telemetry...)
+	for id, record := range t.maintenance {
+		s.maintenance[id] = record
+	}
+	for id, depot := range t.depots {
+		s.depots[id] = depot
+	}
+	for id, station := range t.stations {
+		s.stations[id] = station
+	}
+	for key, slot := range t.slots {
+		if slot == nil {
+			delete(s.slots, key)
+		} else {
+			s.slots[key] = slot
+		}
+	}
+	for _, evt := range t.events {
+		s.outbox = append(s.outbox, &outboxEntry{event: evt})
+	}
+	return nil
+}
+
+func (t *Tx) Rollback(ctx context.Context) error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	t.store.releaseLocks(t)
+	return nil
+}
+
+func (t *Tx) GetOrderForUpdate(ctx context.Context, id string) (*domain.Order, error) {
+	if t.done {
+		return nil, errTxDone
+	}
+	if err := t.store.lock(ctx, t, orderKey(id)); err != nil {
+		return nil, err
+	}
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	order := t.order(id)
+	if order == nil {
+		return nil, domain.ErrNotFound
+	}
+	return cloneOrder(order), nil
+}
+
+func (t *Tx) GetDroneForUpdate(ctx context.Context, id string) (*domain.Drone, error) {
+	if t.done {
+		return nil, errTxDone
+	}
+	if err := t.store.lock(ctx, t, droneKey(id)); err != nil {
+		return nil, err
+	}
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	drone := t.drone(id)
+	if drone == nil {
+		return nil, domain.ErrNotFound
+	}
+	return cloneDrone(drone), nil
+}
+
+// CreateDrone locks the new key first so a concurrent insert of the same ID
+// waits for this transaction and then fails with ErrConflict, like a unique
+// index would.
+func (t *Tx) CreateDrone(ctx context.Context, drone *domain.Drone) error {
+	if t.done {
+		return errTxDone
+	}
+	if err := t.store.lock(ctx, t, droneKey(drone.ID)); err != nil {
+		return err
+	}
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	if t.drone(drone.ID) != nil {
+		return domain.ErrConflict
+	}
+	drone.Version = 1
+	t.drones[drone.ID] = cloneDrone(drone)
+	return nil
+}
+
+func (t *Tx) CreateOrder(ctx context.Context, order *domain.Order) error {
+	if t.done {
+		return errTxDone
+	}
+	if err := t.store.lock(ctx, t, orderKey(order.ID)); err != nil {
+		return err
+	}
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	if t.order(order.ID) != nil {
+		return domain.ErrConflict
+	}
+	order.Version = 1
+	t.orders[order.ID] = cloneOrder(order)
+	return nil
+}
+
+// UpdateOrder writes order if its Version still matches the stored row and
+// sets order.Version to the incremented value.
+func (t *Tx) UpdateOrder(ctx context.Context, order *domain.Order) error {
+	if t.done {
+		return errTxDone
+	}
+	if err := t.store.lock(ctx, t, orderKey(order.ID)); err != nil {
+		return err
+	}
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	current := t.order(order.ID)
+	if current == nil || current.Version != order.Version {
+		return domain.ErrVersionMismatch
+	}
+	order.Version++
+	t.orders[order.ID] = cloneOrder(order)
+	return nil
+}
+
+func (t *Tx) UpdateDrone(ctx context.Context, drone *domain.Drone) error {
+	if t.done {
+		return errTxDone
+	}
+	if err := t.store.lock(ctx, t, droneKey(drone.ID)); err != nil {
+		return err
+	}
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	current := t.drone(drone.ID)
+	if current == nil || current.Version != drone.Version {
+		return domain.ErrVersionMismatch
+	}
+	drone.Version++
+	t.drones[drone.ID] = cloneDrone(drone)
+	return nil
+}
+
+// QueuedOrders reads rows locked by other transactions too, as a plain
+// SELECT does.
+func (t *Tx) QueuedOrders(ctx context.Context, allowed []domain.OrderStatus, due, urgent time.Time, after *domain.Order, limit int) ([]*domain.Order, error) {
+	if t.done {
+		return nil, errTxDone
+	}
+	s := t.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	before := func(a, b *domain.Order) bool {
+		da, db := service.QueueDeadline(a, urgent), service.QueueDeadline(b, urgent)
+		switch {
+		case da != nil && db != nil && !da.Equal(*db):
+			return da.Before(*db)
+		case (da == nil) != (db == nil):
+			return da != nil
+		}
+		if a.CreatedAt.Equal(b.CreatedAt) {
+			return a.ID < b.ID
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	var queued []*domain.Order
+	for id := range s.orders {
+		order := t.order(id)
+		if reservable(order, allowed, due) && (after == nil || before(after, order)) {
+			queued = append(queued, order)
+		}
+	}
+	sort.Slice(queued, func(i, j int) bool { return before(queued[i], queued[j]) })
+	if len(queued) > limit {
+		queued = queued[:limit]
+	}
+	for i, order := range queued {
+		queued[i] = cloneOrder(order)
+	}
+	return queued, nil
+}
+
+func (t *Tx) ReserveOrder(ctx context.Context, id string, allowed []domain.OrderStatus, due time.Time) (*domain.Order, error) {
+	if t.done {
+		return nil, errTxDone
+	}
+	s := t.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lockedByOther(t, orderKey(id)) {
+		return nil, nil
+	}
+	order := t.order(id)
+	if order == nil || !reservable(order, allowed, due) {
+		return nil, nil
+	}
+	s.locks[orderKey(id)] = t
+	t.held[orderKey(id)] = true
+	return cloneOrder(order), nil
+}
+
+// reservable reports whether order is unassigned, in one of the allowed
+// statuses and due for pickup by due.
+func reservable(order *domain.Order, allowed []domain.OrderStatus, due time.Time) bool {
+	if order.AssignedDroneID != nil {
+		return false
+	}
+	if order.PickupNotBefore != nil && order.PickupNotBefore.After(due) {
+		return false
+	}
+	for _, status := range allowed {
+		if order.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Tx) EnqueueEvent(ctx context.Context, event events.Event) error {
+	if t.done {
+		return errTxDone
+	}
+	t.events = append(t.events, event)
+	return nil
+}
+
+// order returns the transaction's view of an order: its staged write if any,
+// otherwise the committed row. Callers must hold the store mutex.
+func (t *Tx) order(id string) *domain.Order {
+	if order, ok := t.orders[id]; ok {
+		return order
+	}
+	return t.store.orders[id]
+}
+
+// drone is the drone counterpart of order. Callers must hold the store mutex.
+func (t *Tx) drone(id string) *domain.Drone {
+	if drone, ok := t.drones[id]; ok {
+		return drone
+	}
+	return t.store.drones[id]
+}
+
+var _ service.Tx = (*Tx)(nil)
@@ -0,0 +1,179 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"penny-assesment/internal/domain"
+)
+
+func (s *Store) GetChargingStation(ctx context.Context, id string) (*domain.ChargingStation, error) {
+	return scanChargingStation(s.pool.QueryRow(ctx, chargingStationSelectByIDSQL, id))
+}
+
+func (s *Store) ListChargingStations(ctx context.Context) ([]*domain.ChargingStation, error) {
+	rows, err := s.pool.Query(ctx, chargingStationListSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stations []*domain.ChargingStation
+	for rows.Next() {
+		station, err := scanChargingStation(rows)
+		if err != nil {
+			return nil, err
+		}
+		stations = append(stations, station)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return stations, nil
+}
+
+func (s *Store) ListChargingSlots(ctx context.Context, stationID string) ([]*domain.ChargingSlot, error) {
+	rows, err := s.pool.Query(ctx, chargingSlotListSQL, stationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var slots []*domain.ChargingSlot
+	for rows.Next() {
+		slot, err := scanChargingSlot(rows)
+		if err != nil {
+			return nil, err
+		}
+		slots = append(slots, slot)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return slots, nil
+}
+
+func (s *Store) CountOccupiedSlots(ctx context.Context) (map[string]int, error) {
+	rows, err := s.pool.Query(ctx, chargingSlotOccupancySQL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	occupied := make(map[string]int)
+	for rows.Next() {
+		var stationID string
+		var n int
+		if err := rows.Scan(&stationID, &n); err != nil {
+			return nil, err
+		}
+		occupied[stationID] = n
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return occupied, nil
+}
+
+func (s *Store) GetDroneChargingSlot(ctx context.Context, droneID string) (*domain.ChargingSlot, error) {
+	return scanChargingSlot(s.pool.QueryRow(ctx, chargingSlotSelectByDroneSQL, droneID))
+}
+
+func (t *Tx) CreateChargingStation(ctx context.Context, station *domain.ChargingStation) error {
+	_, err := t.tx.Exec(ctx, chargingStationInsertSQL,
+		station.ID,
+		station.Name,
+		station.Location.Lat,
+		station.Location.Lng,
+		station.Slots,
+		station.CreatedAt,
+		station.UpdatedAt,
+	)
+	if err != nil {
+		return mapError(err)
+	}
+	station.Version = 1
+	return t.resizeSlots(ctx, station)
+}
+
+func (t *Tx) GetChargingStationForUpdate(ctx context.Context, id string) (*domain.ChargingStation, error) {
+	return scanChargingStation(t.tx.QueryRow(ctx, chargingStationSelectByIDForUpdateSQL, id))
+}
+
+func (t *Tx) UpdateChargingStation(ctx context.Context, station *domain.ChargingStation) error {
+	row := t.tx.QueryRow(ctx, chargingStationUpdateSQL,
+		station.Name,
+		station.Location.Lat,
+		station.Location.Lng,
+		station.Slots,
+		station.UpdatedAt,
+		station.ID,
+		station.Version,
+	)
+	if err := scanVersion(row, &station.Version); err != nil {
+		return err
+	}
+	return t.resizeSlots(ctx, station)
+}
+
+func (t *Tx) ReserveFreeChargingSlot(ctx context.Context, stationID string) (*domain.ChargingSlot, error) {
+	slot, err := scanChargingSlot(t.tx.QueryRow(ctx, chargingSlotReserveSQL, stationID))
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, nil
+	}
+	return slot, err
+}
+
+func (t *Tx) GetDroneChargingSlotForUpdate(ctx context.Context, droneID string) (*domain.ChargingSlot, error) {
+	return scanChargingSlot(t.tx.QueryRow(ctx, chargingSlotSelectByDroneForUpdateSQL, droneID))
+}
+
+func (t *Tx) UpdateChargingSlot(ctx context.Context, slot *domain.ChargingSlot) error {
+	tag, err := t.tx.Exec(ctx, chargingSlotUpdateSQL,
+		slot.DroneID,
+		slot.ReservedAt,
+		slot.StationID,
+		slot.Number,
+	)
+	if err != nil {
+		return mapError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (t *Tx) resizeSlots(ctx context.Context, station *domain.ChargingStation) error {
+	if _, err := t.tx.Exec(ctx, chargingSlotTrimSQL, station.ID, station.Slots); err != nil {
+		return err
+	}
+	_, err := t.tx.Exec(ctx, chargingSlotFillSQL, station.ID, station.Slots)
+	return err
+}
+
+func scanChargingStation(row pgx.Row) (*domain.ChargingStation, error) {
+	station := &domain.ChargingStation{}
+	err := row.Scan(&station.ID, &station.Name, &station.Location.Lat, &station.Location.Lng, &station.Slots, &station.CreatedAt, &station.UpdatedAt, &station.Version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return station, nil
+}
+
+func scanChargingSlot(row pgx.Row) (*domain.ChargingSlot, error) {
+	slot := &domain.ChargingSlot{}
+	err := row.Scan(&slot.StationID, &slot.Number, &slot.DroneID, &slot.ReservedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return slot, nil
+}
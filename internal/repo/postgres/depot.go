@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"penny-assesment/internal/domain"
+)
+
+func (s *Store) GetDepot(ctx context.Context, id string) (*domain.Depot, error) {
+	return scanDepot(s.pool.QueryRow(ctx, depotSelectByIDSQL, id))
+}
+
+func (s *Store) ListDepots(ctx context.Context) ([]*domain.Depot, error) {
+	rows, err := s.pool.Query(ctx, depotListSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var depots []*domain.Depot
+	for rows.Next() {
+		depot, err := scanDepot(rows)
+		if err != nil {
+			return nil, err
+		}
+		depots = append(depots, depot)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return depots, nil
+}
+
+func (s *Store) ListDepotDrones(ctx context.Context, depotID string) ([]*domain.Drone, error) {
+	rows, err := s.pool.Query(ctx, droneListByDepotSQL, depotID)
+	if err != nil {
+		return nil, err
+	}
+	return collectDrones(rows)
+}
+
+func (t *Tx) CreateDepot(ctx context.Context, depot *domain.Depot) error {
+	_, err := t.tx.Exec(ctx, depotInsertSQL,
+		depot.ID,
+		depot.Name,
+		depot.Location.Lat,
+		depot.Location.Lng,
+		depot.Capacity,
+		depot.CreatedAt,
+		depot.UpdatedAt,
+	)
+	if err != nil {
+		return mapError(err)
+	}
+	depot.Version = 1
+	return nil
+}
+
+func (t *Tx) GetDepotForUpdate(ctx context.Context, id string) (*domain.Depot, error) {
+	return scanDepot(t.tx.QueryRow(ctx, depotSelectByIDForUpdateSQL, id))
+}
+
+func (t *Tx) UpdateDepot(ctx context.Context, depot *domain.Depot) error {
+	row := t.tx.QueryRow(ctx, depotUpdateSQL,
+		depot.Name,
+		depot.Location.Lat,
+		depot.Location.Lng,
+		depot.Capacity,
+		depot.UpdatedAt,
+		depot.ID,
+		depot.Version,
+	)
+	return scanVersion(row, &depot.Version)
+}
+
+func scanDepot(row pgx.Row) (*domain.Depot, error) {
+	depot := &domain.Depot{}
+	err := row.Scan(&depot.ID, &depot.Name, &depot.Location.Lat, &depot.Location.Lng, &depot.Capacity, &depot.CreatedAt, &depot.UpdatedAt, &depot.Version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return depot, nil
+}
@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"penny-assesment/internal/domain"
+	"penny-assesment/internal/service"
+)
+
+// SetupPostGIS prepares the optional PostGIS schema and reports whether the
+// geography columns are in place. When migrate is set and the extension is
+// available, the migrations in dir (normally migrations/postgis) are applied
+// first. A false result with a nil error means the database has no PostGIS;
+// the store then answers spatial queries with the haversine fallback.
+func SetupPostGIS(ctx context.Context, pool *pgxpool.Pool, dir string, migrate bool) (bool, error) {
+	if migrate {
+		var available bool
+		if err := pool.QueryRow(ctx, postgisAvailableSQL).Scan(&available); err != nil {
+			return false, err
+		}
+		if available {
+			if err := ApplyMigrations(ctx, pool, dir); err != nil {
+				return false, err
+			}
+		}
+	}
+	var ready bool
+	if err := pool.QueryRow(ctx, postgisSchemaSQL).Scan(&ready); err != nil {
+		return false, err
+	}
+	return ready, nil
+}
+
+// EnablePostGIS switches the spatial queries to the geography columns and
+// GiST indexes created by SetupPostGIS.
+func (s *Store) EnablePostGIS() {
+	s.postgis = true
+}
+
+func (s *Store) OrdersWithinRadius(ctx context.Context, query service.RadiusQuery) ([]*domain.Order, error) {
+	if s.postgis {
+		statuses := make([]string, 0, len(query.Statuses))
+		for _, status := range query.Statuses {
+			statuses = append(statuses, string(status))
+		}
+		rows, err := s.pool.Query(ctx, orderWithinRadiusPostGISSQL,
+			query.Center.Lng, query.Center.Lat, query.RadiusMeters, statuses, query.Limit)
+		if err != nil {
+			return nil, err
+		}
+		return collectOrders(rows)
+	}
+
+	// Without PostGIS, prefilter on the bounding box of the circle, which the
+	// (origin_lat, origin_lng) index can serve, and leave the exact check and
+	// ordering to service.FilterOrdersWithinRadius.
+	q := &orderQuery{}
+	q.add(boxPredicate(q, "origin_lat", "origin_lng", domain.BoundingBoxAround(query.Center, query.RadiusMeters)))
+	if len(query.Statuses) > 0 {
+		statuses := make([]string, 0, len(query.Statuses))
+		for _, status := range query.Statuses {
+			statuses = append(statuses, string(status))
+		}
+		q.add("status = ANY(" + q.arg(statuses) + ")")
+	}
+	rows, err := s.pool.Query(ctx, orderListSQL+"WHERE "+strings.Join(q.where, "\n  AND "), q.args...)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := collectOrders(rows)
+	if err != nil {
+		return nil, err
+	}
+	return service.FilterOrdersWithinRadius(orders, query), nil
+}
+
+func (s *Store) NearestIdleDrones(ctx context.Context, point domain.Location, limit int) ([]*domain.Drone, error) {
+	if s.postgis {
+		rows, err := s.pool.Query(ctx, droneNearestIdlePostGISSQL,
+			point.Lng, point.Lat, assignableStatuses(), limit)
+		if err != nil {
+			return nil, err
+		}
+		return collectDrones(rows)
+	}
+
+	rows, err := s.pool.Query(ctx, droneIdleSQL, assignableStatuses())
+	if err != nil {
+		return nil, err
+	}
+	drones, err := collectDrones(rows)
+	if err != nil {
+		return nil, err
+	}
+	return service.NearestDrones(drones, point, limit), nil
+}
+
+func assignableStatuses() []string {
+	statuses := make([]string, 0, len(domain.AssignableDroneStatuses))
+	for _, status := range domain.AssignableDroneStatuses {
+		statuses = append(statuses, string(status))
+	}
+	return statuses
+}
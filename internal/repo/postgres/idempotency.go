@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"penny-assesment/internal/domain"
+)
+
+func (t *Tx) GetIdempotencyRecord(ctx context.Context, scope, key string) (*domain.IdempotencyRecord, error) {
+	if _, err := t.tx.Exec(ctx, idempotencyLockSQL, scope, key); err != nil {
+		return nil, err
+	}
+	record := &domain.IdempotencyRecord{}
+	err := t.tx.QueryRow(ctx, idempotencySelectSQL, scope, key).Scan(
+		&record.Scope,
+		&record.Key,
+		&record.RequestHash,
+		&record.Response,
+		&record.CreatedAt,
+		&record.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return record, nil
+}
+
+func (t *Tx) SaveIdempotencyRecord(ctx context.Context, record *domain.IdempotencyRecord) error {
+	_, err := t.tx.Exec(ctx, idempotencyUpsertSQL,
+		record.Scope,
+		record.Key,
+		record.RequestHash,
+		record.Response,
+		record.CreatedAt,
+		record.ExpiresAt,
+	)
+	return err
+}
+
+func (s *Store) PruneIdempotencyRecords(ctx context.Context, now time.Time) (int64, error) {
+	tag, err := s.pool.Exec(ctx, idempotencyPruneSQL, now)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
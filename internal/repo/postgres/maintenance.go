@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"penny-assesment/internal/domain"
+)
+
+func (s *Store) ListMaintenanceRecords(ctx context.Context, droneID string) ([]*domain.MaintenanceRecord, error) {
+	rows, err := s.pool.Query(ctx, maintenanceListSQL, droneID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []*domain.MaintenanceRecord
+	for rows.Next() {
+		record, err := scanMaintenanceRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return records, nil
+}
+
+// CreateMaintenanceRecord relies on the partial unique index on open records
+// to refuse a second open record for a drone.
+func (t *Tx) CreateMaintenanceRecord(ctx context.Context, record *domain.MaintenanceRecord) error {
+	_, err := t.tx.Exec(ctx, maintenanceInsertSQL,
+		record.ID,
+		record.DroneID,
+		string(record.Reason),
+		record.FaultCodes,
+		record.Usage.Seconds,
+		record.Usage.Meters,
+		record.OpenedAt,
+		record.ClosedAt,
+		record.Technician,
+		record.Notes,
+		record.Parts,
+	)
+	return mapError(err)
+}
+
+func (t *Tx) GetOpenMaintenanceRecordForUpdate(ctx context.Context, droneID string) (*domain.MaintenanceRecord, error) {
+	return scanMaintenanceRecord(t.tx.QueryRow(ctx, maintenanceSelectOpenForUpdateSQL, droneID))
+}
+
+func (t *Tx) UpdateMaintenanceRecord(ctx context.Context, record *domain.MaintenanceRecord) error {
+	tag, err := t.tx.Exec(ctx, maintenanceUpdateSQL,
+		string(record.Reason),
+		record.FaultCodes,
+		record.ClosedAt,
+		record.Technician,
+		record.Notes,
+		record.Parts,
+		record.ID,
+	)
+	if err != nil {
+		return mapError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func scanMaintenanceRecord(row pgx.Row) (*domain.MaintenanceRecord, error) {
+	var reason string
+	record := &domain.MaintenanceRecord{}
+	err := row.Scan(
+		&record.ID,
+		&record.DroneID,
+		&reason,
+		&record.FaultCodes,
+		&record.Usage.Seconds,
+		&record.Usage.Meters,
+		&record.OpenedAt,
+		&record.ClosedAt,
+		&record.Technician,
+		&record.Notes,
+		&record.Parts,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	record.Reason = domain.MaintenanceReason(reason)
+	return record, nil
+}
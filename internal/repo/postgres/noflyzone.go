@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"penny-assesment/internal/domain"
+)
+
+func (s *Store) GetNoFlyZone(ctx context.Context, id string) (*domain.NoFlyZone, error) {
+	return scanNoFlyZone(s.pool.QueryRow(ctx, noFlyZoneSelectByIDSQL, id))
+}
+
+func (s *Store) ListNoFlyZones(ctx context.Context) ([]*domain.NoFlyZone, error) {
+	rows, err := s.pool.Query(ctx, noFlyZoneListSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var zones []*domain.NoFlyZone
+	for rows.Next() {
+		zone, err := scanNoFlyZone(rows)
+		if err != nil {
+			return nil, err
+		}
+		zones = append(zones, zone)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return zones, nil
+}
+
+func (t *Tx) CreateNoFlyZone(ctx context.Context, zone *domain.NoFlyZone) error {
+	boundary, err := json.Marshal(zone.Boundary.GeoJSONCoordinates())
+	if err != nil {
+		return err
+	}
+	_, err = t.tx.Exec(ctx, noFlyZoneInsertSQL,
+		zone.ID,
+		zone.Name,
+		boundary,
+		zone.ActiveFrom,
+		zone.ActiveUntil,
+		zone.CreatedAt,
+		zone.UpdatedAt,
+	)
+	if err != nil {
+		return mapError(err)
+	}
+	zone.Version = 1
+	return nil
+}
+
+func (t *Tx) GetNoFlyZoneForUpdate(ctx context.Context, id string) (*domain.NoFlyZone, error) {
+	return scanNoFlyZone(t.tx.QueryRow(ctx, noFlyZoneSelectByIDForUpdateSQL, id))
+}
+
+func (t *Tx) UpdateNoFlyZone(ctx context.Context, zone *domain.NoFlyZone) error {
+	boundary, err := json.Marshal(zone.Boundary.GeoJSONCoordinates())
+	if err != nil {
+		return err
+	}
+	row := t.tx.QueryRow(ctx, noFlyZoneUpdateSQL,
+		zone.Name,
+		boundary,
+		zone.ActiveFrom,
+		zone.ActiveUntil,
+		zone.UpdatedAt,
+		zone.ID,
+		zone.Version,
+	)
+	return scanVersion(row, &zone.Version)
+}
+
+func scanNoFlyZone(row pgx.Row) (*domain.NoFlyZone, error) {
+	var boundary []byte
+	zone := &domain.NoFlyZone{}
+	err := row.Scan(&zone.ID, &zone.Name, &boundary, &zone.ActiveFrom, &zone.ActiveUntil, &zone.CreatedAt, &zone.UpdatedAt, &zone.Version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	var coords [][][]float64
+	if err := json.Unmarshal(boundary, &coords); err != nil {
+		return nil, err
+	}
+	if zone.Boundary, err = domain.PolygonFromGeoJSON(coords); err != nil {
+		return nil, err
+	}
+	return zone, nil
+}
@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"penny-assesment/internal/domain"
+	"penny-assesment/internal/service"
+)
+
+// orderQuery accumulates WHERE predicates and their positional arguments.
+type orderQuery struct {
+	where []string
+	args  []any
+}
+
+func (q *orderQuery) arg(v any) string {
+	q.args = append(q.args, v)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+func (q *orderQuery) add(predicate string) {
+	q.where = append(q.where, predicate)
+}
+
+// buildOrderListQuery appends the filter's predicates, keyset position, sort
+// and limit to orderListSQL.
+func buildOrderListQuery(filter service.OrderFilter) (string, []any, error) {
+	sort := filter.Sort
+	if sort == "" {
+		sort = service.OrderSortCreatedAsc
+	}
+	if !sort.Valid() {
+		return "", nil, domain.ErrInvalid
+	}
+	q := orderPredicates(filter)
+	column := sort.Column()
+	direction := "ASC"
+	comparison := ">"
+	if sort.Descending() {
+		direction = "DESC"
+		comparison = "<"
+	}
+	if filter.After != nil {
+		if _, err := uuid.Parse(filter.After.ID); err != nil {
+			return "", nil, domain.ErrInvalid
+		}
+		q.add(fmt.Sprintf("(%s, id) %s (%s, %s::uuid)", column, comparison, q.arg(filter.After.Key), q.arg(filter.After.ID)))
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	var b strings.Builder
+	b.WriteString(orderListSQL)
+	q.writeWhere(&b)
+	fmt.Fprintf(&b, "ORDER BY %s %s, id %s\nLIMIT %s\n", column, direction, direction, q.arg(limit))
+	return b.String(), q.args, nil
+}
+
+// buildOrderCountQuery counts the rows matching the filter's predicates; the
+// sort, keyset position and limit are ignored.
+func buildOrderCountQuery(filter service.OrderFilter) (string, []any) {
+	q := orderPredicates(filter)
+	var b strings.Builder
+	b.WriteString(orderCountSQL)
+	q.writeWhere(&b)
+	return b.String(), q.args
+}
+
+// orderPredicates turns every filter field except the keyset position into a
+// WHERE predicate.
+func orderPredicates(filter service.OrderFilter) *orderQuery {
+	q := &orderQuery{}
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, 0, len(filter.Statuses))
+		for _, status := range filter.Statuses {
+			statuses = append(statuses, string(status))
+		}
+		q.add("status = ANY(" + q.arg(statuses) + ")")
+	}
+	if filter.UserID != nil {
+		q.add("user_id = " + q.arg(*filter.UserID))
+	}
+	if filter.DroneID != nil {
+		q.add("assigned_drone_id = " + q.arg(*filter.DroneID))
+	}
+	if filter.CreatedFrom != nil {
+		q.add("created_at >= " + q.arg(*filter.CreatedFrom))
+	}
+	if filter.CreatedTo != nil {
+		q.add("created_at < " + q.arg(*filter.CreatedTo))
+	}
+	if filter.UpdatedFrom != nil {
+		q.add("updated_at >= " + q.arg(*filter.UpdatedFrom))
+	}
+	if filter.UpdatedTo != nil {
+		q.add("updated_at < " + q.arg(*filter.UpdatedTo))
+	}
+	if filter.FailureReason != "" {
+		q.add("failure_reason ILIKE " + q.arg("%"+escapeLike(filter.FailureReason)+"%"))
+	}
+	if filter.OriginBox != nil {
+		q.add(boxPredicate(q, "origin_lat", "origin_lng", *filter.OriginBox))
+	}
+	if filter.DestinationBox != nil {
+		q.add(boxPredicate(q, "dest_lat", "dest_lng", *filter.DestinationBox))
+	}
+	return q
+}
+
+func (q *orderQuery) writeWhere(b *strings.Builder) {
+	if len(q.where) > 0 {
+		b.WriteString("WHERE ")
+		b.WriteString(strings.Join(q.where, "\n  AND "))
+		b.WriteString("\n")
+	}
+}
+
+func boxPredicate(q *orderQuery, latCol, lngCol string, box domain.BoundingBox) string {
+	lat := fmt.Sprintf("%s BETWEEN %s AND %s", latCol, q.arg(box.MinLat), q.arg(box.MaxLat))
+	if box.CrossesAntimeridian() {
+		return fmt.Sprintf("(%s AND (%s >= %s OR %s <= %s))", lat, lngCol, q.arg(box.MinLng), lngCol, q.arg(box.MaxLng))
+	}
+	return fmt.Sprintf("(%s AND %s BETWEEN %s AND %s)", lat, lngCol, q.arg(box.MinLng), q.arg(box.MaxLng))
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"penny-assesment/internal/domain"
+)
+
+func (s *Store) GetServiceArea(ctx context.Context, id string) (*domain.ServiceArea, error) {
+	return scanServiceArea(s.pool.QueryRow(ctx, serviceAreaSelectByIDSQL, id))
+}
+
+func (s *Store) ListServiceAreas(ctx context.Context) ([]*domain.ServiceArea, error) {
+	rows, err := s.pool.Query(ctx, serviceAreaListSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var areas []*domain.ServiceArea
+	for rows.Next() {
+		area, err := scanServiceArea(rows)
+		if err != nil {
+			return nil, err
+		}
+		areas = append(areas, area)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return areas, nil
+}
+
+func (t *Tx) CreateServiceArea(ctx context.Context, area *domain.ServiceArea) error {
+	boundary, err := json.Marshal(area.Boundary.GeoJSONCoordinates())
+	if err != nil {
+		return err
+	}
+	_, err = t.tx.Exec(ctx, serviceAreaInsertSQL,
+		area.ID,
+		area.Name,
+		boundary,
+		area.Active,
+		area.CreatedAt,
+		area.UpdatedAt,
+	)
+	if err != nil {
+		return mapError(err)
+	}
+	area.Version = 1
+	return nil
+}
+
+func (t *Tx) GetServiceAreaForUpdate(ctx context.Context, id string) (*domain.ServiceArea, error) {
+	return scanServiceArea(t.tx.QueryRow(ctx, serviceAreaSelectByIDForUpdateSQL, id))
+}
+
+func (t *Tx) UpdateServiceArea(ctx context.Context, area *domain.ServiceArea) error {
+	boundary, err := json.Marshal(area.Boundary.GeoJSONCoordinates())
+	if err != nil {
+		return err
+	}
+	row := t.tx.QueryRow(ctx, serviceAreaUpdateSQL,
+		area.Name,
+		boundary,
+		area.Active,
+		area.UpdatedAt,
+		area.ID,
+		area.Version,
+	)
+	return scanVersion(row, &area.Version)
+}
+
+func scanServiceArea(row pgx.Row) (*domain.ServiceArea, error) {
+	var boundary []byte
+	area := &domain.ServiceArea{}
+	err := row.Scan(&area.ID, &area.Name, &boundary, &area.Active, &area.CreatedAt, &area.UpdatedAt, &area.Version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	var coords [][][]float64
+	if err := json.Unmarshal(boundary, &coords); err != nil {
+		return nil, err
+	}
+	if area.Boundary, err = domain.PolygonFromGeoJSON(coords); err != nil {
+		return nil, err
+	}
+	return area, nil
+}
@@ -3,7 +3,8 @@ package postgres
 const orderSelectByIDSQL = `
 SELECT id, user_id, origin_lat, origin_lng, dest_lat, dest_lng, status,
        assigned_drone_id, handoff_origin_lat, handoff_origin_lng,
-       created_at, updated_at, reserved_at, picked_up_at, delivered_at, failed_at, failure_reason
+       created_at, updated_at, reserved_at, picked_up_at, delivered_at, failed_at, failure_reason, route, relay_legs,
+       pickup_not_before, deliver_by, sla_at_risk_at, version
 FROM orders
 WHERE id = $1
 `
@@ -13,22 +14,39 @@ const orderSelectByIDForUpdateSQL = orderSelectByIDSQL + " FOR UPDATE"
 const orderListSQL = `
 SELECT id, user_id, origin_lat, origin_lng, dest_lat, dest_lng, status,
        assigned_drone_id, handoff_origin_lat, handoff_origin_lng,
-       created_at, updated_at, reserved_at, picked_up_at, delivered_at, failed_at, failure_reason
+       created_at, updated_at, reserved_at, picked_up_at, delivered_at, failed_at, failure_reason, route, relay_legs,
+       pickup_not_before, deliver_by, sla_at_risk_at, version
 FROM orders
-WHERE ($1::text IS NULL OR status = $1)
-ORDER BY created_at
-LIMIT $2 OFFSET $3
+`
+
+const orderCountSQL = `
+SELECT count(*)
+FROM orders
+`
+
+// orderQueuePositionsSQL ranks by created_at alone so that orders created at
+// the same instant count none of each other, as CountOrders' CreatedTo does.
+const orderQueuePositionsSQL = `
+SELECT id, position
+FROM (
+  SELECT id, rank() OVER (ORDER BY created_at) - 1 AS position
+  FROM orders
+  WHERE status = ANY($1)
+) queued
+WHERE id = ANY($2)
 `
 
 const orderInsertSQL = `
 INSERT INTO orders (
   id, user_id, origin_lat, origin_lng, dest_lat, dest_lng, status,
   assigned_drone_id, handoff_origin_lat, handoff_origin_lng,
-  created_at, updated_at, reserved_at, picked_up_at, delivered_at, failed_at, failure_reason
+  created_at, updated_at, reserved_at, picked_up_at, delivered_at, failed_at, failure_reason, route, relay_legs,
+  pickup_not_before, deliver_by, sla_at_risk_at
 ) VALUES (
   $1,$2,$3,$4,$5,$6,$7,
   $8,$9,$10,
-  $11,$12,$13,$14,$15,$16,$17
+  $11,$12,$13,$14,$15,$16,$17,$18,$19,
+  $20,$21,$22
 )
 `
 
@@ -48,35 +66,76 @@ UPDATE orders SET
   picked_up_at = $12,
   delivered_at = $13,
   failed_at = $14,
-  failure_reason = $15
-WHERE id = $16
+  failure_reason = $15,
+  route = $16,
+  relay_legs = $17,
+  pickup_not_before = $18,
+  deliver_by = $19,
+  sla_at_risk_at = $20,
+  version = version + 1
+WHERE id = $21 AND version = $22
+RETURNING version
 `
 
-const orderReserveSQL = `
+// orderQueueSQL continues past the order keyed by $5-$7 when $6 is set;
+// 'infinity' stands in for the deadline the ORDER BY sorts NULLS LAST.
+const orderQueueSQL = `
 SELECT id, user_id, origin_lat, origin_lng, dest_lat, dest_lng, status,
        assigned_drone_id, handoff_origin_lat, handoff_origin_lng,
-       created_at, updated_at, reserved_at, picked_up_at, delivered_at, failed_at, failure_reason
+       created_at, updated_at, reserved_at, picked_up_at, delivered_at, failed_at, failure_reason, route, relay_legs,
+       pickup_not_before, deliver_by, sla_at_risk_at, version
 FROM orders
 WHERE status = ANY($1)
   AND assigned_drone_id IS NULL
-ORDER BY created_at
-LIMIT 1
+  AND (pickup_not_before IS NULL OR pickup_not_before <= $2)
+  AND ($6::timestamptz IS NULL OR
+       (COALESCE(CASE WHEN deliver_by <= $3 THEN deliver_by END, 'infinity'), created_at, id) >
+       (COALESCE($5::timestamptz, 'infinity'), $6::timestamptz, $7::uuid))
+ORDER BY CASE WHEN deliver_by <= $3 THEN deliver_by END NULLS LAST, created_at, id
+LIMIT $4
+`
+
+// orderReserveSQL rechecks under the lock what orderQueueSQL read without
+// one, since another drone may have reserved the order since.
+const orderReserveSQL = `
+SELECT id, user_id, origin_lat, origin_lng, dest_lat, dest_lng, status,
+       assigned_drone_id, handoff_origin_lat, handoff_origin_lng,
+       created_at, updated_at, reserved_at, picked_up_at, delivered_at, failed_at, failure_reason, route, relay_legs,
+       pickup_not_before, deliver_by, sla_at_risk_at, version
+FROM orders
+WHERE id = $1
+  AND status = ANY($2)
+  AND assigned_drone_id IS NULL
+  AND (pickup_not_before IS NULL OR pickup_not_before <= $3)
 FOR UPDATE SKIP LOCKED
 `
 
 const droneSelectByIDSQL = `
-SELECT id, status, last_lat, last_lng, last_heartbeat_at, current_order_id, created_at, updated_at
+SELECT id, status, last_lat, last_lng, last_heartbeat_at, current_order_id, created_at, updated_at, recent_fixes,
+  altitude_m, heading_deg, ground_speed_mps, battery_pct, fault_codes,
+  flight_seconds, flight_meters, service_flight_seconds, service_flight_meters, home_depot_id, stops, version
 FROM drones
 WHERE id = $1
 `
 
+const droneSelectByIDsSQL = `
+SELECT id, status, last_lat, last_lng, last_heartbeat_at, current_order_id, created_at, updated_at, recent_fixes,
+  altitude_m, heading_deg, ground_speed_mps, battery_pct, fault_codes,
+  flight_seconds, flight_meters, service_flight_seconds, service_flight_meters, home_depot_id, stops, version
+FROM drones
+WHERE id = ANY($1)
+`
+
 const droneSelectByIDForUpdateSQL = droneSelectByIDSQL + " FOR UPDATE"
 
 const droneInsertSQL = `
 INSERT INTO drones (
-  id, status, last_lat, last_lng, last_heartbeat_at, current_order_id, created_at, updated_at
+  id, status, last_lat, last_lng, last_heartbeat_at, current_order_id, created_at, updated_at, recent_fixes,
+  altitude_m, heading_deg, ground_speed_mps, battery_pct, fault_codes,
+  flight_seconds, flight_meters, service_flight_seconds, service_flight_meters, home_depot_id, stops
 ) VALUES (
-  $1,$2,$3,$4,$5,$6,$7,$8
+  $1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,
+  $15,$16,$17,$18,$19,$20
 )
 `
 
@@ -87,13 +146,38 @@ UPDATE drones SET
   last_lng = $3,
   last_heartbeat_at = $4,
   current_order_id = $5,
-  updated_at = $6
-WHERE id = $7
+  updated_at = $6,
+  recent_fixes = $7,
+  altitude_m = $8,
+  heading_deg = $9,
+  ground_speed_mps = $10,
+  battery_pct = $11,
+  fault_codes = $12,
+  flight_seconds = $13,
+  flight_meters = $14,
+  service_flight_seconds = $15,
+  service_flight_meters = $16,
+  home_depot_id = $17,
+  stops = $18,
+  version = version + 1
+WHERE id = $19 AND version = $20
+RETURNING version
 `
 
 const droneListSQL = `
-SELECT id, status, last_lat, last_lng, last_heartbeat_at, current_order_id, created_at, updated_at
+SELECT id, status, last_lat, last_lng, last_heartbeat_at, current_order_id, created_at, updated_at, recent_fixes,
+  altitude_m, heading_deg, ground_speed_mps, battery_pct, fault_codes,
+  flight_seconds, flight_meters, service_flight_seconds, service_flight_meters, home_depot_id, stops, version
+FROM drones
+ORDER BY id
+`
+
+const droneListByDepotSQL = `
+SELECT id, status, last_lat, last_lng, last_heartbeat_at, current_order_id, created_at, updated_at, recent_fixes,
+  altitude_m, heading_deg, ground_speed_mps, battery_pct, fault_codes,
+  flight_seconds, flight_meters, service_flight_seconds, service_flight_meters, home_depot_id, stops, version
 FROM drones
+WHERE home_depot_id = $1
 ORDER BY id
 `
 
@@ -116,3 +200,324 @@ UPDATE outbox_events
 SET published_at = now()
 WHERE id = ANY($1::uuid[])
 `
+
+// idempotencyLockSQL serialises transactions using the same key, including
+// before the first of them has inserted its row.
+const idempotencyLockSQL = `SELECT pg_advisory_xact_lock(hashtext($1), hashtext($2))`
+
+const idempotencySelectSQL = `
+SELECT scope, idempotency_key, request_hash, response, created_at, expires_at
+FROM idempotency_keys
+WHERE scope = $1 AND idempotency_key = $2
+`
+
+const idempotencyUpsertSQL = `
+INSERT INTO idempotency_keys (
+  scope, idempotency_key, request_hash, response, created_at, expires_at
+) VALUES ($1,$2,$3,$4,$5,$6)
+ON CONFLICT (scope, idempotency_key) DO UPDATE SET
+  request_hash = EXCLUDED.request_hash,
+  response = EXCLUDED.response,
+  created_at = EXCLUDED.created_at,
+  expires_at = EXCLUDED.expires_at
+`
+
+const idempotencyPruneSQL = `
+DELETE FROM idempotency_keys
+WHERE expires_at <= $1
+`
+
+// geographyPointSQL builds the search point from $1 (lng) and $2 (lat).
+// PostGIS distances are taken on the sphere to match domain.DistanceMeters.
+const geographyPointSQL = `ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography`
+
+const orderWithinRadiusPostGISSQL = `
+SELECT id, user_id, origin_lat, origin_lng, dest_lat, dest_lng, status,
+       assigned_drone_id, handoff_origin_lat, handoff_origin_lng,
+       created_at, updated_at, reserved_at, picked_up_at, delivered_at, failed_at, failure_reason, route, relay_legs,
+       pickup_not_before, deliver_by, sla_at_risk_at, version
+FROM orders
+WHERE ST_DWithin(origin_geog, ` + geographyPointSQL + `, $3, false)
+  AND (cardinality($4::text[]) = 0 OR status = ANY($4))
+ORDER BY ST_Distance(origin_geog, ` + geographyPointSQL + `, false), id
+LIMIT $5
+`
+
+const droneNearestIdlePostGISSQL = `
+SELECT id, status, last_lat, last_lng, last_heartbeat_at, current_order_id, created_at, updated_at, recent_fixes,
+  altitude_m, heading_deg, ground_speed_mps, battery_pct, fault_codes,
+  flight_seconds, flight_meters, service_flight_seconds, service_flight_meters, home_depot_id, stops, version
+FROM drones
+WHERE status = ANY($3) AND current_order_id IS NULL AND last_geog IS NOT NULL
+ORDER BY last_geog <-> ` + geographyPointSQL + `, id
+LIMIT $4
+`
+
+const droneIdleSQL = `
+SELECT id, status, last_lat, last_lng, last_heartbeat_at, current_order_id, created_at, updated_at, recent_fixes,
+  altitude_m, heading_deg, ground_speed_mps, battery_pct, fault_codes,
+  flight_seconds, flight_meters, service_flight_seconds, service_flight_meters, home_depot_id, stops, version
+FROM drones
+WHERE status = ANY($1) AND current_order_id IS NULL
+  AND last_lat IS NOT NULL AND last_lng IS NOT NULL
+`
+
+const postgisAvailableSQL = `SELECT EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'postgis')`
+
+const postgisSchemaSQL = `
+SELECT EXISTS (
+  SELECT 1 FROM information_schema.columns
+  WHERE table_schema = current_schema() AND table_name = 'drones' AND column_name = 'last_geog'
+)
+`
+
+const serviceAreaColumns = `id, name, boundary, active, created_at, updated_at, version`
+
+const serviceAreaSelectByIDSQL = `
+SELECT ` + serviceAreaColumns + `
+FROM service_areas
+WHERE id = $1
+`
+
+const serviceAreaSelectByIDForUpdateSQL = serviceAreaSelectByIDSQL + `FOR UPDATE
+`
+
+const serviceAreaListSQL = `
+SELECT ` + serviceAreaColumns + `
+FROM service_areas
+ORDER BY id
+`
+
+const serviceAreaInsertSQL = `
+INSERT INTO service_areas (id, name, boundary, active, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+const serviceAreaUpdateSQL = `
+UPDATE service_areas SET
+  name = $1,
+  boundary = $2,
+  active = $3,
+  updated_at = $4,
+  version = version + 1
+WHERE id = $5 AND version = $6
+RETURNING version
+`
+
+const noFlyZoneColumns = `id, name, boundary, active_from, active_until, created_at, updated_at, version`
+
+const noFlyZoneSelectByIDSQL = `
+SELECT ` + noFlyZoneColumns + `
+FROM no_fly_zones
+WHERE id = $1
+`
+
+const noFlyZoneSelectByIDForUpdateSQL = noFlyZoneSelectByIDSQL + `FOR UPDATE
+`
+
+const noFlyZoneListSQL = `
+SELECT ` + noFlyZoneColumns + `
+FROM no_fly_zones
+ORDER BY id
+`
+
+const noFlyZoneInsertSQL = `
+INSERT INTO no_fly_zones (id, name, boundary, active_from, active_until, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
+const noFlyZoneUpdateSQL = `
+UPDATE no_fly_zones SET
+  name = $1,
+  boundary = $2,
+  active_from = $3,
+  active_until = $4,
+  updated_at = $5,
+  version = version + 1
+WHERE id = $6 AND version = $7
+RETURNING version
+`
+
+const telemetryInsertSQL = `
+INSERT INTO drone_telemetry (drone_id, order_id, lat, lng, recorded_at)
+VALUES ($1,$2,$3,$4,$5)
+`
+
+const telemetryListSQL = `
+SELECT drone_id, order_id, lat, lng, recorded_at
+FROM drone_telemetry
+`
+
+const telemetryPruneSQL = `
+DELETE FROM drone_telemetry
+WHERE recorded_at < $1
+`
+
+// telemetryPartitionSQL creates the partition for the month starting at $1;
+// see migrations/021_telemetry_partitions_ahead.sql.
+const telemetryPartitionSQL = `SELECT ensure_drone_telemetry_partition($1::date)`
+
+// telemetryPartitionsSQL lists drone_telemetry's monthly partitions; the
+// default partition is left out.
+const telemetryPartitionsSQL = `
+SELECT c.relname
+FROM pg_inherits i
+JOIN pg_class c ON c.oid = i.inhrelid
+JOIN pg_class p ON p.oid = i.inhparent
+WHERE p.relname = 'drone_telemetry'
+  AND c.relname LIKE 'drone\_telemetry\_____\___'
+`
+
+const maintenanceColumns = `id, drone_id, reason, fault_codes, flight_seconds, flight_meters,
+  opened_at, closed_at, technician, notes, parts`
+
+const maintenanceInsertSQL = `
+INSERT INTO maintenance_records (` + maintenanceColumns + `)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+`
+
+const maintenanceSelectOpenForUpdateSQL = `
+SELECT ` + maintenanceColumns + `
+FROM maintenance_records
+WHERE drone_id = $1 AND closed_at IS NULL
+FOR UPDATE
+`
+
+const maintenanceListSQL = `
+SELECT ` + maintenanceColumns + `
+FROM maintenance_records
+WHERE drone_id = $1
+ORDER BY opened_at, id
+`
+
+const maintenanceUpdateSQL = `
+UPDATE maintenance_records SET
+  reason = $1,
+  fault_codes = $2,
+  closed_at = $3,
+  technician = $4,
+  notes = $5,
+  parts = $6
+WHERE id = $7
+`
+
+const depotColumns = `id, name, lat, lng, capacity, created_at, updated_at, version`
+
+const depotSelectByIDSQL = `
+SELECT ` + depotColumns + `
+FROM depots
+WHERE id = $1
+`
+
+const depotSelectByIDForUpdateSQL = depotSelectByIDSQL + `FOR UPDATE
+`
+
+const depotListSQL = `
+SELECT ` + depotColumns + `
+FROM depots
+ORDER BY id
+`
+
+const depotInsertSQL = `
+INSERT INTO depots (id, name, lat, lng, capacity, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
+const depotUpdateSQL = `
+UPDATE depots SET
+  name = $1,
+  lat = $2,
+  lng = $3,
+  capacity = $4,
+  updated_at = $5,
+  version = version + 1
+WHERE id = $6 AND version = $7
+RETURNING version
+`
+
+const chargingStationColumns = `id, name, lat, lng, slots, created_at, updated_at, version`
+
+const chargingStationSelectByIDSQL = `
+SELECT ` + chargingStationColumns + `
+FROM charging_stations
+WHERE id = $1
+`
+
+const chargingStationSelectByIDForUpdateSQL = chargingStationSelectByIDSQL + `FOR UPDATE
+`
+
+const chargingStationListSQL = `
+SELECT ` + chargingStationColumns + `
+FROM charging_stations
+ORDER BY id
+`
+
+const chargingStationInsertSQL = `
+INSERT INTO charging_stations (id, name, lat, lng, slots, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
+const chargingStationUpdateSQL = `
+UPDATE charging_stations SET
+  name = $1,
+  lat = $2,
+  lng = $3,
+  slots = $4,
+  updated_at = $5,
+  version = version + 1
+WHERE id = $6 AND version = $7
+RETURNING version
+`
+
+const chargingSlotColumns = `station_id, slot, drone_id, reserved_at`
+
+// chargingSlotFillSQL creates the station's missing slots numbered 1..$2.
+const chargingSlotFillSQL = `
+INSERT INTO charging_slots (station_id, slot)
+SELECT $1, n FROM generate_series(1, $2) AS n
+ON CONFLICT DO NOTHING
+`
+
+const chargingSlotTrimSQL = `
+DELETE FROM charging_slots
+WHERE station_id = $1 AND slot > $2
+`
+
+const chargingSlotListSQL = `
+SELECT ` + chargingSlotColumns + `
+FROM charging_slots
+WHERE station_id = $1
+ORDER BY slot
+`
+
+const chargingSlotOccupancySQL = `
+SELECT station_id, count(*)
+FROM charging_slots
+WHERE drone_id IS NOT NULL
+GROUP BY station_id
+`
+
+const chargingSlotSelectByDroneSQL = `
+SELECT ` + chargingSlotColumns + `
+FROM charging_slots
+WHERE drone_id = $1
+`
+
+const chargingSlotSelectByDroneForUpdateSQL = chargingSlotSelectByDroneSQL + `FOR UPDATE
+`
+
+const chargingSlotReserveSQL = `
+SELECT ` + chargingSlotColumns + `
+FROM charging_slots
+WHERE station_id = $1 AND drone_id IS NULL
+ORDER BY slot
+LIMIT 1
+FOR UPDATE SKIP LOCKED
+`
+
+const chargingSlotUpdateSQL = `
+UPDATE charging_slots SET
+  drone_id = $1,
+  reserved_at = $2
+WHERE station_id = $3 AND slot = $4
+`
@@ -3,10 +3,12 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"penny-assesment/internal/domain"
@@ -16,6 +18,9 @@ import (
 
 type Store struct {
 	pool *pgxpool.Pool
+	// postgis selects the geography-column implementation of the spatial
+	// queries; see EnablePostGIS.
+	postgis bool
 }
 
 func NewStore(pool *pgxpool.Pool) *Store {
@@ -36,20 +41,54 @@ func (s *Store) GetOrder(ctx context.Context, id string) (*domain.Order, error)
 }
 
 func (s *Store) ListOrders(ctx context.Context, filter service.OrderFilter) ([]*domain.Order, error) {
-	status := sql.NullString{}
-	if filter.Status != nil {
-		status = sql.NullString{String: string(*filter.Status), Valid: true}
+	query, args, err := buildOrderListQuery(filter)
+	if err != nil {
+		return nil, err
 	}
-	limit := filter.Limit
-	if limit <= 0 || limit > 500 {
-		limit = 100
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
 	}
-	rows, err := s.pool.Query(ctx, orderListSQL, status, limit, filter.Offset)
+	return collectOrders(rows)
+}
+
+func (s *Store) CountOrders(ctx context.Context, filter service.OrderFilter) (int, error) {
+	query, args := buildOrderCountQuery(filter)
+	var count int
+	if err := s.pool.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (s *Store) QueuePositions(ctx context.Context, statuses []domain.OrderStatus, ids []string) (map[string]int, error) {
+	if len(ids) == 0 {
+		return map[string]int{}, nil
+	}
+	rows, err := s.pool.Query(ctx, orderQueuePositionsSQL, statusValues(statuses), ids)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	positions := make(map[string]int, len(ids))
+	for rows.Next() {
+		var id string
+		var position int
+		if err := rows.Scan(&id, &position); err != nil {
+			return nil, err
+		}
+		positions[id] = position
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return positions, nil
+}
+
+func collectOrders(rows pgx.Rows) ([]*domain.Order, error) {
+	defer rows.Close()
+
 	var orders []*domain.Order
 	for rows.Next() {
 		order, err := scanOrder(rows)
@@ -65,7 +104,15 @@ func (s *Store) ListOrders(ctx context.Context, filter service.OrderFilter) ([]*
 }
 
 func (s *Store) CreateOrder(ctx context.Context, order *domain.Order) error {
-	_, err := s.pool.Exec(ctx, orderInsertSQL,
+	route, err := routeJSON(order.Route)
+	if err != nil {
+		return err
+	}
+	legs, err := relayLegsJSON(order.Legs)
+	if err != nil {
+		return err
+	}
+	_, err = s.pool.Exec(ctx, orderInsertSQL,
 		order.ID,
 		order.UserID,
 		order.Origin.Lat,
@@ -83,8 +130,17 @@ func (s *Store) CreateOrder(ctx context.Context, order *domain.Order) error {
 		nullTime(order.DeliveredAt),
 		nullTime(order.FailedAt),
 		nullString(order.FailureReason),
+		route,
+		legs,
+		nullTime(order.PickupNotBefore),
+		nullTime(order.DeliverBy),
+		nullTime(order.SLAAtRiskAt),
 	)
-	return err
+	if err != nil {
+		return mapError(err)
+	}
+	order.Version = 1
+	return nil
 }
 
 func (s *Store) GetDrone(ctx context.Context, id string) (*domain.Drone, error) {
@@ -92,11 +148,26 @@ func (s *Store) GetDrone(ctx context.Context, id string) (*domain.Drone, error)
 	return scanDrone(row)
 }
 
+func (s *Store) GetDrones(ctx context.Context, ids []string) ([]*domain.Drone, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	rows, err := s.pool.Query(ctx, droneSelectByIDsSQL, ids)
+	if err != nil {
+		return nil, err
+	}
+	return collectDrones(rows)
+}
+
 func (s *Store) ListDrones(ctx context.Context) ([]*domain.Drone, error) {
 	rows, err := s.pool.Query(ctx, droneListSQL)
 	if err != nil {
 		return nil, err
 	}
+	return collectDrones(rows)
+}
+
+func collectDrones(rows pgx.Rows) ([]*domain.Drone, error) {
 	defer rows.Close()
 
 	var drones []*domain.Drone
@@ -136,7 +207,15 @@ func (t *Tx) GetDroneForUpdate(ctx context.Context, id string) (*domain.Drone, e
 }
 
 func (t *Tx) CreateDrone(ctx context.Context, drone *domain.Drone) error {
-	_, err := t.tx.Exec(ctx, droneInsertSQL,
+	fixes, err := fixesJSON(drone.RecentFixes)
+	if err != nil {
+		return err
+	}
+	stops, err := stopsJSON(drone.Stops)
+	if err != nil {
+		return err
+	}
+	_, err = t.tx.Exec(ctx, droneInsertSQL,
 		drone.ID,
 		drone.Status,
 		nullLocationLat(drone.LastLocation),
@@ -145,12 +224,36 @@ func (t *Tx) CreateDrone(ctx context.Context, drone *domain.Drone) error {
 		nullString(drone.CurrentOrderID),
 		drone.CreatedAt,
 		drone.UpdatedAt,
+		fixes,
+		drone.Vitals.AltitudeMeters,
+		drone.Vitals.HeadingDegrees,
+		drone.Vitals.GroundSpeedMPS,
+		drone.Vitals.BatteryPercent,
+		drone.Vitals.FaultCodes,
+		drone.Usage.Seconds,
+		drone.Usage.Meters,
+		drone.SinceService.Seconds,
+		drone.SinceService.Meters,
+		drone.HomeDepotID,
+		stops,
 	)
-	return err
+	if err != nil {
+		return mapError(err)
+	}
+	drone.Version = 1
+	return nil
 }
 
 func (t *Tx) CreateOrder(ctx context.Context, order *domain.Order) error {
-	_, err := t.tx.Exec(ctx, orderInsertSQL,
+	route, err := routeJSON(order.Route)
+	if err != nil {
+		return err
+	}
+	legs, err := relayLegsJSON(order.Legs)
+	if err != nil {
+		return err
+	}
+	_, err = t.tx.Exec(ctx, orderInsertSQL,
 		order.ID,
 		order.UserID,
 		order.Origin.Lat,
@@ -168,12 +271,31 @@ func (t *Tx) CreateOrder(ctx context.Context, order *domain.Order) error {
 		nullTime(order.DeliveredAt),
 		nullTime(order.FailedAt),
 		nullString(order.FailureReason),
+		route,
+		legs,
+		nullTime(order.PickupNotBefore),
+		nullTime(order.DeliverBy),
+		nullTime(order.SLAAtRiskAt),
 	)
-	return err
+	if err != nil {
+		return mapError(err)
+	}
+	order.Version = 1
+	return nil
 }
 
+// UpdateOrder writes order if its Version still matches the stored row and
+// sets order.Version to the incremented value.
 func (t *Tx) UpdateOrder(ctx context.Context, order *domain.Order) error {
-	_, err := t.tx.Exec(ctx, orderUpdateSQL,
+	route, err := routeJSON(order.Route)
+	if err != nil {
+		return err
+	}
+	legs, err := relayLegsJSON(order.Legs)
+	if err != nil {
+		return err
+	}
+	row := t.tx.QueryRow(ctx, orderUpdateSQL,
 		order.UserID,
 		order.Origin.Lat,
 		order.Origin.Lng,
@@ -189,49 +311,85 @@ func (t *Tx) UpdateOrder(ctx context.Context, order *domain.Order) error {
 		nullTime(order.DeliveredAt),
 		nullTime(order.FailedAt),
 		nullString(order.FailureReason),
+		route,
+		legs,
+		nullTime(order.PickupNotBefore),
+		nullTime(order.DeliverBy),
+		nullTime(order.SLAAtRiskAt),
 		order.ID,
+		order.Version,
 	)
-	return err
+	return scanVersion(row, &order.Version)
 }
 
 func (t *Tx) UpdateDrone(ctx context.Context, drone *domain.Drone) error {
-	_, err := t.tx.Exec(ctx, droneUpdateSQL,
+	fixes, err := fixesJSON(drone.RecentFixes)
+	if err != nil {
+		return err
+	}
+	stops, err := stopsJSON(drone.Stops)
+	if err != nil {
+		return err
+	}
+	row := t.tx.QueryRow(ctx, droneUpdateSQL,
 		drone.Status,
 		nullLocationLat(drone.LastLocation),
 		nullLocationLng(drone.LastLocation),
 		nullTime(drone.LastHeartbeatAt),
 		nullString(drone.CurrentOrderID),
 		drone.UpdatedAt,
+		fixes,
+		drone.Vitals.AltitudeMeters,
+		drone.Vitals.HeadingDegrees,
+		drone.Vitals.GroundSpeedMPS,
+		drone.Vitals.BatteryPercent,
+		drone.Vitals.FaultCodes,
+		drone.Usage.Seconds,
+		drone.Usage.Meters,
+		drone.SinceService.Seconds,
+		drone.SinceService.Meters,
+		drone.HomeDepotID,
+		stops,
 		drone.ID,
+		drone.Version,
 	)
-	return err
+	return scanVersion(row, &drone.Version)
 }
 
-func (t *Tx) ReserveNextOrder(ctx context.Context, allowed []domain.OrderStatus) (*domain.Order, error) {
+func (t *Tx) QueuedOrders(ctx context.Context, allowed []domain.OrderStatus, due, urgent time.Time, after *domain.Order, limit int) ([]*domain.Order, error) {
 	if len(allowed) == 0 {
 		return nil, nil
 	}
-	allowedVals := make([]string, 0, len(allowed))
-	for _, status := range allowed {
-		allowedVals = append(allowedVals, string(status))
+	var afterDeadline, afterCreated *time.Time
+	var afterID *string
+	if after != nil {
+		afterDeadline, afterCreated, afterID = service.QueueDeadline(after, urgent), &after.CreatedAt, &after.ID
 	}
-	rows, err := t.tx.Query(ctx, orderReserveSQL, allowedVals)
+	rows, err := t.tx.Query(ctx, orderQueueSQL, statusValues(allowed), due, urgent, limit,
+		nullTime(afterDeadline), nullTime(afterCreated), afterID)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return collectOrders(rows)
+}
 
-	if !rows.Next() {
-		if rows.Err() != nil {
-			return nil, rows.Err()
-		}
+func (t *Tx) ReserveOrder(ctx context.Context, id string, allowed []domain.OrderStatus, due time.Time) (*domain.Order, error) {
+	if len(allowed) == 0 {
 		return nil, nil
 	}
-	order, err := scanOrder(rows)
-	if err != nil {
-		return nil, err
+	order, err := scanOrder(t.tx.QueryRow(ctx, orderReserveSQL, id, statusValues(allowed), due))
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, nil
 	}
-	return order, nil
+	return order, err
+}
+
+func statusValues(statuses []domain.OrderStatus) []string {
+	values := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		values = append(values, string(status))
+	}
+	return values
 }
 
 func (t *Tx) EnqueueEvent(ctx context.Context, event events.Event) error {
@@ -256,6 +414,11 @@ func scanOrder(row pgx.Row) (*domain.Order, error) {
 		deliveredAt     sql.NullTime
 		failedAt        sql.NullTime
 		failureReason   sql.NullString
+		route           []byte
+		relayLegs       []byte
+		pickupNotBefore sql.NullTime
+		deliverBy       sql.NullTime
+		slaAtRiskAt     sql.NullTime
 	)
 	order := &domain.Order{}
 	err := row.Scan(
@@ -276,6 +439,12 @@ func scanOrder(row pgx.Row) (*domain.Order, error) {
 		&deliveredAt,
 		&failedAt,
 		&failureReason,
+		&route,
+		&relayLegs,
+		&pickupNotBefore,
+		&deliverBy,
+		&slaAtRiskAt,
+		&order.Version,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -304,15 +473,52 @@ func scanOrder(row pgx.Row) (*domain.Order, error) {
 	if failureReason.Valid {
 		order.FailureReason = &failureReason.String
 	}
+	if pickupNotBefore.Valid {
+		order.PickupNotBefore = &pickupNotBefore.Time
+	}
+	if deliverBy.Valid {
+		order.DeliverBy = &deliverBy.Time
+	}
+	if slaAtRiskAt.Valid {
+		order.SLAAtRiskAt = &slaAtRiskAt.Time
+	}
+	if route != nil {
+		var coords [][]float64
+		if err := json.Unmarshal(route, &coords); err != nil {
+			return nil, err
+		}
+		if order.Route, err = domain.LineStringFromCoordinates(coords); err != nil {
+			return nil, err
+		}
+	}
+	if relayLegs != nil {
+		var legs []relayLegJSON
+		if err := json.Unmarshal(relayLegs, &legs); err != nil {
+			return nil, err
+		}
+		for _, leg := range legs {
+			order.Legs = append(order.Legs, leg.domain())
+		}
+	}
 	return order, nil
 }
 
+// routeJSON encodes route as GeoJSON LineString coordinates; nil stores NULL.
+func routeJSON(route []domain.Location) ([]byte, error) {
+	if route == nil {
+		return nil, nil
+	}
+	return json.Marshal(domain.LineStringCoordinates(route))
+}
+
 func scanDrone(row pgx.Row) (*domain.Drone, error) {
 	var (
 		lastLat         sql.NullFloat64
 		lastLng         sql.NullFloat64
 		lastHeartbeatAt sql.NullTime
 		currentOrderID  sql.NullString
+		recentFixes     []byte
+		stops           []byte
 	)
 	drone := &domain.Drone{}
 	err := row.Scan(
@@ -324,6 +530,19 @@ func scanDrone(row pgx.Row) (*domain.Drone, error) {
 		&currentOrderID,
 		&drone.CreatedAt,
 		&drone.UpdatedAt,
+		&recentFixes,
+		&drone.Vitals.AltitudeMeters,
+		&drone.Vitals.HeadingDegrees,
+		&drone.Vitals.GroundSpeedMPS,
+		&drone.Vitals.BatteryPercent,
+		&drone.Vitals.FaultCodes,
+		&drone.Usage.Seconds,
+		&drone.Usage.Meters,
+		&drone.SinceService.Seconds,
+		&drone.SinceService.Meters,
+		&drone.HomeDepotID,
+		&stops,
+		&drone.Version,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -340,9 +559,139 @@ func scanDrone(row pgx.Row) (*domain.Drone, error) {
 	if currentOrderID.Valid {
 		drone.CurrentOrderID = &currentOrderID.String
 	}
+	if recentFixes != nil {
+		var fixes []fixJSON
+		if err := json.Unmarshal(recentFixes, &fixes); err != nil {
+			return nil, err
+		}
+		for _, fix := range fixes {
+			drone.RecentFixes = append(drone.RecentFixes, domain.Fix{Location: domain.Location{Lat: fix.Lat, Lng: fix.Lng}, At: fix.At})
+		}
+	}
+	if stops != nil {
+		var stored []stopJSON
+		if err := json.Unmarshal(stops, &stored); err != nil {
+			return nil, err
+		}
+		for _, stop := range stored {
+			drone.Stops = append(drone.Stops, stop.domain())
+		}
+	}
 	return drone, nil
 }
 
+// fixJSON is the stored form of a domain.Fix.
+type fixJSON struct {
+	Lat float64   `json:"lat"`
+	Lng float64   `json:"lng"`
+	At  time.Time `json:"at"`
+}
+
+// fixesJSON encodes fixes as a JSON array; none stores NULL.
+func fixesJSON(fixes []domain.Fix) ([]byte, error) {
+	if len(fixes) == 0 {
+		return nil, nil
+	}
+	stored := make([]fixJSON, 0, len(fixes))
+	for _, fix := range fixes {
+		stored = append(stored, fixJSON{Lat: fix.Location.Lat, Lng: fix.Location.Lng, At: fix.At.UTC()})
+	}
+	return json.Marshal(stored)
+}
+
+// stopJSON is the stored form of a domain.Stop.
+type stopJSON struct {
+	OrderID     string          `json:"order_id"`
+	Kind        domain.StopKind `json:"kind"`
+	Lat         float64         `json:"lat"`
+	Lng         float64         `json:"lng"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+}
+
+func (stop stopJSON) domain() domain.Stop {
+	return domain.Stop{
+		OrderID:     stop.OrderID,
+		Kind:        stop.Kind,
+		Location:    domain.Location{Lat: stop.Lat, Lng: stop.Lng},
+		CompletedAt: stop.CompletedAt,
+	}
+}
+
+// stopsJSON encodes stops as a JSON array; none stores NULL.
+func stopsJSON(stops []domain.Stop) ([]byte, error) {
+	if len(stops) == 0 {
+		return nil, nil
+	}
+	stored := make([]stopJSON, 0, len(stops))
+	for _, stop := range stops {
+		item := stopJSON{OrderID: stop.OrderID, Kind: stop.Kind, Lat: stop.Location.Lat, Lng: stop.Location.Lng}
+		if stop.CompletedAt != nil {
+			at := stop.CompletedAt.UTC()
+			item.CompletedAt = &at
+		}
+		stored = append(stored, item)
+	}
+	return json.Marshal(stored)
+}
+
+// relayLegJSON is the stored form of a domain.RelayLeg.
+type relayLegJSON struct {
+	Lat          float64    `json:"lat"`
+	Lng          float64    `json:"lng"`
+	RelayPointID string     `json:"relay_point_id,omitempty"`
+	DroneID      *string    `json:"drone_id,omitempty"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+}
+
+func (leg relayLegJSON) domain() domain.RelayLeg {
+	return domain.RelayLeg{
+		To:           domain.Location{Lat: leg.Lat, Lng: leg.Lng},
+		RelayPointID: leg.RelayPointID,
+		DroneID:      leg.DroneID,
+		CompletedAt:  leg.CompletedAt,
+	}
+}
+
+// relayLegsJSON encodes legs as a JSON array; none stores NULL.
+func relayLegsJSON(legs []domain.RelayLeg) ([]byte, error) {
+	if len(legs) == 0 {
+		return nil, nil
+	}
+	stored := make([]relayLegJSON, 0, len(legs))
+	for _, leg := range legs {
+		item := relayLegJSON{Lat: leg.To.Lat, Lng: leg.To.Lng, RelayPointID: leg.RelayPointID, DroneID: leg.DroneID}
+		if leg.CompletedAt != nil {
+			at := leg.CompletedAt.UTC()
+			item.CompletedAt = &at
+		}
+		stored = append(stored, item)
+	}
+	return json.Marshal(stored)
+}
+
+// scanVersion reads the RETURNING version of an update. No row means the
+// stored version moved on (or the row is gone).
+func scanVersion(row pgx.Row, version *int64) error {
+	if err := row.Scan(version); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrVersionMismatch
+		}
+		return err
+	}
+	return nil
+}
+
+const uniqueViolationCode = "23505"
+
+// mapError turns unique violations (duplicate IDs) into domain.ErrConflict.
+func mapError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+		return domain.ErrConflict
+	}
+	return err
+}
+
 func nullString(v *string) sql.NullString {
 	if v == nil {
 		return sql.NullString{}
@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"penny-assesment/internal/domain"
+	"penny-assesment/internal/repo/storetest"
+	"penny-assesment/internal/service"
+)
+
+// testPool connects to the disposable database named by
+// STORETEST_POSTGRES_URL and migrates it, or skips the test.
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	url := os.Getenv("STORETEST_POSTGRES_URL")
+	if url == "" {
+		t.Skip("STORETEST_POSTGRES_URL not set")
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, url)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	if err := ApplyMigrations(ctx, pool, "../../../migrations"); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return pool
+}
+
+// TestConformance runs against a disposable database named by
+// STORETEST_POSTGRES_URL; every scenario truncates its tables.
+func TestConformance(t *testing.T) {
+	ctx := context.Background()
+	pool := testPool(t)
+	postgis, err := SetupPostGIS(ctx, pool, "../../../migrations/postgis", true)
+	if err != nil {
+		t.Fatalf("postgis: %v", err)
+	}
+	newStore := func(withPostGIS bool) storetest.Factory {
+		return func(t *testing.T) storetest.Store {
+			if _, err := pool.Exec(ctx, `TRUNCATE orders, drones, outbox_events, idempotency_keys, service_areas, no_fly_zones, drone_telemetry, maintenance_records, depots, charging_slots, charging_stations`); err != nil {
+				t.Fatalf("truncate: %v", err)
+			}
+			store := NewStore(pool)
+			if withPostGIS {
+				store.EnablePostGIS()
+			}
+			return store
+		}
+	}
+	t.Run("haversine", func(t *testing.T) {
+		storetest.Run(t, newStore(false))
+	})
+	t.Run("postgis", func(t *testing.T) {
+		if !postgis {
+			t.Skip("postgis extension not available")
+		}
+		storetest.Run(t, newStore(true))
+	})
+}
+
+// TestTelemetryPartitionAfterMissedMonth drops next month's partition, as if
+// upkeep had never created it, and lets a heartbeat land in the default
+// partition. PruneTelemetry must still create the partition and move the row
+// into it.
+func TestTelemetryPartitionAfterMissedMonth(t *testing.T) {
+	ctx := context.Background()
+	pool := testPool(t)
+	next := monthStart(time.Now()).AddDate(0, 1, 0)
+	partition := pgx.Identifier{next.Format(telemetryPartitionLayout)}.Sanitize()
+	if _, err := pool.Exec(ctx, `TRUNCATE drone_telemetry`); err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+	if _, err := pool.Exec(ctx, `DROP TABLE IF EXISTS `+partition); err != nil {
+		t.Fatalf("drop partition: %v", err)
+	}
+
+	store := NewStore(pool)
+	tx, err := store.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	sample := &domain.TelemetrySample{DroneID: "drone-1", Location: domain.Location{Lat: 1, Lng: 1}, RecordedAt: next.Add(time.Hour)}
+	if err := tx.AppendTelemetry(ctx, sample); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := store.PruneTelemetry(ctx, time.Time{}); err != nil {
+			t.Fatalf("prune run %d: %v", i+1, err)
+		}
+	}
+	count := func(table string) int {
+		t.Helper()
+		var n int
+		if err := pool.QueryRow(ctx, `SELECT count(*) FROM `+table).Scan(&n); err != nil {
+			t.Fatalf("count %s: %v", table, err)
+		}
+		return n
+	}
+	if got := count(partition); got != 1 {
+		t.Fatalf("expected the sample moved into %s, got %d rows", partition, got)
+	}
+	if got := count("drone_telemetry_default"); got != 0 {
+		t.Fatalf("expected the default partition emptied, got %d rows", got)
+	}
+	samples, err := store.ListTelemetry(ctx, service.TelemetryQuery{DroneID: "drone-1"})
+	if err != nil || len(samples) != 1 {
+		t.Fatalf("expected the sample still listed, got %d err=%v", len(samples), err)
+	}
+	var attached bool
+	if err := pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM pg_inherits WHERE inhrelid = 'drone_telemetry_default'::regclass)`).Scan(&attached); err != nil || !attached {
+		t.Fatalf("expected the default partition reattached, got %v err=%v", attached, err)
+	}
+}
@@ -0,0 +1,169 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"penny-assesment/internal/domain"
+	"penny-assesment/internal/service"
+)
+
+// telemetryPartitionLayout names drone_telemetry's monthly partitions.
+const telemetryPartitionLayout = "drone_telemetry_2006_01"
+
+// telemetryMonthsAhead is how many months past the current one PruneTelemetry
+// keeps partitions ready for.
+const telemetryMonthsAhead = 3
+
+func (s *Store) ListTelemetry(ctx context.Context, query service.TelemetryQuery) ([]*domain.TelemetrySample, error) {
+	stmt, args := buildTelemetryListQuery(query)
+	rows, err := s.pool.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var samples []*domain.TelemetrySample
+	for rows.Next() {
+		sample, err := scanTelemetrySample(rows)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, sample)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return samples, nil
+}
+
+// PruneTelemetry also creates the partitions for this month and the
+// telemetryMonthsAhead after it, moving in any rows the default partition
+// caught for them. Monthly partitions that end by before are dropped whole;
+// older rows left in the others are deleted.
+func (s *Store) PruneTelemetry(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	month := monthStart(time.Now())
+	for i := 0; i <= telemetryMonthsAhead; i++ {
+		if _, err := tx.Exec(ctx, telemetryPartitionSQL, month.AddDate(0, i, 0)); err != nil {
+			return 0, err
+		}
+	}
+
+	var pruned int64
+	if !before.IsZero() {
+		expired, err := expiredTelemetryPartitions(ctx, tx, before)
+		if err != nil {
+			return 0, err
+		}
+		for _, name := range expired {
+			table := pgx.Identifier{name}.Sanitize()
+			var count int64
+			if err := tx.QueryRow(ctx, `SELECT count(*) FROM `+table).Scan(&count); err != nil {
+				return 0, err
+			}
+			if _, err := tx.Exec(ctx, `DROP TABLE `+table); err != nil {
+				return 0, err
+			}
+			pruned += count
+		}
+		tag, err := tx.Exec(ctx, telemetryPruneSQL, before)
+		if err != nil {
+			return 0, err
+		}
+		pruned += tag.RowsAffected()
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return pruned, nil
+}
+
+func (t *Tx) AppendTelemetry(ctx context.Context, sample *domain.TelemetrySample) error {
+	_, err := t.tx.Exec(ctx, telemetryInsertSQL,
+		sample.DroneID,
+		nullString(sample.OrderID),
+		sample.Location.Lat,
+		sample.Location.Lng,
+		sample.RecordedAt,
+	)
+	return mapError(err)
+}
+
+// expiredTelemetryPartitions returns the monthly partitions whose month ends
+// by before.
+func expiredTelemetryPartitions(ctx context.Context, tx pgx.Tx, before time.Time) ([]string, error) {
+	rows, err := tx.Query(ctx, telemetryPartitionsSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var expired []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		start, err := time.Parse(telemetryPartitionLayout, name)
+		if err != nil {
+			continue
+		}
+		if !start.AddDate(0, 1, 0).After(before) {
+			expired = append(expired, name)
+		}
+	}
+	return expired, rows.Err()
+}
+
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func buildTelemetryListQuery(query service.TelemetryQuery) (string, []any) {
+	q := &orderQuery{}
+	if query.DroneID != "" {
+		q.add("drone_id = " + q.arg(query.DroneID))
+	}
+	if query.OrderID != "" {
+		q.add("order_id = " + q.arg(query.OrderID))
+	}
+	if query.From != nil {
+		q.add("recorded_at >= " + q.arg(*query.From))
+	}
+	if query.To != nil {
+		q.add("recorded_at < " + q.arg(*query.To))
+	}
+
+	var b strings.Builder
+	b.WriteString(telemetryListSQL)
+	q.writeWhere(&b)
+	b.WriteString("ORDER BY recorded_at\n")
+	if query.Limit > 0 {
+		fmt.Fprintf(&b, "LIMIT %s\n", q.arg(query.Limit))
+	}
+	return b.String(), q.args
+}
+
+func scanTelemetrySample(row pgx.Row) (*domain.TelemetrySample, error) {
+	var orderID sql.NullString
+	sample := &domain.TelemetrySample{}
+	if err := row.Scan(&sample.DroneID, &orderID, &sample.Location.Lat, &sample.Location.Lng, &sample.RecordedAt); err != nil {
+		return nil, err
+	}
+	if orderID.Valid {
+		sample.OrderID = &orderID.String
+	}
+	return sample, nil
+}
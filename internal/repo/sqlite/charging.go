@@ -0,0 +1,202 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"penny-assesment/internal/domain"
+)
+
+func (s *Store) GetChargingStation(ctx context.Context, id string) (*domain.ChargingStation, error) {
+	return scanChargingStation(s.db.QueryRowContext(ctx, chargingStationSelectByIDSQL, id))
+}
+
+func (s *Store) ListChargingStations(ctx context.Context) ([]*domain.ChargingStation, error) {
+	rows, err := s.db.QueryContext(ctx, chargingStationListSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stations []*domain.ChargingStation
+	for rows.Next() {
+		station, err := scanChargingStation(rows)
+		if err != nil {
+			return nil, err
+		}
+		stations = append(stations, station)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return stations, nil
+}
+
+func (s *Store) ListChargingSlots(ctx context.Context, stationID string) ([]*domain.ChargingSlot, error) {
+	rows, err := s.db.QueryContext(ctx, chargingSlotListSQL, stationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var slots []*domain.ChargingSlot
+	for rows.Next() {
+		slot, err := scanChargingSlot(rows)
+		if err != nil {
+			return nil, err
+		}
+		slots = append(slots, slot)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return slots, nil
+}
+
+func (s *Store) CountOccupiedSlots(ctx context.Context) (map[string]int, error) {
+	rows, err := s.db.QueryContext(ctx, chargingSlotOccupancySQL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	occupied := make(map[string]int)
+	for rows.Next() {
+		var stationID string
+		var n int
+		if err := rows.Scan(&stationID, &n); err != nil {
+			return nil, err
+		}
+		occupied[stationID] = n
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return occupied, nil
+}
+
+func (s *Store) GetDroneChargingSlot(ctx context.Context, droneID string) (*domain.ChargingSlot, error) {
+	return scanChargingSlot(s.db.QueryRowContext(ctx, chargingSlotSelectByDroneSQL, droneID))
+}
+
+func (t *Tx) CreateChargingStation(ctx context.Context, station *domain.ChargingStation) error {
+	_, err := t.tx.ExecContext(ctx, chargingStationInsertSQL,
+		station.ID,
+		station.Name,
+		station.Location.Lat,
+		station.Location.Lng,
+		station.Slots,
+		formatTime(station.CreatedAt),
+		formatTime(station.UpdatedAt),
+	)
+	if err != nil {
+		return mapError(err)
+	}
+	station.Version = 1
+	return t.resizeSlots(ctx, station)
+}
+
+// GetChargingStationForUpdate needs no row lock: the transaction already
+// holds the database write lock.
+func (t *Tx) GetChargingStationForUpdate(ctx context.Context, id string) (*domain.ChargingStation, error) {
+	return scanChargingStation(t.tx.QueryRowContext(ctx, chargingStationSelectByIDSQL, id))
+}
+
+func (t *Tx) UpdateChargingStation(ctx context.Context, station *domain.ChargingStation) error {
+	row := t.tx.QueryRowContext(ctx, chargingStationUpdateSQL,
+		station.Name,
+		station.Location.Lat,
+		station.Location.Lng,
+		station.Slots,
+		formatTime(station.UpdatedAt),
+		station.ID,
+		station.Version,
+	)
+	if err := scanVersion(row, &station.Version); err != nil {
+		return err
+	}
+	return t.resizeSlots(ctx, station)
+}
+
+func (t *Tx) ReserveFreeChargingSlot(ctx context.Context, stationID string) (*domain.ChargingSlot, error) {
+	slot, err := scanChargingSlot(t.tx.QueryRowContext(ctx, chargingSlotReserveSQL, stationID))
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, nil
+	}
+	return slot, err
+}
+
+func (t *Tx) GetDroneChargingSlotForUpdate(ctx context.Context, droneID string) (*domain.ChargingSlot, error) {
+	return scanChargingSlot(t.tx.QueryRowContext(ctx, chargingSlotSelectByDroneSQL, droneID))
+}
+
+func (t *Tx) UpdateChargingSlot(ctx context.Context, slot *domain.ChargingSlot) error {
+	res, err := t.tx.ExecContext(ctx, chargingSlotUpdateSQL,
+		nullString(slot.DroneID),
+		nullTime(slot.ReservedAt),
+		slot.StationID,
+		slot.Number,
+	)
+	if err != nil {
+		return mapError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (t *Tx) resizeSlots(ctx context.Context, station *domain.ChargingStation) error {
+	if _, err := t.tx.ExecContext(ctx, chargingSlotTrimSQL, station.ID, station.Slots); err != nil {
+		return err
+	}
+	for n := 1; n <= station.Slots; n++ {
+		if _, err := t.tx.ExecContext(ctx, chargingSlotInsertSQL, station.ID, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scanChargingStation(row rowScanner) (*domain.ChargingStation, error) {
+	var createdAt, updatedAt string
+	station := &domain.ChargingStation{}
+	err := row.Scan(&station.ID, &station.Name, &station.Location.Lat, &station.Location.Lng, &station.Slots, &createdAt, &updatedAt, &station.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	if station.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if station.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+	return station, nil
+}
+
+func scanChargingSlot(row rowScanner) (*domain.ChargingSlot, error) {
+	var droneID, reservedAt sql.NullString
+	slot := &domain.ChargingSlot{}
+	err := row.Scan(&slot.StationID, &slot.Number, &droneID, &reservedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	if droneID.Valid {
+		slot.DroneID = &droneID.String
+	}
+	if slot.ReservedAt, err = parseNullTime(reservedAt); err != nil {
+		return nil, err
+	}
+	return slot, nil
+}
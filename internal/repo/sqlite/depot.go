@@ -0,0 +1,97 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"penny-assesment/internal/domain"
+)
+
+func (s *Store) GetDepot(ctx context.Context, id string) (*domain.Depot, error) {
+	return scanDepot(s.db.QueryRowContext(ctx, depotSelectByIDSQL, id))
+}
+
+func (s *Store) ListDepots(ctx context.Context) ([]*domain.Depot, error) {
+	rows, err := s.db.QueryContext(ctx, depotListSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var depots []*domain.Depot
+	for rows.Next() {
+		depot, err := scanDepot(rows)
+		if err != nil {
+			return nil, err
+		}
+		depots = append(depots, depot)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return depots, nil
+}
+
+func (s *Store) ListDepotDrones(ctx context.Context, depotID string) ([]*domain.Drone, error) {
+	rows, err := s.db.QueryContext(ctx, droneListByDepotSQL, depotID)
+	if err != nil {
+		return nil, err
+	}
+	return collectDrones(rows)
+}
+
+func (t *Tx) CreateDepot(ctx context.Context, depot *domain.Depot) error {
+	_, err := t.tx.ExecContext(ctx, depotInsertSQL,
+		depot.ID,
+		depot.Name,
+		depot.Location.Lat,
+		depot.Location.Lng,
+		depot.Capacity,
+		formatTime(depot.CreatedAt),
+		formatTime(depot.UpdatedAt),
+	)
+	if err != nil {
+		return mapError(err)
+	}
+	depot.Version = 1
+	return nil
+}
+
+// GetDepotForUpdate needs no row lock: the transaction already holds the
+// database write lock.
+func (t *Tx) GetDepotForUpdate(ctx context.Context, id string) (*domain.Depot, error) {
+	return scanDepot(t.tx.QueryRowContext(ctx, depotSelectByIDSQL, id))
+}
+
+func (t *Tx) UpdateDepot(ctx context.Context, depot *domain.Depot) error {
+	row := t.tx.QueryRowContext(ctx, depotUpdateSQL,
+		depot.Name,
+		depot.Location.Lat,
+		depot.Location.Lng,
+		depot.Capacity,
+		formatTime(depot.UpdatedAt),
+		depot.ID,
+		depot.Version,
+	)
+	return scanVersion(row, &depot.Version)
+}
+
+func scanDepot(row rowScanner) (*domain.Depot, error) {
+	var createdAt, updatedAt string
+	depot := &domain.Depot{}
+	err := row.Scan(&depot.ID, &depot.Name, &depot.Location.Lat, &depot.Location.Lng, &depot.Capacity, &createdAt, &updatedAt, &depot.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	if depot.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if depot.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+	return depot, nil
+}
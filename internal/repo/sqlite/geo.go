@@ -0,0 +1,60 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"penny-assesment/internal/domain"
+	"penny-assesment/internal/service"
+)
+
+// OrdersWithinRadius prefilters on the bounding box of the circle, which the
+// (origin_lat, origin_lng) index can serve, and leaves the exact haversine
+// check and ordering to service.FilterOrdersWithinRadius.
+func (s *Store) OrdersWithinRadius(ctx context.Context, query service.RadiusQuery) ([]*domain.Order, error) {
+	q := &orderQuery{}
+	q.add(boxPredicate(q, "origin_lat", "origin_lng", domain.BoundingBoxAround(query.Center, query.RadiusMeters)))
+	if len(query.Statuses) > 0 {
+		placeholders := make([]string, 0, len(query.Statuses))
+		for _, status := range query.Statuses {
+			placeholders = append(placeholders, q.arg(string(status)))
+		}
+		q.add("status IN (" + strings.Join(placeholders, ",") + ")")
+	}
+	rows, err := s.db.QueryContext(ctx, orderListSQL+"WHERE "+strings.Join(q.where, "\n  AND "), q.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []*domain.Order
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return service.FilterOrdersWithinRadius(orders, query), nil
+}
+
+func (s *Store) NearestIdleDrones(ctx context.Context, point domain.Location, limit int) ([]*domain.Drone, error) {
+	statuses := domain.AssignableDroneStatuses
+	args := make([]any, 0, len(statuses))
+	for _, status := range statuses {
+		args = append(args, string(status))
+	}
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(droneIdleSQL, inPlaceholders(len(statuses))), args...)
+	if err != nil {
+		return nil, err
+	}
+	drones, err := collectDrones(rows)
+	if err != nil {
+		return nil, err
+	}
+	return service.NearestDrones(drones, point, limit), nil
+}
@@ -0,0 +1,58 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"penny-assesment/internal/domain"
+)
+
+// GetIdempotencyRecord needs no explicit key lock: the transaction already
+// holds the database write lock.
+func (t *Tx) GetIdempotencyRecord(ctx context.Context, scope, key string) (*domain.IdempotencyRecord, error) {
+	var createdAt, expiresAt string
+	record := &domain.IdempotencyRecord{}
+	err := t.tx.QueryRowContext(ctx, idempotencySelectSQL, scope, key).Scan(
+		&record.Scope,
+		&record.Key,
+		&record.RequestHash,
+		&record.Response,
+		&createdAt,
+		&expiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	if record.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if record.ExpiresAt, err = parseTime(expiresAt); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (t *Tx) SaveIdempotencyRecord(ctx context.Context, record *domain.IdempotencyRecord) error {
+	_, err := t.tx.ExecContext(ctx, idempotencyUpsertSQL,
+		record.Scope,
+		record.Key,
+		record.RequestHash,
+		record.Response,
+		formatTime(record.CreatedAt),
+		formatTime(record.ExpiresAt),
+	)
+	return err
+}
+
+func (s *Store) PruneIdempotencyRecords(ctx context.Context, now time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, idempotencyPruneSQL, formatTime(now))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,146 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"penny-assesment/internal/domain"
+)
+
+func (s *Store) ListMaintenanceRecords(ctx context.Context, droneID string) ([]*domain.MaintenanceRecord, error) {
+	rows, err := s.db.QueryContext(ctx, maintenanceListSQL, droneID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []*domain.MaintenanceRecord
+	for rows.Next() {
+		record, err := scanMaintenanceRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return records, nil
+}
+
+// CreateMaintenanceRecord relies on the partial unique index on open records
+// to refuse a second open record for a drone.
+func (t *Tx) CreateMaintenanceRecord(ctx context.Context, record *domain.MaintenanceRecord) error {
+	faults, err := nullStringList(record.FaultCodes)
+	if err != nil {
+		return err
+	}
+	parts, err := nullStringList(record.Parts)
+	if err != nil {
+		return err
+	}
+	_, err = t.tx.ExecContext(ctx, maintenanceInsertSQL,
+		record.ID,
+		record.DroneID,
+		string(record.Reason),
+		faults,
+		record.Usage.Seconds,
+		record.Usage.Meters,
+		formatTime(record.OpenedAt),
+		nullTime(record.ClosedAt),
+		nullString(record.Technician),
+		nullString(record.Notes),
+		parts,
+	)
+	return mapError(err)
+}
+
+// GetOpenMaintenanceRecordForUpdate needs no row lock: the transaction
+// already holds the database write lock.
+func (t *Tx) GetOpenMaintenanceRecordForUpdate(ctx context.Context, droneID string) (*domain.MaintenanceRecord, error) {
+	return scanMaintenanceRecord(t.tx.QueryRowContext(ctx, maintenanceSelectOpenSQL, droneID))
+}
+
+func (t *Tx) UpdateMaintenanceRecord(ctx context.Context, record *domain.MaintenanceRecord) error {
+	faults, err := nullStringList(record.FaultCodes)
+	if err != nil {
+		return err
+	}
+	parts, err := nullStringList(record.Parts)
+	if err != nil {
+		return err
+	}
+	res, err := t.tx.ExecContext(ctx, maintenanceUpdateSQL,
+		string(record.Reason),
+		faults,
+		nullTime(record.ClosedAt),
+		nullString(record.Technician),
+		nullString(record.Notes),
+		parts,
+		record.ID,
+	)
+	if err != nil {
+		return mapError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func scanMaintenanceRecord(row rowScanner) (*domain.MaintenanceRecord, error) {
+	var (
+		reason     string
+		faultCodes sql.NullString
+		openedAt   string
+		closedAt   sql.NullString
+		technician sql.NullString
+		notes      sql.NullString
+		parts      sql.NullString
+	)
+	record := &domain.MaintenanceRecord{}
+	err := row.Scan(
+		&record.ID,
+		&record.DroneID,
+		&reason,
+		&faultCodes,
+		&record.Usage.Seconds,
+		&record.Usage.Meters,
+		&openedAt,
+		&closedAt,
+		&technician,
+		&notes,
+		&parts,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	record.Reason = domain.MaintenanceReason(reason)
+	if record.OpenedAt, err = parseTime(openedAt); err != nil {
+		return nil, err
+	}
+	if record.ClosedAt, err = parseNullTime(closedAt); err != nil {
+		return nil, err
+	}
+	if technician.Valid {
+		record.Technician = &technician.String
+	}
+	if notes.Valid {
+		record.Notes = &notes.String
+	}
+	if record.FaultCodes, err = parseStringList(faultCodes); err != nil {
+		return nil, err
+	}
+	if record.Parts, err = parseStringList(parts); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
@@ -0,0 +1,82 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+// The SQLite schema is embedded so a depot deployment is a single binary.
+//
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+func ApplyMigrations(ctx context.Context, db *sql.DB) error {
+	if err := ensureMigrationsTable(ctx, db); err != nil {
+		return err
+	}
+	entries, err := fs.ReadDir(migrationFS, "migrations")
+	if err != nil {
+		return err
+	}
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".sql") {
+			files = append(files, entry.Name())
+		}
+	}
+	sort.Strings(files)
+	for _, version := range files {
+		applied, err := isMigrationApplied(ctx, db, version)
+		if err != nil {
+			return err
+		}
+		if applied {
+			continue
+		}
+		content, err := fs.ReadFile(migrationFS, "migrations/"+version)
+		if err != nil {
+			return err
+		}
+		if _, err := db.ExecContext(ctx, string(content)); err != nil {
+			return fmt.Errorf("apply %s: %w", version, err)
+		}
+		if err := markMigrationApplied(ctx, db, version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ensureMigrationsTable(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+  version TEXT PRIMARY KEY,
+  applied_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+)
+`)
+	return err
+}
+
+func isMigrationApplied(ctx context.Context, db *sql.DB, version string) (bool, error) {
+	row := db.QueryRowContext(ctx, `SELECT 1 FROM schema_migrations WHERE version = ?`, version)
+	var one int
+	err := row.Scan(&one)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return false, err
+}
+
+func markMigrationApplied(ctx context.Context, db *sql.DB, version string) error {
+	_, err := db.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES (?)`, version)
+	return err
+}
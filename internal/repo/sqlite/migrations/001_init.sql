@@ -0,0 +1,52 @@
+-- Timestamps are stored as fixed-width UTC RFC3339 text (see formatTime) so
+-- that string comparison and ORDER BY match chronological order.
+CREATE TABLE IF NOT EXISTS orders (
+  id TEXT PRIMARY KEY,
+  user_id TEXT NOT NULL,
+  origin_lat REAL NOT NULL,
+  origin_lng REAL NOT NULL,
+  dest_lat REAL NOT NULL,
+  dest_lng REAL NOT NULL,
+  status TEXT NOT NULL,
+  assigned_drone_id TEXT NULL,
+  handoff_origin_lat REAL NULL,
+  handoff_origin_lng REAL NULL,
+  created_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL,
+  reserved_at TEXT NULL,
+  picked_up_at TEXT NULL,
+  delivered_at TEXT NULL,
+  failed_at TEXT NULL,
+  failure_reason TEXT NULL
+);
+
+CREATE TABLE IF NOT EXISTS drones (
+  id TEXT PRIMARY KEY,
+  status TEXT NOT NULL,
+  last_lat REAL NULL,
+  last_lng REAL NULL,
+  last_heartbeat_at TEXT NULL,
+  current_order_id TEXT NULL,
+  created_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_status_created ON orders (status, created_at);
+CREATE INDEX IF NOT EXISTS idx_orders_user ON orders (user_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_orders_created_id ON orders (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_orders_updated_id ON orders (updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_orders_assigned_drone ON orders (assigned_drone_id);
+CREATE INDEX IF NOT EXISTS idx_drones_status ON drones (status);
+CREATE INDEX IF NOT EXISTS idx_drones_current_order ON drones (current_order_id);
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+  id TEXT PRIMARY KEY,
+  event_type TEXT NOT NULL,
+  aggregate_type TEXT NOT NULL,
+  aggregate_id TEXT NOT NULL,
+  payload BLOB NOT NULL,
+  occurred_at TEXT NOT NULL,
+  published_at TEXT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_published ON outbox_events (published_at, occurred_at);
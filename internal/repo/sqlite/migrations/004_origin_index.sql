@@ -0,0 +1,2 @@
+-- Serves the bounding-box prefilter of OrdersWithinRadius.
+CREATE INDEX IF NOT EXISTS idx_orders_origin ON orders (origin_lat, origin_lng);
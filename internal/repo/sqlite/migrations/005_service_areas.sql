@@ -0,0 +1,10 @@
+-- boundary holds GeoJSON Polygon coordinates ([lng, lat] positions).
+CREATE TABLE IF NOT EXISTS service_areas (
+  id TEXT PRIMARY KEY,
+  name TEXT NOT NULL,
+  boundary TEXT NOT NULL,
+  active INTEGER NOT NULL,
+  created_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL,
+  version INTEGER NOT NULL DEFAULT 1
+);
@@ -0,0 +1,12 @@
+-- boundary holds GeoJSON Polygon coordinates ([lng, lat] positions). A NULL
+-- window end is open.
+CREATE TABLE IF NOT EXISTS no_fly_zones (
+  id TEXT PRIMARY KEY,
+  name TEXT NOT NULL,
+  boundary TEXT NOT NULL,
+  active_from TEXT NULL,
+  active_until TEXT NULL,
+  created_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL,
+  version INTEGER NOT NULL DEFAULT 1
+);
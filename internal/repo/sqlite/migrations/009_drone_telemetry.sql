@@ -0,0 +1,13 @@
+-- drone_telemetry keeps every heartbeat position for track exports. order_id
+-- is the order the drone was carrying out at the time, if any.
+CREATE TABLE IF NOT EXISTS drone_telemetry (
+  drone_id TEXT NOT NULL,
+  order_id TEXT NULL,
+  lat REAL NOT NULL,
+  lng REAL NOT NULL,
+  recorded_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS drone_telemetry_drone_idx ON drone_telemetry (drone_id, recorded_at);
+CREATE INDEX IF NOT EXISTS drone_telemetry_order_idx ON drone_telemetry (order_id, recorded_at) WHERE order_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS drone_telemetry_recorded_idx ON drone_telemetry (recorded_at);
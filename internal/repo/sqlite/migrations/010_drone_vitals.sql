@@ -0,0 +1,7 @@
+-- The readings of each drone's latest heartbeat; NULL when not reported.
+-- fault_codes holds a JSON array of strings.
+ALTER TABLE drones ADD COLUMN altitude_m REAL NULL;
+ALTER TABLE drones ADD COLUMN heading_deg REAL NULL;
+ALTER TABLE drones ADD COLUMN ground_speed_mps REAL NULL;
+ALTER TABLE drones ADD COLUMN battery_pct REAL NULL;
+ALTER TABLE drones ADD COLUMN fault_codes TEXT NULL;
@@ -0,0 +1,4 @@
+-- Drones are only looked up by status to find idle ones to dispatch. Retired
+-- drones pile up over time, so index just the drones free of an order.
+DROP INDEX IF EXISTS idx_drones_status;
+CREATE INDEX IF NOT EXISTS idx_drones_status_idle ON drones (status) WHERE current_order_id IS NULL;
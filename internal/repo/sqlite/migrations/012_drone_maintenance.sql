@@ -0,0 +1,25 @@
+-- The flying each drone has done on its orders, in total and since it was
+-- last serviced.
+ALTER TABLE drones ADD COLUMN flight_seconds REAL NOT NULL DEFAULT 0;
+ALTER TABLE drones ADD COLUMN flight_meters REAL NOT NULL DEFAULT 0;
+ALTER TABLE drones ADD COLUMN service_flight_seconds REAL NOT NULL DEFAULT 0;
+ALTER TABLE drones ADD COLUMN service_flight_meters REAL NOT NULL DEFAULT 0;
+
+-- One row per spell a drone spends BROKEN or in MAINTENANCE. fault_codes and
+-- parts hold JSON arrays of strings; closed_at is NULL while the spell lasts.
+CREATE TABLE IF NOT EXISTS maintenance_records (
+  id TEXT PRIMARY KEY,
+  drone_id TEXT NOT NULL,
+  reason TEXT NOT NULL,
+  fault_codes TEXT NULL,
+  flight_seconds REAL NOT NULL,
+  flight_meters REAL NOT NULL,
+  opened_at TEXT NOT NULL,
+  closed_at TEXT NULL,
+  technician TEXT NULL,
+  notes TEXT NULL,
+  parts TEXT NULL
+);
+
+CREATE INDEX IF NOT EXISTS maintenance_records_drone_idx ON maintenance_records (drone_id, opened_at);
+CREATE UNIQUE INDEX IF NOT EXISTS maintenance_records_open_idx ON maintenance_records (drone_id) WHERE closed_at IS NULL;
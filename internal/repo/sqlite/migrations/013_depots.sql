@@ -0,0 +1,15 @@
+-- Depots drones are based at; a drone's home_depot_id is NULL if it has none.
+CREATE TABLE IF NOT EXISTS depots (
+  id TEXT PRIMARY KEY,
+  name TEXT NOT NULL,
+  lat REAL NOT NULL,
+  lng REAL NOT NULL,
+  capacity INTEGER NOT NULL,
+  created_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL,
+  version INTEGER NOT NULL DEFAULT 1
+);
+
+ALTER TABLE drones ADD COLUMN home_depot_id TEXT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_drones_home_depot ON drones (home_depot_id);
@@ -0,0 +1,22 @@
+-- Charging stations and their slots, numbered from 1. A slot's drone_id is
+-- set while a drone holds it; a drone holds at most one slot.
+CREATE TABLE IF NOT EXISTS charging_stations (
+  id TEXT PRIMARY KEY,
+  name TEXT NOT NULL,
+  lat REAL NOT NULL,
+  lng REAL NOT NULL,
+  slots INTEGER NOT NULL,
+  created_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL,
+  version INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE TABLE IF NOT EXISTS charging_slots (
+  station_id TEXT NOT NULL REFERENCES charging_stations (id),
+  slot INTEGER NOT NULL,
+  drone_id TEXT NULL,
+  reserved_at TEXT NULL,
+  PRIMARY KEY (station_id, slot)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_charging_slots_drone ON charging_slots (drone_id) WHERE drone_id IS NOT NULL;
@@ -0,0 +1,3 @@
+-- relay_legs holds the hops of an order relayed between drones as a JSON
+-- array; NULL for orders one drone flies end to end.
+ALTER TABLE orders ADD COLUMN relay_legs TEXT NULL;
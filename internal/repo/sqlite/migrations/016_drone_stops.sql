@@ -0,0 +1,3 @@
+-- stops holds the pickups and dropoffs of a drone's current trip, in flight
+-- order, as a JSON array; NULL between trips.
+ALTER TABLE drones ADD COLUMN stops TEXT NULL;
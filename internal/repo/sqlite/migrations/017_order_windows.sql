@@ -0,0 +1,5 @@
+-- pickup_not_before and deliver_by are an order's optional delivery window;
+-- sla_at_risk_at records when its ETA was first found to miss deliver_by.
+ALTER TABLE orders ADD COLUMN pickup_not_before TEXT NULL;
+ALTER TABLE orders ADD COLUMN deliver_by TEXT NULL;
+ALTER TABLE orders ADD COLUMN sla_at_risk_at TEXT NULL;
@@ -0,0 +1,2 @@
+-- Serves the sweep that deletes expired idempotency keys.
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
@@ -0,0 +1,117 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"penny-assesment/internal/domain"
+)
+
+func (s *Store) GetNoFlyZone(ctx context.Context, id string) (*domain.NoFlyZone, error) {
+	return scanNoFlyZone(s.db.QueryRowContext(ctx, noFlyZoneSelectByIDSQL, id))
+}
+
+func (s *Store) ListNoFlyZones(ctx context.Context) ([]*domain.NoFlyZone, error) {
+	rows, err := s.db.QueryContext(ctx, noFlyZoneListSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var zones []*domain.NoFlyZone
+	for rows.Next() {
+		zone, err := scanNoFlyZone(rows)
+		if err != nil {
+			return nil, err
+		}
+		zones = append(zones, zone)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return zones, nil
+}
+
+func (t *Tx) CreateNoFlyZone(ctx context.Context, zone *domain.NoFlyZone) error {
+	boundary, err := json.Marshal(zone.Boundary.GeoJSONCoordinates())
+	if err != nil {
+		return err
+	}
+	_, err = t.tx.ExecContext(ctx, noFlyZoneInsertSQL,
+		zone.ID,
+		zone.Name,
+		string(boundary),
+		nullTime(zone.ActiveFrom),
+		nullTime(zone.ActiveUntil),
+		formatTime(zone.CreatedAt),
+		formatTime(zone.UpdatedAt),
+	)
+	if err != nil {
+		return mapError(err)
+	}
+	zone.Version = 1
+	return nil
+}
+
+// GetNoFlyZoneForUpdate needs no row lock: the transaction already holds the
+// database write lock.
+func (t *Tx) GetNoFlyZoneForUpdate(ctx context.Context, id string) (*domain.NoFlyZone, error) {
+	return scanNoFlyZone(t.tx.QueryRowContext(ctx, noFlyZoneSelectByIDSQL, id))
+}
+
+func (t *Tx) UpdateNoFlyZone(ctx context.Context, zone *domain.NoFlyZone) error {
+	boundary, err := json.Marshal(zone.Boundary.GeoJSONCoordinates())
+	if err != nil {
+		return err
+	}
+	row := t.tx.QueryRowContext(ctx, noFlyZoneUpdateSQL,
+		zone.Name,
+		string(boundary),
+		nullTime(zone.ActiveFrom),
+		nullTime(zone.ActiveUntil),
+		formatTime(zone.UpdatedAt),
+		zone.ID,
+		zone.Version,
+	)
+	return scanVersion(row, &zone.Version)
+}
+
+func scanNoFlyZone(row rowScanner) (*domain.NoFlyZone, error) {
+	var (
+		boundary    string
+		activeFrom  sql.NullString
+		activeUntil sql.NullString
+		createdAt   string
+		updatedAt   string
+	)
+	zone := &domain.NoFlyZone{}
+	err := row.Scan(&zone.ID, &zone.Name, &boundary, &activeFrom, &activeUntil, &createdAt, &updatedAt, &zone.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	var coords [][][]float64
+	if err := json.Unmarshal([]byte(boundary), &coords); err != nil {
+		return nil, err
+	}
+	if zone.Boundary, err = domain.PolygonFromGeoJSON(coords); err != nil {
+		return nil, err
+	}
+	if zone.ActiveFrom, err = parseNullTime(activeFrom); err != nil {
+		return nil, err
+	}
+	if zone.ActiveUntil, err = parseNullTime(activeUntil); err != nil {
+		return nil, err
+	}
+	if zone.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if zone.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+	return zone, nil
+}
@@ -0,0 +1,58 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"penny-assesment/internal/events"
+)
+
+func (s *Store) FetchPending(ctx context.Context, limit int) ([]events.Event, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	rows, err := s.db.QueryContext(ctx, outboxFetchPendingSQL, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var evts []events.Event
+	for rows.Next() {
+		evt, err := scanOutboxEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		evts = append(evts, evt)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return evts, nil
+}
+
+func (s *Store) MarkPublished(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	args := append([]any{formatTime(time.Now())}, stringArgs(ids)...)
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(outboxMarkPublishedSQL, inPlaceholders(len(ids))), args...)
+	return err
+}
+
+func scanOutboxEvent(row rowScanner) (events.Event, error) {
+	var payload []byte
+	var occurredAt string
+	var evt events.Event
+	if err := row.Scan(&evt.ID, &evt.Type, &evt.AggregateType, &evt.AggregateID, &payload, &occurredAt); err != nil {
+		return events.Event{}, err
+	}
+	t, err := parseTime(occurredAt)
+	if err != nil {
+		return events.Event{}, err
+	}
+	evt.Payload = payload
+	evt.OccurredAt = t
+	return evt, nil
+}
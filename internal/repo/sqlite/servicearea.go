@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"penny-assesment/internal/domain"
+)
+
+func (s *Store) GetServiceArea(ctx context.Context, id string) (*domain.ServiceArea, error) {
+	return scanServiceArea(s.db.QueryRowContext(ctx, serviceAreaSelectByIDSQL, id))
+}
+
+func (s *Store) ListServiceAreas(ctx context.Context) ([]*domain.ServiceArea, error) {
+	rows, err := s.db.QueryContext(ctx, serviceAreaListSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var areas []*domain.ServiceArea
+	for rows.Next() {
+		area, err := scanServiceArea(rows)
+		if err != nil {
+			return nil, err
+		}
+		areas = append(areas, area)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return areas, nil
+}
+
+func (t *Tx) CreateServiceArea(ctx context.Context, area *domain.ServiceArea) error {
+	boundary, err := json.Marshal(area.Boundary.GeoJSONCoordinates())
+	if err != nil {
+		return err
+	}
+	_, err = t.tx.ExecContext(ctx, serviceAreaInsertSQL,
+		area.ID,
+		area.Name,
+		string(boundary),
+		area.Active,
+		formatTime(area.CreatedAt),
+		formatTime(area.UpdatedAt),
+	)
+	if err != nil {
+		return mapError(err)
+	}
+	area.Version = 1
+	return nil
+}
+
+// GetServiceAreaForUpdate needs no row lock: the transaction already holds
+// the database write lock.
+func (t *Tx) GetServiceAreaForUpdate(ctx context.Context, id string) (*domain.ServiceArea, error) {
+	return scanServiceArea(t.tx.QueryRowContext(ctx, serviceAreaSelectByIDSQL, id))
+}
+
+func (t *Tx) UpdateServiceArea(ctx context.Context, area *domain.ServiceArea) error {
+	boundary, err := json.Marshal(area.Boundary.GeoJSONCoordinates())
+	if err != nil {
+		return err
+	}
+	row := t.tx.QueryRowContext(ctx, serviceAreaUpdateSQL,
+		area.Name,
+		string(boundary),
+		area.Active,
+		formatTime(area.UpdatedAt),
+		area.ID,
+		area.Version,
+	)
+	return scanVersion(row, &area.Version)
+}
+
+func scanServiceArea(row rowScanner) (*domain.ServiceArea, error) {
+	var (
+		boundary  string
+		createdAt string
+		updatedAt string
+	)
+	area := &domain.ServiceArea{}
+	err := row.Scan(&area.ID, &area.Name, &boundary, &area.Active, &createdAt, &updatedAt, &area.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	var coords [][][]float64
+	if err := json.Unmarshal([]byte(boundary), &coords); err != nil {
+		return nil, err
+	}
+	if area.Boundary, err = domain.PolygonFromGeoJSON(coords); err != nil {
+		return nil, err
+	}
+	if area.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if area.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+	return area, nil
+}
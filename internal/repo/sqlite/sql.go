@@ -0,0 +1,439 @@
+package sqlite
+
+const orderColumns = `id, user_id, origin_lat, origin_lng, dest_lat, dest_lng, status,
+       assigned_drone_id, handoff_origin_lat, handoff_origin_lng,
+       created_at, updated_at, reserved_at, picked_up_at, delivered_at, failed_at, failure_reason, route, relay_legs,
+       pickup_not_before, deliver_by, sla_at_risk_at, version`
+
+const droneColumns = `id, status, last_lat, last_lng, last_heartbeat_at, current_order_id, created_at, updated_at, recent_fixes,
+  altitude_m, heading_deg, ground_speed_mps, battery_pct, fault_codes,
+  flight_seconds, flight_meters, service_flight_seconds, service_flight_meters, home_depot_id, stops, version`
+
+const orderSelectByIDSQL = `
+SELECT ` + orderColumns + `
+FROM orders
+WHERE id = ?
+`
+
+const orderListSQL = `
+SELECT ` + orderColumns + `
+FROM orders
+`
+
+const orderCountSQL = `
+SELECT count(*)
+FROM orders
+`
+
+// orderQueuePositionsSQL ranks by created_at alone so that orders created at
+// the same instant count none of each other, as CountOrders' CreatedTo does.
+// The placeholders are filled in with the statuses, then the IDs.
+const orderQueuePositionsSQL = `
+SELECT id, position
+FROM (
+  SELECT id, rank() OVER (ORDER BY created_at) - 1 AS position
+  FROM orders
+  WHERE status IN (%s)
+) queued
+WHERE id IN (%s)
+`
+
+const orderInsertSQL = `
+INSERT INTO orders (
+  id, user_id, origin_lat, origin_lng, dest_lat, dest_lng, status,
+  assigned_drone_id, handoff_origin_lat, handoff_origin_lng,
+  created_at, updated_at, reserved_at, picked_up_at, delivered_at, failed_at, failure_reason, route, relay_legs,
+  pickup_not_before, deliver_by, sla_at_risk_at
+) VALUES (
+  ?,?,?,?,?,?,?,
+  ?,?,?,
+  ?,?,?,?,?,?,?,?,?,
+  ?,?,?
+)
+`
+
+const orderUpdateSQL = `
+UPDATE orders SET
+  user_id = ?,
+  origin_lat = ?,
+  origin_lng = ?,
+  dest_lat = ?,
+  dest_lng = ?,
+  status = ?,
+  assigned_drone_id = ?,
+  handoff_origin_lat = ?,
+  handoff_origin_lng = ?,
+  updated_at = ?,
+  reserved_at = ?,
+  picked_up_at = ?,
+  delivered_at = ?,
+  failed_at = ?,
+  failure_reason = ?,
+  route = ?,
+  relay_legs = ?,
+  pickup_not_before = ?,
+  deliver_by = ?,
+  sla_at_risk_at = ?,
+  version = version + 1
+WHERE id = ? AND version = ?
+RETURNING version
+`
+
+// orderQueueSQL's status placeholders are expanded by inPlaceholders. A
+// non-NULL created_at argument continues past that order; '9999' sorts after
+// every stored time, standing in for the deadline sorted NULLS LAST.
+const orderQueueSQL = `
+SELECT ` + orderColumns + `
+FROM orders
+WHERE status IN (%s)
+  AND assigned_drone_id IS NULL
+  AND (pickup_not_before IS NULL OR pickup_not_before <= ?)
+  AND (? IS NULL OR
+       (COALESCE(CASE WHEN deliver_by <= ? THEN deliver_by END, '9999'), created_at, id) >
+       (COALESCE(?, '9999'), ?, ?))
+ORDER BY CASE WHEN deliver_by <= ? THEN deliver_by END NULLS LAST, created_at, id
+LIMIT ?
+`
+
+// orderReserveSQL has no SKIP LOCKED: transactions start with BEGIN IMMEDIATE,
+// so only one writer runs at a time and any row it sees unassigned is free.
+// The status placeholders are expanded by inPlaceholders.
+const orderReserveSQL = `
+SELECT ` + orderColumns + `
+FROM orders
+WHERE id = ?
+  AND status IN (%s)
+  AND assigned_drone_id IS NULL
+  AND (pickup_not_before IS NULL OR pickup_not_before <= ?)
+`
+
+const droneSelectByIDSQL = `
+SELECT ` + droneColumns + `
+FROM drones
+WHERE id = ?
+`
+
+const droneSelectByIDsSQL = `
+SELECT ` + droneColumns + `
+FROM drones
+WHERE id IN (%s)
+`
+
+const droneInsertSQL = `
+INSERT INTO drones (
+  id, status, last_lat, last_lng, last_heartbeat_at, current_order_id, created_at, updated_at, recent_fixes,
+  altitude_m, heading_deg, ground_speed_mps, battery_pct, fault_codes,
+  flight_seconds, flight_meters, service_flight_seconds, service_flight_meters, home_depot_id, stops
+) VALUES (
+  ?,?,?,?,?,?,?,?,?,?,?,?,?,?,
+  ?,?,?,?,?,?
+)
+`
+
+const droneUpdateSQL = `
+UPDATE drones SET
+  status = ?,
+  last_lat = ?,
+  last_lng = ?,
+  last_heartbeat_at = ?,
+  current_order_id = ?,
+  updated_at = ?,
+  recent_fixes = ?,
+  altitude_m = ?,
+  heading_deg = ?,
+  ground_speed_mps = ?,
+  battery_pct = ?,
+  fault_codes = ?,
+  flight_seconds = ?,
+  flight_meters = ?,
+  service_flight_seconds = ?,
+  service_flight_meters = ?,
+  home_depot_id = ?,
+  stops = ?,
+  version = version + 1
+WHERE id = ? AND version = ?
+RETURNING version
+`
+
+const droneListSQL = `
+SELECT ` + droneColumns + `
+FROM drones
+ORDER BY id
+`
+
+const droneListByDepotSQL = `
+SELECT ` + droneColumns + `
+FROM drones
+WHERE home_depot_id = ?
+ORDER BY id
+`
+
+const outboxInsertSQL = `
+INSERT INTO outbox_events (
+  id, event_type, aggregate_type, aggregate_id, payload, occurred_at
+) VALUES (?,?,?,?,?,?)
+`
+
+const outboxFetchPendingSQL = `
+SELECT id, event_type, aggregate_type, aggregate_id, payload, occurred_at
+FROM outbox_events
+WHERE published_at IS NULL
+ORDER BY occurred_at
+LIMIT ?
+`
+
+const outboxMarkPublishedSQL = `
+UPDATE outbox_events
+SET published_at = ?
+WHERE id IN (%s)
+`
+
+const idempotencySelectSQL = `
+SELECT scope, idempotency_key, request_hash, response, created_at, expires_at
+FROM idempotency_keys
+WHERE scope = ? AND idempotency_key = ?
+`
+
+const idempotencyUpsertSQL = `
+INSERT INTO idempotency_keys (
+  scope, idempotency_key, request_hash, response, created_at, expires_at
+) VALUES (?,?,?,?,?,?)
+ON CONFLICT (scope, idempotency_key) DO UPDATE SET
+  request_hash = excluded.request_hash,
+  response = excluded.response,
+  created_at = excluded.created_at,
+  expires_at = excluded.expires_at
+`
+
+const idempotencyPruneSQL = `
+DELETE FROM idempotency_keys
+WHERE expires_at <= ?
+`
+
+// droneIdleSQL's status placeholders are expanded by inPlaceholders.
+const droneIdleSQL = `
+SELECT ` + droneColumns + `
+FROM drones
+WHERE status IN (%s) AND current_order_id IS NULL
+  AND last_lat IS NOT NULL AND last_lng IS NOT NULL
+`
+
+const serviceAreaColumns = `id, name, boundary, active, created_at, updated_at, version`
+
+const serviceAreaSelectByIDSQL = `
+SELECT ` + serviceAreaColumns + `
+FROM service_areas
+WHERE id = ?
+`
+
+const serviceAreaListSQL = `
+SELECT ` + serviceAreaColumns + `
+FROM service_areas
+ORDER BY id
+`
+
+const serviceAreaInsertSQL = `
+INSERT INTO service_areas (id, name, boundary, active, created_at, updated_at)
+VALUES (?,?,?,?,?,?)
+`
+
+const serviceAreaUpdateSQL = `
+UPDATE service_areas SET
+  name = ?,
+  boundary = ?,
+  active = ?,
+  updated_at = ?,
+  version = version + 1
+WHERE id = ? AND version = ?
+RETURNING version
+`
+
+const noFlyZoneColumns = `id, name, boundary, active_from, active_until, created_at, updated_at, version`
+
+const noFlyZoneSelectByIDSQL = `
+SELECT ` + noFlyZoneColumns + `
+FROM no_fly_zones
+WHERE id = ?
+`
+
+const noFlyZoneListSQL = `
+SELECT ` + noFlyZoneColumns + `
+FROM no_fly_zones
+ORDER BY id
+`
+
+const noFlyZoneInsertSQL = `
+INSERT INTO no_fly_zones (id, name, boundary, active_from, active_until, created_at, updated_at)
+VALUES (?,?,?,?,?,?,?)
+`
+
+const noFlyZoneUpdateSQL = `
+UPDATE no_fly_zones SET
+  name = ?,
+  boundary = ?,
+  active_from = ?,
+  active_until = ?,
+  updated_at = ?,
+  version = version + 1
+WHERE id = ? AND version = ?
+RETURNING version
+`
+
+const telemetryInsertSQL = `
+INSERT INTO drone_telemetry (drone_id, order_id, lat, lng, recorded_at)
+VALUES (?,?,?,?,?)
+`
+
+const telemetryListSQL = `
+SELECT drone_id, order_id, lat, lng, recorded_at
+FROM drone_telemetry
+`
+
+const telemetryPruneSQL = `
+DELETE FROM drone_telemetry
+WHERE recorded_at < ?
+`
+
+const maintenanceColumns = `id, drone_id, reason, fault_codes, flight_seconds, flight_meters,
+  opened_at, closed_at, technician, notes, parts`
+
+const maintenanceInsertSQL = `
+INSERT INTO maintenance_records (` + maintenanceColumns + `)
+VALUES (?,?,?,?,?,?,?,?,?,?,?)
+`
+
+const maintenanceSelectOpenSQL = `
+SELECT ` + maintenanceColumns + `
+FROM maintenance_records
+WHERE drone_id = ? AND closed_at IS NULL
+`
+
+const maintenanceListSQL = `
+SELECT ` + maintenanceColumns + `
+FROM maintenance_records
+WHERE drone_id = ?
+ORDER BY opened_at, id
+`
+
+const maintenanceUpdateSQL = `
+UPDATE maintenance_records SET
+  reason = ?,
+  fault_codes = ?,
+  closed_at = ?,
+  technician = ?,
+  notes = ?,
+  parts = ?
+WHERE id = ?
+`
+
+const depotColumns = `id, name, lat, lng, capacity, created_at, updated_at, version`
+
+const depotSelectByIDSQL = `
+SELECT ` + depotColumns + `
+FROM depots
+WHERE id = ?
+`
+
+const depotListSQL = `
+SELECT ` + depotColumns + `
+FROM depots
+ORDER BY id
+`
+
+const depotInsertSQL = `
+INSERT INTO depots (id, name, lat, lng, capacity, created_at, updated_at)
+VALUES (?,?,?,?,?,?,?)
+`
+
+const depotUpdateSQL = `
+UPDATE depots SET
+  name = ?,
+  lat = ?,
+  lng = ?,
+  capacity = ?,
+  updated_at = ?,
+  version = version + 1
+WHERE id = ? AND version = ?
+RETURNING version
+`
+
+const chargingStationColumns = `id, name, lat, lng, slots, created_at, updated_at, version`
+
+const chargingStationSelectByIDSQL = `
+SELECT ` + chargingStationColumns + `
+FROM charging_stations
+WHERE id = ?
+`
+
+const chargingStationListSQL = `
+SELECT ` + chargingStationColumns + `
+FROM charging_stations
+ORDER BY id
+`
+
+const chargingStationInsertSQL = `
+INSERT INTO charging_stations (id, name, lat, lng, slots, created_at, updated_at)
+VALUES (?,?,?,?,?,?,?)
+`
+
+const chargingStationUpdateSQL = `
+UPDATE charging_stations SET
+  name = ?,
+  lat = ?,
+  lng = ?,
+  slots = ?,
+  updated_at = ?,
+  version = version + 1
+WHERE id = ? AND version = ?
+RETURNING version
+`
+
+const chargingSlotColumns = `station_id, slot, drone_id, reserved_at`
+
+const chargingSlotInsertSQL = `
+INSERT INTO charging_slots (station_id, slot)
+VALUES (?,?)
+ON CONFLICT DO NOTHING
+`
+
+const chargingSlotTrimSQL = `
+DELETE FROM charging_slots
+WHERE station_id = ? AND slot > ?
+`
+
+const chargingSlotListSQL = `
+SELECT ` + chargingSlotColumns + `
+FROM charging_slots
+WHERE station_id = ?
+ORDER BY slot
+`
+
+const chargingSlotOccupancySQL = `
+SELECT station_id, count(*)
+FROM charging_slots
+WHERE drone_id IS NOT NULL
+GROUP BY station_id
+`
+
+const chargingSlotSelectByDroneSQL = `
+SELECT ` + chargingSlotColumns + `
+FROM charging_slots
+WHERE drone_id = ?
+`
+
+// chargingSlotReserveSQL has no SKIP LOCKED for the reason orderReserveSQL
+// has none.
+const chargingSlotReserveSQL = `
+SELECT ` + chargingSlotColumns + `
+FROM charging_slots
+WHERE station_id = ? AND drone_id IS NULL
+ORDER BY slot
+LIMIT 1
+`
+
+const chargingSlotUpdateSQL = `
+UPDATE charging_slots SET
+  drone_id = ?,
+  reserved_at = ?
+WHERE station_id = ? AND slot = ?
+`
@@ -0,0 +1,866 @@
+// Package sqlite implements service.Store and events.OutboxRepository on an
+// embedded SQLite database for single-binary deployments.
+//
+// SQLite has no row locks, so every transaction is opened with BEGIN IMMEDIATE
+// and holds the database write lock until it ends. That serialises writers,
+// which gives the same guarantees as the Postgres store's FOR UPDATE and
+// FOR UPDATE SKIP LOCKED reads at the cost of write concurrency. Readers
+// outside a transaction are not blocked (the database runs in WAL mode).
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"penny-assesment/internal/domain"
+	"penny-assesment/internal/events"
+	"penny-assesment/internal/service"
+)
+
+// Open opens (creating if needed) the database file at path with the settings
+// the store relies on.
+func Open(path string) (*sql.DB, error) {
+	dsn := "file:" + path +
+		"?_txlock=immediate" +
+		"&_pragma=busy_timeout(10000)" +
+		"&_pragma=journal_mode(WAL)" +
+		"&_pragma=synchronous(NORMAL)" +
+		"&_pragma=foreign_keys(1)"
+	return sql.Open("sqlite", dsn)
+}
+
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+func (s *Store) BeginTx(ctx context.Context) (service.Tx, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{tx: tx}, nil
+}
+
+func (s *Store) GetOrder(ctx context.Context, id string) (*domain.Order, error) {
+	row := s.db.QueryRowContext(ctx, orderSelectByIDSQL, id)
+	return scanOrder(row)
+}
+
+func (s *Store) ListOrders(ctx context.Context, filter service.OrderFilter) ([]*domain.Order, error) {
+	query, args, err := buildOrderListQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return collectOrders(rows)
+}
+
+func collectOrders(rows *sql.Rows) ([]*domain.Order, error) {
+	defer rows.Close()
+
+	var orders []*domain.Order
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return orders, nil
+}
+
+func (s *Store) CountOrders(ctx context.Context, filter service.OrderFilter) (int, error) {
+	query, args := buildOrderCountQuery(filter)
+	var count int
+	if err := s.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (s *Store) QueuePositions(ctx context.Context, statuses []domain.OrderStatus, ids []string) (map[string]int, error) {
+	if len(ids) == 0 {
+		return map[string]int{}, nil
+	}
+	args := append(statusArgs(statuses), stringArgs(ids)...)
+	query := fmt.Sprintf(orderQueuePositionsSQL, inPlaceholders(len(statuses)), inPlaceholders(len(ids)))
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	positions := make(map[string]int, len(ids))
+	for rows.Next() {
+		var id string
+		var position int
+		if err := rows.Scan(&id, &position); err != nil {
+			return nil, err
+		}
+		positions[id] = position
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return positions, nil
+}
+
+func (s *Store) CreateOrder(ctx context.Context, order *domain.Order) error {
+	args, err := orderInsertArgs(order)
+	if err != nil {
+		return err
+	}
+	if _, err := s.db.ExecContext(ctx, orderInsertSQL, args...); err != nil {
+		return mapError(err)
+	}
+	order.Version = 1
+	return nil
+}
+
+func (s *Store) GetDrone(ctx context.Context, id string) (*domain.Drone, error) {
+	row := s.db.QueryRowContext(ctx, droneSelectByIDSQL, id)
+	return scanDrone(row)
+}
+
+func (s *Store) GetDrones(ctx context.Context, ids []string) ([]*domain.Drone, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(droneSelectByIDsSQL, inPlaceholders(len(ids))), stringArgs(ids)...)
+	if err != nil {
+		return nil, err
+	}
+	return collectDrones(rows)
+}
+
+func (s *Store) ListDrones(ctx context.Context) ([]*domain.Drone, error) {
+	rows, err := s.db.QueryContext(ctx, droneListSQL)
+	if err != nil {
+		return nil, err
+	}
+	return collectDrones(rows)
+}
+
+func collectDrones(rows *sql.Rows) ([]*domain.Drone, error) {
+	defer rows.Close()
+
+	var drones []*domain.Drone
+	for rows.Next() {
+		drone, err := scanDrone(rows)
+		if err != nil {
+			return nil, err
+		}
+		drones = append(drones, drone)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return drones, nil
+}
+
+type Tx struct {
+	tx *sql.Tx
+}
+
+func (t *Tx) Commit(ctx context.Context) error {
+	return t.tx.Commit()
+}
+
+// Rollback is safe to call after Commit, matching pgx.Tx.
+func (t *Tx) Rollback(ctx context.Context) error {
+	err := t.tx.Rollback()
+	if errors.Is(err, sql.ErrTxDone) {
+		return nil
+	}
+	return err
+}
+
+// GetOrderForUpdate is a plain read: the transaction already holds the
+// database write lock.
+func (t *Tx) GetOrderForUpdate(ctx context.Context, id string) (*domain.Order, error) {
+	row := t.tx.QueryRowContext(ctx, orderSelectByIDSQL, id)
+	return scanOrder(row)
+}
+
+func (t *Tx) GetDroneForUpdate(ctx context.Context, id string) (*domain.Drone, error) {
+	row := t.tx.QueryRowContext(ctx, droneSelectByIDSQL, id)
+	return scanDrone(row)
+}
+
+func (t *Tx) CreateDrone(ctx context.Context, drone *domain.Drone) error {
+	fixes, err := nullFixes(drone.RecentFixes)
+	if err != nil {
+		return err
+	}
+	faults, err := nullStringList(drone.Vitals.FaultCodes)
+	if err != nil {
+		return err
+	}
+	stops, err := nullStops(drone.Stops)
+	if err != nil {
+		return err
+	}
+	_, err = t.tx.ExecContext(ctx, droneInsertSQL,
+		drone.ID,
+		drone.Status,
+		nullLocationLat(drone.LastLocation),
+		nullLocationLng(drone.LastLocation),
+		nullTime(drone.LastHeartbeatAt),
+		nullString(drone.CurrentOrderID),
+		formatTime(drone.CreatedAt),
+		formatTime(drone.UpdatedAt),
+		fixes,
+		nullFloat(drone.Vitals.AltitudeMeters),
+		nullFloat(drone.Vitals.HeadingDegrees),
+		nullFloat(drone.Vitals.GroundSpeedMPS),
+		nullFloat(drone.Vitals.BatteryPercent),
+		faults,
+		drone.Usage.Seconds,
+		drone.Usage.Meters,
+		drone.SinceService.Seconds,
+		drone.SinceService.Meters,
+		nullString(drone.HomeDepotID),
+		stops,
+	)
+	if err != nil {
+		return mapError(err)
+	}
+	drone.Version = 1
+	return nil
+}
+
+func (t *Tx) CreateOrder(ctx context.Context, order *domain.Order) error {
+	args, err := orderInsertArgs(order)
+	if err != nil {
+		return err
+	}
+	if _, err := t.tx.ExecContext(ctx, orderInsertSQL, args...); err != nil {
+		return mapError(err)
+	}
+	order.Version = 1
+	return nil
+}
+
+// UpdateOrder writes order if its Version still matches the stored row and
+// sets order.Version to the incremented value.
+func (t *Tx) UpdateOrder(ctx context.Context, order *domain.Order) error {
+	route, err := nullRoute(order.Route)
+	if err != nil {
+		return err
+	}
+	legs, err := nullRelayLegs(order.Legs)
+	if err != nil {
+		return err
+	}
+	row := t.tx.QueryRowContext(ctx, orderUpdateSQL,
+		order.UserID,
+		order.Origin.Lat,
+		order.Origin.Lng,
+		order.Destination.Lat,
+		order.Destination.Lng,
+		order.Status,
+		nullString(order.AssignedDroneID),
+		nullLocationLat(order.HandoffOrigin),
+		nullLocationLng(order.HandoffOrigin),
+		formatTime(order.UpdatedAt),
+		nullTime(order.ReservedAt),
+		nullTime(order.PickedUpAt),
+		nullTime(order.DeliveredAt),
+		nullTime(order.FailedAt),
+		nullString(order.FailureReason),
+		route,
+		legs,
+		nullTime(order.PickupNotBefore),
+		nullTime(order.DeliverBy),
+		nullTime(order.SLAAtRiskAt),
+		order.ID,
+		order.Version,
+	)
+	return scanVersion(row, &order.Version)
+}
+
+func (t *Tx) UpdateDrone(ctx context.Context, drone *domain.Drone) error {
+	fixes, err := nullFixes(drone.RecentFixes)
+	if err != nil {
+		return err
+	}
+	faults, err := nullStringList(drone.Vitals.FaultCodes)
+	if err != nil {
+		return err
+	}
+	stops, err := nullStops(drone.Stops)
+	if err != nil {
+		return err
+	}
+	row := t.tx.QueryRowContext(ctx, droneUpdateSQL,
+		drone.Status,
+		nullLocationLat(drone.LastLocation),
+		nullLocationLng(drone.LastLocation),
+		nullTime(drone.LastHeartbeatAt),
+		nullString(drone.CurrentOrderID),
+		formatTime(drone.UpdatedAt),
+		fixes,
+		nullFloat(drone.Vitals.AltitudeMeters),
+		nullFloat(drone.Vitals.HeadingDegrees),
+		nullFloat(drone.Vitals.GroundSpeedMPS),
+		nullFloat(drone.Vitals.BatteryPercent),
+		faults,
+		drone.Usage.Seconds,
+		drone.Usage.Meters,
+		drone.SinceService.Seconds,
+		drone.SinceService.Meters,
+		nullString(drone.HomeDepotID),
+		stops,
+		drone.ID,
+		drone.Version,
+	)
+	return scanVersion(row, &drone.Version)
+}
+
+func (t *Tx) QueuedOrders(ctx context.Context, allowed []domain.OrderStatus, due, urgent time.Time, after *domain.Order, limit int) ([]*domain.Order, error) {
+	if len(allowed) == 0 {
+		return nil, nil
+	}
+	var afterDeadline, afterCreated, afterID any
+	if after != nil {
+		afterDeadline, afterCreated, afterID = nullTime(service.QueueDeadline(after, urgent)), formatTime(after.CreatedAt), after.ID
+	}
+	args := append(statusArgs(allowed), formatTime(due),
+		afterCreated, formatTime(urgent), afterDeadline, afterCreated, afterID,
+		formatTime(urgent), limit)
+	rows, err := t.tx.QueryContext(ctx, fmt.Sprintf(orderQueueSQL, inPlaceholders(len(allowed))), args...)
+	if err != nil {
+		return nil, err
+	}
+	return collectOrders(rows)
+}
+
+func (t *Tx) ReserveOrder(ctx context.Context, id string, allowed []domain.OrderStatus, due time.Time) (*domain.Order, error) {
+	if len(allowed) == 0 {
+		return nil, nil
+	}
+	args := append([]any{id}, statusArgs(allowed)...)
+	args = append(args, formatTime(due))
+	row := t.tx.QueryRowContext(ctx, fmt.Sprintf(orderReserveSQL, inPlaceholders(len(allowed))), args...)
+	order, err := scanOrder(row)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, nil
+	}
+	return order, err
+}
+
+func (t *Tx) EnqueueEvent(ctx context.Context, event events.Event) error {
+	_, err := t.tx.ExecContext(ctx, outboxInsertSQL,
+		event.ID,
+		event.Type,
+		event.AggregateType,
+		event.AggregateID,
+		[]byte(event.Payload),
+		formatTime(event.OccurredAt),
+	)
+	return err
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanOrder(row rowScanner) (*domain.Order, error) {
+	var (
+		createdAt       string
+		updatedAt       string
+		assignedDroneID sql.NullString
+		handoffLat      sql.NullFloat64
+		handoffLng      sql.NullFloat64
+		reservedAt      sql.NullString
+		pickedUpAt      sql.NullString
+		deliveredAt     sql.NullString
+		failedAt        sql.NullString
+		failureReason   sql.NullString
+		route           sql.NullString
+		relayLegs       sql.NullString
+		pickupNotBefore sql.NullString
+		deliverBy       sql.NullString
+		slaAtRiskAt     sql.NullString
+	)
+	order := &domain.Order{}
+	err := row.Scan(
+		&order.ID,
+		&order.UserID,
+		&order.Origin.Lat,
+		&order.Origin.Lng,
+		&order.Destination.Lat,
+		&order.Destination.Lng,
+		&order.Status,
+		&assignedDroneID,
+		&handoffLat,
+		&handoffLng,
+		&createdAt,
+		&updatedAt,
+		&reservedAt,
+		&pickedUpAt,
+		&deliveredAt,
+		&failedAt,
+		&failureReason,
+		&route,
+		&relayLegs,
+		&pickupNotBefore,
+		&deliverBy,
+		&slaAtRiskAt,
+		&order.Version,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	if order.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if order.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+	if assignedDroneID.Valid {
+		order.AssignedDroneID = &assignedDroneID.String
+	}
+	if handoffLat.Valid && handoffLng.Valid {
+		order.HandoffOrigin = &domain.Location{Lat: handoffLat.Float64, Lng: handoffLng.Float64}
+	}
+	for _, field := range []struct {
+		src sql.NullString
+		dst **time.Time
+	}{
+		{reservedAt, &order.ReservedAt},
+		{pickedUpAt, &order.PickedUpAt},
+		{deliveredAt, &order.DeliveredAt},
+		{failedAt, &order.FailedAt},
+		{pickupNotBefore, &order.PickupNotBefore},
+		{deliverBy, &order.DeliverBy},
+		{slaAtRiskAt, &order.SLAAtRiskAt},
+	} {
+		if *field.dst, err = parseNullTime(field.src); err != nil {
+			return nil, err
+		}
+	}
+	if failureReason.Valid {
+		order.FailureReason = &failureReason.String
+	}
+	if route.Valid {
+		var coords [][]float64
+		if err := json.Unmarshal([]byte(route.String), &coords); err != nil {
+			return nil, err
+		}
+		if order.Route, err = domain.LineStringFromCoordinates(coords); err != nil {
+			return nil, err
+		}
+	}
+	if relayLegs.Valid {
+		var legs []relayLegJSON
+		if err := json.Unmarshal([]byte(relayLegs.String), &legs); err != nil {
+			return nil, err
+		}
+		for _, leg := range legs {
+			order.Legs = append(order.Legs, leg.domain())
+		}
+	}
+	return order, nil
+}
+
+func scanDrone(row rowScanner) (*domain.Drone, error) {
+	var (
+		lastLat         sql.NullFloat64
+		lastLng         sql.NullFloat64
+		lastHeartbeatAt sql.NullString
+		currentOrderID  sql.NullString
+		createdAt       string
+		updatedAt       string
+		recentFixes     sql.NullString
+		altitude        sql.NullFloat64
+		heading         sql.NullFloat64
+		groundSpeed     sql.NullFloat64
+		battery         sql.NullFloat64
+		faultCodes      sql.NullString
+		homeDepotID     sql.NullString
+		stops           sql.NullString
+	)
+	drone := &domain.Drone{}
+	err := row.Scan(
+		&drone.ID,
+		&drone.Status,
+		&lastLat,
+		&lastLng,
+		&lastHeartbeatAt,
+		&currentOrderID,
+		&createdAt,
+		&updatedAt,
+		&recentFixes,
+		&altitude,
+		&heading,
+		&groundSpeed,
+		&battery,
+		&faultCodes,
+		&drone.Usage.Seconds,
+		&drone.Usage.Meters,
+		&drone.SinceService.Seconds,
+		&drone.SinceService.Meters,
+		&homeDepotID,
+		&stops,
+		&drone.Version,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	if drone.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if drone.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+	if drone.LastHeartbeatAt, err = parseNullTime(lastHeartbeatAt); err != nil {
+		return nil, err
+	}
+	if lastLat.Valid && lastLng.Valid {
+		drone.LastLocation = &domain.Location{Lat: lastLat.Float64, Lng: lastLng.Float64}
+	}
+	if currentOrderID.Valid {
+		drone.CurrentOrderID = &currentOrderID.String
+	}
+	if homeDepotID.Valid {
+		drone.HomeDepotID = &homeDepotID.String
+	}
+	if recentFixes.Valid {
+		var fixes []fixJSON
+		if err := json.Unmarshal([]byte(recentFixes.String), &fixes); err != nil {
+			return nil, err
+		}
+		for _, fix := range fixes {
+			drone.RecentFixes = append(drone.RecentFixes, domain.Fix{Location: domain.Location{Lat: fix.Lat, Lng: fix.Lng}, At: fix.At})
+		}
+	}
+	if stops.Valid {
+		var stored []stopJSON
+		if err := json.Unmarshal([]byte(stops.String), &stored); err != nil {
+			return nil, err
+		}
+		for _, stop := range stored {
+			drone.Stops = append(drone.Stops, stop.domain())
+		}
+	}
+	drone.Vitals.AltitudeMeters = floatPtr(altitude)
+	drone.Vitals.HeadingDegrees = floatPtr(heading)
+	drone.Vitals.GroundSpeedMPS = floatPtr(groundSpeed)
+	drone.Vitals.BatteryPercent = floatPtr(battery)
+	if drone.Vitals.FaultCodes, err = parseStringList(faultCodes); err != nil {
+		return nil, err
+	}
+	return drone, nil
+}
+
+// nullStringList encodes values as a JSON array, or NULL when there are none.
+func nullStringList(values []string) (sql.NullString, error) {
+	if len(values) == 0 {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+func parseStringList(v sql.NullString) ([]string, error) {
+	if !v.Valid {
+		return nil, nil
+	}
+	var values []string
+	if err := json.Unmarshal([]byte(v.String), &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// fixJSON is the stored form of a domain.Fix.
+type fixJSON struct {
+	Lat float64   `json:"lat"`
+	Lng float64   `json:"lng"`
+	At  time.Time `json:"at"`
+}
+
+// nullFixes encodes fixes as a JSON array, or NULL when there are none.
+func nullFixes(fixes []domain.Fix) (sql.NullString, error) {
+	if len(fixes) == 0 {
+		return sql.NullString{}, nil
+	}
+	stored := make([]fixJSON, 0, len(fixes))
+	for _, fix := range fixes {
+		stored = append(stored, fixJSON{Lat: fix.Location.Lat, Lng: fix.Location.Lng, At: fix.At.UTC()})
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// stopJSON is the stored form of a domain.Stop.
+type stopJSON struct {
+	OrderID     string          `json:"order_id"`
+	Kind        domain.StopKind `json:"kind"`
+	Lat         float64         `json:"lat"`
+	Lng         float64         `json:"lng"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+}
+
+func (stop stopJSON) domain() domain.Stop {
+	return domain.Stop{
+		OrderID:     stop.OrderID,
+		Kind:        stop.Kind,
+		Location:    domain.Location{Lat: stop.Lat, Lng: stop.Lng},
+		CompletedAt: stop.CompletedAt,
+	}
+}
+
+// nullStops encodes stops as a JSON array, or NULL when there are none.
+func nullStops(stops []domain.Stop) (sql.NullString, error) {
+	if len(stops) == 0 {
+		return sql.NullString{}, nil
+	}
+	stored := make([]stopJSON, 0, len(stops))
+	for _, stop := range stops {
+		item := stopJSON{OrderID: stop.OrderID, Kind: stop.Kind, Lat: stop.Location.Lat, Lng: stop.Location.Lng}
+		if stop.CompletedAt != nil {
+			at := stop.CompletedAt.UTC()
+			item.CompletedAt = &at
+		}
+		stored = append(stored, item)
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+func orderInsertArgs(order *domain.Order) ([]any, error) {
+	route, err := nullRoute(order.Route)
+	if err != nil {
+		return nil, err
+	}
+	legs, err := nullRelayLegs(order.Legs)
+	if err != nil {
+		return nil, err
+	}
+	return []any{
+		order.ID,
+		order.UserID,
+		order.Origin.Lat,
+		order.Origin.Lng,
+		order.Destination.Lat,
+		order.Destination.Lng,
+		order.Status,
+		nullString(order.AssignedDroneID),
+		nullLocationLat(order.HandoffOrigin),
+		nullLocationLng(order.HandoffOrigin),
+		formatTime(order.CreatedAt),
+		formatTime(order.UpdatedAt),
+		nullTime(order.ReservedAt),
+		nullTime(order.PickedUpAt),
+		nullTime(order.DeliveredAt),
+		nullTime(order.FailedAt),
+		nullString(order.FailureReason),
+		route,
+		legs,
+		nullTime(order.PickupNotBefore),
+		nullTime(order.DeliverBy),
+		nullTime(order.SLAAtRiskAt),
+	}, nil
+}
+
+// relayLegJSON is the stored form of a domain.RelayLeg.
+type relayLegJSON struct {
+	Lat          float64    `json:"lat"`
+	Lng          float64    `json:"lng"`
+	RelayPointID string     `json:"relay_point_id,omitempty"`
+	DroneID      *string    `json:"drone_id,omitempty"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+}
+
+func (leg relayLegJSON) domain() domain.RelayLeg {
+	return domain.RelayLeg{
+		To:           domain.Location{Lat: leg.Lat, Lng: leg.Lng},
+		RelayPointID: leg.RelayPointID,
+		DroneID:      leg.DroneID,
+		CompletedAt:  leg.CompletedAt,
+	}
+}
+
+// nullRelayLegs encodes legs as a JSON array, or NULL when there are none.
+func nullRelayLegs(legs []domain.RelayLeg) (sql.NullString, error) {
+	if len(legs) == 0 {
+		return sql.NullString{}, nil
+	}
+	stored := make([]relayLegJSON, 0, len(legs))
+	for _, leg := range legs {
+		item := relayLegJSON{Lat: leg.To.Lat, Lng: leg.To.Lng, RelayPointID: leg.RelayPointID, DroneID: leg.DroneID}
+		if leg.CompletedAt != nil {
+			at := leg.CompletedAt.UTC()
+			item.CompletedAt = &at
+		}
+		stored = append(stored, item)
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// nullRoute encodes route as GeoJSON LineString coordinates, or NULL.
+func nullRoute(route []domain.Location) (sql.NullString, error) {
+	if route == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(domain.LineStringCoordinates(route))
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// scanVersion reads the RETURNING version of an update. No row means the
+// stored version moved on (or the row is gone).
+func scanVersion(row rowScanner, version *int64) error {
+	if err := row.Scan(version); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrVersionMismatch
+		}
+		return err
+	}
+	return nil
+}
+
+// mapError turns primary key and unique violations into domain.ErrConflict.
+func mapError(err error) error {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code() {
+		case sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY, sqlite3.SQLITE_CONSTRAINT_UNIQUE:
+			return domain.ErrConflict
+		}
+	}
+	return err
+}
+
+// timeLayout is fixed width (always nine fractional digits, always UTC) so
+// stored timestamps sort lexically in chronological order.
+const timeLayout = "2006-01-02T15:04:05.000000000Z"
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+func parseTime(s string) (time.Time, error) {
+	return time.Parse(timeLayout, s)
+}
+
+func parseNullTime(v sql.NullString) (*time.Time, error) {
+	if !v.Valid {
+		return nil, nil
+	}
+	t, err := parseTime(v.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func nullString(v *string) sql.NullString {
+	if v == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *v, Valid: true}
+}
+
+func nullTime(v *time.Time) sql.NullString {
+	if v == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: formatTime(*v), Valid: true}
+}
+
+func nullFloat(v *float64) sql.NullFloat64 {
+	if v == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: *v, Valid: true}
+}
+
+func floatPtr(v sql.NullFloat64) *float64 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Float64
+}
+
+func nullLocationLat(loc *domain.Location) sql.NullFloat64 {
+	if loc == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: loc.Lat, Valid: true}
+}
+
+func nullLocationLng(loc *domain.Location) sql.NullFloat64 {
+	if loc == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: loc.Lng, Valid: true}
+}
+
+// inPlaceholders returns "?,?,..." for an IN list of n values.
+func inPlaceholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+func statusArgs(statuses []domain.OrderStatus) []any {
+	args := make([]any, 0, len(statuses))
+	for _, status := range statuses {
+		args = append(args, string(status))
+	}
+	return args
+}
+
+func stringArgs(values []string) []any {
+	args := make([]any, 0, len(values))
+	for _, v := range values {
+		args = append(args, v)
+	}
+	return args
+}
+
+var (
+	_ service.Store           = (*Store)(nil)
+	_ service.Tx              = (*Tx)(nil)
+	_ events.OutboxRepository = (*Store)(nil)
+)
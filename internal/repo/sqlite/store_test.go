@@ -0,0 +1,23 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"penny-assesment/internal/repo/storetest"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) storetest.Store {
+		db, err := Open(filepath.Join(t.TempDir(), "drone.db"))
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		if err := ApplyMigrations(context.Background(), db); err != nil {
+			t.Fatalf("migrate: %v", err)
+		}
+		return NewStore(db)
+	})
+}
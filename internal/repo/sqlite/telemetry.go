@@ -0,0 +1,102 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"penny-assesment/internal/domain"
+	"penny-assesment/internal/service"
+)
+
+func (s *Store) ListTelemetry(ctx context.Context, query service.TelemetryQuery) ([]*domain.TelemetrySample, error) {
+	stmt, args := buildTelemetryListQuery(query)
+	rows, err := s.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var samples []*domain.TelemetrySample
+	for rows.Next() {
+		sample, err := scanTelemetrySample(rows)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, sample)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return samples, nil
+}
+
+func (s *Store) PruneTelemetry(ctx context.Context, before time.Time) (int64, error) {
+	if before.IsZero() {
+		return 0, nil
+	}
+	res, err := s.db.ExecContext(ctx, telemetryPruneSQL, formatTime(before))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (t *Tx) AppendTelemetry(ctx context.Context, sample *domain.TelemetrySample) error {
+	_, err := t.tx.ExecContext(ctx, telemetryInsertSQL,
+		sample.DroneID,
+		nullString(sample.OrderID),
+		sample.Location.Lat,
+		sample.Location.Lng,
+		formatTime(sample.RecordedAt),
+	)
+	return mapError(err)
+}
+
+// buildTelemetryListQuery reuses the order filter's predicate accumulator;
+// timestamps are bound in their stored text form.
+func buildTelemetryListQuery(query service.TelemetryQuery) (string, []any) {
+	q := &orderQuery{}
+	if query.DroneID != "" {
+		q.add("drone_id = " + q.arg(query.DroneID))
+	}
+	if query.OrderID != "" {
+		q.add("order_id = " + q.arg(query.OrderID))
+	}
+	if query.From != nil {
+		q.add("recorded_at >= " + q.arg(formatTime(*query.From)))
+	}
+	if query.To != nil {
+		q.add("recorded_at < " + q.arg(formatTime(*query.To)))
+	}
+
+	var b strings.Builder
+	b.WriteString(telemetryListSQL)
+	q.writeWhere(&b)
+	b.WriteString("ORDER BY recorded_at\n")
+	if query.Limit > 0 {
+		fmt.Fprintf(&b, "LIMIT %s\n", q.arg(query.Limit))
+	}
+	return b.String(), q.args
+}
+
+func scanTelemetrySample(row rowScanner) (*domain.TelemetrySample, error) {
+	var (
+		orderID    sql.NullString
+		recordedAt string
+	)
+	sample := &domain.TelemetrySample{}
+	if err := row.Scan(&sample.DroneID, &orderID, &sample.Location.Lat, &sample.Location.Lng, &recordedAt); err != nil {
+		return nil, err
+	}
+	if orderID.Valid {
+		sample.OrderID = &orderID.String
+	}
+	var err error
+	if sample.RecordedAt, err = parseTime(recordedAt); err != nil {
+		return nil, err
+	}
+	return sample, nil
+}
@@ -0,0 +1,259 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"penny-assesment/internal/domain"
+	"penny-assesment/internal/service"
+)
+
+func testChargingStations(t *testing.T, store Store) {
+	ctx := context.Background()
+	now := baseTime()
+	if stations, err := store.ListChargingStations(ctx); err != nil || len(stations) != 0 {
+		t.Fatalf("empty store: expected no charging stations, got %d (err=%v)", len(stations), err)
+	}
+	if _, err := store.GetChargingStation(ctx, uuid.NewString()); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("GetChargingStation: expected ErrNotFound, got %v", err)
+	}
+	if _, err := store.GetDroneChargingSlot(ctx, "drone-1"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("GetDroneChargingSlot: expected ErrNotFound, got %v", err)
+	}
+
+	station := &domain.ChargingStation{ID: uuid.NewString(), Name: "Pad A", Location: domain.Location{Lat: 24.7, Lng: 46.68}, Slots: 3, CreatedAt: now, UpdatedAt: now}
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		return tx.CreateChargingStation(ctx, station)
+	})
+	if station.Version != 1 {
+		t.Fatalf("expected created station at version 1, got %d", station.Version)
+	}
+	got, err := store.GetChargingStation(ctx, station.ID)
+	if err != nil {
+		t.Fatalf("get charging station: %v", err)
+	}
+	if g, w := chargingStationString(got), chargingStationString(station); g != w {
+		t.Fatalf("charging station round trip\n got: %s\nwant: %s", g, w)
+	}
+	assertChargingSlots(t, store, station.ID, "1:free 2:free 3:free")
+
+	// Reservations take the lowest free slot and skip one another's.
+	var reserved []string
+	for _, droneID := range []string{"drone-1", "drone-2"} {
+		droneID := droneID
+		commit(t, store, func(ctx context.Context, tx service.Tx) error {
+			slot, err := tx.ReserveFreeChargingSlot(ctx, station.ID)
+			if err != nil {
+				return err
+			}
+			if slot == nil {
+				return fmt.Errorf("%s: no free slot", droneID)
+			}
+			reservedAt := now.Add(time.Minute)
+			slot.DroneID, slot.ReservedAt = &droneID, &reservedAt
+			reserved = append(reserved, fmt.Sprint(slot.Number))
+			return tx.UpdateChargingSlot(ctx, slot)
+		})
+	}
+	if fmt.Sprint(reserved) != "[1 2]" {
+		t.Fatalf("expected slots 1 and 2 reserved in turn, got %v", reserved)
+	}
+	assertChargingSlots(t, store, station.ID, "1:drone-1 2:drone-2 3:free")
+	reservedAt := now.Add(time.Minute)
+	slot, err := store.GetDroneChargingSlot(ctx, "drone-2")
+	if err != nil || slot.StationID != station.ID || slot.Number != 2 || ts(slot.ReservedAt) != ts(&reservedAt) {
+		t.Fatalf("expected drone-2 on slot 2 since %s, got %s (err=%v)", ts(&reservedAt), chargingSlotString(slot), err)
+	}
+	occupied, err := store.CountOccupiedSlots(ctx)
+	if err != nil || fmt.Sprint(occupied) != fmt.Sprintf("map[%s:2]", station.ID) {
+		t.Fatalf("expected 2 occupied slots at the station, got %v (err=%v)", occupied, err)
+	}
+
+	// A drone holds at most one slot.
+	err = tryTx(ctx, store, func(ctx context.Context, tx service.Tx) error {
+		slot, err := tx.ReserveFreeChargingSlot(ctx, station.ID)
+		if err != nil || slot == nil {
+			return fmt.Errorf("reserve: %v %v", slot, err)
+		}
+		droneID := "drone-1"
+		slot.DroneID, slot.ReservedAt = &droneID, &now
+		return tx.UpdateChargingSlot(ctx, slot)
+	})
+	if !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected ErrConflict for a second slot for drone-1, got %v", err)
+	}
+
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		slot, err := tx.GetDroneChargingSlotForUpdate(ctx, "drone-1")
+		if err != nil {
+			return err
+		}
+		slot.DroneID, slot.ReservedAt = nil, nil
+		return tx.UpdateChargingSlot(ctx, slot)
+	})
+	assertChargingSlots(t, store, station.ID, "1:free 2:drone-2 3:free")
+	err = tryTx(ctx, store, func(ctx context.Context, tx service.Tx) error {
+		_, err := tx.GetDroneChargingSlotForUpdate(ctx, "drone-1")
+		return err
+	})
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a drone holding no slot, got %v", err)
+	}
+
+	// Resizing adds free slots at the end and drops those past the new count.
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		locked, err := tx.GetChargingStationForUpdate(ctx, station.ID)
+		if err != nil {
+			return err
+		}
+		locked.Slots = 5
+		locked.UpdatedAt = now.Add(time.Hour)
+		station = locked
+		return tx.UpdateChargingStation(ctx, locked)
+	})
+	assertChargingSlots(t, store, station.ID, "1:free 2:drone-2 3:free 4:free 5:free")
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		locked, err := tx.GetChargingStationForUpdate(ctx, station.ID)
+		if err != nil {
+			return err
+		}
+		locked.Name = "Pad A1"
+		locked.Slots = 2
+		locked.UpdatedAt = now.Add(2 * time.Hour)
+		station = locked
+		return tx.UpdateChargingStation(ctx, locked)
+	})
+	if station.Version != 3 {
+		t.Fatalf("expected updated station at version 3, got %d", station.Version)
+	}
+	if got, err = store.GetChargingStation(ctx, station.ID); err != nil || chargingStationString(got) != chargingStationString(station) {
+		t.Fatalf("charging station after update\n got: %s (err=%v)\nwant: %s", chargingStationString(got), err, chargingStationString(station))
+	}
+	assertChargingSlots(t, store, station.ID, "1:free 2:drone-2")
+
+	stale := *station
+	stale.Version = 1
+	err = tryTx(ctx, store, func(ctx context.Context, tx service.Tx) error {
+		return tx.UpdateChargingStation(ctx, &stale)
+	})
+	if !errors.Is(err, domain.ErrVersionMismatch) {
+		t.Fatalf("expected ErrVersionMismatch for a stale station update, got %v", err)
+	}
+	err = tryTx(ctx, store, func(ctx context.Context, tx service.Tx) error {
+		return tx.CreateChargingStation(ctx, &domain.ChargingStation{ID: station.ID, Name: "Again", Slots: 1, CreatedAt: now, UpdatedAt: now})
+	})
+	if !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected ErrConflict for a duplicate station, got %v", err)
+	}
+}
+
+// testConcurrentChargingReservations races more drones than there are free
+// slots, each reserving in its own transaction the way the service does.
+func testConcurrentChargingReservations(t *testing.T, store Store) {
+	ctx := context.Background()
+	now := baseTime()
+	const slots, drones = 4, 10
+	station := &domain.ChargingStation{ID: uuid.NewString(), Name: "Pad", Slots: slots, CreatedAt: now, UpdatedAt: now}
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		return tx.CreateChargingStation(ctx, station)
+	})
+
+	reserve := func(droneID string) (int, error) {
+		tx, err := store.BeginTx(ctx)
+		if err != nil {
+			return 0, err
+		}
+		defer tx.Rollback(ctx)
+		slot, err := tx.ReserveFreeChargingSlot(ctx, station.ID)
+		if err != nil || slot == nil {
+			return 0, err
+		}
+		slot.DroneID, slot.ReservedAt = &droneID, &now
+		if err := tx.UpdateChargingSlot(ctx, slot); err != nil {
+			return 0, err
+		}
+		return slot.Number, tx.Commit(ctx)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		reserved = make(map[int]string)
+		errs     []error
+	)
+	for i := 0; i < drones; i++ {
+		droneID := fmt.Sprintf("drone-%d", i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			number, err := reserve(droneID)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				errs = append(errs, err)
+			case number == 0:
+			case reserved[number] != "":
+				errs = append(errs, fmt.Errorf("slot %d reserved by %s and %s", number, reserved[number], droneID))
+			default:
+				reserved[number] = droneID
+			}
+		}()
+	}
+	wg.Wait()
+	if len(errs) > 0 {
+		t.Fatalf("reservation errors: %v", errs)
+	}
+	if len(reserved) != slots {
+		t.Fatalf("expected all %d slots reserved once, got %d", slots, len(reserved))
+	}
+	for number, droneID := range reserved {
+		slot, err := store.GetDroneChargingSlot(ctx, droneID)
+		if err != nil || slot.Number != number {
+			t.Fatalf("%s: expected slot %d, got %s err=%v", droneID, number, chargingSlotString(slot), err)
+		}
+	}
+}
+
+func assertChargingSlots(t *testing.T, store Store, stationID, want string) {
+	t.Helper()
+	slots, err := store.ListChargingSlots(context.Background(), stationID)
+	if err != nil {
+		t.Fatalf("list charging slots: %v", err)
+	}
+	got := ""
+	for i, slot := range slots {
+		if i > 0 {
+			got += " "
+		}
+		holder := "free"
+		if !slot.Free() {
+			holder = *slot.DroneID
+		}
+		got += fmt.Sprintf("%d:%s", slot.Number, holder)
+	}
+	if got != want {
+		t.Fatalf("charging slots\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func chargingStationString(s *domain.ChargingStation) string {
+	if s == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("%s v%d name=%s loc=%v slots=%d created=%s updated=%s",
+		s.ID, s.Version, s.Name, s.Location, s.Slots, ts(&s.CreatedAt), ts(&s.UpdatedAt))
+}
+
+func chargingSlotString(s *domain.ChargingSlot) string {
+	if s == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("%s/%d drone=%s reserved=%s", s.StationID, s.Number, str(s.DroneID), ts(s.ReservedAt))
+}
@@ -0,0 +1,449 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"penny-assesment/internal/domain"
+	"penny-assesment/internal/service"
+)
+
+func testNotFound(t *testing.T, store Store) {
+	ctx := context.Background()
+	missing := uuid.NewString()
+	if _, err := store.GetOrder(ctx, missing); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("GetOrder: expected ErrNotFound, got %v", err)
+	}
+	if _, err := store.GetDrone(ctx, "missing"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("GetDrone: expected ErrNotFound, got %v", err)
+	}
+	if drones, err := store.GetDrones(ctx, []string{"missing"}); err != nil || len(drones) != 0 {
+		t.Fatalf("GetDrones: expected no drones, got %d err=%v", len(drones), err)
+	}
+	if drones, err := store.GetDrones(ctx, nil); err != nil || len(drones) != 0 {
+		t.Fatalf("GetDrones(nil): expected no drones, got %d err=%v", len(drones), err)
+	}
+
+	tx, err := store.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.GetOrderForUpdate(ctx, missing); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("GetOrderForUpdate: expected ErrNotFound, got %v", err)
+	}
+	if _, err := tx.GetDroneForUpdate(ctx, "missing"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("GetDroneForUpdate: expected ErrNotFound, got %v", err)
+	}
+}
+
+func testDuplicateCreateConflicts(t *testing.T, store Store) {
+	ctx := context.Background()
+	now := baseTime()
+	order := newOrder(now)
+	if err := store.CreateOrder(ctx, order); err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	if err := store.CreateOrder(ctx, order); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("Store.CreateOrder: expected ErrConflict, got %v", err)
+	}
+	drone := &domain.Drone{ID: "drone-1", Status: domain.DroneStatusActive, CreatedAt: now, UpdatedAt: now}
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		return tx.CreateDrone(ctx, drone)
+	})
+
+	// Each duplicate gets its own transaction: Postgres aborts a transaction
+	// after a failed statement.
+	expectConflict := func(name string, fn func(ctx context.Context, tx service.Tx) error) {
+		tx, err := store.BeginTx(ctx)
+		if err != nil {
+			t.Fatalf("begin: %v", err)
+		}
+		defer tx.Rollback(ctx)
+		if err := fn(ctx, tx); !errors.Is(err, domain.ErrConflict) {
+			t.Fatalf("%s: expected ErrConflict, got %v", name, err)
+		}
+	}
+	expectConflict("Tx.CreateOrder", func(ctx context.Context, tx service.Tx) error {
+		return tx.CreateOrder(ctx, order)
+	})
+	expectConflict("Tx.CreateDrone", func(ctx context.Context, tx service.Tx) error {
+		return tx.CreateDrone(ctx, drone)
+	})
+}
+
+// testConcurrentReservations races more drones than there are orders, each
+// reserving and assigning in its own transaction the way DroneReserveJob does.
+func testConcurrentReservations(t *testing.T, store Store) {
+	ctx := context.Background()
+	now := baseTime()
+	const orders, drones = 8, 16
+	for i := 0; i < orders; i++ {
+		if err := store.CreateOrder(ctx, newOrder(now.Add(time.Duration(i)*time.Second))); err != nil {
+			t.Fatalf("create order: %v", err)
+		}
+	}
+
+	reserve := func(droneID string) (string, error) {
+		tx, err := store.BeginTx(ctx)
+		if err != nil {
+			return "", err
+		}
+		defer tx.Rollback(ctx)
+		created := []domain.OrderStatus{domain.OrderStatusCreated}
+		queued, err := tx.QueuedOrders(ctx, created, now, now, nil, orders)
+		if err != nil {
+			return "", err
+		}
+		var order *domain.Order
+		for _, candidate := range queued {
+			if order, err = tx.ReserveOrder(ctx, candidate.ID, created, now); err != nil {
+				return "", err
+			}
+			if order != nil {
+				break
+			}
+		}
+		if order == nil {
+			return "", nil
+		}
+		order.Status = domain.OrderStatusReserved
+		order.AssignedDroneID = &droneID
+		if err := tx.UpdateOrder(ctx, order); err != nil {
+			return "", err
+		}
+		return order.ID, tx.Commit(ctx)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		reserved = make(map[string]string)
+		errs     []error
+	)
+	for i := 0; i < drones; i++ {
+		droneID := fmt.Sprintf("drone-%d", i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			orderID, err := reserve(droneID)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				errs = append(errs, err)
+			case orderID == "":
+			case reserved[orderID] != "":
+				errs = append(errs, fmt.Errorf("order %s reserved by %s and %s", orderID, reserved[orderID], droneID))
+			default:
+				reserved[orderID] = droneID
+			}
+		}()
+	}
+	wg.Wait()
+	if len(errs) > 0 {
+		t.Fatalf("reservation errors: %v", errs)
+	}
+	if len(reserved) != orders {
+		t.Fatalf("expected all %d orders reserved once, got %d", orders, len(reserved))
+	}
+	for orderID, droneID := range reserved {
+		order, err := store.GetOrder(ctx, orderID)
+		if err != nil || order.AssignedDroneID == nil || *order.AssignedDroneID != droneID {
+			t.Fatalf("order %s: expected assignment to %s, got %v err=%v", orderID, droneID, order, err)
+		}
+	}
+}
+
+// testUncommittedWritesInvisible checks reads outside a transaction only see
+// committed state while the transaction is still open.
+func testUncommittedWritesInvisible(t *testing.T, store Store) {
+	ctx := context.Background()
+	now := baseTime()
+	existing := newOrder(now)
+	if err := store.CreateOrder(ctx, existing); err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+
+	tx, err := store.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+	order, err := tx.GetOrderForUpdate(ctx, existing.ID)
+	if err != nil {
+		t.Fatalf("get for update: %v", err)
+	}
+	order.Status = domain.OrderStatusWithdrawn
+	if err := tx.UpdateOrder(ctx, order); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	created := newOrder(now)
+	if err := tx.CreateOrder(ctx, created); err != nil {
+		t.Fatalf("create in tx: %v", err)
+	}
+	if err := tx.EnqueueEvent(ctx, newEvent(now)); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	// The transaction reads its own writes.
+	if own, err := tx.GetOrderForUpdate(ctx, existing.ID); err != nil || own.Status != domain.OrderStatusWithdrawn {
+		t.Fatalf("expected tx to see its own update, got %v err=%v", own, err)
+	}
+	if got, err := store.GetOrder(ctx, existing.ID); err != nil || got.Status != domain.OrderStatusCreated {
+		t.Fatalf("expected uncommitted update to be invisible, got %v err=%v", got, err)
+	}
+	if _, err := store.GetOrder(ctx, created.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected uncommitted insert to be invisible, got %v", err)
+	}
+	if pending, err := store.FetchPending(ctx, 10); err != nil || len(pending) != 0 {
+		t.Fatalf("expected uncommitted event to be invisible, got %d err=%v", len(pending), err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if got, _ := store.GetOrder(ctx, existing.ID); got.Status != domain.OrderStatusWithdrawn {
+		t.Fatalf("expected committed update to be visible")
+	}
+	if _, err := store.GetOrder(ctx, created.ID); err != nil {
+		t.Fatalf("expected committed insert to be visible, got %v", err)
+	}
+	if pending, _ := store.FetchPending(ctx, 10); len(pending) != 1 {
+		t.Fatalf("expected committed event to be pending, got %d", len(pending))
+	}
+}
+
+func testOutboxLimitAndMark(t *testing.T, store Store) {
+	ctx := context.Background()
+	now := baseTime()
+	var ids []string
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		for i := 0; i < 5; i++ {
+			evt := newEvent(now.Add(time.Duration(i) * time.Second))
+			ids = append(ids, evt.ID)
+			if err := tx.EnqueueEvent(ctx, evt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	pending, err := store.FetchPending(ctx, 2)
+	if err != nil {
+		t.Fatalf("fetch pending: %v", err)
+	}
+	if fmt.Sprint(eventIDs(pending)) != fmt.Sprint(ids[:2]) {
+		t.Fatalf("expected first two events, got %v", eventIDs(pending))
+	}
+	if err := store.MarkPublished(ctx, nil); err != nil {
+		t.Fatalf("mark published (empty): %v", err)
+	}
+	if err := store.MarkPublished(ctx, append(eventIDs(pending), uuid.NewString())); err != nil {
+		t.Fatalf("mark published: %v", err)
+	}
+	// Marking again is a no-op, as the worker may retry after a crash.
+	if err := store.MarkPublished(ctx, eventIDs(pending)); err != nil {
+		t.Fatalf("mark published twice: %v", err)
+	}
+	pending, _ = store.FetchPending(ctx, 10)
+	if fmt.Sprint(eventIDs(pending)) != fmt.Sprint(ids[2:]) {
+		t.Fatalf("expected remaining events, got %v", eventIDs(pending))
+	}
+}
+
+func testVersions(t *testing.T, store Store) {
+	ctx := context.Background()
+	now := baseTime()
+	order := newOrder(now)
+	if err := store.CreateOrder(ctx, order); err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	drone := &domain.Drone{ID: "drone-1", Status: domain.DroneStatusActive, CreatedAt: now, UpdatedAt: now}
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		return tx.CreateDrone(ctx, drone)
+	})
+	if order.Version != 1 || drone.Version != 1 {
+		t.Fatalf("expected created rows at version 1, got order=%d drone=%d", order.Version, drone.Version)
+	}
+
+	stale := *order
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		if err := tx.UpdateOrder(ctx, order); err != nil {
+			return err
+		}
+		return tx.UpdateDrone(ctx, drone)
+	})
+	if order.Version != 2 || drone.Version != 2 {
+		t.Fatalf("expected updates to bump to version 2, got order=%d drone=%d", order.Version, drone.Version)
+	}
+	if got, _ := store.GetOrder(ctx, order.ID); got.Version != 2 {
+		t.Fatalf("expected stored order at version 2, got %d", got.Version)
+	}
+
+	staleDrone := *drone
+	staleDrone.Version = 1
+	for name, update := range map[string]func(ctx context.Context, tx service.Tx) error{
+		"UpdateOrder": func(ctx context.Context, tx service.Tx) error { return tx.UpdateOrder(ctx, &stale) },
+		"UpdateDrone": func(ctx context.Context, tx service.Tx) error { return tx.UpdateDrone(ctx, &staleDrone) },
+	} {
+		tx, err := store.BeginTx(ctx)
+		if err != nil {
+			t.Fatalf("begin: %v", err)
+		}
+		err = update(ctx, tx)
+		tx.Rollback(ctx)
+		if !errors.Is(err, domain.ErrVersionMismatch) {
+			t.Fatalf("%s with stale version: expected ErrVersionMismatch, got %v", name, err)
+		}
+	}
+	if got, _ := store.GetOrder(ctx, order.ID); got.Version != 2 {
+		t.Fatalf("expected stale update to leave version 2, got %d", got.Version)
+	}
+}
+
+func testIdempotencyRecords(t *testing.T, store Store) {
+	ctx := context.Background()
+	now := baseTime()
+	record := &domain.IdempotencyRecord{
+		Scope:       "user:u1",
+		Key:         "key-1",
+		RequestHash: "hash-1",
+		Response:    []byte(`{"ID":"order-1"}`),
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(time.Hour),
+	}
+
+	tx, err := store.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	if _, err := tx.GetIdempotencyRecord(ctx, record.Scope, record.Key); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("missing record: expected ErrNotFound, got %v", err)
+	}
+	if err := tx.SaveIdempotencyRecord(ctx, record); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	tx.Rollback(ctx)
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		if _, err := tx.GetIdempotencyRecord(ctx, record.Scope, record.Key); !errors.Is(err, domain.ErrNotFound) {
+			return fmt.Errorf("rolled back record: expected ErrNotFound, got %v", err)
+		}
+		return tx.SaveIdempotencyRecord(ctx, record)
+	})
+
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		got, err := tx.GetIdempotencyRecord(ctx, record.Scope, record.Key)
+		if err != nil {
+			return err
+		}
+		assertIdempotencyRecord(t, got, record)
+		if _, err := tx.GetIdempotencyRecord(ctx, "user:u2", record.Key); !errors.Is(err, domain.ErrNotFound) {
+			return fmt.Errorf("other scope: expected ErrNotFound, got %v", err)
+		}
+		return nil
+	})
+
+	replacement := *record
+	replacement.RequestHash = "hash-2"
+	replacement.Response = []byte(`{"ID":"order-2"}`)
+	replacement.CreatedAt = now.Add(2 * time.Hour)
+	replacement.ExpiresAt = now.Add(3 * time.Hour)
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		return tx.SaveIdempotencyRecord(ctx, &replacement)
+	})
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		got, err := tx.GetIdempotencyRecord(ctx, record.Scope, record.Key)
+		if err != nil {
+			return err
+		}
+		assertIdempotencyRecord(t, got, &replacement)
+		return nil
+	})
+
+	if pruned, err := store.PruneIdempotencyRecords(ctx, replacement.ExpiresAt.Add(-time.Microsecond)); err != nil || pruned != 0 {
+		t.Fatalf("prune before expiry: pruned %d, err %v", pruned, err)
+	}
+	if pruned, err := store.PruneIdempotencyRecords(ctx, replacement.ExpiresAt); err != nil || pruned != 1 {
+		t.Fatalf("prune at expiry: pruned %d, err %v", pruned, err)
+	}
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		if _, err := tx.GetIdempotencyRecord(ctx, record.Scope, record.Key); !errors.Is(err, domain.ErrNotFound) {
+			return fmt.Errorf("pruned record: expected ErrNotFound, got %v", err)
+		}
+		return nil
+	})
+}
+
+// testConcurrentIdempotencyKey checks that GetIdempotencyRecord serialises
+// transactions using the same key, so only one of them finds it unused.
+func testConcurrentIdempotencyKey(t *testing.T, store Store) {
+	ctx := context.Background()
+	now := baseTime()
+	const workers = 4
+	var wg sync.WaitGroup
+	claimed := make(chan int, workers)
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tx, err := store.BeginTx(ctx)
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer tx.Rollback(ctx)
+			_, err = tx.GetIdempotencyRecord(ctx, "drone:d1", "key-1")
+			if err == nil {
+				errs <- tx.Commit(ctx)
+				return
+			}
+			if !errors.Is(err, domain.ErrNotFound) {
+				errs <- err
+				return
+			}
+			err = tx.SaveIdempotencyRecord(ctx, &domain.IdempotencyRecord{
+				Scope:       "drone:d1",
+				Key:         "key-1",
+				RequestHash: fmt.Sprintf("hash-%d", i),
+				Response:    []byte(`{}`),
+				CreatedAt:   now,
+				ExpiresAt:   now.Add(time.Hour),
+			})
+			if err == nil {
+				err = tx.Commit(ctx)
+			}
+			if err == nil {
+				claimed <- i
+			}
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(claimed)
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("worker: %v", err)
+		}
+	}
+	if len(claimed) != 1 {
+		t.Fatalf("expected exactly one transaction to find the key unused, got %d", len(claimed))
+	}
+}
+
+func assertIdempotencyRecord(t *testing.T, got, want *domain.IdempotencyRecord) {
+	t.Helper()
+	if got.Scope != want.Scope || got.Key != want.Key || got.RequestHash != want.RequestHash ||
+		string(got.Response) != string(want.Response) ||
+		!got.CreatedAt.Equal(want.CreatedAt) || !got.ExpiresAt.Equal(want.ExpiresAt) {
+		t.Fatalf("idempotency record mismatch:\n got %+v\nwant %+v", got, want)
+	}
+}
@@ -0,0 +1,125 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"penny-assesment/internal/domain"
+	"penny-assesment/internal/service"
+)
+
+func testDepots(t *testing.T, store Store) {
+	ctx := context.Background()
+	now := baseTime()
+	if depots, err := store.ListDepots(ctx); err != nil || len(depots) != 0 {
+		t.Fatalf("empty store: expected no depots, got %d (err=%v)", len(depots), err)
+	}
+	if _, err := store.GetDepot(ctx, uuid.NewString()); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("GetDepot: expected ErrNotFound, got %v", err)
+	}
+
+	north := &domain.Depot{ID: uuid.NewString(), Name: "North", Location: domain.Location{Lat: 24.8, Lng: 46.65}, Capacity: 2, CreatedAt: now, UpdatedAt: now}
+	south := &domain.Depot{ID: uuid.NewString(), Name: "South", Location: domain.Location{Lat: 24.55, Lng: 46.7}, Capacity: 10, CreatedAt: now, UpdatedAt: now}
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		if err := tx.CreateDepot(ctx, north); err != nil {
+			return err
+		}
+		if err := tx.CreateDepot(ctx, south); err != nil {
+			return err
+		}
+		for _, drone := range []*domain.Drone{
+			{ID: "drone-2", Status: domain.DroneStatusActive, HomeDepotID: &north.ID, CreatedAt: now, UpdatedAt: now},
+			{ID: "drone-1", Status: domain.DroneStatusActive, HomeDepotID: &north.ID, CreatedAt: now, UpdatedAt: now},
+			{ID: "drone-3", Status: domain.DroneStatusActive, HomeDepotID: &south.ID, CreatedAt: now, UpdatedAt: now},
+			{ID: "drone-4", Status: domain.DroneStatusActive, CreatedAt: now, UpdatedAt: now},
+		} {
+			if err := tx.CreateDrone(ctx, drone); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if north.Version != 1 {
+		t.Fatalf("expected created depot at version 1, got %d", north.Version)
+	}
+	got, err := store.GetDepot(ctx, north.ID)
+	if err != nil {
+		t.Fatalf("get depot: %v", err)
+	}
+	if g, w := depotString(got), depotString(north); g != w {
+		t.Fatalf("depot round trip\n got: %s\nwant: %s", g, w)
+	}
+	depots, err := store.ListDepots(ctx)
+	if err != nil {
+		t.Fatalf("list depots: %v", err)
+	}
+	wantIDs := []string{north.ID, south.ID}
+	if wantIDs[0] > wantIDs[1] {
+		wantIDs[0], wantIDs[1] = wantIDs[1], wantIDs[0]
+	}
+	var gotIDs []string
+	for _, depot := range depots {
+		gotIDs = append(gotIDs, depot.ID)
+	}
+	if fmt.Sprint(gotIDs) != fmt.Sprint(wantIDs) {
+		t.Fatalf("expected depots by ID %v, got %v", wantIDs, gotIDs)
+	}
+
+	drones, err := store.ListDepotDrones(ctx, north.ID)
+	if err != nil {
+		t.Fatalf("list depot drones: %v", err)
+	}
+	if ids := droneIDs(drones); fmt.Sprint(ids) != "[drone-1 drone-2]" {
+		t.Fatalf("expected the north depot's drones by id, got %v", ids)
+	}
+	if drones[0].HomeDepotID == nil || *drones[0].HomeDepotID != north.ID {
+		t.Fatalf("expected the drone's home depot kept, got %s", str(drones[0].HomeDepotID))
+	}
+
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		locked, err := tx.GetDepotForUpdate(ctx, north.ID)
+		if err != nil {
+			return err
+		}
+		locked.Name = "North hub"
+		locked.Location = domain.Location{Lat: 24.81, Lng: 46.66}
+		locked.Capacity = 4
+		locked.UpdatedAt = now.Add(time.Hour)
+		north = locked
+		return tx.UpdateDepot(ctx, locked)
+	})
+	if north.Version != 2 {
+		t.Fatalf("expected updated depot at version 2, got %d", north.Version)
+	}
+	if got, err = store.GetDepot(ctx, north.ID); err != nil || depotString(got) != depotString(north) {
+		t.Fatalf("depot after update\n got: %s (err=%v)\nwant: %s", depotString(got), err, depotString(north))
+	}
+
+	stale := *north
+	stale.Version = 1
+	err = tryTx(ctx, store, func(ctx context.Context, tx service.Tx) error {
+		return tx.UpdateDepot(ctx, &stale)
+	})
+	if !errors.Is(err, domain.ErrVersionMismatch) {
+		t.Fatalf("expected ErrVersionMismatch for a stale depot update, got %v", err)
+	}
+	err = tryTx(ctx, store, func(ctx context.Context, tx service.Tx) error {
+		return tx.CreateDepot(ctx, &domain.Depot{ID: south.ID, Name: "Again", Capacity: 1, CreatedAt: now, UpdatedAt: now})
+	})
+	if !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected ErrConflict for a duplicate depot, got %v", err)
+	}
+}
+
+func depotString(d *domain.Depot) string {
+	if d == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("%s v%d name=%s loc=%v capacity=%d created=%s updated=%s",
+		d.ID, d.Version, d.Name, d.Location, d.Capacity, ts(&d.CreatedAt), ts(&d.UpdatedAt))
+}
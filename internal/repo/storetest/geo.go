@@ -0,0 +1,102 @@
+package storetest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"penny-assesment/internal/domain"
+	"penny-assesment/internal/service"
+)
+
+// The geo scenarios search around a point just west of the antimeridian, so
+// both the bounding-box prefilter and the spatial index have to wrap.
+var geoCenter = domain.Location{Lat: 10, Lng: 179.95}
+
+func testOrdersWithinRadius(t *testing.T, store Store) {
+	ctx := context.Background()
+	now := baseTime()
+
+	far := newOrder(now) // ~8.8 km, across the antimeridian
+	far.Origin = domain.Location{Lat: 10, Lng: -179.97}
+	near := newOrder(now) // ~2.2 km
+	near.Origin = domain.Location{Lat: 10.02, Lng: 179.95}
+	outside := newOrder(now) // ~49 km
+	outside.Origin = domain.Location{Lat: 10, Lng: 179.5}
+	failed := newOrder(now) // ~6.6 km, across the antimeridian
+	failed.Origin = domain.Location{Lat: 10, Lng: -179.99}
+	failed.Status = domain.OrderStatusFailed
+	for _, order := range []*domain.Order{far, near, outside, failed} {
+		if err := store.CreateOrder(ctx, order); err != nil {
+			t.Fatalf("create order: %v", err)
+		}
+	}
+
+	cases := []struct {
+		name  string
+		query service.RadiusQuery
+		want  []*domain.Order
+	}{
+		{"nearest first", service.RadiusQuery{Center: geoCenter, RadiusMeters: 20_000, Limit: 10}, []*domain.Order{near, failed, far}},
+		{"statuses", service.RadiusQuery{Center: geoCenter, RadiusMeters: 20_000, Statuses: []domain.OrderStatus{domain.OrderStatusCreated}, Limit: 10}, []*domain.Order{near, far}},
+		{"radius", service.RadiusQuery{Center: geoCenter, RadiusMeters: 5_000, Limit: 10}, []*domain.Order{near}},
+		{"limit", service.RadiusQuery{Center: geoCenter, RadiusMeters: 100_000, Limit: 2}, []*domain.Order{near, failed}},
+	}
+	for _, tc := range cases {
+		got, err := store.OrdersWithinRadius(ctx, tc.query)
+		if err != nil {
+			t.Fatalf("%s: query: %v", tc.name, err)
+		}
+		if g, w := fmt.Sprint(orderIDs(got)), fmt.Sprint(orderIDs(tc.want)); g != w {
+			t.Fatalf("%s: expected %v, got %v", tc.name, w, g)
+		}
+	}
+}
+
+func testNearestIdleDrones(t *testing.T, store Store) {
+	ctx := context.Background()
+	now := baseTime()
+	orderID := uuid.NewString()
+	drone := func(id string, status domain.DroneStatus, loc *domain.Location, currentOrderID *string) *domain.Drone {
+		return &domain.Drone{ID: id, Status: status, LastLocation: loc, CurrentOrderID: currentOrderID, CreatedAt: now, UpdatedAt: now}
+	}
+	drones := []*domain.Drone{
+		drone("drone-far", domain.DroneStatusActive, &domain.Location{Lat: 10, Lng: -179.97}, nil),
+		drone("drone-near", domain.DroneStatusActive, &domain.Location{Lat: 10.02, Lng: 179.95}, nil),
+		drone("drone-busy", domain.DroneStatusActive, &geoCenter, &orderID),
+		drone("drone-broken", domain.DroneStatusBroken, &geoCenter, nil),
+		drone("drone-charging", domain.DroneStatusCharging, &geoCenter, nil),
+		drone("drone-retired", domain.DroneStatusRetired, &geoCenter, nil),
+		drone("drone-unlocated", domain.DroneStatusActive, nil, nil),
+	}
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		for _, d := range drones {
+			if err := tx.CreateDrone(ctx, d); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	for _, tc := range []struct {
+		limit int
+		want  []string
+	}{
+		{10, []string{"drone-near", "drone-far"}},
+		{1, []string{"drone-near"}},
+	} {
+		got, err := store.NearestIdleDrones(ctx, geoCenter, tc.limit)
+		if err != nil {
+			t.Fatalf("nearest idle drones: %v", err)
+		}
+		ids := make([]string, 0, len(got))
+		for _, d := range got {
+			ids = append(ids, d.ID)
+		}
+		if g, w := fmt.Sprint(ids), fmt.Sprint(tc.want); g != w {
+			t.Fatalf("limit %d: expected %v, got %v", tc.limit, w, g)
+		}
+	}
+}
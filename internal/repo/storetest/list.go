@@ -0,0 +1,169 @@
+package storetest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"penny-assesment/internal/domain"
+	"penny-assesment/internal/service"
+)
+
+func testListOrdersFilters(t *testing.T, store Store) {
+	ctx := context.Background()
+	now := baseTime()
+	droneID := "drone-1"
+	reason := "Low BATTERY over water"
+
+	a := newOrder(now)
+	a.Origin = domain.Location{Lat: 10, Lng: 179}
+	a.UpdatedAt = now.Add(3 * time.Hour)
+	b := newOrder(now.Add(time.Hour))
+	b.Origin = domain.Location{Lat: 10, Lng: -179}
+	b.Status = domain.OrderStatusReserved
+	b.AssignedDroneID = &droneID
+	c := newOrder(now.Add(2 * time.Hour))
+	c.UserID = "user-2"
+	c.Origin = domain.Location{Lat: 10, Lng: 0}
+	c.Destination = domain.Location{Lat: -5, Lng: 5}
+	c.Status = domain.OrderStatusFailed
+	c.FailureReason = &reason
+	for _, order := range []*domain.Order{a, b, c} {
+		if err := store.CreateOrder(ctx, order); err != nil {
+			t.Fatalf("create order: %v", err)
+		}
+	}
+
+	user2 := "user-2"
+	from := now.Add(30 * time.Minute)
+	to := now.Add(2 * time.Hour)
+	updatedFrom := now.Add(2 * time.Hour)
+	cases := []struct {
+		name   string
+		filter service.OrderFilter
+		want   []*domain.Order
+	}{
+		{"all", service.OrderFilter{}, []*domain.Order{a, b, c}},
+		{"statuses", service.OrderFilter{Statuses: []domain.OrderStatus{domain.OrderStatusCreated, domain.OrderStatusFailed}}, []*domain.Order{a, c}},
+		{"user", service.OrderFilter{UserID: &user2}, []*domain.Order{c}},
+		{"drone", service.OrderFilter{DroneID: &droneID}, []*domain.Order{b}},
+		{"created range", service.OrderFilter{CreatedFrom: &from, CreatedTo: &to}, []*domain.Order{b}},
+		{"updated from", service.OrderFilter{UpdatedFrom: &updatedFrom, Sort: service.OrderSortUpdatedAsc}, []*domain.Order{c, a}},
+		{"failure reason", service.OrderFilter{FailureReason: "battery"}, []*domain.Order{c}},
+		{"failure reason wildcard", service.OrderFilter{FailureReason: "%"}, nil},
+		{"origin box across antimeridian", service.OrderFilter{OriginBox: &domain.BoundingBox{MinLat: 0, MinLng: 170, MaxLat: 20, MaxLng: -170}}, []*domain.Order{a, b}},
+		{"destination box", service.OrderFilter{DestinationBox: &domain.BoundingBox{MinLat: -10, MinLng: 0, MaxLat: 0, MaxLng: 10}}, []*domain.Order{c}},
+		{"sort updated desc", service.OrderFilter{Sort: service.OrderSortUpdatedDesc}, []*domain.Order{a, c, b}},
+		{"limit", service.OrderFilter{Limit: 2}, []*domain.Order{a, b}},
+	}
+	for _, tc := range cases {
+		got, err := store.ListOrders(ctx, tc.filter)
+		if err != nil {
+			t.Fatalf("%s: list: %v", tc.name, err)
+		}
+		if g, w := fmt.Sprint(orderIDs(got)), fmt.Sprint(orderIDs(tc.want)); g != w {
+			t.Fatalf("%s: expected %v, got %v", tc.name, w, g)
+		}
+		if tc.filter.Limit != 0 {
+			continue
+		}
+		count, err := store.CountOrders(ctx, tc.filter)
+		if err != nil {
+			t.Fatalf("%s: count: %v", tc.name, err)
+		}
+		if count != len(tc.want) {
+			t.Fatalf("%s: expected count %d, got %d", tc.name, len(tc.want), count)
+		}
+	}
+}
+
+// testQueuePositions checks that orders created at the same instant do not
+// count each other and that orders out of the queue are left out.
+func testQueuePositions(t *testing.T, store Store) {
+	ctx := context.Background()
+	now := baseTime()
+	a := newOrder(now)
+	b := newOrder(now)
+	b.Status = domain.OrderStatusHandoffRequested
+	c := newOrder(now.Add(time.Minute))
+	c.Status = domain.OrderStatusReserved
+	d := newOrder(now.Add(2 * time.Minute))
+	for _, order := range []*domain.Order{a, b, c, d} {
+		if err := store.CreateOrder(ctx, order); err != nil {
+			t.Fatalf("create order: %v", err)
+		}
+	}
+
+	statuses := []domain.OrderStatus{domain.OrderStatusCreated, domain.OrderStatusHandoffRequested}
+	got, err := store.QueuePositions(ctx, statuses, []string{a.ID, b.ID, c.ID, d.ID, "missing"})
+	if err != nil {
+		t.Fatalf("queue positions: %v", err)
+	}
+	want := map[string]int{a.ID: 0, b.ID: 0, d.ID: 2}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+// testListOrdersKeyset walks pages with the (sort column, id) cursor across
+// orders that share timestamps, in both directions.
+func testListOrdersKeyset(t *testing.T, store Store) {
+	ctx := context.Background()
+	now := baseTime()
+	var orders []*domain.Order
+	for i := 0; i < 7; i++ {
+		order := newOrder(now.Add(time.Duration(i/3) * time.Minute))
+		orders = append(orders, order)
+		if err := store.CreateOrder(ctx, order); err != nil {
+			t.Fatalf("create order: %v", err)
+		}
+	}
+	filter := service.OrderFilter{}
+	asc := append([]*domain.Order(nil), orders...)
+	sort.Slice(asc, func(i, j int) bool {
+		return filter.Less(&service.OrderCursor{Key: asc[i].CreatedAt, ID: asc[i].ID}, asc[j])
+	})
+	desc := make([]*domain.Order, len(asc))
+	for i := range asc {
+		desc[len(asc)-1-i] = asc[i]
+	}
+
+	for _, tc := range []struct {
+		sort service.OrderSort
+		want []*domain.Order
+	}{
+		{service.OrderSortCreatedAsc, asc},
+		{service.OrderSortCreatedDesc, desc},
+	} {
+		var got []*domain.Order
+		filter := service.OrderFilter{Sort: tc.sort, Limit: 3}
+		for pages := 0; ; pages++ {
+			if pages > len(orders) {
+				t.Fatalf("%s: pagination did not terminate", tc.sort)
+			}
+			page, err := store.ListOrders(ctx, filter)
+			if err != nil {
+				t.Fatalf("%s: list: %v", tc.sort, err)
+			}
+			got = append(got, page...)
+			if len(page) < filter.Limit {
+				break
+			}
+			last := page[len(page)-1]
+			filter.After = &service.OrderCursor{Key: tc.sort.Key(last), ID: last.ID}
+		}
+		if g, w := fmt.Sprint(orderIDs(got)), fmt.Sprint(orderIDs(tc.want)); g != w {
+			t.Fatalf("%s: expected %v, got %v", tc.sort, w, g)
+		}
+	}
+}
+
+func orderIDs(orders []*domain.Order) []string {
+	ids := make([]string, 0, len(orders))
+	for _, o := range orders {
+		ids = append(ids, o.ID)
+	}
+	return ids
+}
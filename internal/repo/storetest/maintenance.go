@@ -0,0 +1,132 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"penny-assesment/internal/domain"
+	"penny-assesment/internal/service"
+)
+
+func testMaintenanceRecords(t *testing.T, store Store) {
+	ctx := context.Background()
+	now := baseTime()
+	closedAt := now.Add(time.Hour)
+	technician, notes := "Sam", "replaced rotor"
+	closed := &domain.MaintenanceRecord{
+		ID:         uuid.NewString(),
+		DroneID:    "drone-1",
+		Reason:     domain.MaintenanceReasonBroken,
+		FaultCodes: []string{"MOTOR_FAILURE"},
+		Usage:      domain.FlightUsage{Seconds: 3600, Meters: 12500.5},
+		OpenedAt:   now,
+		ClosedAt:   &closedAt,
+		Technician: &technician,
+		Notes:      &notes,
+		Parts:      []string{"rotor", "esc"},
+	}
+	open := &domain.MaintenanceRecord{
+		ID:       uuid.NewString(),
+		DroneID:  "drone-1",
+		Reason:   domain.MaintenanceReasonScheduled,
+		Usage:    domain.FlightUsage{Seconds: 7200, Meters: 30000},
+		OpenedAt: now.Add(2 * time.Hour),
+	}
+	other := &domain.MaintenanceRecord{
+		ID:       uuid.NewString(),
+		DroneID:  "drone-2",
+		Reason:   domain.MaintenanceReasonServiceInterval,
+		OpenedAt: now,
+	}
+	// Created newest first: listings sort by OpenedAt.
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		for _, record := range []*domain.MaintenanceRecord{open, other, closed} {
+			if err := tx.CreateMaintenanceRecord(ctx, record); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	assertMaintenance(t, store, "drone-1", closed, open)
+	if records, err := store.ListMaintenanceRecords(ctx, "drone-3"); err != nil || len(records) != 0 {
+		t.Fatalf("expected no records for an unknown drone, got %d (err=%v)", len(records), err)
+	}
+
+	err := tryTx(ctx, store, func(ctx context.Context, tx service.Tx) error {
+		return tx.CreateMaintenanceRecord(ctx, &domain.MaintenanceRecord{
+			ID: uuid.NewString(), DroneID: "drone-1", Reason: domain.MaintenanceReasonBroken, OpenedAt: now,
+		})
+	})
+	if !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected ErrConflict for a second open record, got %v", err)
+	}
+
+	reopenedAt := now.Add(3 * time.Hour)
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		locked, err := tx.GetOpenMaintenanceRecordForUpdate(ctx, "drone-1")
+		if err != nil {
+			return err
+		}
+		if locked.ID != open.ID {
+			return fmt.Errorf("expected open record %s, got %s", open.ID, locked.ID)
+		}
+		locked.ClosedAt = &reopenedAt
+		locked.Parts = []string{"battery"}
+		open = locked
+		return tx.UpdateMaintenanceRecord(ctx, locked)
+	})
+	assertMaintenance(t, store, "drone-1", closed, open)
+
+	err = tryTx(ctx, store, func(ctx context.Context, tx service.Tx) error {
+		_, err := tx.GetOpenMaintenanceRecordForUpdate(ctx, "drone-1")
+		return err
+	})
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound once every record is closed, got %v", err)
+	}
+	err = tryTx(ctx, store, func(ctx context.Context, tx service.Tx) error {
+		return tx.UpdateMaintenanceRecord(ctx, &domain.MaintenanceRecord{ID: uuid.NewString(), DroneID: "drone-1", OpenedAt: now})
+	})
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound updating a missing record, got %v", err)
+	}
+}
+
+// tryTx runs fn in a transaction it rolls back and returns fn's error. Each
+// failing call gets its own transaction: Postgres aborts a transaction after a
+// failed statement.
+func tryTx(ctx context.Context, store Store, fn func(ctx context.Context, tx service.Tx) error) error {
+	tx, err := store.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	return fn(ctx, tx)
+}
+
+func assertMaintenance(t *testing.T, store Store, droneID string, want ...*domain.MaintenanceRecord) {
+	t.Helper()
+	got, err := store.ListMaintenanceRecords(context.Background(), droneID)
+	if err != nil {
+		t.Fatalf("list maintenance records: %v", err)
+	}
+	if g, w := maintenanceStrings(got), maintenanceStrings(want); !reflect.DeepEqual(g, w) {
+		t.Fatalf("maintenance records mismatch\n got: %v\nwant: %v", g, w)
+	}
+}
+
+func maintenanceStrings(records []*domain.MaintenanceRecord) []string {
+	out := make([]string, 0, len(records))
+	for _, r := range records {
+		out = append(out, fmt.Sprintf("%s drone=%s reason=%s faults=%v usage=%v opened=%s closed=%s technician=%s notes=%s parts=%v",
+			r.ID, r.DroneID, r.Reason, r.FaultCodes, r.Usage, ts(&r.OpenedAt), ts(r.ClosedAt), str(r.Technician), str(r.Notes), r.Parts))
+	}
+	return out
+}
@@ -0,0 +1,131 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"penny-assesment/internal/domain"
+	"penny-assesment/internal/service"
+)
+
+func testNoFlyZones(t *testing.T, store Store) {
+	ctx := context.Background()
+	now := baseTime()
+	if zones, err := store.ListNoFlyZones(ctx); err != nil || len(zones) != 0 {
+		t.Fatalf("empty store: expected no no-fly zones, got %v (err=%v)", zones, err)
+	}
+	if _, err := store.GetNoFlyZone(ctx, uuid.NewString()); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("GetNoFlyZone: expected ErrNotFound, got %v", err)
+	}
+
+	from := now.Add(time.Hour)
+	until := now.Add(3 * time.Hour)
+	windowed := &domain.NoFlyZone{
+		ID:          uuid.NewString(),
+		Name:        "Air show",
+		Boundary:    domain.Polygon{{{Lat: 24.9, Lng: 46.6}, {Lat: 24.9, Lng: 46.75}, {Lat: 25, Lng: 46.75}, {Lat: 24.9, Lng: 46.6}}},
+		ActiveFrom:  &from,
+		ActiveUntil: &until,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	permanent := &domain.NoFlyZone{
+		ID:        uuid.NewString(),
+		Name:      "KKIA",
+		Boundary:  domain.Polygon{{{Lat: 24.9, Lng: 46.65}, {Lat: 24.9, Lng: 46.75}, {Lat: 25, Lng: 46.75}, {Lat: 25, Lng: 46.65}, {Lat: 24.9, Lng: 46.65}}},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		if err := tx.CreateNoFlyZone(ctx, windowed); err != nil {
+			return err
+		}
+		return tx.CreateNoFlyZone(ctx, permanent)
+	})
+	if windowed.Version != 1 {
+		t.Fatalf("expected created no-fly zone at version 1, got %d", windowed.Version)
+	}
+	for _, want := range []*domain.NoFlyZone{windowed, permanent} {
+		got, err := store.GetNoFlyZone(ctx, want.ID)
+		if err != nil {
+			t.Fatalf("get no-fly zone: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("no-fly zone round trip:\n got  %+v\n want %+v", got, want)
+		}
+	}
+	zones, err := store.ListNoFlyZones(ctx)
+	if err != nil {
+		t.Fatalf("list no-fly zones: %v", err)
+	}
+	wantIDs := []string{windowed.ID, permanent.ID}
+	if wantIDs[0] > wantIDs[1] {
+		wantIDs[0], wantIDs[1] = wantIDs[1], wantIDs[0]
+	}
+	var gotIDs []string
+	for _, zone := range zones {
+		gotIDs = append(gotIDs, zone.ID)
+	}
+	if fmt.Sprint(gotIDs) != fmt.Sprint(wantIDs) {
+		t.Fatalf("expected no-fly zones by ID %v, got %v", wantIDs, gotIDs)
+	}
+
+	tx, err := store.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	err = tx.CreateNoFlyZone(ctx, &domain.NoFlyZone{ID: permanent.ID, Name: "dup", Boundary: permanent.Boundary, CreatedAt: now, UpdatedAt: now})
+	tx.Rollback(ctx)
+	if !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("duplicate CreateNoFlyZone: expected ErrConflict, got %v", err)
+	}
+
+	stale := *windowed
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		zone, err := tx.GetNoFlyZoneForUpdate(ctx, windowed.ID)
+		if err != nil {
+			return err
+		}
+		zone.ActiveFrom = nil
+		zone.UpdatedAt = now.Add(time.Minute)
+		if err := tx.UpdateNoFlyZone(ctx, zone); err != nil {
+			return err
+		}
+		if zone.Version != 2 {
+			return fmt.Errorf("expected update to bump to version 2, got %d", zone.Version)
+		}
+		return nil
+	})
+	got, err := store.GetNoFlyZone(ctx, windowed.ID)
+	if err != nil {
+		t.Fatalf("get no-fly zone: %v", err)
+	}
+	if got.ActiveFrom != nil || got.ActiveUntil == nil || !got.ActiveUntil.Equal(until) || got.Version != 2 {
+		t.Fatalf("expected window start cleared, got %+v", got)
+	}
+
+	tx, err = store.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	err = tx.UpdateNoFlyZone(ctx, &stale)
+	tx.Rollback(ctx)
+	if !errors.Is(err, domain.ErrVersionMismatch) {
+		t.Fatalf("UpdateNoFlyZone with stale version: expected ErrVersionMismatch, got %v", err)
+	}
+	tx, err = store.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	_, err = tx.GetNoFlyZoneForUpdate(ctx, uuid.NewString())
+	tx.Rollback(ctx)
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("GetNoFlyZoneForUpdate: expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"penny-assesment/internal/domain"
+	"penny-assesment/internal/service"
+)
+
+func testServiceAreas(t *testing.T, store Store) {
+	ctx := context.Background()
+	now := baseTime()
+	if areas, err := store.ListServiceAreas(ctx); err != nil || len(areas) != 0 {
+		t.Fatalf("empty store: expected no service areas, got %v (err=%v)", areas, err)
+	}
+	if _, err := store.GetServiceArea(ctx, uuid.NewString()); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("GetServiceArea: expected ErrNotFound, got %v", err)
+	}
+
+	// An exterior ring crossing the antimeridian with a hole; coordinates are
+	// chosen to survive a JSON round trip exactly.
+	withHole := &domain.ServiceArea{
+		ID:   uuid.NewString(),
+		Name: "Fiji",
+		Boundary: domain.Polygon{
+			{{Lat: -20, Lng: 177}, {Lat: -20, Lng: -178.125}, {Lat: -15.5, Lng: -178.125}, {Lat: -15.5, Lng: 177}, {Lat: -20, Lng: 177}},
+			{{Lat: -18, Lng: 178}, {Lat: -18, Lng: 179}, {Lat: -17, Lng: 179}, {Lat: -18, Lng: 178}},
+		},
+		Active:    true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	inactive := &domain.ServiceArea{
+		ID:        uuid.NewString(),
+		Name:      "Riyadh",
+		Boundary:  domain.Polygon{{{Lat: 24.6, Lng: 46.6}, {Lat: 24.6, Lng: 46.8}, {Lat: 24.8, Lng: 46.8}, {Lat: 24.6, Lng: 46.6}}},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		if err := tx.CreateServiceArea(ctx, withHole); err != nil {
+			return err
+		}
+		return tx.CreateServiceArea(ctx, inactive)
+	})
+	if withHole.Version != 1 {
+		t.Fatalf("expected created service area at version 1, got %d", withHole.Version)
+	}
+
+	got, err := store.GetServiceArea(ctx, withHole.ID)
+	if err != nil {
+		t.Fatalf("get service area: %v", err)
+	}
+	if !reflect.DeepEqual(got, withHole) {
+		t.Fatalf("service area round trip:\n got  %+v\n want %+v", got, withHole)
+	}
+	areas, err := store.ListServiceAreas(ctx)
+	if err != nil {
+		t.Fatalf("list service areas: %v", err)
+	}
+	wantIDs := []string{withHole.ID, inactive.ID}
+	if wantIDs[0] > wantIDs[1] {
+		wantIDs[0], wantIDs[1] = wantIDs[1], wantIDs[0]
+	}
+	var gotIDs []string
+	for _, area := range areas {
+		gotIDs = append(gotIDs, area.ID)
+	}
+	if fmt.Sprint(gotIDs) != fmt.Sprint(wantIDs) {
+		t.Fatalf("expected service areas by ID %v, got %v", wantIDs, gotIDs)
+	}
+
+	tx, err := store.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	err = tx.CreateServiceArea(ctx, &domain.ServiceArea{ID: inactive.ID, Name: "dup", Boundary: inactive.Boundary, CreatedAt: now, UpdatedAt: now})
+	tx.Rollback(ctx)
+	if !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("duplicate CreateServiceArea: expected ErrConflict, got %v", err)
+	}
+
+	stale := *inactive
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		area, err := tx.GetServiceAreaForUpdate(ctx, inactive.ID)
+		if err != nil {
+			return err
+		}
+		area.Active = true
+		area.Name = "Riyadh North"
+		area.UpdatedAt = now.Add(time.Minute)
+		if err := tx.UpdateServiceArea(ctx, area); err != nil {
+			return err
+		}
+		if area.Version != 2 {
+			return fmt.Errorf("expected update to bump to version 2, got %d", area.Version)
+		}
+		return nil
+	})
+	if got, _ := store.GetServiceArea(ctx, inactive.ID); !got.Active || got.Name != "Riyadh North" || got.Version != 2 {
+		t.Fatalf("expected updated service area, got %+v", got)
+	}
+
+	tx, err = store.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	err = tx.UpdateServiceArea(ctx, &stale)
+	tx.Rollback(ctx)
+	if !errors.Is(err, domain.ErrVersionMismatch) {
+		t.Fatalf("UpdateServiceArea with stale version: expected ErrVersionMismatch, got %v", err)
+	}
+	tx, err = store.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	_, err = tx.GetServiceAreaForUpdate(ctx, uuid.NewString())
+	tx.Rollback(ctx)
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("GetServiceAreaForUpdate: expected ErrNotFound, got %v", err)
+	}
+}
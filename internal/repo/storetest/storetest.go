@@ -0,0 +1,608 @@
+// Package storetest is a conformance suite for service.Store implementations.
+// Each backend's tests call Run with a factory returning an empty store; the
+// same scenarios then run against memory, SQLite and Postgres.
+//
+// The suite is the specification of the store contract:
+//   - Getters (including the *ForUpdate reads) return domain.ErrNotFound for
+//     missing rows; GetDrones silently omits them.
+//   - Creating a row whose ID already exists returns domain.ErrConflict.
+//   - Create* stores version 1; Update* only applies when the passed Version
+//     matches the stored one (domain.ErrVersionMismatch otherwise) and sets the
+//     incremented version on the passed struct.
+//   - QueuedOrders returns unassigned orders in an allowed status, oldest
+//     first with ties by id, up to limit, without locking them. Orders with
+//     PickupNotBefore after due are left out, and those with DeliverBy up to
+//     urgent come first, earliest DeliverBy first; a non-nil after continues
+//     the same order past that order. ReserveOrder locks and
+//     returns the given order only if it still meets the same conditions,
+//     otherwise nil; concurrent reservations never get the same order.
+//   - Writes, including enqueued events, are invisible outside the
+//     transaction until Commit and are discarded by Rollback.
+//   - FetchPending returns unpublished events oldest first, up to limit.
+//   - GetIdempotencyRecord returns domain.ErrNotFound for unused keys and
+//     serialises transactions that look up the same (scope, key);
+//     SaveIdempotencyRecord replaces any existing record for the key.
+//     PruneIdempotencyRecords deletes the records expiring at or before the
+//     given time and reports how many.
+//   - ListOrders applies every service.OrderFilter field with the semantics of
+//     OrderFilter.Matches and pages by (sort column, id). CountOrders counts
+//     the same matches without the keyset position or limit. QueuePositions
+//     counts, for each given order in one of the statuses, the orders in those
+//     statuses created strictly before it; other IDs are left out.
+//   - OrdersWithinRadius returns orders whose origin is within the radius
+//     (great-circle distance, wrapping across the antimeridian) and in one of
+//     the statuses, nearest first with ties by id, up to Limit.
+//   - Service areas follow the same ErrNotFound, ErrConflict and version rules
+//     as orders and drones, keep their boundary polygon exactly, and are
+//     listed by ID. No-fly zones follow the same rules and also keep their
+//     optional window ends, nil included.
+//   - NearestIdleDrones returns drones in an assignable status (see
+//     domain.AssignableDroneStatuses) with a location and no current order,
+//     nearest first with ties by id, up to limit.
+//   - ListTelemetry returns the samples of a drone or an order recorded in
+//     [From, To), oldest first, up to Limit. PruneTelemetry deletes the
+//     samples recorded before the cutoff and reports how many; a zero cutoff
+//     deletes nothing.
+//   - A drone has at most one open maintenance record (nil ClosedAt); creating
+//     a second returns domain.ErrConflict and GetOpenMaintenanceRecordForUpdate
+//     returns domain.ErrNotFound when there is none. ListMaintenanceRecords
+//     lists a drone's records oldest first with ties by id.
+//   - Depots follow the same ErrNotFound, ErrConflict and version rules as
+//     service areas and are listed by ID. ListDepotDrones returns the drones
+//     whose HomeDepotID is the depot, by ID.
+//   - Charging stations follow the same rules as depots. Creating or updating
+//     a station makes its slots 1..Slots, adding free slots and dropping those
+//     past the count. ReserveFreeChargingSlot returns the lowest-numbered free
+//     slot not locked by another transaction, or nil; a drone holds at most
+//     one slot, so UpdateChargingSlot returns domain.ErrConflict for a second.
+//
+// Scenarios never hold two transactions open on one goroutine: the SQLite
+// store serialises transactions, so that would block.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"penny-assesment/internal/domain"
+	"penny-assesment/internal/events"
+	"penny-assesment/internal/service"
+)
+
+// Store is what a backend must provide: the service store plus the outbox.
+type Store interface {
+	service.Store
+	events.OutboxRepository
+}
+
+// Factory returns an empty store. It is called once per scenario.
+type Factory func(t *testing.T) Store
+
+// Run executes every scenario against stores from newStore.
+func Run(t *testing.T, newStore Factory) {
+	scenarios := []struct {
+		name string
+		run  func(t *testing.T, store Store)
+	}{
+		{"OrderRoundTrip", testOrderRoundTrip},
+		{"DroneRoundTrip", testDroneRoundTrip},
+		{"NotFound", testNotFound},
+		{"DuplicateCreateConflicts", testDuplicateCreateConflicts},
+		{"Versions", testVersions},
+		{"QueuedOrders", testQueuedOrders},
+		{"QueuedOrdersWindows", testQueuedOrdersWindows},
+		{"ConcurrentReservations", testConcurrentReservations},
+		{"RollbackDiscardsWrites", testRollbackDiscardsWrites},
+		{"UncommittedWritesInvisible", testUncommittedWritesInvisible},
+		{"Outbox", testOutbox},
+		{"OutboxLimitAndMark", testOutboxLimitAndMark},
+		{"ListOrdersFilters", testListOrdersFilters},
+		{"ListOrdersKeyset", testListOrdersKeyset},
+		{"QueuePositions", testQueuePositions},
+		{"IdempotencyRecords", testIdempotencyRecords},
+		{"ConcurrentIdempotencyKey", testConcurrentIdempotencyKey},
+		{"OrdersWithinRadius", testOrdersWithinRadius},
+		{"NearestIdleDrones", testNearestIdleDrones},
+		{"ServiceAreas", testServiceAreas},
+		{"NoFlyZones", testNoFlyZones},
+		{"Telemetry", testTelemetry},
+		{"MaintenanceRecords", testMaintenanceRecords},
+		{"Depots", testDepots},
+		{"ChargingStations", testChargingStations},
+		{"ConcurrentChargingReservations", testConcurrentChargingReservations},
+	}
+	for _, sc := range scenarios {
+		sc := sc
+		t.Run(sc.name, func(t *testing.T) {
+			sc.run(t, newStore(t))
+		})
+	}
+}
+
+// baseTime is truncated to microseconds, the coarsest precision among the
+// backends.
+func baseTime() time.Time {
+	return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC).Add(123456 * time.Microsecond)
+}
+
+func newOrder(createdAt time.Time) *domain.Order {
+	return &domain.Order{
+		ID:          uuid.NewString(),
+		UserID:      "user-1",
+		Origin:      domain.Location{Lat: 24.7136, Lng: 46.6753},
+		Destination: domain.Location{Lat: 24.7743, Lng: 46.7386},
+		Status:      domain.OrderStatusCreated,
+		CreatedAt:   createdAt,
+		UpdatedAt:   createdAt,
+	}
+}
+
+func commit(t *testing.T, store Store, fn func(ctx context.Context, tx service.Tx) error) {
+	t.Helper()
+	ctx := context.Background()
+	tx, err := store.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+	if err := fn(ctx, tx); err != nil {
+		t.Fatalf("tx: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+}
+
+func testOrderRoundTrip(t *testing.T, store Store) {
+	ctx := context.Background()
+	now := baseTime()
+	plain := newOrder(now)
+	if err := store.CreateOrder(ctx, plain); err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	got, err := store.GetOrder(ctx, plain.ID)
+	if err != nil {
+		t.Fatalf("get order: %v", err)
+	}
+	assertOrder(t, got, plain)
+
+	droneID := "drone-1"
+	reason := "battery"
+	full := newOrder(now)
+	full.Status = domain.OrderStatusFailed
+	full.AssignedDroneID = &droneID
+	full.HandoffOrigin = &domain.Location{Lat: 1.25, Lng: -2.5}
+	reservedAt := now.Add(time.Minute)
+	pickedUpAt := now.Add(2 * time.Minute)
+	failedAt := now.Add(3 * time.Minute)
+	full.ReservedAt, full.PickedUpAt, full.FailedAt = &reservedAt, &pickedUpAt, &failedAt
+	full.FailureReason = &reason
+	full.Route = []domain.Location{{Lat: 0, Lng: 0}, {Lat: 0.5, Lng: 0.75}, {Lat: 1, Lng: 1}}
+	full.Legs = []domain.RelayLeg{
+		{To: domain.Location{Lat: 0.5, Lng: 0.5}, RelayPointID: uuid.NewString(), DroneID: &droneID, CompletedAt: &pickedUpAt},
+		{To: full.Destination},
+	}
+	deliverBy := now.Add(time.Hour)
+	full.PickupNotBefore, full.DeliverBy, full.SLAAtRiskAt = &reservedAt, &deliverBy, &pickedUpAt
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		return tx.CreateOrder(ctx, full)
+	})
+	got, err = store.GetOrder(ctx, full.ID)
+	if err != nil {
+		t.Fatalf("get order: %v", err)
+	}
+	assertOrder(t, got, full)
+
+	// Updates go through a locked read, like the service does.
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		order, err := tx.GetOrderForUpdate(ctx, plain.ID)
+		if err != nil {
+			return err
+		}
+		order.Status = domain.OrderStatusReserved
+		order.AssignedDroneID = &droneID
+		order.Route = []domain.Location{order.Origin, order.Destination}
+		order.Legs = []domain.RelayLeg{{To: order.Destination, DroneID: &droneID}}
+		order.DeliverBy = &deliverBy
+		order.UpdatedAt = now.Add(time.Hour)
+		plain = order
+		return tx.UpdateOrder(ctx, order)
+	})
+	got, _ = store.GetOrder(ctx, plain.ID)
+	assertOrder(t, got, plain)
+}
+
+func testDroneRoundTrip(t *testing.T, store Store) {
+	ctx := context.Background()
+	now := baseTime()
+	orderID := uuid.NewString()
+	depotID := uuid.NewString()
+	heartbeat := now.Add(time.Second)
+	drone := &domain.Drone{
+		ID:              "drone-1",
+		Status:          domain.DroneStatusActive,
+		LastLocation:    &domain.Location{Lat: 5, Lng: 6},
+		LastHeartbeatAt: &heartbeat,
+		CurrentOrderID:  &orderID,
+		Stops: []domain.Stop{
+			{OrderID: orderID, Kind: domain.StopPickup, Location: domain.Location{Lat: 5, Lng: 6}, CompletedAt: &heartbeat},
+			{OrderID: orderID, Kind: domain.StopDropoff, Location: domain.Location{Lat: 5.5, Lng: 6.5}},
+		},
+		HomeDepotID: &depotID,
+		RecentFixes: []domain.Fix{
+			{Location: domain.Location{Lat: 4.9, Lng: 6}, At: now},
+			{Location: domain.Location{Lat: 5, Lng: 6}, At: heartbeat},
+		},
+		Vitals: domain.Vitals{
+			AltitudeMeters: ptr(120.5),
+			HeadingDegrees: ptr(0),
+			GroundSpeedMPS: ptr(14.2),
+			BatteryPercent: ptr(76),
+			FaultCodes:     []string{"LOW_SIGNAL", "CAMERA_FAULT"},
+		},
+		Usage:        domain.FlightUsage{Seconds: 5400.5, Meters: 81234.25},
+		SinceService: domain.FlightUsage{Seconds: 1800, Meters: 20000},
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	bare := &domain.Drone{ID: "drone-0", Status: domain.DroneStatusBroken, CreatedAt: now, UpdatedAt: now}
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		if err := tx.CreateDrone(ctx, drone); err != nil {
+			return err
+		}
+		return tx.CreateDrone(ctx, bare)
+	})
+	got, err := store.GetDrone(ctx, drone.ID)
+	if err != nil {
+		t.Fatalf("get drone: %v", err)
+	}
+	assertDrone(t, got, drone)
+
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		locked, err := tx.GetDroneForUpdate(ctx, drone.ID)
+		if err != nil {
+			return err
+		}
+		locked.CurrentOrderID = nil
+		locked.Stops = nil
+		locked.HomeDepotID = nil
+		locked.RecentFixes = locked.RecentFixes[1:]
+		locked.Vitals = domain.Vitals{BatteryPercent: ptr(75)}
+		locked.Status = domain.DroneStatusBroken
+		locked.SinceService = domain.FlightUsage{}
+		locked.UpdatedAt = now.Add(time.Hour)
+		drone = locked
+		return tx.UpdateDrone(ctx, locked)
+	})
+
+	drones, err := store.ListDrones(ctx)
+	if err != nil {
+		t.Fatalf("list drones: %v", err)
+	}
+	if len(drones) != 2 || drones[0].ID != "drone-0" || drones[1].ID != "drone-1" {
+		t.Fatalf("expected drones ordered by id, got %v", droneIDs(drones))
+	}
+	assertDrone(t, drones[1], drone)
+
+	batch, err := store.GetDrones(ctx, []string{"drone-1", "missing"})
+	if err != nil {
+		t.Fatalf("get drones: %v", err)
+	}
+	if len(batch) != 1 || batch[0].ID != "drone-1" {
+		t.Fatalf("expected only existing drones, got %v", droneIDs(batch))
+	}
+}
+
+func testQueuedOrders(t *testing.T, store Store) {
+	ctx := context.Background()
+	now := baseTime()
+	droneID := "drone-1"
+	assigned := newOrder(now)
+	assigned.Status = domain.OrderStatusReserved
+	assigned.AssignedDroneID = &droneID
+	handoff := newOrder(now.Add(time.Second))
+	handoff.Status = domain.OrderStatusHandoffRequested
+	second := newOrder(now.Add(3 * time.Second))
+	first := newOrder(now.Add(2 * time.Second))
+	for _, order := range []*domain.Order{assigned, handoff, second, first} {
+		if err := store.CreateOrder(ctx, order); err != nil {
+			t.Fatalf("create order: %v", err)
+		}
+	}
+
+	created := []domain.OrderStatus{domain.OrderStatusCreated}
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		for _, tc := range []struct {
+			name    string
+			allowed []domain.OrderStatus
+			limit   int
+			want    []*domain.Order
+		}{
+			{"created", created, 10, []*domain.Order{first, second}},
+			{"limit", created, 1, []*domain.Order{first}},
+			{"created and handoff", []domain.OrderStatus{domain.OrderStatusCreated, domain.OrderStatusHandoffRequested}, 10, []*domain.Order{handoff, first, second}},
+			{"none", []domain.OrderStatus{domain.OrderStatusPickedUp}, 10, nil},
+		} {
+			got, err := tx.QueuedOrders(ctx, tc.allowed, now, now, nil, tc.limit)
+			if err != nil {
+				return err
+			}
+			if g, w := fmt.Sprint(orderIDs(got)), fmt.Sprint(orderIDs(tc.want)); g != w {
+				return fmt.Errorf("%s: expected %v, got %v", tc.name, w, g)
+			}
+		}
+		return nil
+	})
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		order, err := tx.ReserveOrder(ctx, second.ID, created, now)
+		if err != nil {
+			return err
+		}
+		if order == nil || order.ID != second.ID {
+			return fmt.Errorf("expected to reserve %s, got %v", second.ID, order)
+		}
+		for _, id := range []string{assigned.ID, handoff.ID, uuid.NewString()} {
+			order, err := tx.ReserveOrder(ctx, id, created, now)
+			if err != nil {
+				return err
+			}
+			if order != nil {
+				return fmt.Errorf("expected %s not to be reservable, got %s", id, order.ID)
+			}
+		}
+		return nil
+	})
+}
+
+func testQueuedOrdersWindows(t *testing.T, store Store) {
+	ctx := context.Background()
+	now := baseTime()
+	at := func(d time.Duration) *time.Time {
+		v := now.Add(d)
+		return &v
+	}
+	oldest := newOrder(now)
+	scheduled := newOrder(now.Add(time.Second))
+	scheduled.PickupNotBefore = at(time.Hour)
+	relaxed := newOrder(now.Add(2 * time.Second))
+	relaxed.DeliverBy = at(3 * time.Hour)
+	later := newOrder(now.Add(3 * time.Second))
+	later.DeliverBy = at(time.Hour)
+	sooner := newOrder(now.Add(4 * time.Second))
+	sooner.DeliverBy = at(30 * time.Minute)
+	for _, order := range []*domain.Order{oldest, scheduled, relaxed, later, sooner} {
+		if err := store.CreateOrder(ctx, order); err != nil {
+			t.Fatalf("create order: %v", err)
+		}
+	}
+
+	// Deadlines up to urgent go first, soonest first; the rest by age, and
+	// scheduled orders only once due.
+	created := []domain.OrderStatus{domain.OrderStatusCreated}
+	queued := func(due, urgent time.Time, limit int) []string {
+		var ids []string
+		commit(t, store, func(ctx context.Context, tx service.Tx) error {
+			var after *domain.Order
+			for {
+				orders, err := tx.QueuedOrders(ctx, created, due, urgent, after, limit)
+				if err != nil {
+					return err
+				}
+				ids = append(ids, orderIDs(orders)...)
+				if len(orders) < limit {
+					return nil
+				}
+				after = orders[len(orders)-1]
+			}
+		})
+		return ids
+	}
+	// Paging one order at a time crosses from the deadlines to the rest.
+	for _, limit := range []int{10, 1} {
+		got := queued(now, now.Add(time.Hour), limit)
+		want := []string{sooner.ID, later.ID, oldest.ID, relaxed.ID}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("expected queue %v in pages of %d, got %v", want, limit, got)
+		}
+		got = queued(now.Add(time.Hour), now, limit)
+		want = []string{oldest.ID, scheduled.ID, relaxed.ID, later.ID, sooner.ID}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("expected queue %v once due and none urgent in pages of %d, got %v", want, limit, got)
+		}
+	}
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		if order, err := tx.ReserveOrder(ctx, scheduled.ID, created, now); err != nil || order != nil {
+			return fmt.Errorf("expected %s not reservable before it is due, got %v err=%v", scheduled.ID, order, err)
+		}
+		if order, err := tx.ReserveOrder(ctx, scheduled.ID, created, now.Add(time.Hour)); err != nil || order == nil {
+			return fmt.Errorf("expected %s reservable once due, got %v err=%v", scheduled.ID, order, err)
+		}
+		return nil
+	})
+}
+
+func testRollbackDiscardsWrites(t *testing.T, store Store) {
+	ctx := context.Background()
+	now := baseTime()
+	existing := newOrder(now)
+	if err := store.CreateOrder(ctx, existing); err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+
+	tx, err := store.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	order, err := tx.GetOrderForUpdate(ctx, existing.ID)
+	if err != nil {
+		t.Fatalf("get for update: %v", err)
+	}
+	order.Status = domain.OrderStatusWithdrawn
+	if err := tx.UpdateOrder(ctx, order); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	created := newOrder(now)
+	if err := tx.CreateOrder(ctx, created); err != nil {
+		t.Fatalf("create in tx: %v", err)
+	}
+	if err := tx.CreateDrone(ctx, &domain.Drone{ID: "drone-rb", Status: domain.DroneStatusActive, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("create drone: %v", err)
+	}
+	if err := tx.EnqueueEvent(ctx, newEvent(now)); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if err := tx.Rollback(ctx); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+
+	got, err := store.GetOrder(ctx, existing.ID)
+	if err != nil || got.Status != domain.OrderStatusCreated {
+		t.Fatalf("expected update to be rolled back, got %v err=%v", got, err)
+	}
+	if _, err := store.GetOrder(ctx, created.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected rolled back order to be absent, got %v", err)
+	}
+	if _, err := store.GetDrone(ctx, "drone-rb"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected rolled back drone to be absent, got %v", err)
+	}
+	if pending, err := store.FetchPending(ctx, 10); err != nil || len(pending) != 0 {
+		t.Fatalf("expected rolled back event to be absent, got %d err=%v", len(pending), err)
+	}
+}
+
+func testOutbox(t *testing.T, store Store) {
+	ctx := context.Background()
+	now := baseTime()
+	later := newEvent(now.Add(time.Second))
+	earlier := newEvent(now)
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		if err := tx.EnqueueEvent(ctx, later); err != nil {
+			return err
+		}
+		return tx.EnqueueEvent(ctx, earlier)
+	})
+
+	pending, err := store.FetchPending(ctx, 10)
+	if err != nil {
+		t.Fatalf("fetch pending: %v", err)
+	}
+	if len(pending) != 2 || pending[0].ID != earlier.ID || pending[1].ID != later.ID {
+		t.Fatalf("expected events oldest first, got %v", eventIDs(pending))
+	}
+	if pending[0].Type != earlier.Type || pending[0].AggregateID != earlier.AggregateID || !pending[0].OccurredAt.Equal(earlier.OccurredAt) {
+		t.Fatalf("event did not round-trip: %+v", pending[0])
+	}
+	if err := store.MarkPublished(ctx, []string{earlier.ID}); err != nil {
+		t.Fatalf("mark published: %v", err)
+	}
+	pending, _ = store.FetchPending(ctx, 10)
+	if len(pending) != 1 || pending[0].ID != later.ID {
+		t.Fatalf("expected only the unpublished event, got %v", eventIDs(pending))
+	}
+}
+
+func newEvent(occurredAt time.Time) events.Event {
+	return events.Event{
+		ID:            uuid.NewString(),
+		Type:          events.EventOrderCreated,
+		AggregateType: "order",
+		AggregateID:   uuid.NewString(),
+		Payload:       []byte(`{"ok":true}`),
+		OccurredAt:    occurredAt,
+	}
+}
+
+func assertOrder(t *testing.T, got, want *domain.Order) {
+	t.Helper()
+	if g, w := orderString(got), orderString(want); g != w {
+		t.Fatalf("order mismatch\n got: %s\nwant: %s", g, w)
+	}
+}
+
+func assertDrone(t *testing.T, got, want *domain.Drone) {
+	t.Helper()
+	if g, w := droneString(got), droneString(want); g != w {
+		t.Fatalf("drone mismatch\n got: %s\nwant: %s", g, w)
+	}
+}
+
+// orderString renders every field with times normalised to UTC so values from
+// different backends compare equal.
+func orderString(o *domain.Order) string {
+	legs := make([]string, 0, len(o.Legs))
+	for _, leg := range o.Legs {
+		legs = append(legs, fmt.Sprintf("%v via=%s drone=%s completed=%s", leg.To, leg.RelayPointID, str(leg.DroneID), ts(leg.CompletedAt)))
+	}
+	return fmt.Sprintf("%s v%d user=%s origin=%v dest=%v status=%s drone=%s handoff=%s created=%s updated=%s reserved=%s picked=%s delivered=%s failed=%s reason=%s route=%v legs=%v not_before=%s deliver_by=%s at_risk=%s",
+		o.ID, o.Version, o.UserID, o.Origin, o.Destination, o.Status, str(o.AssignedDroneID), loc(o.HandoffOrigin),
+		ts(&o.CreatedAt), ts(&o.UpdatedAt), ts(o.ReservedAt), ts(o.PickedUpAt), ts(o.DeliveredAt), ts(o.FailedAt), str(o.FailureReason), o.Route, legs,
+		ts(o.PickupNotBefore), ts(o.DeliverBy), ts(o.SLAAtRiskAt))
+}
+
+func droneString(d *domain.Drone) string {
+	fixes := make([]string, 0, len(d.RecentFixes))
+	for _, fix := range d.RecentFixes {
+		fixes = append(fixes, fmt.Sprintf("%v@%s", fix.Location, ts(&fix.At)))
+	}
+	stops := make([]string, 0, len(d.Stops))
+	for _, stop := range d.Stops {
+		stops = append(stops, fmt.Sprintf("%s:%s@%v completed=%s", stop.Kind, stop.OrderID, stop.Location, ts(stop.CompletedAt)))
+	}
+	v := d.Vitals
+	return fmt.Sprintf("%s v%d status=%s loc=%s heartbeat=%s order=%s stops=%v depot=%s fixes=%v alt=%s heading=%s speed=%s battery=%s faults=%v usage=%v since_service=%v created=%s updated=%s",
+		d.ID, d.Version, d.Status, loc(d.LastLocation), ts(d.LastHeartbeatAt), str(d.CurrentOrderID), stops, str(d.HomeDepotID), fixes,
+		num(v.AltitudeMeters), num(v.HeadingDegrees), num(v.GroundSpeedMPS), num(v.BatteryPercent), v.FaultCodes, d.Usage, d.SinceService, ts(&d.CreatedAt), ts(&d.UpdatedAt))
+}
+
+func str(v *string) string {
+	if v == nil {
+		return "<nil>"
+	}
+	return *v
+}
+
+func num(v *float64) string {
+	if v == nil {
+		return "<nil>"
+	}
+	return fmt.Sprint(*v)
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+func ts(v *time.Time) string {
+	if v == nil {
+		return "<nil>"
+	}
+	return v.UTC().Format(time.RFC3339Nano)
+}
+
+func loc(v *domain.Location) string {
+	if v == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("%v", *v)
+}
+
+func droneIDs(drones []*domain.Drone) []string {
+	ids := make([]string, 0, len(drones))
+	for _, d := range drones {
+		ids = append(ids, d.ID)
+	}
+	return ids
+}
+
+func eventIDs(evts []events.Event) []string {
+	ids := make([]string, 0, len(evts))
+	for _, e := range evts {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
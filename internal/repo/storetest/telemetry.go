@@ -0,0 +1,81 @@
+package storetest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"penny-assesment/internal/domain"
+	"penny-assesment/internal/service"
+)
+
+func testTelemetry(t *testing.T, store Store) {
+	ctx := context.Background()
+	now := baseTime()
+	orderID := uuid.NewString()
+	at := func(minutes int) time.Time { return now.Add(time.Duration(minutes) * time.Minute) }
+	samples := []*domain.TelemetrySample{
+		{DroneID: "drone-1", Location: domain.Location{Lat: 24.7, Lng: 46.6}, RecordedAt: at(0)},
+		{DroneID: "drone-1", OrderID: &orderID, Location: domain.Location{Lat: 24.71, Lng: 46.61}, RecordedAt: at(1)},
+		{DroneID: "drone-2", Location: domain.Location{Lat: 25, Lng: 47}, RecordedAt: at(2)},
+		{DroneID: "drone-1", OrderID: &orderID, Location: domain.Location{Lat: 24.72, Lng: 46.62}, RecordedAt: at(3)},
+		{DroneID: "drone-1", Location: domain.Location{Lat: 24.73, Lng: 46.63}, RecordedAt: at(4)},
+	}
+	// Appended out of order: listings sort by RecordedAt.
+	commit(t, store, func(ctx context.Context, tx service.Tx) error {
+		for _, i := range []int{4, 0, 2, 3, 1} {
+			if err := tx.AppendTelemetry(ctx, samples[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	from, to := at(1), at(4)
+	cases := []struct {
+		name  string
+		query service.TelemetryQuery
+		want  []*domain.TelemetrySample
+	}{
+		{"drone", service.TelemetryQuery{DroneID: "drone-1"}, []*domain.TelemetrySample{samples[0], samples[1], samples[3], samples[4]}},
+		{"order", service.TelemetryQuery{OrderID: orderID}, []*domain.TelemetrySample{samples[1], samples[3]}},
+		{"range", service.TelemetryQuery{DroneID: "drone-1", From: &from, To: &to}, []*domain.TelemetrySample{samples[1], samples[3]}},
+		{"limit", service.TelemetryQuery{DroneID: "drone-1", Limit: 2}, []*domain.TelemetrySample{samples[0], samples[1]}},
+		{"unknown order", service.TelemetryQuery{OrderID: uuid.NewString()}, nil},
+	}
+	for _, tc := range cases {
+		got, err := store.ListTelemetry(ctx, tc.query)
+		if err != nil {
+			t.Fatalf("%s: list telemetry: %v", tc.name, err)
+		}
+		if g, w := telemetryStrings(got), telemetryStrings(tc.want); !reflect.DeepEqual(g, w) {
+			t.Fatalf("%s: telemetry mismatch\n got: %v\nwant: %v", tc.name, g, w)
+		}
+	}
+
+	if pruned, err := store.PruneTelemetry(ctx, time.Time{}); err != nil || pruned != 0 {
+		t.Fatalf("prune with zero cutoff: expected nothing deleted, got %d (err=%v)", pruned, err)
+	}
+	if pruned, err := store.PruneTelemetry(ctx, at(2)); err != nil || pruned != 2 {
+		t.Fatalf("prune: expected 2 samples deleted, got %d (err=%v)", pruned, err)
+	}
+	got, err := store.ListTelemetry(ctx, service.TelemetryQuery{DroneID: "drone-1"})
+	if err != nil {
+		t.Fatalf("list telemetry after prune: %v", err)
+	}
+	if g, w := telemetryStrings(got), telemetryStrings(samples[3:]); !reflect.DeepEqual(g, w) {
+		t.Fatalf("telemetry after prune\n got: %v\nwant: %v", g, w)
+	}
+}
+
+func telemetryStrings(samples []*domain.TelemetrySample) []string {
+	out := make([]string, 0, len(samples))
+	for _, s := range samples {
+		out = append(out, fmt.Sprintf("%s order=%s %v@%s", s.DroneID, str(s.OrderID), s.Location, ts(&s.RecordedAt)))
+	}
+	return out
+}
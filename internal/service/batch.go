@@ -0,0 +1,298 @@
+package service
+
+import (
+	"time"
+
+	"penny-assesment/internal/domain"
+)
+
+// DefaultBatchRadius is how far apart, in meters, the pickups of orders on one
+// trip may be.
+const DefaultBatchRadius = 1_000
+
+// maxBatchDetour caps how much longer than its direct flight an order's ride
+// from pickup to dropoff may get on a batched trip; the batch radius is
+// allowed on top.
+const maxBatchDetour = 1.5
+
+// SetBatching sets how many orders a drone carries on one trip and how far
+// apart their pickups may be. A capacity of 1, the default, gives each drone
+// one order at a time.
+func (s *Service) SetBatching(capacity int, radiusMeters float64) {
+	s.droneCapacity = capacity
+	s.batchRadius = radiusMeters
+}
+
+// orderStops are the stops of a trip carrying order alone.
+func orderStops(order *domain.Order) []domain.Stop {
+	return []domain.Stop{
+		{OrderID: order.ID, Kind: domain.StopPickup, Location: RouteStart(order)},
+		{OrderID: order.ID, Kind: domain.StopDropoff, Location: LegEnd(order)},
+	}
+}
+
+// tripOrders returns the IDs of the orders on drone's trip, in the order of
+// their first stop.
+func tripOrders(drone *domain.Drone) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, stop := range drone.Stops {
+		if !seen[stop.OrderID] {
+			seen[stop.OrderID] = true
+			ids = append(ids, stop.OrderID)
+		}
+	}
+	return ids
+}
+
+// trackedOrders returns the orders a heartbeat's telemetry is recorded for:
+// the one drone is flying to next, and every other order on board, picked up
+// on its trip but not yet set down.
+func trackedOrders(drone *domain.Drone) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	if drone.CurrentOrderID != nil {
+		ids = append(ids, *drone.CurrentOrderID)
+		seen[*drone.CurrentOrderID] = true
+	}
+	pickedUp := make(map[string]bool)
+	for _, stop := range drone.Stops {
+		switch {
+		case stop.Kind == domain.StopPickup && stop.CompletedAt != nil:
+			pickedUp[stop.OrderID] = true
+		case stop.Kind == domain.StopDropoff && stop.CompletedAt == nil && pickedUp[stop.OrderID] && !seen[stop.OrderID]:
+			seen[stop.OrderID] = true
+			ids = append(ids, stop.OrderID)
+		}
+	}
+	return ids
+}
+
+// heldOrders returns the orders drone has yet to set down: those with stops
+// left on its trip, or its current order if it flies without stops.
+func heldOrders(drone *domain.Drone) []string {
+	if len(drone.Stops) == 0 {
+		if drone.CurrentOrderID == nil {
+			return nil
+		}
+		return []string{*drone.CurrentOrderID}
+	}
+	var ids []string
+	seen := make(map[string]bool)
+	for _, stop := range drone.Stops {
+		if stop.CompletedAt == nil && !seen[stop.OrderID] {
+			seen[stop.OrderID] = true
+			ids = append(ids, stop.OrderID)
+		}
+	}
+	return ids
+}
+
+// canBatch reports whether drone, already holding an order, may take another
+// onto its trip: batching is on, it has spare capacity and has not made its
+// first stop yet.
+func (s *Service) canBatch(drone *domain.Drone) bool {
+	if s.droneCapacity <= 1 || len(drone.Stops) == 0 || nextStop(drone) != 0 {
+		return false
+	}
+	return len(tripOrders(drone)) < s.droneCapacity
+}
+
+// tripWith plans drone's trip with order on it. A drone without a trip gets
+// one for order alone. Otherwise order's pickup goes after the others and the
+// dropoffs follow, nearest first; ok is false when order is not compatible:
+// its pickup is further than the batch radius from the trip's first, or the
+// trip would stretch some order's ride beyond maxBatchDetour.
+func (s *Service) tripWith(drone *domain.Drone, order *domain.Order) (stops []domain.Stop, ok bool) {
+	added := orderStops(order)
+	if len(drone.Stops) == 0 {
+		return added, true
+	}
+	if domain.DistanceMeters(drone.Stops[0].Location, added[0].Location) > s.batchRadius {
+		return nil, false
+	}
+	var pickups, dropoffs []domain.Stop
+	for _, stop := range drone.Stops {
+		if stop.Kind == domain.StopPickup {
+			pickups = append(pickups, stop)
+		} else {
+			dropoffs = append(dropoffs, stop)
+		}
+	}
+	pickups = append(pickups, added[0])
+	dropoffs = append(dropoffs, added[1])
+	stops = append(pickups, nearestFirst(pickups[len(pickups)-1].Location, dropoffs)...)
+
+	var along float64
+	pickedUp := make(map[string]domain.Stop)
+	ridden := make(map[string]float64)
+	for i, stop := range stops {
+		if i > 0 {
+			along += domain.DistanceMeters(stops[i-1].Location, stop.Location)
+		}
+		if stop.Kind == domain.StopPickup {
+			pickedUp[stop.OrderID] = stop
+			ridden[stop.OrderID] = along
+			continue
+		}
+		direct := domain.DistanceMeters(pickedUp[stop.OrderID].Location, stop.Location)
+		if along-ridden[stop.OrderID] > maxBatchDetour*direct+s.batchRadius {
+			return nil, false
+		}
+	}
+	return stops, true
+}
+
+// nearestFirst orders stops by flying from from to the nearest one left each
+// time.
+func nearestFirst(from domain.Location, stops []domain.Stop) []domain.Stop {
+	left := append([]domain.Stop(nil), stops...)
+	ordered := make([]domain.Stop, 0, len(stops))
+	for len(left) > 0 {
+		best := 0
+		for i := range left {
+			if domain.DistanceMeters(from, left[i].Location) < domain.DistanceMeters(from, left[best].Location) {
+				best = i
+			}
+		}
+		ordered = append(ordered, left[best])
+		from = left[best].Location
+		left = append(left[:best], left[best+1:]...)
+	}
+	return ordered
+}
+
+// nextStop returns the index of drone's first stop not yet completed, or -1.
+func nextStop(drone *domain.Drone) int {
+	for i, stop := range drone.Stops {
+		if stop.CompletedAt == nil {
+			return i
+		}
+	}
+	return -1
+}
+
+// syncTrip points CurrentOrderID at the order of drone's next stop and ends
+// the trip once every stop is completed.
+func syncTrip(drone *domain.Drone) {
+	if next := nextStop(drone); next >= 0 {
+		id := drone.Stops[next].OrderID
+		drone.CurrentOrderID = &id
+		return
+	}
+	drone.Stops = nil
+	drone.CurrentOrderID = nil
+}
+
+// completeStop marks orderID's stop of kind on drone's trip completed at now.
+// Drones fly their stops in turn, so it must be the next one; a failed order's
+// dropoff is the exception, and is recorded where the drone gave up on it.
+// A drone without stops has nothing to check.
+func completeStop(drone *domain.Drone, orderID string, kind domain.StopKind, failed bool, now time.Time) error {
+	if len(drone.Stops) == 0 {
+		return nil
+	}
+	next := nextStop(drone)
+	at := -1
+	for i := max(next, 0); i < len(drone.Stops); i++ {
+		if drone.Stops[i].OrderID == orderID && drone.Stops[i].Kind == kind {
+			at = i
+			break
+		}
+	}
+	if at < 0 || (at != next && !failed) {
+		return domain.ErrPrecondition
+	}
+	stop := drone.Stops[at]
+	copy(drone.Stops[next+1:at+1], drone.Stops[next:at])
+	if failed && drone.LastLocation != nil {
+		stop.Location = *drone.LastLocation
+	}
+	stop.CompletedAt = &now
+	drone.Stops[next] = stop
+	return nil
+}
+
+// leaveTrip takes orderID's remaining stops off drone's trip and reports
+// whether drone was carrying it out.
+func leaveTrip(drone *domain.Drone, orderID string) bool {
+	held := drone.CurrentOrderID != nil && *drone.CurrentOrderID == orderID
+	stops := drone.Stops[:0]
+	for _, stop := range drone.Stops {
+		if stop.OrderID == orderID && stop.CompletedAt == nil {
+			held = true
+			continue
+		}
+		stops = append(stops, stop)
+	}
+	drone.Stops = stops
+	if held {
+		syncTrip(drone)
+	}
+	return held
+}
+
+// moveStops follows order's pickup and dropoff to where they are now, after
+// an admin moved the order.
+func moveStops(drone *domain.Drone, order *domain.Order) {
+	for i, stop := range drone.Stops {
+		if stop.OrderID != order.ID || stop.CompletedAt != nil {
+			continue
+		}
+		if stop.Kind == domain.StopPickup {
+			drone.Stops[i].Location = RouteStart(order)
+		} else {
+			drone.Stops[i].Location = LegEnd(order)
+		}
+	}
+}
+
+// tripFlight is the flying drone did on a batched trip since it last
+// completed an order, up to its just-completed stop for order: the time since
+// that completion, or since order was reserved, and the path through the
+// stops completed in between.
+func tripFlight(drone *domain.Drone, order *domain.Order, now time.Time) domain.FlightUsage {
+	since := order.ReservedAt
+	var path []domain.Location
+	for _, stop := range drone.Stops {
+		if stop.CompletedAt == nil {
+			break
+		}
+		path = append(path, stop.Location)
+		if stop.Kind == domain.StopDropoff && stop.OrderID != order.ID {
+			since = stop.CompletedAt
+			path = path[len(path)-1:]
+		}
+	}
+	var flight domain.FlightUsage
+	if since != nil && now.After(*since) {
+		flight.Seconds = now.Sub(*since).Seconds()
+	}
+	flight.Meters = domain.PathLengthMeters(path)
+	return flight
+}
+
+// tripAhead returns the stops drone makes before, and including, order's
+// dropoff when order rides a batched trip with others, and when the leg to
+// the first of them began; stops is nil otherwise, or when drone's location
+// is unknown.
+func tripAhead(order *domain.Order, drone *domain.Drone) (stops []domain.Stop, since *time.Time) {
+	if drone == nil || drone.LastLocation == nil || len(tripOrders(drone)) < 2 {
+		return nil, nil
+	}
+	if order.Status != domain.OrderStatusReserved && order.Status != domain.OrderStatusPickedUp {
+		return nil, nil
+	}
+	since = order.ReservedAt
+	for _, stop := range drone.Stops {
+		if stop.CompletedAt != nil {
+			since = stop.CompletedAt
+			continue
+		}
+		stops = append(stops, stop)
+		if stop.OrderID == order.ID && stop.Kind == domain.StopDropoff {
+			return stops, since
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"penny-assesment/internal/domain"
+	"penny-assesment/internal/events"
+)
+
+const (
+	maxChargingStationNameLength = 200
+	maxChargingSlots             = 1_000
+	// DefaultLowBatteryPercent is the battery level below which a heartbeat
+	// is answered with the nearest charging station with a free slot.
+	DefaultLowBatteryPercent = 20
+)
+
+// ChargingStationUpdate holds the fields of an admin charging station update;
+// nil fields are left unchanged.
+type ChargingStationUpdate struct {
+	Name     *string
+	Location *domain.Location
+	Slots    *int
+}
+
+// ChargingStationView is a charging station with how many of its slots are
+// held. Slots is only filled in for a single station.
+type ChargingStationView struct {
+	Station  *domain.ChargingStation
+	Occupied int
+	Slots    []*domain.ChargingSlot
+}
+
+// ChargingRecommendation points a drone low on battery at the nearest station
+// with a free slot.
+type ChargingRecommendation struct {
+	StationID      string
+	Name           string
+	Location       domain.Location
+	DistanceMeters float64
+	FreeSlots      int
+}
+
+// SetLowBattery sets the battery percentage below which heartbeats get a
+// charging recommendation; zero turns recommendations off.
+func (s *Service) SetLowBattery(percent float64) {
+	s.lowBattery = percent
+}
+
+func (s *Service) AdminListChargingStations(ctx context.Context) ([]*ChargingStationView, error) {
+	stations, err := s.store.ListChargingStations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	occupied, err := s.store.CountOccupiedSlots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]*ChargingStationView, 0, len(stations))
+	for _, station := range stations {
+		views = append(views, &ChargingStationView{Station: station, Occupied: occupied[station.ID]})
+	}
+	return views, nil
+}
+
+func (s *Service) AdminGetChargingStation(ctx context.Context, id string) (*ChargingStationView, error) {
+	station, err := s.store.GetChargingStation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.chargingStationView(ctx, station)
+}
+
+func (s *Service) AdminCreateChargingStation(ctx context.Context, name string, location domain.Location, slots int) (*ChargingStationView, error) {
+	name = strings.TrimSpace(name)
+	if err := validateChargingStation(name, location, slots); err != nil {
+		return nil, err
+	}
+	tx, err := s.store.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	now := s.now()
+	station := &domain.ChargingStation{
+		ID:        uuidFunc(),
+		Name:      name,
+		Location:  location,
+		Slots:     slots,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := tx.CreateChargingStation(ctx, station); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return s.chargingStationView(ctx, station)
+}
+
+// AdminUpdateChargingStation changes a charging station. Removing a slot a
+// drone holds is domain.ErrConflict.
+func (s *Service) AdminUpdateChargingStation(ctx context.Context, id string, update ChargingStationUpdate, expectedVersion int64) (*ChargingStationView, error) {
+	tx, err := s.store.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	station, err := tx.GetChargingStationForUpdate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(expectedVersion, station.Version); err != nil {
+		return nil, err
+	}
+	if update.Name != nil {
+		station.Name = strings.TrimSpace(*update.Name)
+	}
+	if update.Location != nil {
+		station.Location = *update.Location
+	}
+	if update.Slots != nil {
+		station.Slots = *update.Slots
+	}
+	if err := validateChargingStation(station.Name, station.Location, station.Slots); err != nil {
+		return nil, err
+	}
+	if update.Slots != nil {
+		slots, err := s.store.ListChargingSlots(ctx, station.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, slot := range slots {
+			if slot.Number > station.Slots && !slot.Free() {
+				return nil, fmt.Errorf("slots: slot %d is held by drone %s: %w", slot.Number, *slot.DroneID, domain.ErrConflict)
+			}
+		}
+	}
+	station.UpdatedAt = s.now()
+	if err := tx.UpdateChargingStation(ctx, station); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return s.chargingStationView(ctx, station)
+}
+
+// DroneReserveChargingSlot gives the drone a free slot at the station. A
+// drone already holding a slot there gets it back; one holding a slot
+// elsewhere, or a full station, is domain.ErrConflict. The drone is locked
+// first so that its own reservations are made one at a time, and slots are
+// taken skip-locked like orders in DroneReserveJob.
+func (s *Service) DroneReserveChargingSlot(ctx context.Context, droneID, stationID string) (*domain.ChargingSlot, error) {
+	tx, err := s.store.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.GetDroneForUpdate(ctx, droneID); err != nil {
+		return nil, err
+	}
+	if _, err := s.store.GetChargingStation(ctx, stationID); err != nil {
+		return nil, err
+	}
+	held, err := tx.GetDroneChargingSlotForUpdate(ctx, droneID)
+	switch {
+	case err == nil && held.StationID == stationID:
+		return held, nil
+	case err == nil:
+		return nil, fmt.Errorf("drone holds a slot at station %s: %w", held.StationID, domain.ErrConflict)
+	case !errors.Is(err, domain.ErrNotFound):
+		return nil, err
+	}
+	slot, err := tx.ReserveFreeChargingSlot(ctx, stationID)
+	if err != nil {
+		return nil, err
+	}
+	if slot == nil {
+		return nil, fmt.Errorf("no free slot: %w", domain.ErrConflict)
+	}
+	now := s.now()
+	slot.DroneID = &droneID
+	slot.ReservedAt = &now
+	if err := tx.UpdateChargingSlot(ctx, slot); err != nil {
+		return nil, err
+	}
+	if err := tx.EnqueueEvent(ctx, events.NewChargingSlotEvent(events.EventChargingSlotReserved, slot, droneID, now)); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return slot, nil
+}
+
+// DroneReleaseChargingSlot frees the slot the drone holds, which is
+// domain.ErrNotFound if it holds none.
+func (s *Service) DroneReleaseChargingSlot(ctx context.Context, droneID string) (*domain.ChargingSlot, error) {
+	tx, err := s.store.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.GetDroneForUpdate(ctx, droneID); err != nil {
+		return nil, err
+	}
+	slot, err := tx.GetDroneChargingSlotForUpdate(ctx, droneID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	slot.DroneID = nil
+	slot.ReservedAt = nil
+	if err := tx.UpdateChargingSlot(ctx, slot); err != nil {
+		return nil, err
+	}
+	if err := tx.EnqueueEvent(ctx, events.NewChargingSlotEvent(events.EventChargingSlotReleased, slot, droneID, now)); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return slot, nil
+}
+
+func (s *Service) chargingStationView(ctx context.Context, station *domain.ChargingStation) (*ChargingStationView, error) {
+	slots, err := s.store.ListChargingSlots(ctx, station.ID)
+	if err != nil {
+		return nil, err
+	}
+	view := &ChargingStationView{Station: station, Slots: slots}
+	for _, slot := range slots {
+		if !slot.Free() {
+			view.Occupied++
+		}
+	}
+	return view, nil
+}
+
+func validateChargingStation(name string, location domain.Location, slots int) error {
+	if name == "" || len(name) > maxChargingStationNameLength {
+		return fmt.Errorf("name: %w", domain.ErrInvalid)
+	}
+	if err := domain.ValidateLocation(location); err != nil {
+		return fmt.Errorf("location: %w", domain.ErrInvalid)
+	}
+	if slots <= 0 || slots > maxChargingSlots {
+		return fmt.Errorf("slots: %w", domain.ErrInvalid)
+	}
+	return nil
+}
+
+// chargeAt recommends where a drone reporting low battery should charge, or
+// returns nil: its battery is unknown or not low, it already holds a slot, it
+// has no location, or every station is full.
+func (s *Service) chargeAt(ctx context.Context, drone *domain.Drone) (*ChargingRecommendation, error) {
+	battery := drone.Vitals.BatteryPercent
+	if s.lowBattery <= 0 || battery == nil || *battery >= s.lowBattery || drone.LastLocation == nil {
+		return nil, nil
+	}
+	if _, err := s.store.GetDroneChargingSlot(ctx, drone.ID); err == nil {
+		return nil, nil
+	} else if !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	stations, err := s.store.ListChargingStations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	occupied, err := s.store.CountOccupiedSlots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var best *ChargingRecommendation
+	for _, station := range stations {
+		free := station.Slots - occupied[station.ID]
+		if free <= 0 {
+			continue
+		}
+		d := domain.DistanceMeters(*drone.LastLocation, station.Location)
+		if best == nil || d < best.DistanceMeters || (d == best.DistanceMeters && station.ID < best.StationID) {
+			best = &ChargingRecommendation{
+				StationID:      station.ID,
+				Name:           station.Name,
+				Location:       station.Location,
+				DistanceMeters: d,
+				FreeSlots:      free,
+			}
+		}
+	}
+	return best, nil
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"penny-assesment/internal/domain"
+)
+
+const (
+	defaultListLimit = 100
+	maxListLimit     = 500
+)
+
+// OrderCursor is the keyset position of the last order on a page: the value
+// of the sort column and the order ID. The next page starts strictly after it.
+type OrderCursor struct {
+	Key time.Time
+	ID  string
+}
+
+type cursorPayload struct {
+	Sort OrderSort `json:"s"`
+	Key  time.Time `json:"k"`
+	ID   string    `json:"id"`
+}
+
+// OrderPage is one page of an order listing. NextCursor is empty on the last page.
+type OrderPage struct {
+	Orders     []*OrderView
+	NextCursor string
+}
+
+func encodeOrderCursor(sort OrderSort, order *domain.Order) string {
+	data, _ := json.Marshal(cursorPayload{Sort: sort, Key: sort.Key(order), ID: order.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeOrderCursor rejects cursors issued for a different sort, since their
+// key would be compared against the wrong column.
+func decodeOrderCursor(sort OrderSort, cursor string) (*OrderCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("cursor: %w", domain.ErrInvalid)
+	}
+	var payload cursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil || payload.ID == "" || payload.Key.IsZero() || payload.Sort != sort {
+		return nil, fmt.Errorf("cursor: %w", domain.ErrInvalid)
+	}
+	return &OrderCursor{Key: payload.Key, ID: payload.ID}, nil
+}
+
+func normalizeListLimit(limit int) int {
+	if limit <= 0 {
+		return defaultListLimit
+	}
+	if limit > maxListLimit {
+		return maxListLimit
+	}
+	return limit
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"penny-assesment/internal/domain"
+)
+
+const (
+	maxDepotNameLength = 200
+	maxDepotCapacity   = 10_000
+	// depotArrivalMeters is how close to its depot a drone has to be to count
+	// as back at base.
+	depotArrivalMeters = 50
+)
+
+// DepotUpdate holds the fields of an admin depot update; nil fields are left
+// unchanged.
+type DepotUpdate struct {
+	Name     *string
+	Location *domain.Location
+	Capacity *int
+}
+
+// ReturnToBase tells an idle drone away from its home depot to fly back, by
+// Route, with the distance and time that flight takes.
+type ReturnToBase struct {
+	DepotID        string
+	Location       domain.Location
+	Route          []domain.Location
+	DistanceMeters float64
+	ETASeconds     int64
+}
+
+func (s *Service) AdminListDepots(ctx context.Context) ([]*domain.Depot, error) {
+	return s.store.ListDepots(ctx)
+}
+
+func (s *Service) AdminGetDepot(ctx context.Context, id string) (*domain.Depot, error) {
+	return s.store.GetDepot(ctx, id)
+}
+
+func (s *Service) AdminCreateDepot(ctx context.Context, name string, location domain.Location, capacity int) (*domain.Depot, error) {
+	name = strings.TrimSpace(name)
+	if err := validateDepot(name, location, capacity); err != nil {
+		return nil, err
+	}
+	tx, err := s.store.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	now := s.now()
+	depot := &domain.Depot{
+		ID:        uuidFunc(),
+		Name:      name,
+		Location:  location,
+		Capacity:  capacity,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := tx.CreateDepot(ctx, depot); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return depot, nil
+}
+
+// AdminUpdateDepot changes a depot. Lowering its capacity below the number of
+// drones homed there is domain.ErrConflict.
+func (s *Service) AdminUpdateDepot(ctx context.Context, id string, update DepotUpdate, expectedVersion int64) (*domain.Depot, error) {
+	tx, err := s.store.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	depot, err := tx.GetDepotForUpdate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(expectedVersion, depot.Version); err != nil {
+		return nil, err
+	}
+	if update.Name != nil {
+		depot.Name = strings.TrimSpace(*update.Name)
+	}
+	if update.Location != nil {
+		depot.Location = *update.Location
+	}
+	if update.Capacity != nil {
+		depot.Capacity = *update.Capacity
+	}
+	if err := validateDepot(depot.Name, depot.Location, depot.Capacity); err != nil {
+		return nil, err
+	}
+	if update.Capacity != nil {
+		homed, err := s.store.ListDepotDrones(ctx, depot.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(homed) > depot.Capacity {
+			return nil, fmt.Errorf("capacity: %d drones are homed at the depot: %w", len(homed), domain.ErrConflict)
+		}
+	}
+	depot.UpdatedAt = s.now()
+	if err := tx.UpdateDepot(ctx, depot); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return depot, nil
+}
+
+// AdminSetDroneDepot homes a drone at a depot, or clears its home depot when
+// depotID is nil. A depot already at capacity is domain.ErrConflict. The
+// depot is locked after the drone so that concurrent assignments to it are
+// counted one at a time.
+func (s *Service) AdminSetDroneDepot(ctx context.Context, droneID string, depotID *string, expectedVersion int64) (*domain.Drone, error) {
+	tx, err := s.store.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	drone, err := tx.GetDroneForUpdate(ctx, droneID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(expectedVersion, drone.Version); err != nil {
+		return nil, err
+	}
+	if depotID != nil {
+		depot, err := tx.GetDepotForUpdate(ctx, *depotID)
+		if err != nil {
+			return nil, err
+		}
+		homed, err := s.store.ListDepotDrones(ctx, depot.ID)
+		if err != nil {
+			return nil, err
+		}
+		others := 0
+		for _, d := range homed {
+			if d.ID != drone.ID {
+				others++
+			}
+		}
+		if others >= depot.Capacity {
+			return nil, fmt.Errorf("depot is at capacity: %w", domain.ErrConflict)
+		}
+		id := depot.ID
+		depotID = &id
+	}
+	drone.HomeDepotID = depotID
+	drone.UpdatedAt = s.now()
+	if err := tx.UpdateDrone(ctx, drone); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return drone, nil
+}
+
+func validateDepot(name string, location domain.Location, capacity int) error {
+	if name == "" || len(name) > maxDepotNameLength {
+		return fmt.Errorf("name: %w", domain.ErrInvalid)
+	}
+	if err := domain.ValidateLocation(location); err != nil {
+		return fmt.Errorf("location: %w", domain.ErrInvalid)
+	}
+	if capacity <= 0 || capacity > maxDepotCapacity {
+		return fmt.Errorf("capacity: %w", domain.ErrInvalid)
+	}
+	return nil
+}
+
+// NearestDepot returns the depot closest to point (ties by ID), or nil if
+// there are none.
+func NearestDepot(depots []*domain.Depot, point domain.Location) *domain.Depot {
+	var nearest *domain.Depot
+	var best float64
+	for _, depot := range depots {
+		d := domain.DistanceMeters(point, depot.Location)
+		if nearest == nil || d < best || (d == best && depot.ID < nearest.ID) {
+			nearest, best = depot, d
+		}
+	}
+	return nearest
+}
+
+// returnToBase is the instruction for an idle drone away from its home depot
+// to fly back, or nil if it has nothing to do there: it is busy, out of
+// service, has no home depot or location, or is already at the depot. The
+// flight is routed around the no-fly zones in force and timed at the drone's
+// observed speed toward the depot when that can be trusted.
+func (s *Service) returnToBase(ctx context.Context, drone *domain.Drone) (*ReturnToBase, error) {
+	if drone.CurrentOrderID != nil || !drone.Status.Assignable() || drone.HomeDepotID == nil || drone.LastLocation == nil {
+		return nil, nil
+	}
+	depot, err := s.store.GetDepot(ctx, *drone.HomeDepotID)
+	if err != nil {
+		return nil, err
+	}
+	from := *drone.LastLocation
+	if domain.DistanceMeters(from, depot.Location) <= depotArrivalMeters {
+		return nil, nil
+	}
+	zones, err := s.store.ListNoFlyZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	route := PlanRoute(zones, from, depot.Location, s.now())
+	if route == nil {
+		// A zone blocks every way back; the drone holds position until it
+		// lifts, but is still shown the direct line.
+		route = []domain.Location{from, depot.Location}
+	}
+	rtb := &ReturnToBase{
+		DepotID:        depot.ID,
+		Location:       depot.Location,
+		Route:          route,
+		DistanceMeters: domain.PathLengthMeters(route),
+	}
+	mps := s.eta.SpeedMPS
+	if observed := ObserveSpeed(drone.RecentFixes, depot.Location, nil); observed != nil && observed.Confidence >= nominalConfidence {
+		mps = observed.MPS
+	}
+	if mps > 0 {
+		rtb.ETASeconds = int64(rtb.DistanceMeters / mps)
+	}
+	return rtb, nil
+}
+
+// depotPreference decides, during dispatch, which orders to leave for drones
+// homed at the depot nearest their pickup. It looks the depots and their
+// drones up at most once per reservation.
+type depotPreference struct {
+	store  Store
+	drone  *domain.Drone
+	depots []*domain.Depot
+	idle   map[string]bool
+}
+
+func (s *Service) newDepotPreference(ctx context.Context, drone *domain.Drone) (*depotPreference, error) {
+	depots, err := s.store.ListDepots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &depotPreference{store: s.store, drone: drone, depots: depots, idle: map[string]bool{}}, nil
+}
+
+// deferOrder reports whether order should go to another drone: the depot nearest
+// its pickup is not this drone's home and has an idle drone of its own.
+func (p *depotPreference) deferOrder(ctx context.Context, order *domain.Order) (bool, error) {
+	depot := NearestDepot(p.depots, RouteStart(order))
+	if depot == nil || (p.drone.HomeDepotID != nil && *p.drone.HomeDepotID == depot.ID) {
+		return false, nil
+	}
+	idle, ok := p.idle[depot.ID]
+	if !ok {
+		homed, err := p.store.ListDepotDrones(ctx, depot.ID)
+		if err != nil {
+			return false, err
+		}
+		for _, d := range homed {
+			if d.ID != p.drone.ID && IsIdle(d) {
+				idle = true
+				break
+			}
+		}
+		p.idle[depot.ID] = idle
+	}
+	return idle, nil
+}
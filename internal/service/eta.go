@@ -1,7 +1,7 @@
 package service
 
 import (
-	"math"
+	"time"
 
 	"penny-assesment/internal/domain"
 )
@@ -10,11 +10,86 @@ type OrderView struct {
 	Order           *domain.Order
 	CurrentLocation *domain.Location
 	ETASeconds      *int64
+	// ETALegs breaks ETASeconds down by stage, in the order they happen.
+	ETALegs []ETALeg
+	// ETASource and ETAConfidence are set with ETASeconds.
+	ETASource     ETASource
+	ETAConfidence float64
+}
+
+// ETASource says which speed an ETA's current flight leg was estimated at.
+type ETASource string
+
+const (
+	// ETASourceObserved is the drone's speed measured from its heartbeats.
+	ETASourceObserved ETASource = "observed"
+	// ETASourceNominal is the configured fleet speed.
+	ETASourceNominal ETASource = "nominal"
+)
+
+// ETALegKind names a stage of an order's remaining journey.
+type ETALegKind string
+
+const (
+	// ETALegQueue is the wait for a drone to reserve the order.
+	ETALegQueue ETALegKind = "queue"
+	// ETALegToPickup is the assigned drone's flight to the pickup point.
+	ETALegToPickup ETALegKind = "to_pickup"
+	// ETALegPickup is the dwell while the package is loaded.
+	ETALegPickup ETALegKind = "pickup"
+	// ETALegDelivery is the flight with the package to the destination, or
+	// to the end of a relayed order's current leg.
+	ETALegDelivery ETALegKind = "delivery"
+	// ETALegDropoff is the dwell while the package is unloaded.
+	ETALegDropoff ETALegKind = "dropoff"
+	// ETALegRelay is the flight of a relay leg after the current one, by the
+	// next drone.
+	ETALegRelay ETALegKind = "relay"
+	// ETALegStop is the flight to a stop the drone makes for another order
+	// on its batched trip, and the dwell there.
+	ETALegStop ETALegKind = "stop"
+)
+
+// ETALeg is one stage of an ETA. Dwell and queue legs have no distance.
+type ETALeg struct {
+	Kind           ETALegKind
+	DistanceMeters float64
+	Seconds        int64
+}
+
+// ETAModel holds the fleet-wide figures ETAs are computed from.
+type ETAModel struct {
+	SpeedMPS     float64
+	PickupDwell  time.Duration
+	DropoffDwell time.Duration
+}
+
+// QueueState is an unassigned order's place in the dispatch queue.
+type QueueState struct {
+	// Ahead counts the waiting orders that will be reserved before it.
+	Ahead int
+	// ActiveDrones counts the drones that can take jobs.
+	ActiveDrones int
+	// UntilPickup is how long until a scheduled order's pickup window opens.
+	UntilPickup time.Duration
+}
+
+// ETA is an estimate of the time left until delivery and its legs.
+type ETA struct {
+	Seconds    int64
+	Legs       []ETALeg
+	Source     ETASource
+	Confidence float64
 }
 
 type DroneStatusView struct {
 	Drone        *domain.Drone
 	CurrentOrder *OrderView
+	// ReturnToBase is set when the drone is idle away from its home depot.
+	ReturnToBase *ReturnToBase
+	// ChargeAt is set when the drone reports low battery and holds no
+	// charging slot.
+	ChargeAt *ChargingRecommendation
 }
 
 func CurrentLocation(order *domain.Order, drone *domain.Drone) *domain.Location {
@@ -44,58 +119,140 @@ func CurrentLocation(order *domain.Order, drone *domain.Drone) *domain.Location
 	return nil
 }
 
-func ComputeETA(order *domain.Order, drone *domain.Drone, speedMPS float64) *int64 {
+// ComputeETA estimates the time until order is delivered, leg by leg: the
+// queue wait (unassigned orders, when queue is known), the assigned drone's
+// flight to the pickup point, the pickup dwell, the delivery flight and the
+// dropoff dwell. Legs already behind the order are left out. Once an order is
+// reserved with a planned route, the delivery leg follows that route rather
+// than the straight line. A relayed order's current leg ends at its relay
+// point; each leg after it adds a pickup dwell, a relay flight and a dropoff
+// dwell, with no wait between drones. An order on a batched trip is timed
+// along the drone's stops in turn, each stop for another order adding a stop
+// leg, and straight between stops.
+//
+// The leg the assigned drone is flying (to the pickup point, or with the
+// package to the destination) is timed at the drone's observed speed over its
+// fixes since the leg began, when there are enough of them and they are more
+// trustworthy than the configured speed; every other leg uses model.SpeedMPS.
+// Source and Confidence describe that choice.
+//
+// The queue wait assumes the active drones share the orders ahead evenly and
+// each takes as long as this one, and lasts at least until a scheduled order's
+// pickup window opens. It returns nil for terminal orders, when the
+// speed is unknown, when a picked-up order's drone has no location, and when
+// an unassigned order has no active drone to wait for.
+func ComputeETA(order *domain.Order, drone *domain.Drone, model ETAModel, queue *QueueState) *ETA {
 	if domain.IsTerminal(order.Status) {
 		return nil
 	}
-	if speedMPS <= 0 {
+	if model.SpeedMPS <= 0 {
 		return nil
 	}
-	var from domain.Location
-	switch order.Status {
-	case domain.OrderStatusCreated:
-		from = order.Origin
-	case domain.OrderStatusReserved:
-		if order.HandoffOrigin != nil {
-			from = *order.HandoffOrigin
-		} else {
-			from = order.Origin
+	flightAt := func(kind ETALegKind, meters, mps float64) ETALeg {
+		seconds := int64(meters / mps)
+		if seconds < 0 {
+			seconds = 0
 		}
-	case domain.OrderStatusPickedUp:
-		if drone == nil || drone.LastLocation == nil {
+		return ETALeg{Kind: kind, DistanceMeters: meters, Seconds: seconds}
+	}
+	flight := func(kind ETALegKind, meters float64) ETALeg {
+		return flightAt(kind, meters, model.SpeedMPS)
+	}
+	source, confidence := ETASourceNominal, nominalConfidence
+	// current times the leg the drone is flying, at its observed speed toward
+	// target if that can be trusted.
+	current := func(kind ETALegKind, meters float64, target domain.Location, since *time.Time) ETALeg {
+		observed := ObserveSpeed(drone.RecentFixes, target, since)
+		if observed == nil || observed.Confidence < nominalConfidence {
+			return flight(kind, meters)
+		}
+		source, confidence = ETASourceObserved, observed.Confidence
+		return flightAt(kind, meters, observed.MPS)
+	}
+	pickup := ETALeg{Kind: ETALegPickup, Seconds: int64(model.PickupDwell / time.Second)}
+	dropoff := ETALeg{Kind: ETALegDropoff, Seconds: int64(model.DropoffDwell / time.Second)}
+
+	var legs []ETALeg
+	stops, since := tripAhead(order, drone)
+	switch {
+	case stops != nil:
+		from := *drone.LastLocation
+		for i, stop := range stops {
+			meters := domain.DistanceMeters(from, stop.Location)
+			from = stop.Location
+			kind, dwell := ETALegStop, pickup
+			switch {
+			case stop.OrderID == order.ID && stop.Kind == domain.StopPickup:
+				kind = ETALegToPickup
+			case stop.OrderID == order.ID:
+				kind, dwell = ETALegDelivery, dropoff
+			case stop.Kind == domain.StopDropoff:
+				dwell = dropoff
+			}
+			leg := flight(kind, meters)
+			if i == 0 {
+				leg = current(kind, meters, stop.Location, since)
+			}
+			if kind == ETALegStop {
+				leg.Seconds += dwell.Seconds
+				legs = append(legs, leg)
+				continue
+			}
+			legs = append(legs, leg, dwell)
+		}
+	case order.Status == domain.OrderStatusCreated, order.Status == domain.OrderStatusHandoffRequested:
+		if order.Status == domain.OrderStatusHandoffRequested && order.HandoffOrigin == nil {
 			return nil
 		}
-		from = *drone.LastLocation
-	case domain.OrderStatusHandoffRequested:
-		if order.HandoffOrigin == nil {
+		delivery := flight(ETALegDelivery, domain.DistanceMeters(RouteStart(order), LegEnd(order)))
+		if queue != nil {
+			if queue.ActiveDrones <= 0 {
+				return nil
+			}
+			job := pickup.Seconds + delivery.Seconds + dropoff.Seconds
+			rounds := int64(queue.Ahead / queue.ActiveDrones)
+			wait := max(rounds*job, int64(queue.UntilPickup/time.Second))
+			legs = append(legs, ETALeg{Kind: ETALegQueue, Seconds: wait})
+		}
+		legs = append(legs, pickup, delivery, dropoff)
+	case order.Status == domain.OrderStatusReserved:
+		start := RouteStart(order)
+		if drone != nil && drone.LastLocation != nil {
+			legs = append(legs, current(ETALegToPickup, domain.DistanceMeters(*drone.LastLocation, start), start, order.ReservedAt))
+		}
+		meters := domain.DistanceMeters(start, LegEnd(order))
+		if len(order.Route) >= 2 {
+			meters = domain.PathLengthMeters(order.Route)
+		}
+		legs = append(legs, pickup, flight(ETALegDelivery, meters), dropoff)
+	case order.Status == domain.OrderStatusPickedUp:
+		if drone == nil || drone.LastLocation == nil {
 			return nil
 		}
-		from = *order.HandoffOrigin
+		end := LegEnd(order)
+		meters := domain.DistanceMeters(*drone.LastLocation, end)
+		if len(order.Route) >= 2 {
+			meters = RemainingRouteMeters(order.Route, *drone.LastLocation)
+		}
+		legs = append(legs, current(ETALegDelivery, meters, end, order.PickedUpAt), dropoff)
 	default:
 		return nil
 	}
-	dist := haversineMeters(from, order.Destination)
-	seconds := int64(dist / speedMPS)
-	if seconds < 0 {
-		seconds = 0
+	if cur := order.CurrentLeg(); cur >= 0 {
+		for i := cur + 1; i < len(order.Legs); i++ {
+			meters := domain.DistanceMeters(order.Legs[i-1].To, order.Legs[i].To)
+			legs = append(legs, pickup, flight(ETALegRelay, meters), dropoff)
+		}
 	}
-	return &seconds
-}
-
-func haversineMeters(a, b domain.Location) float64 {
-	const earthRadius = 6371000.0
-	lat1 := degreesToRadians(a.Lat)
-	lat2 := degreesToRadians(b.Lat)
-	dLat := degreesToRadians(b.Lat - a.Lat)
-	dLng := degreesToRadians(b.Lng - a.Lng)
 
-	sinLat := math.Sin(dLat / 2)
-	sinLng := math.Sin(dLng / 2)
-
-	h := sinLat*sinLat + math.Cos(lat1)*math.Cos(lat2)*sinLng*sinLng
-	return 2 * earthRadius * math.Asin(math.Sqrt(h))
-}
-
-func degreesToRadians(deg float64) float64 {
-	return deg * math.Pi / 180
+	eta := &ETA{Legs: make([]ETALeg, 0, len(legs)), Source: source, Confidence: confidence}
+	for _, leg := range legs {
+		// Dwells are left out when not configured.
+		if leg.Seconds == 0 && (leg.Kind == ETALegPickup || leg.Kind == ETALegDropoff) {
+			continue
+		}
+		eta.Seconds += leg.Seconds
+		eta.Legs = append(eta.Legs, leg)
+	}
+	return eta
 }
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"penny-assesment/internal/domain"
+)
+
+// OrderSort names the column an order listing is ordered by. A leading "-"
+// sorts descending. Ties are always broken by order ID in the same direction.
+type OrderSort string
+
+const (
+	OrderSortCreatedAsc  OrderSort = "created_at"
+	OrderSortCreatedDesc OrderSort = "-created_at"
+	OrderSortUpdatedAsc  OrderSort = "updated_at"
+	OrderSortUpdatedDesc OrderSort = "-updated_at"
+)
+
+func (s OrderSort) Valid() bool {
+	switch s {
+	case OrderSortCreatedAsc, OrderSortCreatedDesc, OrderSortUpdatedAsc, OrderSortUpdatedDesc:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s OrderSort) Descending() bool {
+	return strings.HasPrefix(string(s), "-")
+}
+
+// Column is the sort column without direction, e.g. "updated_at".
+func (s OrderSort) Column() string {
+	return strings.TrimPrefix(string(s), "-")
+}
+
+// Key returns the value of the sort column for order.
+func (s OrderSort) Key(order *domain.Order) time.Time {
+	if s.Column() == "updated_at" {
+		return order.UpdatedAt
+	}
+	return order.CreatedAt
+}
+
+// OrderFilter selects orders for a listing. Transports set Cursor to the
+// opaque value from a previous page; the service decodes it into After before
+// calling the store. Stores must return orders in Sort order, ties broken by ID.
+// Offset is carried only so that clients still sending the pre-cursor offset
+// parameter get an error instead of a silently different page.
+type OrderFilter struct {
+	Statuses       []domain.OrderStatus
+	UserID         *string
+	DroneID        *string
+	CreatedFrom    *time.Time
+	CreatedTo      *time.Time
+	UpdatedFrom    *time.Time
+	UpdatedTo      *time.Time
+	FailureReason  string
+	OriginBox      *domain.BoundingBox
+	DestinationBox *domain.BoundingBox
+	Sort           OrderSort
+	Limit          int
+	Cursor         string
+	After          *OrderCursor
+	Offset         int
+}
+
+func (f *OrderFilter) validate() error {
+	if f.Sort == "" {
+		f.Sort = OrderSortCreatedAsc
+	}
+	if !f.Sort.Valid() {
+		return fmt.Errorf("sort: %w", domain.ErrInvalid)
+	}
+	if f.Offset != 0 {
+		return fmt.Errorf("offset: %w", domain.ErrInvalid)
+	}
+	for _, status := range f.Statuses {
+		if !domain.ValidateOrderStatus(status) {
+			return fmt.Errorf("status: %w", domain.ErrInvalid)
+		}
+	}
+	if f.CreatedFrom != nil && f.CreatedTo != nil && !f.CreatedFrom.Before(*f.CreatedTo) {
+		return fmt.Errorf("created range: %w", domain.ErrInvalid)
+	}
+	if f.UpdatedFrom != nil && f.UpdatedTo != nil && !f.UpdatedFrom.Before(*f.UpdatedTo) {
+		return fmt.Errorf("updated range: %w", domain.ErrInvalid)
+	}
+	if f.OriginBox != nil {
+		if err := domain.ValidateBoundingBox(*f.OriginBox); err != nil {
+			return fmt.Errorf("origin box: %w", domain.ErrInvalid)
+		}
+	}
+	if f.DestinationBox != nil {
+		if err := domain.ValidateBoundingBox(*f.DestinationBox); err != nil {
+			return fmt.Errorf("destination box: %w", domain.ErrInvalid)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether order satisfies every predicate of the filter,
+// including the keyset position in After. It is the reference semantics for
+// stores that filter in Go rather than in SQL.
+func (f OrderFilter) Matches(order *domain.Order) bool {
+	if len(f.Statuses) > 0 && !containsStatus(f.Statuses, order.Status) {
+		return false
+	}
+	if f.UserID != nil && order.UserID != *f.UserID {
+		return false
+	}
+	if f.DroneID != nil && (order.AssignedDroneID == nil || *order.AssignedDroneID != *f.DroneID) {
+		return false
+	}
+	if !inRange(order.CreatedAt, f.CreatedFrom, f.CreatedTo) || !inRange(order.UpdatedAt, f.UpdatedFrom, f.UpdatedTo) {
+		return false
+	}
+	if f.FailureReason != "" {
+		if order.FailureReason == nil || !strings.Contains(strings.ToLower(*order.FailureReason), strings.ToLower(f.FailureReason)) {
+			return false
+		}
+	}
+	if f.OriginBox != nil && !f.OriginBox.Contains(order.Origin) {
+		return false
+	}
+	if f.DestinationBox != nil && !f.DestinationBox.Contains(order.Destination) {
+		return false
+	}
+	if f.After != nil && !f.Less(f.After, order) {
+		return false
+	}
+	return true
+}
+
+// Less reports whether the cursor position sorts strictly before order.
+func (f OrderFilter) Less(cursor *OrderCursor, order *domain.Order) bool {
+	key := f.Sort.Key(order)
+	if key.Equal(cursor.Key) {
+		if f.Sort.Descending() {
+			return order.ID < cursor.ID
+		}
+		return order.ID > cursor.ID
+	}
+	if f.Sort.Descending() {
+		return key.Before(cursor.Key)
+	}
+	return key.After(cursor.Key)
+}
+
+func containsStatus(statuses []domain.OrderStatus, status domain.OrderStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func inRange(t time.Time, from, to *time.Time) bool {
+	if from != nil && t.Before(*from) {
+		return false
+	}
+	if to != nil && !t.Before(*to) {
+		return false
+	}
+	return true
+}
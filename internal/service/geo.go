@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"penny-assesment/internal/domain"
+)
+
+// MaxRadiusMeters bounds radius searches so that the haversine fallback's
+// bounding-box prefilter stays selective.
+const MaxRadiusMeters = 500_000
+
+// RadiusQuery selects orders whose origin lies within RadiusMeters of Center,
+// nearest first. An empty Statuses matches every status.
+type RadiusQuery struct {
+	Center       domain.Location
+	RadiusMeters float64
+	Statuses     []domain.OrderStatus
+	Limit        int
+}
+
+func (q *RadiusQuery) validate() error {
+	if err := domain.ValidateLocation(q.Center); err != nil {
+		return fmt.Errorf("center: %w", domain.ErrInvalid)
+	}
+	if q.RadiusMeters <= 0 || q.RadiusMeters > MaxRadiusMeters {
+		return fmt.Errorf("radius: %w", domain.ErrInvalid)
+	}
+	for _, status := range q.Statuses {
+		if !domain.ValidateOrderStatus(status) {
+			return fmt.Errorf("status: %w", domain.ErrInvalid)
+		}
+	}
+	q.Limit = normalizeListLimit(q.Limit)
+	return nil
+}
+
+// Matches reports whether order is in one of the query's statuses. The
+// distance check is left to the caller.
+func (q RadiusQuery) Matches(order *domain.Order) bool {
+	if len(q.Statuses) == 0 {
+		return true
+	}
+	for _, status := range q.Statuses {
+		if order.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterOrdersWithinRadius is the haversine implementation of
+// Store.OrdersWithinRadius for stores without a spatial index: it keeps the
+// candidates query matches, nearest origin first (ties by ID), up to
+// query.Limit.
+func FilterOrdersWithinRadius(candidates []*domain.Order, query RadiusQuery) []*domain.Order {
+	type hit struct {
+		order    *domain.Order
+		distance float64
+	}
+	var hits []hit
+	for _, order := range candidates {
+		if !query.Matches(order) {
+			continue
+		}
+		if d := domain.DistanceMeters(query.Center, order.Origin); d <= query.RadiusMeters {
+			hits = append(hits, hit{order: order, distance: d})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].distance != hits[j].distance {
+			return hits[i].distance < hits[j].distance
+		}
+		return hits[i].order.ID < hits[j].order.ID
+	})
+	if query.Limit > 0 && len(hits) > query.Limit {
+		hits = hits[:query.Limit]
+	}
+	orders := make([]*domain.Order, 0, len(hits))
+	for _, h := range hits {
+		orders = append(orders, h.order)
+	}
+	return orders
+}
+
+// IsIdle reports whether drone can take a job and has a known location.
+func IsIdle(drone *domain.Drone) bool {
+	return drone.Status.Assignable() && drone.CurrentOrderID == nil && drone.LastLocation != nil
+}
+
+// NearestDrones is the haversine implementation of Store.NearestIdleDrones:
+// it orders the idle candidates by distance from point (ties by ID) and keeps
+// the first limit.
+func NearestDrones(candidates []*domain.Drone, point domain.Location, limit int) []*domain.Drone {
+	var drones []*domain.Drone
+	for _, drone := range candidates {
+		if IsIdle(drone) {
+			drones = append(drones, drone)
+		}
+	}
+	sort.Slice(drones, func(i, j int) bool {
+		di := domain.DistanceMeters(point, *drones[i].LastLocation)
+		dj := domain.DistanceMeters(point, *drones[j].LastLocation)
+		if di != dj {
+			return di < dj
+		}
+		return drones[i].ID < drones[j].ID
+	})
+	if limit > 0 && len(drones) > limit {
+		drones = drones[:limit]
+	}
+	return drones
+}
+
+// AdminOrdersNear lists orders whose origin lies within a radius of a point,
+// nearest first.
+func (s *Service) AdminOrdersNear(ctx context.Context, query RadiusQuery) ([]*OrderView, error) {
+	if err := query.validate(); err != nil {
+		return nil, err
+	}
+	orders, err := s.store.OrdersWithinRadius(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildOrderViews(ctx, orders)
+}
+
+// AdminNearestIdleDrones lists up to limit idle drones nearest to point.
+func (s *Service) AdminNearestIdleDrones(ctx context.Context, point domain.Location, limit int) ([]*domain.Drone, error) {
+	if err := domain.ValidateLocation(point); err != nil {
+		return nil, fmt.Errorf("point: %w", domain.ErrInvalid)
+	}
+	return s.store.NearestIdleDrones(ctx, point, normalizeListLimit(limit))
+}
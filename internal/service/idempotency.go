@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"penny-assesment/internal/domain"
+)
+
+// DefaultIdempotencyTTL is how long a stored result answers retries that reuse
+// its idempotency key.
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+const maxIdempotencyKeyLength = 255
+
+// idempotencyRequest identifies a mutation made under a client-supplied key.
+type idempotencyRequest struct {
+	scope string
+	key   string
+	hash  string
+}
+
+// newIdempotencyRequest returns nil when key is empty, i.e. the caller did not
+// ask for idempotency. The hash covers the operation and its parameters so
+// that a key reused for a different request can be rejected.
+func newIdempotencyRequest(scope, key, operation string, params ...any) (*idempotencyRequest, error) {
+	if key == "" {
+		return nil, nil
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, fmt.Errorf("idempotency key: %w", domain.ErrInvalid)
+	}
+	payload, err := json.Marshal(append([]any{operation}, params...))
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(payload)
+	return &idempotencyRequest{scope: scope, key: key, hash: hex.EncodeToString(sum[:])}, nil
+}
+
+func userScope(userID string) string {
+	return "user:" + userID
+}
+
+func droneScope(droneID string) string {
+	return "drone:" + droneID
+}
+
+// replay decodes the stored result for req into result and reports whether
+// there was one. It must run inside the transaction that would otherwise
+// perform the mutation, before anything is written.
+func (s *Service) replay(ctx context.Context, tx Tx, req *idempotencyRequest, result any) (bool, error) {
+	if req == nil {
+		return false, nil
+	}
+	record, err := tx.GetIdempotencyRecord(ctx, req.scope, req.key)
+	if errors.Is(err, domain.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !record.ExpiresAt.After(s.now()) {
+		return false, nil
+	}
+	if record.RequestHash != req.hash {
+		return false, domain.ErrIdempotencyKeyReused
+	}
+	if err := json.Unmarshal(record.Response, result); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// PruneIdempotencyRecords deletes the idempotency records that have expired
+// and returns how many went. Expired records are already ignored on replay;
+// this only keeps them from piling up.
+func (s *Service) PruneIdempotencyRecords(ctx context.Context) (int64, error) {
+	return s.store.PruneIdempotencyRecords(ctx, s.now())
+}
+
+// remember stores result for req in tx, so it commits or rolls back together
+// with the mutation that produced it.
+func (s *Service) remember(ctx context.Context, tx Tx, req *idempotencyRequest, result any) error {
+	if req == nil {
+		return nil
+	}
+	response, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	return tx.SaveIdempotencyRecord(ctx, &domain.IdempotencyRecord{
+		Scope:       req.scope,
+		Key:         req.key,
+		RequestHash: req.hash,
+		Response:    response,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.idempotencyTTL),
+	})
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"penny-assesment/internal/domain"
+)
+
+// ServiceInterval is how much a drone may fly between services; a zero field
+// sets no limit of its kind.
+type ServiceInterval struct {
+	FlightTime time.Duration
+	Meters     float64
+}
+
+// Exceeded reports whether usage has reached either limit.
+func (i ServiceInterval) Exceeded(usage domain.FlightUsage) bool {
+	if i.FlightTime > 0 && usage.Seconds >= i.FlightTime.Seconds() {
+		return true
+	}
+	return i.Meters > 0 && usage.Meters >= i.Meters
+}
+
+// SetServiceInterval sets how much a drone may fly before it is taken into
+// MAINTENANCE on completing an order; the zero interval never does.
+func (s *Service) SetServiceInterval(interval ServiceInterval) {
+	s.serviceInterval = interval
+}
+
+// AdminDroneMaintenance returns the maintenance history of drone droneID,
+// oldest first.
+func (s *Service) AdminDroneMaintenance(ctx context.Context, droneID string) ([]*domain.MaintenanceRecord, error) {
+	if _, err := s.store.GetDrone(ctx, droneID); err != nil {
+		return nil, err
+	}
+	return s.store.ListMaintenanceRecords(ctx, droneID)
+}
+
+// orderFlight is the flying drone did on order, completed at now: the time
+// since it was reserved and the length of its route, less what was left of it
+// if the order failed on the way.
+func orderFlight(order *domain.Order, drone *domain.Drone, now time.Time) domain.FlightUsage {
+	var flight domain.FlightUsage
+	if order.ReservedAt != nil && now.After(*order.ReservedAt) {
+		flight.Seconds = now.Sub(*order.ReservedAt).Seconds()
+	}
+	route := order.Route
+	if len(route) < 2 {
+		route = []domain.Location{RouteStart(order), LegEnd(order)}
+	}
+	flight.Meters = domain.PathLengthMeters(route)
+	if order.Status == domain.OrderStatusFailed {
+		if drone.LastLocation == nil {
+			flight.Meters = 0
+		} else {
+			flight.Meters = max(0, flight.Meters-RemainingRouteMeters(route, *drone.LastLocation))
+		}
+	}
+	return flight
+}
+
+// outOfService reports whether status is one a maintenance record covers.
+func outOfService(status domain.DroneStatus) bool {
+	return status == domain.DroneStatusBroken || status == domain.DroneStatusMaintenance
+}
+
+// trackMaintenance keeps the drone's maintenance record in step with its move
+// to status, which the caller then makes. Going out of service opens a record
+// for reason; breaking while in MAINTENANCE turns the open record into a
+// BROKEN one; coming back into service closes it with report and restarts
+// the drone's service interval.
+func trackMaintenance(ctx context.Context, tx Tx, drone *domain.Drone, status domain.DroneStatus, reason domain.MaintenanceReason, report domain.MaintenanceReport, now time.Time) error {
+	from, to := outOfService(drone.Status), outOfService(status)
+	switch {
+	case !from && to:
+		return tx.CreateMaintenanceRecord(ctx, newMaintenanceRecord(drone, reason, now))
+	case from && to:
+		if status != domain.DroneStatusBroken || drone.Status == domain.DroneStatusBroken {
+			return nil
+		}
+		record, err := tx.GetOpenMaintenanceRecordForUpdate(ctx, drone.ID)
+		if errors.Is(err, domain.ErrNotFound) {
+			return tx.CreateMaintenanceRecord(ctx, newMaintenanceRecord(drone, reason, now))
+		}
+		if err != nil {
+			return err
+		}
+		record.Reason = domain.MaintenanceReasonBroken
+		record.FaultCodes = drone.Vitals.FaultCodes
+		return tx.UpdateMaintenanceRecord(ctx, record)
+	case from && !to:
+		drone.SinceService = domain.FlightUsage{}
+		record, err := tx.GetOpenMaintenanceRecordForUpdate(ctx, drone.ID)
+		if errors.Is(err, domain.ErrNotFound) {
+			// Drones taken out of service before records were kept have
+			// none open: record the repair on its own.
+			record = newMaintenanceRecord(drone, domain.MaintenanceReasonScheduled, now)
+			if drone.Status == domain.DroneStatusBroken {
+				record.Reason = domain.MaintenanceReasonBroken
+			}
+			closeMaintenanceRecord(record, report, now)
+			return tx.CreateMaintenanceRecord(ctx, record)
+		}
+		if err != nil {
+			return err
+		}
+		closeMaintenanceRecord(record, report, now)
+		return tx.UpdateMaintenanceRecord(ctx, record)
+	}
+	return nil
+}
+
+func newMaintenanceRecord(drone *domain.Drone, reason domain.MaintenanceReason, now time.Time) *domain.MaintenanceRecord {
+	record := &domain.MaintenanceRecord{
+		ID:       uuidFunc(),
+		DroneID:  drone.ID,
+		Reason:   reason,
+		Usage:    drone.Usage,
+		OpenedAt: now,
+	}
+	if reason == domain.MaintenanceReasonBroken {
+		record.FaultCodes = drone.Vitals.FaultCodes
+	}
+	return record
+}
+
+func closeMaintenanceRecord(record *domain.MaintenanceRecord, report domain.MaintenanceReport, now time.Time) {
+	record.ClosedAt = &now
+	if report.Technician != "" {
+		record.Technician = &report.Technician
+	}
+	if report.Notes != "" {
+		record.Notes = &report.Notes
+	}
+	record.Parts = report.Parts
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"penny-assesment/internal/domain"
+	"penny-assesment/internal/events"
+)
+
+const maxNoFlyZoneNameLength = 200
+
+// openOrderStatuses are the statuses whose route may still be flown.
+var openOrderStatuses = []domain.OrderStatus{
+	domain.OrderStatusCreated,
+	domain.OrderStatusReserved,
+	domain.OrderStatusPickedUp,
+	domain.OrderStatusHandoffRequested,
+}
+
+// NoFlyWindow is the period a no-fly zone is in force; a nil end is open.
+type NoFlyWindow struct {
+	From  *time.Time
+	Until *time.Time
+}
+
+// NoFlyZoneUpdate holds the fields of an admin no-fly zone update; nil fields
+// are left unchanged. A non-nil Window replaces both ends of the window.
+type NoFlyZoneUpdate struct {
+	Name     *string
+	Boundary domain.Polygon
+	Window   *NoFlyWindow
+}
+
+// RouteStart is where the remaining route of order begins: the
+// handoff point once a drone has handed it off, otherwise its origin.
+func RouteStart(order *domain.Order) domain.Location {
+	if order.HandoffOrigin != nil {
+		return *order.HandoffOrigin
+	}
+	return order.Origin
+}
+
+// LegEnd is where the drone carrying order next sets it down: the end of its
+// current relay leg, otherwise its destination.
+func LegEnd(order *domain.Order) domain.Location {
+	if cur := order.CurrentLeg(); cur >= 0 {
+		return order.Legs[cur].To
+	}
+	return order.Destination
+}
+
+// BlockingZone returns the first of zones in force at now whose boundary the
+// route from→to crosses, or nil.
+func BlockingZone(zones []*domain.NoFlyZone, from, to domain.Location, now time.Time) *domain.NoFlyZone {
+	for _, zone := range zones {
+		if zone.ActiveAt(now) && zone.Boundary.CrossesPath(from, to) {
+			return zone
+		}
+	}
+	return nil
+}
+
+func (s *Service) AdminListNoFlyZones(ctx context.Context) ([]*domain.NoFlyZone, error) {
+	return s.store.ListNoFlyZones(ctx)
+}
+
+func (s *Service) AdminGetNoFlyZone(ctx context.Context, id string) (*domain.NoFlyZone, error) {
+	return s.store.GetNoFlyZone(ctx, id)
+}
+
+// AdminCreateNoFlyZone adds a zone and emits order.route_blocked for every
+// open order whose route it crosses, unless its window has already ended.
+func (s *Service) AdminCreateNoFlyZone(ctx context.Context, name string, boundary domain.Polygon, window NoFlyWindow) (*domain.NoFlyZone, error) {
+	name = strings.TrimSpace(name)
+	if err := validateNoFlyZone(name, boundary, window); err != nil {
+		return nil, err
+	}
+	tx, err := s.store.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	now := s.now()
+	zone := &domain.NoFlyZone{
+		ID:          uuidFunc(),
+		Name:        name,
+		Boundary:    boundary,
+		ActiveFrom:  window.From,
+		ActiveUntil: window.Until,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := tx.CreateNoFlyZone(ctx, zone); err != nil {
+		return nil, err
+	}
+	if err := s.enqueueRouteBlocked(ctx, tx, nil, zone); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return zone, nil
+}
+
+// AdminUpdateNoFlyZone changes a zone. Orders the changed zone newly crosses
+// get an order.route_blocked event; orders it already crossed do not.
+func (s *Service) AdminUpdateNoFlyZone(ctx context.Context, id string, update NoFlyZoneUpdate, expectedVersion int64) (*domain.NoFlyZone, error) {
+	tx, err := s.store.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	zone, err := tx.GetNoFlyZoneForUpdate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(expectedVersion, zone.Version); err != nil {
+		return nil, err
+	}
+	previous := *zone
+	if update.Name != nil {
+		zone.Name = strings.TrimSpace(*update.Name)
+	}
+	if update.Boundary != nil {
+		zone.Boundary = update.Boundary
+	}
+	if update.Window != nil {
+		zone.ActiveFrom = update.Window.From
+		zone.ActiveUntil = update.Window.Until
+	}
+	if err := validateNoFlyZone(zone.Name, zone.Boundary, NoFlyWindow{From: zone.ActiveFrom, Until: zone.ActiveUntil}); err != nil {
+		return nil, err
+	}
+	zone.UpdatedAt = s.now()
+	if err := tx.UpdateNoFlyZone(ctx, zone); err != nil {
+		return nil, err
+	}
+	if update.Boundary != nil || update.Window != nil {
+		if err := s.enqueueRouteBlocked(ctx, tx, &previous, zone); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return zone, nil
+}
+
+func validateNoFlyZone(name string, boundary domain.Polygon, window NoFlyWindow) error {
+	if name == "" || len(name) > maxNoFlyZoneNameLength {
+		return fmt.Errorf("name: %w", domain.ErrInvalid)
+	}
+	if err := domain.ValidatePolygon(boundary); err != nil {
+		return fmt.Errorf("boundary: %v: %w", err, domain.ErrInvalid)
+	}
+	if window.From != nil && window.Until != nil && !window.From.Before(*window.Until) {
+		return fmt.Errorf("active window: %w", domain.ErrInvalid)
+	}
+	return nil
+}
+
+// enqueueRouteBlocked emits order.route_blocked in tx for each open order
+// whose route zone crosses and previous (the zone before an update, or nil)
+// did not. A zone whose window has ended blocks nothing.
+func (s *Service) enqueueRouteBlocked(ctx context.Context, tx Tx, previous, zone *domain.NoFlyZone) error {
+	now := s.now()
+	if zone.ExpiredAt(now) {
+		return nil
+	}
+	if previous != nil && previous.ExpiredAt(now) {
+		previous = nil
+	}
+	filter := OrderFilter{Statuses: openOrderStatuses, Sort: OrderSortCreatedAsc, Limit: maxListLimit}
+	for {
+		orders, err := s.store.ListOrders(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, order := range orders {
+			from, to := RouteStart(order), LegEnd(order)
+			if !zone.Boundary.CrossesPath(from, to) {
+				continue
+			}
+			if previous != nil && previous.Boundary.CrossesPath(from, to) {
+				continue
+			}
+			if err := tx.EnqueueEvent(ctx, events.NewRouteBlockedEvent(order, zone, now)); err != nil {
+				return err
+			}
+		}
+		if len(orders) < filter.Limit {
+			return nil
+		}
+		last := orders[len(orders)-1]
+		filter.After = &OrderCursor{Key: filter.Sort.Key(last), ID: last.ID}
+	}
+}
+
+// planRoute plans a path from→to around the no-fly zones in force now, or
+// refuses with a domain.RouteBlockedError naming a zone in the way when there
+// is none.
+func (s *Service) planRoute(ctx context.Context, from, to domain.Location) ([]domain.Location, error) {
+	zones, err := s.store.ListNoFlyZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return routeOrBlocked(zones, from, to, s.now())
+}
+
+func routeOrBlocked(zones []*domain.NoFlyZone, from, to domain.Location, now time.Time) ([]domain.Location, error) {
+	if route := PlanRoute(zones, from, to, now); route != nil {
+		return route, nil
+	}
+	zone := BlockingZone(zones, from, to, now)
+	if zone == nil {
+		// PlanRoute only fails when the direct leg is blocked.
+		return nil, fmt.Errorf("no route: %w", domain.ErrInvalid)
+	}
+	return nil, &domain.RouteBlockedError{ZoneID: zone.ID, ZoneName: zone.Name}
+}
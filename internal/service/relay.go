@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"penny-assesment/internal/domain"
+)
+
+// relayPoint is a depot or charging station an order can be handed between
+// drones at.
+type relayPoint struct {
+	ID       string
+	Location domain.Location
+}
+
+// SetDroneRange sets the furthest a drone flies on one leg, in meters. Longer
+// orders are relayed across depots and charging stations; zero, the default,
+// lets one drone fly every order end to end.
+func (s *Service) SetDroneRange(meters float64) {
+	s.droneRange = meters
+}
+
+// planLegs plans the trip from→to around the no-fly zones in force. It returns
+// nil when one drone can fly it, and otherwise the relay legs of the shortest
+// chain of relay points whose hops are each within range. A trip with no such
+// chain is domain.ErrOutOfRange.
+func (s *Service) planLegs(ctx context.Context, from, to domain.Location) ([]domain.RelayLeg, error) {
+	zones, err := s.store.ListNoFlyZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	route, err := routeOrBlocked(zones, from, to, now)
+	if err != nil {
+		return nil, err
+	}
+	if s.droneRange <= 0 || domain.PathLengthMeters(route) <= s.droneRange {
+		return nil, nil
+	}
+	points, err := s.relayPoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	legs := shortestRelay(zones, from, to, points, s.droneRange, now)
+	if legs == nil {
+		return nil, domain.ErrOutOfRange
+	}
+	return legs, nil
+}
+
+// replanLegs plans order's remaining legs again from its route start, after
+// its destination moved, keeping those already completed and the drone
+// holding the current one.
+func (s *Service) replanLegs(ctx context.Context, order *domain.Order) error {
+	legs, err := s.planLegs(ctx, RouteStart(order), order.Destination)
+	if err != nil {
+		return err
+	}
+	cur := order.CurrentLeg()
+	if cur > 0 && legs == nil {
+		legs = []domain.RelayLeg{{To: order.Destination}}
+	}
+	if cur >= 0 && legs != nil {
+		legs[0].DroneID = order.Legs[cur].DroneID
+	}
+	if cur > 0 {
+		legs = append(order.Legs[:cur:cur], legs...)
+	}
+	order.Legs = legs
+	return nil
+}
+
+// relayPoints returns the depots then the charging stations, each by ID.
+func (s *Service) relayPoints(ctx context.Context) ([]relayPoint, error) {
+	depots, err := s.store.ListDepots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stations, err := s.store.ListChargingStations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]relayPoint, 0, len(depots)+len(stations))
+	for _, depot := range depots {
+		points = append(points, relayPoint{ID: depot.ID, Location: depot.Location})
+	}
+	for _, station := range stations {
+		points = append(points, relayPoint{ID: station.ID, Location: station.Location})
+	}
+	return points, nil
+}
+
+// shortestRelay finds the chain from→points→to with the least total flying
+// whose every hop has a route within rangeMeters, or nil. It is Dijkstra's
+// algorithm over from (node 0), points and to (the last node); hops are
+// planned only when reached.
+func shortestRelay(zones []*domain.NoFlyZone, from, to domain.Location, points []relayPoint, rangeMeters float64, now time.Time) []domain.RelayLeg {
+	n := len(points) + 2
+	location := func(i int) domain.Location {
+		switch i {
+		case 0:
+			return from
+		case n - 1:
+			return to
+		default:
+			return points[i-1].Location
+		}
+	}
+	dist := make([]float64, n)
+	prev := make([]int, n)
+	done := make([]bool, n)
+	for i := range dist {
+		dist[i] = math.Inf(1)
+		prev[i] = -1
+	}
+	dist[0] = 0
+	for {
+		u := -1
+		for i := range dist {
+			if !done[i] && !math.IsInf(dist[i], 1) && (u < 0 || dist[i] < dist[u]) {
+				u = i
+			}
+		}
+		if u < 0 {
+			return nil
+		}
+		if u == n-1 {
+			break
+		}
+		done[u] = true
+		for v := 1; v < n; v++ {
+			if done[v] {
+				continue
+			}
+			// The straight line is never longer than the route, so hops
+			// that cannot be in range or improve v are not planned.
+			straight := domain.DistanceMeters(location(u), location(v))
+			if straight > rangeMeters || dist[u]+straight >= dist[v] {
+				continue
+			}
+			route := PlanRoute(zones, location(u), location(v), now)
+			if route == nil {
+				continue
+			}
+			meters := domain.PathLengthMeters(route)
+			if meters > rangeMeters || dist[u]+meters >= dist[v] {
+				continue
+			}
+			dist[v] = dist[u] + meters
+			prev[v] = u
+		}
+	}
+	var legs []domain.RelayLeg
+	for v := n - 1; v > 0; v = prev[v] {
+		leg := domain.RelayLeg{To: location(v)}
+		if v < n-1 {
+			leg.RelayPointID = points[v-1].ID
+		}
+		legs = append([]domain.RelayLeg{leg}, legs...)
+	}
+	return legs
+}
+
+// assignLeg records droneID, or no drone, on order's current relay leg.
+func assignLeg(order *domain.Order, droneID *string) {
+	if cur := order.CurrentLeg(); cur >= 0 {
+		order.Legs[cur].DroneID = droneID
+	}
+}
+
+// flewPreviousLeg reports whether droneID carried order to the start of its
+// current relay leg; the next leg is left to another drone.
+func flewPreviousLeg(order *domain.Order, droneID string) bool {
+	cur := order.CurrentLeg()
+	if cur <= 0 {
+		return false
+	}
+	previous := order.Legs[cur-1].DroneID
+	return previous != nil && *previous == droneID
+}
+
+// relayStart is the relay point order's current leg starts from, or nil while
+// it is on its first leg or has none.
+func relayStart(order *domain.Order) *domain.Location {
+	cur := order.CurrentLeg()
+	if cur <= 0 {
+		return nil
+	}
+	loc := order.Legs[cur-1].To
+	return &loc
+}
+
+// finalLeg reports whether delivering order sets it down at its destination
+// rather than at a relay point.
+func finalLeg(order *domain.Order) bool {
+	cur := order.CurrentLeg()
+	return cur < 0 || cur == len(order.Legs)-1
+}
+
+// completeLeg marks order's current relay leg flown at now and queues the
+// order for the next drone to collect from the relay point.
+func completeLeg(order *domain.Order, now time.Time) {
+	cur := order.CurrentLeg()
+	order.Legs[cur].CompletedAt = &now
+	loc := order.Legs[cur].To
+	order.Status = domain.OrderStatusHandoffRequested
+	order.HandoffOrigin = &loc
+	order.AssignedDroneID = nil
+	order.ReservedAt = nil
+	order.Route = nil
+	order.UpdatedAt = now
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"container/heap"
+	"math"
+	"time"
+
+	"penny-assesment/internal/domain"
+)
+
+// routeBufferMeters is how far outside a no-fly zone's corners a planned
+// route may bend.
+const routeBufferMeters = 200
+
+// PlanRoute returns the shortest path from→to that crosses none of the zones
+// in force at now, as a list of points starting at from and ending at to. It
+// returns nil when no such path exists, e.g. because an end lies inside a
+// zone.
+//
+// The planner searches a visibility graph: the nodes are the two ends plus a
+// point just outside every corner of every zone (see domain.Polygon.Waypoints),
+// and two nodes are joined when the great-circle leg between them is clear.
+// Zone holes are treated as part of the zone.
+func PlanRoute(zones []*domain.NoFlyZone, from, to domain.Location, now time.Time) []domain.Location {
+	var active []domain.Polygon
+	for _, zone := range zones {
+		if zone.ActiveAt(now) {
+			active = append(active, domain.Polygon{zone.Boundary[0]})
+		}
+	}
+	clear := func(a, b domain.Location) bool {
+		for _, polygon := range active {
+			if polygon.CrossesPath(a, b) {
+				return false
+			}
+		}
+		return true
+	}
+	if clear(from, to) {
+		return []domain.Location{from, to}
+	}
+	for _, polygon := range active {
+		if polygon.Contains(from) || polygon.Contains(to) {
+			return nil
+		}
+	}
+
+	nodes := []domain.Location{from, to}
+	for _, polygon := range active {
+		for _, waypoint := range polygon.Waypoints(routeBufferMeters) {
+			if !insideAny(active, waypoint) {
+				nodes = append(nodes, waypoint)
+			}
+		}
+	}
+
+	// Dijkstra from node 0 to node 1. Legs are checked lazily, when their
+	// start node is settled, so most pairs are never tested.
+	dist := make([]float64, len(nodes))
+	prev := make([]int, len(nodes))
+	settled := make([]bool, len(nodes))
+	for i := range dist {
+		dist[i] = math.Inf(1)
+		prev[i] = -1
+	}
+	dist[0] = 0
+	queue := &routeQueue{{node: 0}}
+	for queue.Len() > 0 {
+		current := heap.Pop(queue).(routeItem)
+		if settled[current.node] {
+			continue
+		}
+		settled[current.node] = true
+		if current.node == 1 {
+			break
+		}
+		for next := range nodes {
+			if settled[next] {
+				continue
+			}
+			candidate := dist[current.node] + domain.DistanceMeters(nodes[current.node], nodes[next])
+			if candidate >= dist[next] || !clear(nodes[current.node], nodes[next]) {
+				continue
+			}
+			dist[next] = candidate
+			prev[next] = current.node
+			heap.Push(queue, routeItem{node: next, dist: candidate})
+		}
+	}
+	if !settled[1] {
+		return nil
+	}
+	var reversed []domain.Location
+	for node := 1; node != -1; node = prev[node] {
+		reversed = append(reversed, nodes[node])
+	}
+	route := make([]domain.Location, 0, len(reversed))
+	for i := len(reversed) - 1; i >= 0; i-- {
+		route = append(route, reversed[i])
+	}
+	return route
+}
+
+// RemainingRouteMeters is the distance left along route for a drone at loc:
+// to the end of the leg it is closest to being on, then along the rest of the
+// route.
+func RemainingRouteMeters(route []domain.Location, loc domain.Location) float64 {
+	if len(route) < 2 {
+		return 0
+	}
+	// The leg the drone is on is the one it detours least from.
+	best, bestDetour := 0, math.Inf(1)
+	for i := 1; i < len(route); i++ {
+		detour := domain.DistanceMeters(route[i-1], loc) + domain.DistanceMeters(loc, route[i]) - domain.DistanceMeters(route[i-1], route[i])
+		if detour < bestDetour {
+			best, bestDetour = i, detour
+		}
+	}
+	return domain.DistanceMeters(loc, route[best]) + domain.PathLengthMeters(route[best:])
+}
+
+func insideAny(polygons []domain.Polygon, loc domain.Location) bool {
+	for _, polygon := range polygons {
+		if polygon.Contains(loc) {
+			return true
+		}
+	}
+	return false
+}
+
+type routeItem struct {
+	node int
+	dist float64
+}
+
+type routeQueue []routeItem
+
+func (q routeQueue) Len() int           { return len(q) }
+func (q routeQueue) Less(i, j int) bool { return q[i].dist < q[j].dist }
+func (q routeQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *routeQueue) Push(x any)        { *q = append(*q, x.(routeItem)) }
+func (q *routeQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
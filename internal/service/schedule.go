@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"penny-assesment/internal/domain"
+	"penny-assesment/internal/events"
+)
+
+// DefaultDeadlineWindow is how close to its deliver_by an order must be to be
+// reserved ahead of older orders.
+const DefaultDeadlineWindow = 30 * time.Minute
+
+// DeliveryWindow is an order's optional schedule: it is not reserved before
+// PickupNotBefore, and is due at its destination by DeliverBy.
+type DeliveryWindow struct {
+	PickupNotBefore *time.Time
+	DeliverBy       *time.Time
+}
+
+// SetDeadlineWindow sets how close to its deliver_by an order must be to be
+// reserved ahead of older orders.
+func (s *Service) SetDeadlineWindow(window time.Duration) {
+	s.deadlineWindow = window
+}
+
+func validateDeliveryWindow(window DeliveryWindow, now time.Time) error {
+	if window.DeliverBy == nil {
+		return nil
+	}
+	if !window.DeliverBy.After(now) {
+		return fmt.Errorf("deliver_by: %w", domain.ErrInvalid)
+	}
+	if window.PickupNotBefore != nil && !window.PickupNotBefore.Before(*window.DeliverBy) {
+		return fmt.Errorf("deliver_by: %w", domain.ErrInvalid)
+	}
+	return nil
+}
+
+// markSLAAtRisk records on order, the first time eta would deliver it after
+// its deliver_by, that it is at risk, and returns the order.sla_at_risk event
+// to emit once order is written; nil otherwise.
+func markSLAAtRisk(order *domain.Order, eta *ETA, now time.Time) *events.Event {
+	if order.DeliverBy == nil || order.SLAAtRiskAt != nil || eta == nil {
+		return nil
+	}
+	estimated := now.Add(time.Duration(eta.Seconds) * time.Second)
+	if !estimated.After(*order.DeliverBy) {
+		return nil
+	}
+	order.SLAAtRiskAt = &now
+	event := events.NewSLAAtRiskEvent(order, estimated, now)
+	return &event
+}
+
+// checkSLAs flags each order drone holds whose ETA, now that the drone has
+// reported in, would miss its deliver_by.
+func (s *Service) checkSLAs(ctx context.Context, drone *domain.Drone) error {
+	for _, orderID := range heldOrders(drone) {
+		order, err := s.store.GetOrder(ctx, orderID)
+		if errors.Is(err, domain.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		// Checked without a lock first so that orders on time cost no write.
+		if markSLAAtRisk(order, ComputeETA(order, drone, s.eta, nil), s.now()) == nil {
+			continue
+		}
+		if err := s.flagSLAAtRisk(ctx, orderID, drone); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) flagSLAAtRisk(ctx context.Context, orderID string, drone *domain.Drone) error {
+	tx, err := s.store.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	order, err := tx.GetOrderForUpdate(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	risk := markSLAAtRisk(order, ComputeETA(order, drone, s.eta, nil), now)
+	if risk == nil {
+		return nil
+	}
+	order.UpdatedAt = now
+	if err := tx.UpdateOrder(ctx, order); err != nil {
+		return err
+	}
+	if err := tx.EnqueueEvent(ctx, *risk); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -4,7 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"sort"
+	"log"
+	"strings"
 	"time"
 
 	"penny-assesment/internal/domain"
@@ -15,9 +16,57 @@ type Store interface {
 	BeginTx(ctx context.Context) (Tx, error)
 	GetOrder(ctx context.Context, id string) (*domain.Order, error)
 	ListOrders(ctx context.Context, filter OrderFilter) ([]*domain.Order, error)
+	// CountOrders counts the orders matching filter, ignoring its sort,
+	// cursor and limit.
+	CountOrders(ctx context.Context, filter OrderFilter) (int, error)
+	// QueuePositions returns, for each of ids whose order is in one of
+	// statuses, how many orders in those statuses were created before it.
+	QueuePositions(ctx context.Context, statuses []domain.OrderStatus, ids []string) (map[string]int, error)
 	CreateOrder(ctx context.Context, order *domain.Order) error
 	GetDrone(ctx context.Context, id string) (*domain.Drone, error)
+	// GetDrones returns the drones that exist among ids, in no particular order.
+	GetDrones(ctx context.Context, ids []string) ([]*domain.Drone, error)
 	ListDrones(ctx context.Context) ([]*domain.Drone, error)
+	// OrdersWithinRadius returns the orders matching query whose origin is
+	// within query.RadiusMeters, nearest first, then by ID.
+	OrdersWithinRadius(ctx context.Context, query RadiusQuery) ([]*domain.Order, error)
+	// NearestIdleDrones returns up to limit drones for which IsIdle holds,
+	// nearest to point first, then by ID.
+	NearestIdleDrones(ctx context.Context, point domain.Location, limit int) ([]*domain.Drone, error)
+	GetServiceArea(ctx context.Context, id string) (*domain.ServiceArea, error)
+	// ListServiceAreas returns every service area, active or not, by ID.
+	ListServiceAreas(ctx context.Context) ([]*domain.ServiceArea, error)
+	GetNoFlyZone(ctx context.Context, id string) (*domain.NoFlyZone, error)
+	// ListNoFlyZones returns every no-fly zone, whatever its window, by ID.
+	ListNoFlyZones(ctx context.Context) ([]*domain.NoFlyZone, error)
+	// ListTelemetry returns the samples matching query, oldest first (ties
+	// in no particular order), up to query.Limit.
+	ListTelemetry(ctx context.Context, query TelemetryQuery) ([]*domain.TelemetrySample, error)
+	// PruneTelemetry deletes the samples recorded before before and returns
+	// how many there were; a zero before deletes nothing.
+	PruneTelemetry(ctx context.Context, before time.Time) (int64, error)
+	// PruneIdempotencyRecords deletes the records that expired at or before
+	// now and returns how many there were.
+	PruneIdempotencyRecords(ctx context.Context, now time.Time) (int64, error)
+	// ListMaintenanceRecords returns the drone's maintenance records, oldest
+	// first, then by ID.
+	ListMaintenanceRecords(ctx context.Context, droneID string) ([]*domain.MaintenanceRecord, error)
+	GetDepot(ctx context.Context, id string) (*domain.Depot, error)
+	// ListDepots returns every depot by ID.
+	ListDepots(ctx context.Context) ([]*domain.Depot, error)
+	// ListDepotDrones returns the drones whose home is the depot, by ID.
+	ListDepotDrones(ctx context.Context, depotID string) ([]*domain.Drone, error)
+	GetChargingStation(ctx context.Context, id string) (*domain.ChargingStation, error)
+	// ListChargingStations returns every charging station by ID.
+	ListChargingStations(ctx context.Context) ([]*domain.ChargingStation, error)
+	// ListChargingSlots returns the station's slots by number.
+	ListChargingSlots(ctx context.Context, stationID string) ([]*domain.ChargingSlot, error)
+	// CountOccupiedSlots returns, by station ID, how many of each station's
+	// slots are held; stations with none held are left out.
+	CountOccupiedSlots(ctx context.Context) (map[string]int, error)
+	// GetDroneChargingSlot returns the slot the drone holds, or
+	// domain.ErrNotFound.
+	GetDroneChargingSlot(ctx context.Context, droneID string) (*domain.ChargingSlot, error)
 }
 
 type Tx interface {
@@ -29,62 +78,193 @@ type Tx interface {
 	CreateOrder(ctx context.Context, order *domain.Order) error
 	UpdateOrder(ctx context.Context, order *domain.Order) error
 	UpdateDrone(ctx context.Context, drone *domain.Drone) error
-	ReserveNextOrder(ctx context.Context, allowed []domain.OrderStatus) (*domain.Order, error)
+	// QueuedOrders returns up to limit unassigned orders in one of the allowed
+	// statuses without locking them, oldest first except that those to be
+	// delivered by urgent go first, soonest deadline first. Orders whose
+	// pickup window opens after due are left out. A non-nil after, the last
+	// order of the previous page, continues the queue past it.
+	QueuedOrders(ctx context.Context, allowed []domain.OrderStatus, due, urgent time.Time, after *domain.Order, limit int) ([]*domain.Order, error)
+	// ReserveOrder locks and returns order id if it is still unassigned, in
+	// one of the allowed statuses and due for pickup by due; nil if it is not,
+	// or if another transaction holds it, which it does not wait for.
+	ReserveOrder(ctx context.Context, id string, allowed []domain.OrderStatus, due time.Time) (*domain.Order, error)
 	EnqueueEvent(ctx context.Context, event events.Event) error
-}
-
-type OrderFilter struct {
-	Status *domain.OrderStatus
-	Limit  int
-	Offset int
+	// GetIdempotencyRecord returns the record stored for (scope, key), even if
+	// it has expired, or domain.ErrNotFound. It locks the key until the
+	// transaction ends so that concurrent requests reusing it are serialised.
+	GetIdempotencyRecord(ctx context.Context, scope, key string) (*domain.IdempotencyRecord, error)
+	// SaveIdempotencyRecord stores record, replacing any existing record for
+	// the same scope and key.
+	SaveIdempotencyRecord(ctx context.Context, record *domain.IdempotencyRecord) error
+	CreateServiceArea(ctx context.Context, area *domain.ServiceArea) error
+	GetServiceAreaForUpdate(ctx context.Context, id string) (*domain.ServiceArea, error)
+	UpdateServiceArea(ctx context.Context, area *domain.ServiceArea) error
+	CreateNoFlyZone(ctx context.Context, zone *domain.NoFlyZone) error
+	GetNoFlyZoneForUpdate(ctx context.Context, id string) (*domain.NoFlyZone, error)
+	UpdateNoFlyZone(ctx context.Context, zone *domain.NoFlyZone) error
+	AppendTelemetry(ctx context.Context, sample *domain.TelemetrySample) error
+	// CreateMaintenanceRecord fails with domain.ErrConflict if the drone
+	// already has an open record.
+	CreateMaintenanceRecord(ctx context.Context, record *domain.MaintenanceRecord) error
+	// GetOpenMaintenanceRecordForUpdate returns the drone's open record, or
+	// domain.ErrNotFound.
+	GetOpenMaintenanceRecordForUpdate(ctx context.Context, droneID string) (*domain.MaintenanceRecord, error)
+	UpdateMaintenanceRecord(ctx context.Context, record *domain.MaintenanceRecord) error
+	CreateDepot(ctx context.Context, depot *domain.Depot) error
+	GetDepotForUpdate(ctx context.Context, id string) (*domain.Depot, error)
+	UpdateDepot(ctx context.Context, depot *domain.Depot) error
+	// CreateChargingStation also creates the station's free slots.
+	CreateChargingStation(ctx context.Context, station *domain.ChargingStation) error
+	GetChargingStationForUpdate(ctx context.Context, id string) (*domain.ChargingStation, error)
+	// UpdateChargingStation adds free slots up to station.Slots and removes
+	// those numbered above it, which the caller must have checked are free.
+	UpdateChargingStation(ctx context.Context, station *domain.ChargingStation) error
+	// ReserveFreeChargingSlot locks and returns the station's lowest-numbered
+	// free slot, passing over slots locked by other transactions the way
+	// ReserveOrder does, or nil if none is free.
+	ReserveFreeChargingSlot(ctx context.Context, stationID string) (*domain.ChargingSlot, error)
+	// GetDroneChargingSlotForUpdate returns the slot the drone holds, locked,
+	// or domain.ErrNotFound.
+	GetDroneChargingSlotForUpdate(ctx context.Context, droneID string) (*domain.ChargingSlot, error)
+	// UpdateChargingSlot records who holds the slot. Giving a drone a second
+	// slot is domain.ErrConflict.
+	UpdateChargingSlot(ctx context.Context, slot *domain.ChargingSlot) error
 }
 
 type Service struct {
-	store Store
-	now   func() time.Time
-	speed float64
+	store              Store
+	now                func() time.Time
+	eta                ETAModel
+	idempotencyTTL     time.Duration
+	speedWindowFixes   int
+	speedWindow        time.Duration
+	telemetryRetention time.Duration
+	serviceInterval    ServiceInterval
+	lowBattery         float64
+	droneRange         float64
+	droneCapacity      int
+	batchRadius        float64
+	deadlineWindow     time.Duration
 }
 
 func New(store Store, speedMPS float64) *Service {
-	return &Service{store: store, now: func() time.Time { return time.Now().UTC() }, speed: speedMPS}
+	return &Service{
+		store:            store,
+		now:              func() time.Time { return time.Now().UTC() },
+		eta:              ETAModel{SpeedMPS: speedMPS},
+		idempotencyTTL:   DefaultIdempotencyTTL,
+		speedWindowFixes: DefaultSpeedWindowFixes,
+		speedWindow:      DefaultSpeedWindow,
+		lowBattery:       DefaultLowBatteryPercent,
+		droneCapacity:    1,
+		batchRadius:      DefaultBatchRadius,
+		deadlineWindow:   DefaultDeadlineWindow,
+	}
+}
+
+// SetSpeedWindow sets how many heartbeat fixes, and how old, are kept per
+// drone to measure its speed.
+func (s *Service) SetSpeedWindow(fixes int, maxAge time.Duration) {
+	s.speedWindowFixes = fixes
+	s.speedWindow = maxAge
+}
+
+// SetDwellTimes sets how long ETAs allow for loading and unloading a package.
+func (s *Service) SetDwellTimes(pickup, dropoff time.Duration) {
+	s.eta.PickupDwell = pickup
+	s.eta.DropoffDwell = dropoff
 }
 
-func (s *Service) SubmitOrder(ctx context.Context, userID string, origin, dest domain.Location) (*domain.Order, error) {
+// SetIdempotencyTTL sets how long results are kept for idempotent retries.
+func (s *Service) SetIdempotencyTTL(ttl time.Duration) {
+	s.idempotencyTTL = ttl
+}
+
+// SubmitOrder creates an order, scheduled by window if it has one. A non-empty
+// idempotencyKey makes retries with the same key, locations and window return
+// the originally created order.
+func (s *Service) SubmitOrder(ctx context.Context, userID string, origin, dest domain.Location, window DeliveryWindow, idempotencyKey string) (*domain.Order, error) {
 	if err := domain.ValidateLocation(origin); err != nil {
 		return nil, fmt.Errorf("origin: %w", domain.ErrInvalid)
 	}
 	if err := domain.ValidateLocation(dest); err != nil {
 		return nil, fmt.Errorf("destination: %w", domain.ErrInvalid)
 	}
+	idem, err := newIdempotencyRequest(userScope(userID), idempotencyKey, "SubmitOrder", origin, dest, window)
+	if err != nil {
+		return nil, err
+	}
 	tx, err := s.store.BeginTx(ctx)
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
+	replayed := &domain.Order{}
+	found, err := s.replay(ctx, tx, idem, replayed)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return replayed, nil
+	}
+	// Checked after the replay: a retry gets its order back even once the
+	// deadline, service areas or no-fly zones have moved on.
+	if err := validateDeliveryWindow(window, s.now()); err != nil {
+		return nil, err
+	}
+	if err := s.checkServiceAreas(ctx, &origin, &dest); err != nil {
+		return nil, err
+	}
+	legs, err := s.planLegs(ctx, origin, dest)
+	if err != nil {
+		return nil, err
+	}
 	now := s.now()
 	order := &domain.Order{
-		ID:          newOrderID(),
-		UserID:      userID,
-		Origin:      origin,
-		Destination: dest,
-		Status:      domain.OrderStatusCreated,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		ID:              newOrderID(),
+		UserID:          userID,
+		Origin:          origin,
+		Destination:     dest,
+		Status:          domain.OrderStatusCreated,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		Legs:            legs,
+		PickupNotBefore: window.PickupNotBefore,
+		DeliverBy:       window.DeliverBy,
+	}
+	queues, err := s.queueStates(ctx, []*domain.Order{order})
+	if err != nil {
+		return nil, err
 	}
+	// The order is not stored yet, so every queued order is ahead of it.
+	ahead, err := s.store.CountOrders(ctx, OrderFilter{Statuses: queuedOrderStatuses})
+	if err != nil {
+		return nil, err
+	}
+	queues[order.ID].Ahead = ahead
+	risk := markSLAAtRisk(order, ComputeETA(order, nil, s.eta, queues[order.ID]), now)
 	if err := tx.CreateOrder(ctx, order); err != nil {
 		return nil, err
 	}
 	if err := tx.EnqueueEvent(ctx, events.NewOrderEvent(events.EventOrderCreated, order, nil, now)); err != nil {
 		return nil, err
 	}
+	if risk != nil {
+		if err := tx.EnqueueEvent(ctx, *risk); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.remember(ctx, tx, idem, order); err != nil {
+		return nil, err
+	}
 	if err := tx.Commit(ctx); err != nil {
 		return nil, err
 	}
 	return order, nil
 }
 
-func (s *Service) WithdrawOrder(ctx context.Context, userID, orderID string) (*domain.Order, error) {
+func (s *Service) WithdrawOrder(ctx context.Context, userID, orderID string, expectedVersion int64) (*domain.Order, error) {
 	tx, err := s.store.BeginTx(ctx)
 	if err != nil {
 		return nil, err
@@ -98,6 +278,9 @@ func (s *Service) WithdrawOrder(ctx context.Context, userID, orderID string) (*d
 	if order.UserID != userID {
 		return nil, domain.ErrForbidden
 	}
+	if err := checkVersion(expectedVersion, order.Version); err != nil {
+		return nil, err
+	}
 	if order.Status != domain.OrderStatusCreated && order.Status != domain.OrderStatusReserved {
 		return nil, domain.ErrPrecondition
 	}
@@ -106,7 +289,7 @@ func (s *Service) WithdrawOrder(ctx context.Context, userID, orderID string) (*d
 		if err != nil {
 			return nil, err
 		}
-		drone.CurrentOrderID = nil
+		leaveTrip(drone, order.ID)
 		drone.UpdatedAt = s.now()
 		if err := tx.UpdateDrone(ctx, drone); err != nil {
 			return nil, err
@@ -139,39 +322,61 @@ func (s *Service) GetOrderView(ctx context.Context, requesterID, role, orderID s
 	return s.buildOrderView(ctx, order)
 }
 
-func (s *Service) AdminListOrders(ctx context.Context, filter OrderFilter) ([]*OrderView, error) {
-	orders, err := s.store.ListOrders(ctx, filter)
-	if err != nil {
-		return nil, err
-	}
-	views := make([]*OrderView, 0, len(orders))
-	for _, order := range orders {
-		view, err := s.buildOrderView(ctx, order)
-		if err != nil {
-			return nil, err
-		}
-		views = append(views, view)
-	}
-	sort.Slice(views, func(i, j int) bool {
-		return views[i].Order.CreatedAt.Before(views[j].Order.CreatedAt)
+// ListMyOrders lists the caller's own orders. Only the status, creation range,
+// sort and paging fields of filter apply; admin search fields are ignored.
+func (s *Service) ListMyOrders(ctx context.Context, userID string, filter OrderFilter) (*OrderPage, error) {
+	return s.listOrders(ctx, OrderFilter{
+		Statuses:    filter.Statuses,
+		UserID:      &userID,
+		CreatedFrom: filter.CreatedFrom,
+		CreatedTo:   filter.CreatedTo,
+		Sort:        filter.Sort,
+		Limit:       filter.Limit,
+		Cursor:      filter.Cursor,
+		Offset:      filter.Offset,
 	})
-	return views, nil
 }
 
-func (s *Service) AdminUpdateOrder(ctx context.Context, orderID string, origin, dest *domain.Location) (*domain.Order, error) {
+func (s *Service) AdminListOrders(ctx context.Context, filter OrderFilter) (*OrderPage, error) {
+	return s.listOrders(ctx, filter)
+}
+
+// AdminUpdateOrder moves an order's origin or destination, and the assigned
+// drone's stops with them. Like AdminUnassignOrder it locks the drone before
+// the order, reading the order unlocked first to find the drone.
+func (s *Service) AdminUpdateOrder(ctx context.Context, orderID string, origin, dest *domain.Location, expectedVersion int64) (*domain.Order, error) {
+	current, err := s.store.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	moved := origin != nil || dest != nil
 	tx, err := s.store.BeginTx(ctx)
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
+	var drone *domain.Drone
+	if moved && current.AssignedDroneID != nil {
+		drone, err = tx.GetDroneForUpdate(ctx, *current.AssignedDroneID)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return nil, err
+		}
+	}
 	order, err := tx.GetOrderForUpdate(ctx, orderID)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkVersion(expectedVersion, order.Version); err != nil {
+		return nil, err
+	}
 	if domain.IsTerminal(order.Status) {
 		return nil, domain.ErrPrecondition
 	}
+	if moved && !sameDrone(order.AssignedDroneID, current.AssignedDroneID) {
+		// Reassigned between the read and the lock.
+		return nil, domain.ErrConflict
+	}
 	if origin != nil {
 		if err := domain.ValidateLocation(*origin); err != nil {
 			return nil, domain.ErrInvalid
@@ -184,10 +389,34 @@ func (s *Service) AdminUpdateOrder(ctx context.Context, orderID string, origin,
 		}
 		order.Destination = *dest
 	}
+	if err := s.checkServiceAreas(ctx, origin, dest); err != nil {
+		return nil, err
+	}
+	if moved {
+		if err := s.replanLegs(ctx, order); err != nil {
+			return nil, err
+		}
+		// Only a reserved order has a route to follow; others are routed
+		// when a drone takes them.
+		if order.Route != nil {
+			route, err := s.planRoute(ctx, RouteStart(order), LegEnd(order))
+			if err != nil {
+				return nil, err
+			}
+			order.Route = route
+		}
+	}
 	order.UpdatedAt = s.now()
 	if err := tx.UpdateOrder(ctx, order); err != nil {
 		return nil, err
 	}
+	if drone != nil && len(drone.Stops) > 0 {
+		moveStops(drone, order)
+		drone.UpdatedAt = order.UpdatedAt
+		if err := tx.UpdateDrone(ctx, drone); err != nil {
+			return nil, err
+		}
+	}
 	if err := tx.EnqueueEvent(ctx, events.NewOrderEvent(events.EventOrderUpdated, order, nil, s.now())); err != nil {
 		return nil, err
 	}
@@ -197,58 +426,327 @@ func (s *Service) AdminUpdateOrder(ctx context.Context, orderID string, origin,
 	return order, nil
 }
 
-func (s *Service) DroneReserveJob(ctx context.Context, droneID string) (*domain.Order, error) {
+func (s *Service) AdminAssignOrder(ctx context.Context, orderID, droneID string, expectedVersion int64) (*domain.Order, error) {
+	return s.assignOrder(ctx, orderID, droneID, expectedVersion, domain.OrderStatusCreated, events.EventOrderAssigned)
+}
+
+func (s *Service) AdminReassignOrder(ctx context.Context, orderID, droneID string, expectedVersion int64) (*domain.Order, error) {
+	return s.assignOrder(ctx, orderID, droneID, expectedVersion, domain.OrderStatusHandoffRequested, events.EventOrderReassigned)
+}
+
+// AdminUnassignOrder puts a reserved order back in the queue. Locks are taken
+// drone first, then order, like assignOrder: the order is read unlocked to find
+// its drone and checked again once both are locked.
+func (s *Service) AdminUnassignOrder(ctx context.Context, orderID string, expectedVersion int64) (*domain.Order, error) {
+	current, err := s.store.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
 	tx, err := s.store.BeginTx(ctx)
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
-	drone, err := getOrCreateDrone(ctx, tx, droneID, s.now())
+	var drone *domain.Drone
+	if current.AssignedDroneID != nil {
+		drone, err = tx.GetDroneForUpdate(ctx, *current.AssignedDroneID)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return nil, err
+		}
+	}
+	order, err := tx.GetOrderForUpdate(ctx, orderID)
 	if err != nil {
 		return nil, err
 	}
-	if drone.Status == domain.DroneStatusBroken {
+	if err := checkVersion(expectedVersion, order.Version); err != nil {
+		return nil, err
+	}
+	if order.Status != domain.OrderStatusReserved {
 		return nil, domain.ErrPrecondition
 	}
-	if drone.CurrentOrderID != nil {
+	if !sameDrone(order.AssignedDroneID, current.AssignedDroneID) {
+		// Reassigned between the read and the lock.
 		return nil, domain.ErrConflict
 	}
-	order, err := tx.ReserveNextOrder(ctx, []domain.OrderStatus{domain.OrderStatusCreated, domain.OrderStatusHandoffRequested})
+	now := s.now()
+	if drone != nil && leaveTrip(drone, order.ID) {
+		drone.UpdatedAt = now
+		if err := tx.UpdateDrone(ctx, drone); err != nil {
+			return nil, err
+		}
+	}
+	requeueOrder(order, now)
+	if err := tx.UpdateOrder(ctx, order); err != nil {
+		return nil, err
+	}
+	if err := tx.EnqueueEvent(ctx, events.NewOrderEvent(events.EventOrderUnassigned, order, drone, now)); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// AdminForceTransition moves an order straight to DELIVERED, FAILED or back to
+// CREATED, skipping the drone-ownership checks drones go through. It exists for
+// operations to close out orders that can no longer progress normally (e.g. a
+// crashed drone was recovered by hand), so a reason is mandatory and the change
+// is recorded as an order.admin_override event.
+func (s *Service) AdminForceTransition(ctx context.Context, adminID, orderID string, target domain.OrderStatus, reason string, expectedVersion int64) (*domain.Order, error) {
+	switch target {
+	case domain.OrderStatusDelivered, domain.OrderStatusFailed, domain.OrderStatusCreated:
+	default:
+		return nil, fmt.Errorf("status: %w", domain.ErrInvalid)
+	}
+	if strings.TrimSpace(reason) == "" {
+		return nil, fmt.Errorf("reason: %w", domain.ErrInvalid)
+	}
+	tx, err := s.store.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	order, err := tx.GetOrderForUpdate(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(expectedVersion, order.Version); err != nil {
+		return nil, err
+	}
+	if domain.IsTerminal(order.Status) || order.Status == target {
+		return nil, domain.ErrPrecondition
+	}
+	now := s.now()
+	var drone *domain.Drone
+	if order.AssignedDroneID != nil {
+		drone, err = tx.GetDroneForUpdate(ctx, *order.AssignedDroneID)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return nil, err
+		}
+		if drone != nil && leaveTrip(drone, order.ID) {
+			drone.UpdatedAt = now
+			if err := tx.UpdateDrone(ctx, drone); err != nil {
+				return nil, err
+			}
+		} else {
+			drone = nil
+		}
+	}
+	previous := order.Status
+	order.Status = target
+	order.UpdatedAt = now
+	switch target {
+	case domain.OrderStatusDelivered:
+		order.DeliveredAt = &now
+	case domain.OrderStatusFailed:
+		order.FailedAt = &now
+		order.FailureReason = &reason
+	case domain.OrderStatusCreated:
+		order.AssignedDroneID = nil
+		order.HandoffOrigin = nil
+		order.ReservedAt = nil
+		order.PickedUpAt = nil
+		// The package goes back to the origin, so every relay leg is
+		// flown again.
+		for i := range order.Legs {
+			order.Legs[i].DroneID = nil
+			order.Legs[i].CompletedAt = nil
+		}
+	}
+	if err := tx.UpdateOrder(ctx, order); err != nil {
+		return nil, err
+	}
+	if err := tx.EnqueueEvent(ctx, events.NewOrderOverrideEvent(order, drone, adminID, previous, reason, now)); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+func (s *Service) DroneReserveJob(ctx context.Context, droneID, idempotencyKey string) (*domain.Order, error) {
+	idem, err := newIdempotencyRequest(droneScope(droneID), idempotencyKey, "ReserveJob")
+	if err != nil {
+		return nil, err
+	}
+	tx, err := s.store.BeginTx(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if order == nil {
-		return nil, domain.ErrNoJob
+	defer tx.Rollback(ctx)
+
+	replayed := &domain.Order{}
+	found, err := s.replay(ctx, tx, idem, replayed)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return replayed, nil
+	}
+	drone, err := getOrCreateDrone(ctx, tx, droneID, s.now())
+	if err != nil {
+		return nil, err
+	}
+	if !drone.Status.Assignable() {
+		return nil, domain.ErrPrecondition
+	}
+	if drone.CurrentOrderID != nil && !s.canBatch(drone) {
+		return nil, domain.ErrConflict
+	}
+	order, err := s.reserveFlyableOrder(ctx, tx, drone)
+	if err != nil {
+		return nil, err
 	}
 	now := s.now()
 	order.Status = domain.OrderStatusReserved
 	order.AssignedDroneID = &drone.ID
 	order.ReservedAt = &now
 	order.UpdatedAt = now
+	assignLeg(order, &drone.ID)
+	drone.Stops, _ = s.tripWith(drone, order)
+	syncTrip(drone)
+	drone.UpdatedAt = now
+	risk := markSLAAtRisk(order, ComputeETA(order, drone, s.eta, nil), now)
 	if err := tx.UpdateOrder(ctx, order); err != nil {
 		return nil, err
 	}
-	drone.CurrentOrderID = &order.ID
-	drone.UpdatedAt = now
 	if err := tx.UpdateDrone(ctx, drone); err != nil {
 		return nil, err
 	}
 	if err := tx.EnqueueEvent(ctx, events.NewOrderEvent(events.EventOrderReserved, order, drone, now)); err != nil {
 		return nil, err
 	}
+	if risk != nil {
+		if err := tx.EnqueueEvent(ctx, *risk); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.remember(ctx, tx, idem, order); err != nil {
+		return nil, err
+	}
 	if err := tx.Commit(ctx); err != nil {
 		return nil, err
 	}
 	return order, nil
 }
 
-func (s *Service) DronePickup(ctx context.Context, droneID, orderID string) (*domain.Order, error) {
-	return s.updateOrderForDrone(ctx, droneID, orderID, events.EventOrderPickedUp, func(order *domain.Order) error {
+// queuedOrderStatuses are the statuses of orders waiting for a drone.
+var queuedOrderStatuses = []domain.OrderStatus{
+	domain.OrderStatusCreated,
+	domain.OrderStatusHandoffRequested,
+}
+
+// queuePageSize is how many queued orders a reservation reads at a time.
+const queuePageSize = 50
+
+// QueueDeadline is the deadline order is queued by: its DeliverBy if that is
+// no later than urgent, nil otherwise. Orders with a deadline go first.
+func QueueDeadline(order *domain.Order, urgent time.Time) *time.Time {
+	if order.DeliverBy == nil || order.DeliverBy.After(urgent) {
+		return nil
+	}
+	return order.DeliverBy
+}
+
+// reserveFlyableOrder locks and returns the first queued order drone can fly,
+// with its route around the no-fly zones in force set. The queue is read
+// without locks, a page at a time until an order is reserved or the queue
+// runs out, and only the chosen order is locked, so orders passed over stay
+// free for other drones. Orders nearest another depot with an idle drone of
+// its own are only taken when nothing else is.
+func (s *Service) reserveFlyableOrder(ctx context.Context, tx Tx, drone *domain.Drone) (*domain.Order, error) {
+	zones, err := s.store.ListNoFlyZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	preference, err := s.newDepotPreference(ctx, drone)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	urgent := now.Add(s.deadlineWindow)
+	flyable := func(order *domain.Order) []domain.Location {
+		if flewPreviousLeg(order, drone.ID) {
+			return nil
+		}
+		if _, ok := s.tripWith(drone, order); !ok {
+			return nil
+		}
+		return PlanRoute(zones, RouteStart(order), LegEnd(order), now)
+	}
+	reserve := func(candidate *domain.Order) (*domain.Order, error) {
+		order, err := tx.ReserveOrder(ctx, candidate.ID, queuedOrderStatuses, now)
+		if err != nil || order == nil {
+			return nil, err
+		}
+		route := candidate.Route
+		if order.Version != candidate.Version {
+			// Changed since it was read; it stays locked if no longer flyable.
+			if route = flyable(order); route == nil {
+				return nil, nil
+			}
+		}
+		order.Route = route
+		return order, nil
+	}
+	var deferred []*domain.Order
+	var after *domain.Order
+	for {
+		queued, err := tx.QueuedOrders(ctx, queuedOrderStatuses, now, urgent, after, queuePageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, candidate := range queued {
+			route := flyable(candidate)
+			if route == nil {
+				continue
+			}
+			candidate.Route = route
+			deferOrder, err := preference.deferOrder(ctx, candidate)
+			if err != nil {
+				return nil, err
+			}
+			if deferOrder {
+				deferred = append(deferred, candidate)
+				continue
+			}
+			order, err := reserve(candidate)
+			if err != nil || order != nil {
+				return order, err
+			}
+		}
+		if len(queued) < queuePageSize {
+			break
+		}
+		after = queued[len(queued)-1]
+	}
+	for _, candidate := range deferred {
+		order, err := reserve(candidate)
+		if err != nil || order != nil {
+			return order, err
+		}
+	}
+	return nil, domain.ErrNoJob
+}
+
+func (s *Service) DronePickup(ctx context.Context, droneID, orderID, idempotencyKey string) (*domain.Order, error) {
+	idem, err := newIdempotencyRequest(droneScope(droneID), idempotencyKey, "PickupOrder", orderID)
+	if err != nil {
+		return nil, err
+	}
+	return s.updateOrderForDrone(ctx, droneID, orderID, idem, events.EventOrderPickedUp, func(order *domain.Order, drone *domain.Drone) error {
 		if order.Status != domain.OrderStatusReserved && order.Status != domain.OrderStatusHandoffRequested {
 			return domain.ErrPrecondition
 		}
 		now := s.now()
+		if err := completeStop(drone, order.ID, domain.StopPickup, false, now); err != nil {
+			return err
+		}
+		syncTrip(drone)
 		order.Status = domain.OrderStatusPickedUp
 		order.PickedUpAt = &now
 		order.UpdatedAt = now
@@ -256,34 +754,81 @@ func (s *Service) DronePickup(ctx context.Context, droneID, orderID string) (*do
 	})
 }
 
-func (s *Service) DroneDeliver(ctx context.Context, droneID, orderID string) (*domain.Order, error) {
-	return s.completeOrderForDrone(ctx, droneID, orderID, domain.OrderStatusDelivered, "")
+func (s *Service) DroneDeliver(ctx context.Context, droneID, orderID, idempotencyKey string) (*domain.Order, error) {
+	idem, err := newIdempotencyRequest(droneScope(droneID), idempotencyKey, "DeliverOrder", orderID)
+	if err != nil {
+		return nil, err
+	}
+	return s.completeOrderForDrone(ctx, droneID, orderID, idem, domain.OrderStatusDelivered, "")
 }
 
-func (s *Service) DroneFail(ctx context.Context, droneID, orderID, reason string) (*domain.Order, error) {
+func (s *Service) DroneFail(ctx context.Context, droneID, orderID, reason, idempotencyKey string) (*domain.Order, error) {
 	if reason == "" {
 		return nil, domain.ErrInvalid
 	}
-	return s.completeOrderForDrone(ctx, droneID, orderID, domain.OrderStatusFailed, reason)
+	idem, err := newIdempotencyRequest(droneScope(droneID), idempotencyKey, "FailOrder", orderID, reason)
+	if err != nil {
+		return nil, err
+	}
+	return s.completeOrderForDrone(ctx, droneID, orderID, idem, domain.OrderStatusFailed, reason)
+}
+
+func (s *Service) DroneMarkBroken(ctx context.Context, droneID, idempotencyKey string) (*domain.Drone, error) {
+	idem, err := newIdempotencyRequest(droneScope(droneID), idempotencyKey, "MarkBroken")
+	if err != nil {
+		return nil, err
+	}
+	return s.markDroneBroken(ctx, droneID, domain.RoleDrone, 0, idem)
 }
 
-func (s *Service) DroneMarkBroken(ctx context.Context, droneID string) (*domain.Drone, error) {
+func (s *Service) markDroneBroken(ctx context.Context, droneID, role string, expectedVersion int64, idem *idempotencyRequest) (*domain.Drone, error) {
 	tx, err := s.store.BeginTx(ctx)
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
+	replayed := &domain.Drone{}
+	found, err := s.replay(ctx, tx, idem, replayed)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return replayed, nil
+	}
 	drone, err := getOrCreateDrone(ctx, tx, droneID, s.now())
 	if err != nil {
 		return nil, err
 	}
-	now := s.now()
+	if err := checkVersion(expectedVersion, drone.Version); err != nil {
+		return nil, err
+	}
+	if !domain.CanTransitionDrone(drone.Status, domain.DroneStatusBroken, role) {
+		return nil, domain.ErrPrecondition
+	}
+	if err := breakDrone(ctx, tx, drone, s.now()); err != nil {
+		return nil, err
+	}
+	if err := s.remember(ctx, tx, idem, drone); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return drone, nil
+}
+
+// breakDrone marks drone BROKEN within tx: an in-flight order becomes a
+// handoff job and a reserved one goes back to the queue.
+func breakDrone(ctx context.Context, tx Tx, drone *domain.Drone, now time.Time) error {
+	if err := trackMaintenance(ctx, tx, drone, domain.DroneStatusBroken, domain.MaintenanceReasonBroken, domain.MaintenanceReport{}, now); err != nil {
+		return err
+	}
 	drone.Status = domain.DroneStatusBroken
-	if drone.CurrentOrderID != nil {
-		order, err := tx.GetOrderForUpdate(ctx, *drone.CurrentOrderID)
+	for _, orderID := range heldOrders(drone) {
+		order, err := tx.GetOrderForUpdate(ctx, orderID)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		// Only create a handoff job if the package is actually in-flight.
@@ -292,50 +837,68 @@ func (s *Service) DroneMarkBroken(ctx context.Context, droneID string) (*domain.
 		case domain.OrderStatusPickedUp:
 			order.Status = domain.OrderStatusHandoffRequested
 			order.AssignedDroneID = nil
+			assignLeg(order, nil)
 			if drone.LastLocation != nil {
 				loc := *drone.LastLocation
 				order.HandoffOrigin = &loc
 			}
 			order.UpdatedAt = now
 			if err := tx.UpdateOrder(ctx, order); err != nil {
-				return nil, err
+				return err
 			}
-			drone.CurrentOrderID = nil
 			if err := tx.EnqueueEvent(ctx, events.NewOrderEvent(events.EventOrderHandoffRequested, order, drone, now)); err != nil {
-				return nil, err
+				return err
 			}
 		case domain.OrderStatusReserved:
 			order.Status = domain.OrderStatusCreated
 			order.AssignedDroneID = nil
 			order.ReservedAt = nil
-			order.HandoffOrigin = nil
+			// A relayed order waits where the previous leg left it.
+			order.HandoffOrigin = relayStart(order)
+			if order.HandoffOrigin != nil {
+				order.Status = domain.OrderStatusHandoffRequested
+			}
+			assignLeg(order, nil)
 			order.UpdatedAt = now
 			if err := tx.UpdateOrder(ctx, order); err != nil {
-				return nil, err
+				return err
 			}
-			drone.CurrentOrderID = nil
 			if err := tx.EnqueueEvent(ctx, events.NewOrderEvent(events.EventOrderUpdated, order, drone, now)); err != nil {
-				return nil, err
+				return err
 			}
 		default:
 			// For any other state, don't mutate the order; still mark drone broken.
-			drone.CurrentOrderID = nil
 		}
 	}
+	drone.CurrentOrderID = nil
+	drone.Stops = nil
 	drone.UpdatedAt = now
 	if err := tx.UpdateDrone(ctx, drone); err != nil {
-		return nil, err
+		return err
 	}
 	if err := tx.EnqueueEvent(ctx, events.NewDroneEvent(events.EventDroneBroken, drone, now)); err != nil {
-		return nil, err
-	}
-	if err := tx.Commit(ctx); err != nil {
-		return nil, err
+		return err
 	}
-	return drone, nil
+	return nil
+}
+
+// DroneSetStatus records a status the drone reports for itself, such as
+// CHARGING or OFFLINE, as allowed by domain.CanTransitionDrone.
+func (s *Service) DroneSetStatus(ctx context.Context, droneID string, status domain.DroneStatus) (*domain.Drone, error) {
+	return s.setDroneStatus(ctx, droneID, status, domain.RoleDrone, domain.MaintenanceReport{}, 0)
 }
 
-func (s *Service) DroneMarkFixed(ctx context.Context, droneID string) (*domain.Drone, error) {
+// setDroneStatus moves a drone to status on role's behalf. BROKEN goes through
+// markDroneBroken, which hands off or requeues the drone's order; any other
+// status that takes the drone out of dispatch needs it to hold no order.
+// report is recorded if the move closes the drone's maintenance record.
+func (s *Service) setDroneStatus(ctx context.Context, droneID string, status domain.DroneStatus, role string, report domain.MaintenanceReport, expectedVersion int64) (*domain.Drone, error) {
+	if !domain.ValidateDroneStatus(status) {
+		return nil, domain.ErrInvalid
+	}
+	if status == domain.DroneStatusBroken {
+		return s.markDroneBroken(ctx, droneID, role, expectedVersion, nil)
+	}
 	tx, err := s.store.BeginTx(ctx)
 	if err != nil {
 		return nil, err
@@ -346,13 +909,32 @@ func (s *Service) DroneMarkFixed(ctx context.Context, droneID string) (*domain.D
 	if err != nil {
 		return nil, err
 	}
+	if err := checkVersion(expectedVersion, drone.Version); err != nil {
+		return nil, err
+	}
+	if !domain.CanTransitionDrone(drone.Status, status, role) {
+		return nil, domain.ErrPrecondition
+	}
+	if !status.Assignable() && drone.CurrentOrderID != nil {
+		return nil, domain.ErrConflict
+	}
+	eventType := events.EventDroneStatusChanged
+	switch {
+	case status == domain.DroneStatusRetired:
+		eventType = events.EventDroneRetired
+	case drone.Status == domain.DroneStatusBroken:
+		eventType = events.EventDroneFixed
+	}
 	now := s.now()
-	drone.Status = domain.DroneStatusActive
+	if err := trackMaintenance(ctx, tx, drone, status, domain.MaintenanceReasonScheduled, report, now); err != nil {
+		return nil, err
+	}
+	drone.Status = status
 	drone.UpdatedAt = now
 	if err := tx.UpdateDrone(ctx, drone); err != nil {
 		return nil, err
 	}
-	if err := tx.EnqueueEvent(ctx, events.NewDroneEvent(events.EventDroneFixed, drone, now)); err != nil {
+	if err := tx.EnqueueEvent(ctx, events.NewDroneEvent(eventType, drone, now)); err != nil {
 		return nil, err
 	}
 	if err := tx.Commit(ctx); err != nil {
@@ -361,10 +943,18 @@ func (s *Service) DroneMarkFixed(ctx context.Context, droneID string) (*domain.D
 	return drone, nil
 }
 
-func (s *Service) DroneHeartbeat(ctx context.Context, droneID string, loc domain.Location) (*DroneStatusView, error) {
+// DroneHeartbeat records the drone's position and latest vitals, replacing
+// the readings of its previous heartbeat. A heartbeat brings an OFFLINE drone
+// back to ACTIVE, and one reporting a critical fault code marks the drone
+// broken, as DroneMarkBroken does. A drone idle away from its home depot is
+// told to return to it.
+func (s *Service) DroneHeartbeat(ctx context.Context, droneID string, loc domain.Location, vitals domain.Vitals) (*DroneStatusView, error) {
 	if err := domain.ValidateLocation(loc); err != nil {
 		return nil, domain.ErrInvalid
 	}
+	if err := domain.ValidateVitals(vitals); err != nil {
+		return nil, fmt.Errorf("%v: %w", err, domain.ErrInvalid)
+	}
 	tx, err := s.store.BeginTx(ctx)
 	if err != nil {
 		return nil, err
@@ -379,12 +969,52 @@ func (s *Service) DroneHeartbeat(ctx context.Context, droneID string, loc domain
 	drone.LastLocation = &loc
 	drone.LastHeartbeatAt = &now
 	drone.UpdatedAt = now
+	drone.Vitals = vitals
+	recordFix(drone, domain.Fix{Location: loc, At: now}, s.speedWindowFixes, s.speedWindow)
+	reconnected := drone.Status == domain.DroneStatusOffline
+	if reconnected {
+		drone.Status = domain.DroneStatusActive
+	}
 	if err := tx.UpdateDrone(ctx, drone); err != nil {
 		return nil, err
 	}
+	if reconnected {
+		if err := tx.EnqueueEvent(ctx, events.NewDroneEvent(events.EventDroneStatusChanged, drone, now)); err != nil {
+			return nil, err
+		}
+	}
+	// One sample per order on board, so each order's track covers the whole
+	// of a batched trip.
+	orderIDs := trackedOrders(drone)
+	if len(orderIDs) == 0 {
+		sample := &domain.TelemetrySample{DroneID: drone.ID, Location: loc, RecordedAt: now}
+		if err := tx.AppendTelemetry(ctx, sample); err != nil {
+			return nil, err
+		}
+	}
+	for _, orderID := range orderIDs {
+		sample := &domain.TelemetrySample{DroneID: drone.ID, OrderID: &orderID, Location: loc, RecordedAt: now}
+		if err := tx.AppendTelemetry(ctx, sample); err != nil {
+			return nil, err
+		}
+	}
+	grounded := drone.Status != domain.DroneStatusBroken &&
+		domain.CanTransitionDrone(drone.Status, domain.DroneStatusBroken, domain.RoleDrone)
+	if grounded && len(vitals.CriticalFaults()) > 0 {
+		// Same transaction as the heartbeat, so a stored fault code never
+		// leaves the drone assignable.
+		if err := breakDrone(ctx, tx, drone, now); err != nil {
+			return nil, err
+		}
+	}
 	if err := tx.Commit(ctx); err != nil {
 		return nil, err
 	}
+	// The heartbeat is stored by now; a failed SLA sweep must not make the
+	// drone retry it, and the next heartbeat sweeps again.
+	if err := s.checkSLAs(ctx, drone); err != nil {
+		log.Printf("heartbeat drone=%s: sla check: %v", drone.ID, err)
+	}
 
 	var orderView *OrderView
 	if drone.CurrentOrderID != nil {
@@ -399,7 +1029,15 @@ func (s *Service) DroneHeartbeat(ctx context.Context, droneID string, loc domain
 			}
 		}
 	}
-	return &DroneStatusView{Drone: drone, CurrentOrder: orderView}, nil
+	rtb, err := s.returnToBase(ctx, drone)
+	if err != nil {
+		return nil, err
+	}
+	charge, err := s.chargeAt(ctx, drone)
+	if err != nil {
+		return nil, err
+	}
+	return &DroneStatusView{Drone: drone, CurrentOrder: orderView, ReturnToBase: rtb, ChargeAt: charge}, nil
 }
 
 func (s *Service) DroneCurrentOrder(ctx context.Context, droneID string) (*OrderView, error) {
@@ -421,21 +1059,110 @@ func (s *Service) AdminListDrones(ctx context.Context) ([]*domain.Drone, error)
 	return s.store.ListDrones(ctx)
 }
 
-func (s *Service) AdminMarkDroneBroken(ctx context.Context, droneID string) (*domain.Drone, error) {
-	return s.DroneMarkBroken(ctx, droneID)
+func (s *Service) AdminMarkDroneBroken(ctx context.Context, droneID string, expectedVersion int64) (*domain.Drone, error) {
+	return s.markDroneBroken(ctx, droneID, domain.RoleAdmin, expectedVersion, nil)
 }
 
-func (s *Service) AdminMarkDroneFixed(ctx context.Context, droneID string) (*domain.Drone, error) {
-	return s.DroneMarkFixed(ctx, droneID)
+// AdminMarkDroneFixed returns a drone to ACTIVE, closing its maintenance
+// record with report.
+func (s *Service) AdminMarkDroneFixed(ctx context.Context, droneID string, report domain.MaintenanceReport, expectedVersion int64) (*domain.Drone, error) {
+	if err := domain.ValidateMaintenanceReport(report); err != nil {
+		return nil, fmt.Errorf("%v: %w", err, domain.ErrInvalid)
+	}
+	return s.setDroneStatus(ctx, droneID, domain.DroneStatusActive, domain.RoleAdmin, report, expectedVersion)
+}
+
+// AdminSetDroneStatus moves a drone to any status an admin may move it to,
+// e.g. into or out of MAINTENANCE.
+func (s *Service) AdminSetDroneStatus(ctx context.Context, droneID string, status domain.DroneStatus, expectedVersion int64) (*domain.Drone, error) {
+	return s.setDroneStatus(ctx, droneID, status, domain.RoleAdmin, domain.MaintenanceReport{}, expectedVersion)
+}
+
+// AdminRetireDrone takes a drone out of service for good. A drone holding an
+// order is domain.ErrConflict: the order has to be finished or moved first.
+func (s *Service) AdminRetireDrone(ctx context.Context, droneID string, expectedVersion int64) (*domain.Drone, error) {
+	return s.setDroneStatus(ctx, droneID, domain.DroneStatusRetired, domain.RoleAdmin, domain.MaintenanceReport{}, expectedVersion)
+}
+
+// assignOrder hands a specific order to a specific drone on an admin's behalf.
+// Locks are taken drone first, then order, mirroring DroneReserveJob.
+func (s *Service) assignOrder(ctx context.Context, orderID, droneID string, expectedVersion int64, from domain.OrderStatus, eventType string) (*domain.Order, error) {
+	if droneID == "" {
+		return nil, domain.ErrInvalid
+	}
+	tx, err := s.store.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	drone, err := tx.GetDroneForUpdate(ctx, droneID)
+	if err != nil {
+		return nil, err
+	}
+	if !drone.Status.Assignable() {
+		return nil, domain.ErrPrecondition
+	}
+	if drone.CurrentOrderID != nil {
+		return nil, domain.ErrConflict
+	}
+	order, err := tx.GetOrderForUpdate(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(expectedVersion, order.Version); err != nil {
+		return nil, err
+	}
+	if order.Status != from || order.AssignedDroneID != nil {
+		return nil, domain.ErrPrecondition
+	}
+	if flewPreviousLeg(order, drone.ID) {
+		return nil, domain.ErrConflict
+	}
+	route, err := s.planRoute(ctx, RouteStart(order), LegEnd(order))
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	order.Route = route
+	order.Status = domain.OrderStatusReserved
+	order.AssignedDroneID = &drone.ID
+	order.ReservedAt = &now
+	order.UpdatedAt = now
+	assignLeg(order, &drone.ID)
+	if err := tx.UpdateOrder(ctx, order); err != nil {
+		return nil, err
+	}
+	drone.Stops = orderStops(order)
+	drone.CurrentOrderID = &order.ID
+	drone.UpdatedAt = now
+	if err := tx.UpdateDrone(ctx, drone); err != nil {
+		return nil, err
+	}
+	if err := tx.EnqueueEvent(ctx, events.NewOrderEvent(eventType, order, drone, now)); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return order, nil
 }
 
-func (s *Service) updateOrderForDrone(ctx context.Context, droneID, orderID string, eventType string, fn func(order *domain.Order) error) (*domain.Order, error) {
+func (s *Service) updateOrderForDrone(ctx context.Context, droneID, orderID string, idem *idempotencyRequest, eventType string, fn func(order *domain.Order, drone *domain.Drone) error) (*domain.Order, error) {
 	tx, err := s.store.BeginTx(ctx)
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
+	replayed := &domain.Order{}
+	found, err := s.replay(ctx, tx, idem, replayed)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return replayed, nil
+	}
 	order, err := tx.GetOrderForUpdate(ctx, orderID)
 	if err != nil {
 		return nil, err
@@ -443,28 +1170,57 @@ func (s *Service) updateOrderForDrone(ctx context.Context, droneID, orderID stri
 	if order.AssignedDroneID == nil || *order.AssignedDroneID != droneID {
 		return nil, domain.ErrForbidden
 	}
-	if err := fn(order); err != nil {
+	drone, err := tx.GetDroneForUpdate(ctx, droneID)
+	if err != nil {
 		return nil, err
 	}
+	// Only drones flying a trip of stops have anything to record.
+	tracked := len(drone.Stops) > 0
+	if err := fn(order, drone); err != nil {
+		return nil, err
+	}
+	risk := markSLAAtRisk(order, ComputeETA(order, drone, s.eta, nil), s.now())
 	if err := tx.UpdateOrder(ctx, order); err != nil {
 		return nil, err
 	}
+	if tracked {
+		drone.UpdatedAt = order.UpdatedAt
+		if err := tx.UpdateDrone(ctx, drone); err != nil {
+			return nil, err
+		}
+	}
 	if err := tx.EnqueueEvent(ctx, events.NewOrderEvent(eventType, order, nil, s.now())); err != nil {
 		return nil, err
 	}
+	if risk != nil {
+		if err := tx.EnqueueEvent(ctx, *risk); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.remember(ctx, tx, idem, order); err != nil {
+		return nil, err
+	}
 	if err := tx.Commit(ctx); err != nil {
 		return nil, err
 	}
 	return order, nil
 }
 
-func (s *Service) completeOrderForDrone(ctx context.Context, droneID, orderID string, status domain.OrderStatus, reason string) (*domain.Order, error) {
+func (s *Service) completeOrderForDrone(ctx context.Context, droneID, orderID string, idem *idempotencyRequest, status domain.OrderStatus, reason string) (*domain.Order, error) {
 	tx, err := s.store.BeginTx(ctx)
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
+	replayed := &domain.Order{}
+	found, err := s.replay(ctx, tx, idem, replayed)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return replayed, nil
+	}
 	order, err := tx.GetOrderForUpdate(ctx, orderID)
 	if err != nil {
 		return nil, err
@@ -476,53 +1232,239 @@ func (s *Service) completeOrderForDrone(ctx context.Context, droneID, orderID st
 		return nil, domain.ErrPrecondition
 	}
 	now := s.now()
-	order.Status = status
+	// Delivering a relay leg short of the destination hands the order on at
+	// the relay point instead.
+	relayed := status == domain.OrderStatusDelivered && !finalLeg(order)
 	order.UpdatedAt = now
-	if status == domain.OrderStatusDelivered {
+	switch {
+	case relayed:
+		// completeLeg moves it on once the flight is accounted.
+	case status == domain.OrderStatusDelivered:
+		order.Status = status
 		order.DeliveredAt = &now
-	} else {
+		if cur := order.CurrentLeg(); cur >= 0 {
+			order.Legs[cur].CompletedAt = &now
+		}
+	default:
+		order.Status = status
 		order.FailedAt = &now
 		if reason != "" {
 			order.FailureReason = &reason
 		}
 	}
-	if err := tx.UpdateOrder(ctx, order); err != nil {
-		return nil, err
-	}
 	drone, err := tx.GetDroneForUpdate(ctx, droneID)
 	if err != nil {
 		return nil, err
 	}
-	drone.CurrentOrderID = nil
+	batched := len(tripOrders(drone)) > 1
+	if err := completeStop(drone, order.ID, domain.StopDropoff, status == domain.OrderStatusFailed, now); err != nil {
+		return nil, err
+	}
+	flight := orderFlight(order, drone, now)
+	if batched {
+		flight = tripFlight(drone, order, now)
+	}
+	if relayed {
+		completeLeg(order, now)
+	}
+	if err := tx.UpdateOrder(ctx, order); err != nil {
+		return nil, err
+	}
+	syncTrip(drone)
 	drone.UpdatedAt = now
+	drone.Usage = drone.Usage.Add(flight)
+	drone.SinceService = drone.SinceService.Add(flight)
+	// Taking a drone due for service into MAINTENANCE is the system's doing,
+	// so it is not subject to domain.CanTransitionDrone. A drone still
+	// carrying orders on its trip goes in once it has set them all down.
+	serviceDue := drone.CurrentOrderID == nil && drone.Status.Assignable() && s.serviceInterval.Exceeded(drone.SinceService)
+	if serviceDue {
+		if err := trackMaintenance(ctx, tx, drone, domain.DroneStatusMaintenance, domain.MaintenanceReasonServiceInterval, domain.MaintenanceReport{}, now); err != nil {
+			return nil, err
+		}
+		drone.Status = domain.DroneStatusMaintenance
+	}
 	if err := tx.UpdateDrone(ctx, drone); err != nil {
 		return nil, err
 	}
+	if serviceDue {
+		if err := tx.EnqueueEvent(ctx, events.NewDroneEvent(events.EventDroneStatusChanged, drone, now)); err != nil {
+			return nil, err
+		}
+	}
 	eventType := events.EventOrderDelivered
-	if status == domain.OrderStatusFailed {
+	switch {
+	case relayed:
+		eventType = events.EventOrderHandoffRequested
+	case status == domain.OrderStatusFailed:
 		eventType = events.EventOrderFailed
 	}
 	if err := tx.EnqueueEvent(ctx, events.NewOrderEvent(eventType, order, drone, now)); err != nil {
 		return nil, err
 	}
+	if err := s.remember(ctx, tx, idem, order); err != nil {
+		return nil, err
+	}
 	if err := tx.Commit(ctx); err != nil {
 		return nil, err
 	}
 	return order, nil
 }
 
+func (s *Service) listOrders(ctx context.Context, filter OrderFilter) (*OrderPage, error) {
+	if err := filter.validate(); err != nil {
+		return nil, err
+	}
+	after, err := decodeOrderCursor(filter.Sort, filter.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	filter.After = after
+	limit := normalizeListLimit(filter.Limit)
+	// Fetch one extra row to learn whether another page exists.
+	filter.Limit = limit + 1
+	orders, err := s.store.ListOrders(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	page := &OrderPage{}
+	if len(orders) > limit {
+		orders = orders[:limit]
+		page.NextCursor = encodeOrderCursor(filter.Sort, orders[len(orders)-1])
+	}
+	page.Orders, err = s.buildOrderViews(ctx, orders)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
 func (s *Service) buildOrderView(ctx context.Context, order *domain.Order) (*OrderView, error) {
-	var drone *domain.Drone
-	if order.AssignedDroneID != nil {
-		var err error
-		drone, err = s.store.GetDrone(ctx, *order.AssignedDroneID)
-		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+	views, err := s.buildOrderViews(ctx, []*domain.Order{order})
+	if err != nil {
+		return nil, err
+	}
+	return views[0], nil
+}
+
+// buildOrderViews resolves every assigned drone for a page of orders with a
+// single GetDrones call instead of one GetDrone per order.
+func (s *Service) buildOrderViews(ctx context.Context, orders []*domain.Order) ([]*OrderView, error) {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, order := range orders {
+		if order.AssignedDroneID != nil && !seen[*order.AssignedDroneID] {
+			seen[*order.AssignedDroneID] = true
+			ids = append(ids, *order.AssignedDroneID)
+		}
+	}
+	drones := make(map[string]*domain.Drone, len(ids))
+	if len(ids) > 0 {
+		list, err := s.store.GetDrones(ctx, ids)
+		if err != nil {
 			return nil, err
 		}
+		for _, drone := range list {
+			drones[drone.ID] = drone
+		}
+	}
+	queues, err := s.queueStates(ctx, orders)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]*OrderView, 0, len(orders))
+	for _, order := range orders {
+		var drone *domain.Drone
+		if order.AssignedDroneID != nil {
+			drone = drones[*order.AssignedDroneID]
+		}
+		views = append(views, s.orderView(order, drone, queues[order.ID]))
+	}
+	return views, nil
+}
+
+// queueStates finds the place in the dispatch queue of each waiting order
+// among orders. The active drones are counted once for the whole batch.
+func (s *Service) queueStates(ctx context.Context, orders []*domain.Order) (map[string]*QueueState, error) {
+	queues := make(map[string]*QueueState)
+	var queued []string
+	for _, order := range orders {
+		if containsStatus(queuedOrderStatuses, order.Status) {
+			queued = append(queued, order.ID)
+		}
+	}
+	if len(queued) == 0 {
+		return queues, nil
+	}
+	list, err := s.store.ListDrones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	activeDrones := 0
+	for _, drone := range list {
+		if drone.Status.Assignable() {
+			activeDrones++
+		}
+	}
+	positions, err := s.store.QueuePositions(ctx, queuedOrderStatuses, queued)
+	if err != nil {
+		return nil, err
+	}
+	for _, order := range orders {
+		if !containsStatus(queuedOrderStatuses, order.Status) {
+			continue
+		}
+		// An order reserved since it was read is missing; it waits on nothing.
+		queue := &QueueState{Ahead: positions[order.ID], ActiveDrones: activeDrones}
+		if order.PickupNotBefore != nil {
+			queue.UntilPickup = max(order.PickupNotBefore.Sub(s.now()), 0)
+		}
+		queues[order.ID] = queue
+	}
+	return queues, nil
+}
+
+func (s *Service) orderView(order *domain.Order, drone *domain.Drone, queue *QueueState) *OrderView {
+	view := &OrderView{Order: order, CurrentLocation: CurrentLocation(order, drone)}
+	if eta := ComputeETA(order, drone, s.eta, queue); eta != nil {
+		view.ETASeconds = &eta.Seconds
+		view.ETALegs = eta.Legs
+		view.ETASource = eta.Source
+		view.ETAConfidence = eta.Confidence
+	}
+	return view
+}
+
+// sameDrone reports whether a and b name the same drone, or both none.
+func sameDrone(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// requeueOrder releases a reserved order back to the dispatch queue. Orders
+// that were reserved from a handoff go back to HANDOFF_REQUESTED so the next
+// drone still collects the package from the handoff point.
+func requeueOrder(order *domain.Order, now time.Time) {
+	if order.HandoffOrigin != nil {
+		order.Status = domain.OrderStatusHandoffRequested
+	} else {
+		order.Status = domain.OrderStatusCreated
+	}
+	order.AssignedDroneID = nil
+	order.ReservedAt = nil
+	order.UpdatedAt = now
+	assignLeg(order, nil)
+}
+
+// checkVersion enforces a caller's expected version (If-Match). Zero means the
+// caller did not send one and the write is unconditional.
+func checkVersion(expected, actual int64) error {
+	if expected != 0 && expected != actual {
+		return domain.ErrVersionMismatch
 	}
-	eta := ComputeETA(order, drone, s.speed)
-	loc := CurrentLocation(order, drone)
-	return &OrderView{Order: order, CurrentLocation: loc, ETASeconds: eta}, nil
+	return nil
 }
 
 func getOrCreateDrone(ctx context.Context, tx Tx, droneID string, now time.Time) (*domain.Drone, error) {
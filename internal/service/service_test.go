@@ -1,185 +1,41 @@
-package service
+package service_test
 
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
 	"sync"
 	"testing"
 	"time"
 
 	"penny-assesment/internal/domain"
-	"penny-assesment/internal/events"
+	"penny-assesment/internal/repo/memory"
+	"penny-assesment/internal/service"
 )
 
-type memStore struct {
-	mu     sync.Mutex
-	orders map[string]*domain.Order
-	drones map[string]*domain.Drone
-}
-
-type memTx struct {
-	store  *memStore
-	closed bool
-}
-
-func newMemStore() *memStore {
-	return &memStore{
-		orders: make(map[string]*domain.Order),
-		drones: make(map[string]*domain.Drone),
-	}
-}
-
-func (m *memStore) BeginTx(ctx context.Context) (Tx, error) {
-	m.mu.Lock()
-	return &memTx{store: m}, nil
-}
-
-func (m *memStore) GetOrder(ctx context.Context, id string) (*domain.Order, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	order, ok := m.orders[id]
-	if !ok {
-		return nil, domain.ErrNotFound
-	}
-	copy := *order
-	return &copy, nil
-}
-
-func (m *memStore) ListOrders(ctx context.Context, filter OrderFilter) ([]*domain.Order, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	var orders []*domain.Order
-	for _, order := range m.orders {
-		if filter.Status != nil && order.Status != *filter.Status {
-			continue
-		}
-		copy := *order
-		orders = append(orders, &copy)
-	}
-	return orders, nil
-}
-
-func (m *memStore) CreateOrder(ctx context.Context, order *domain.Order) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	if _, ok := m.orders[order.ID]; ok {
-		return domain.ErrConflict
-	}
-	copy := *order
-	m.orders[order.ID] = &copy
-	return nil
-}
-
-func (m *memStore) GetDrone(ctx context.Context, id string) (*domain.Drone, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	drone, ok := m.drones[id]
-	if !ok {
-		return nil, domain.ErrNotFound
-	}
-	copy := *drone
-	return &copy, nil
-}
-
-func (m *memStore) ListDrones(ctx context.Context) ([]*domain.Drone, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	var drones []*domain.Drone
-	for _, drone := range m.drones {
-		copy := *drone
-		drones = append(drones, &copy)
-	}
-	return drones, nil
-}
-
-func (t *memTx) Commit(ctx context.Context) error {
-	return t.close()
-}
-
-func (t *memTx) Rollback(ctx context.Context) error {
-	return t.close()
-}
-
-func (t *memTx) close() error {
-	if t.closed {
-		return nil
-	}
-	t.closed = true
-	t.store.mu.Unlock()
-	return nil
-}
-
-func (t *memTx) GetOrderForUpdate(ctx context.Context, id string) (*domain.Order, error) {
-	order, ok := t.store.orders[id]
-	if !ok {
-		return nil, domain.ErrNotFound
-	}
-	copy := *order
-	return &copy, nil
-}
-
-func (t *memTx) GetDroneForUpdate(ctx context.Context, id string) (*domain.Drone, error) {
-	drone, ok := t.store.drones[id]
-	if !ok {
-		return nil, domain.ErrNotFound
+// putDrone and putOrder seed committed rows through a transaction, the same
+// way the service writes them.
+func putDrone(tb testing.TB, store *memory.Store, drone *domain.Drone) {
+	tb.Helper()
+	ctx := context.Background()
+	tx, err := store.BeginTx(ctx)
+	if err != nil {
+		tb.Fatalf("begin: %v", err)
 	}
-	copy := *drone
-	return &copy, nil
-}
-
-func (t *memTx) CreateDrone(ctx context.Context, drone *domain.Drone) error {
-	if _, ok := t.store.drones[drone.ID]; ok {
-		return domain.ErrConflict
+	if err := tx.CreateDrone(ctx, drone); err != nil {
+		tb.Fatalf("create drone: %v", err)
 	}
-	copy := *drone
-	t.store.drones[drone.ID] = &copy
-	return nil
-}
-
-func (t *memTx) CreateOrder(ctx context.Context, order *domain.Order) error {
-	if _, ok := t.store.orders[order.ID]; ok {
-		return domain.ErrConflict
+	if err := tx.Commit(ctx); err != nil {
+		tb.Fatalf("commit: %v", err)
 	}
-	copy := *order
-	t.store.orders[order.ID] = &copy
-	return nil
-}
-
-func (t *memTx) UpdateOrder(ctx context.Context, order *domain.Order) error {
-	copy := *order
-	t.store.orders[order.ID] = &copy
-	return nil
 }
 
-func (t *memTx) UpdateDrone(ctx context.Context, drone *domain.Drone) error {
-	copy := *drone
-	t.store.drones[drone.ID] = &copy
-	return nil
-}
-
-func (t *memTx) ReserveNextOrder(ctx context.Context, allowed []domain.OrderStatus) (*domain.Order, error) {
-	allowedSet := map[domain.OrderStatus]bool{}
-	for _, status := range allowed {
-		allowedSet[status] = true
-	}
-	var selected *domain.Order
-	for _, order := range t.store.orders {
-		if order.AssignedDroneID != nil {
-			continue
-		}
-		if !allowedSet[order.Status] {
-			continue
-		}
-		if selected == nil || order.CreatedAt.Before(selected.CreatedAt) {
-			copy := *order
-			selected = &copy
-		}
+func putOrder(tb testing.TB, store *memory.Store, order *domain.Order) {
+	tb.Helper()
+	if err := store.CreateOrder(context.Background(), order); err != nil {
+		tb.Fatalf("create order: %v", err)
 	}
-	return selected, nil
-}
-
-func (t *memTx) EnqueueEvent(ctx context.Context, event events.Event) error {
-	return nil
 }
 
 func TestComputeETA(t *testing.T) {
@@ -190,8 +46,9 @@ func TestComputeETA(t *testing.T) {
 		Destination: domain.Location{Lat: 24.7743, Lng: 46.7386},
 		Status:      domain.OrderStatusCreated,
 	}
-	eta := ComputeETA(order, nil, 10)
-	if eta == nil || *eta <= 0 {
+	model := service.ETAModel{SpeedMPS: 10}
+	eta := service.ComputeETA(order, nil, model, nil)
+	if eta == nil || eta.Seconds <= 0 {
 		t.Fatalf("expected ETA to be positive")
 	}
 
@@ -199,21 +56,21 @@ func TestComputeETA(t *testing.T) {
 	handoff := domain.Location{Lat: 24.72, Lng: 46.68}
 	order.HandoffOrigin = &handoff
 	order.Status = domain.OrderStatusReserved
-	eta = ComputeETA(order, nil, 10)
-	if eta == nil || *eta <= 0 {
+	eta = service.ComputeETA(order, nil, model, nil)
+	if eta == nil || eta.Seconds <= 0 {
 		t.Fatalf("expected ETA to be positive for reserved handoff")
 	}
 
 	order.Status = domain.OrderStatusDelivered
-	eta = ComputeETA(order, nil, 10)
+	eta = service.ComputeETA(order, nil, model, nil)
 	if eta != nil {
 		t.Fatalf("expected ETA to be nil for delivered order")
 	}
 }
 
 func TestReserveJobConcurrency(t *testing.T) {
-	store := newMemStore()
-	svc := New(store, 10)
+	store := memory.NewStore()
+	svc := service.New(store, 10)
 	now := time.Now().UTC()
 	order := &domain.Order{
 		ID:          "o1",
@@ -233,12 +90,12 @@ func TestReserveJobConcurrency(t *testing.T) {
 	results := make(chan error, 2)
 	go func() {
 		defer wg.Done()
-		_, err := svc.DroneReserveJob(context.Background(), "drone-a")
+		_, err := svc.DroneReserveJob(context.Background(), "drone-a", "")
 		results <- err
 	}()
 	go func() {
 		defer wg.Done()
-		_, err := svc.DroneReserveJob(context.Background(), "drone-b")
+		_, err := svc.DroneReserveJob(context.Background(), "drone-b", "")
 		results <- err
 	}()
 	wg.Wait()
@@ -260,19 +117,19 @@ func TestReserveJobConcurrency(t *testing.T) {
 }
 
 func TestWithdrawClearsDroneAssignment(t *testing.T) {
-	store := newMemStore()
-	svc := New(store, 10)
+	store := memory.NewStore()
+	svc := service.New(store, 10)
 	now := time.Now().UTC()
 	droneID := "drone-1"
 	orderID := "order-1"
-	store.drones[droneID] = &domain.Drone{
+	putDrone(t, store, &domain.Drone{
 		ID:             droneID,
 		Status:         domain.DroneStatusActive,
 		CurrentOrderID: &orderID,
 		CreatedAt:      now,
 		UpdatedAt:      now,
-	}
-	store.orders[orderID] = &domain.Order{
+	})
+	putOrder(t, store, &domain.Order{
 		ID:              orderID,
 		UserID:          "user-1",
 		Origin:          domain.Location{Lat: 1, Lng: 1},
@@ -281,9 +138,9 @@ func TestWithdrawClearsDroneAssignment(t *testing.T) {
 		AssignedDroneID: &droneID,
 		CreatedAt:       now,
 		UpdatedAt:       now,
-	}
+	})
 
-	order, err := svc.WithdrawOrder(context.Background(), "user-1", orderID)
+	order, err := svc.WithdrawOrder(context.Background(), "user-1", orderID, 0)
 	if err != nil {
 		t.Fatalf("withdraw: %v", err)
 	}
@@ -300,21 +157,21 @@ func TestWithdrawClearsDroneAssignment(t *testing.T) {
 }
 
 func TestMarkBrokenCreatesHandoff(t *testing.T) {
-	store := newMemStore()
-	svc := New(store, 10)
+	store := memory.NewStore()
+	svc := service.New(store, 10)
 	now := time.Now().UTC()
 	droneID := "drone-1"
 	orderID := "order-1"
 	loc := &domain.Location{Lat: 5, Lng: 6}
-	store.drones[droneID] = &domain.Drone{
+	putDrone(t, store, &domain.Drone{
 		ID:             droneID,
 		Status:         domain.DroneStatusActive,
 		CurrentOrderID: &orderID,
 		LastLocation:   loc,
 		CreatedAt:      now,
 		UpdatedAt:      now,
-	}
-	store.orders[orderID] = &domain.Order{
+	})
+	putOrder(t, store, &domain.Order{
 		ID:              orderID,
 		UserID:          "user-1",
 		Origin:          domain.Location{Lat: 1, Lng: 1},
@@ -323,9 +180,9 @@ func TestMarkBrokenCreatesHandoff(t *testing.T) {
 		AssignedDroneID: &droneID,
 		CreatedAt:       now,
 		UpdatedAt:       now,
-	}
+	})
 
-	drone, err := svc.DroneMarkBroken(context.Background(), droneID)
+	drone, err := svc.DroneMarkBroken(context.Background(), droneID, "")
 	if err != nil {
 		t.Fatalf("mark broken: %v", err)
 	}
@@ -348,22 +205,22 @@ func TestMarkBrokenCreatesHandoff(t *testing.T) {
 }
 
 func TestMarkBroken_RequeuesReservedOrder(t *testing.T) {
-	store := newMemStore()
-	svc := New(store, 10)
+	store := memory.NewStore()
+	svc := service.New(store, 10)
 	now := time.Now().UTC()
 	droneID := "drone-1"
 	orderID := "order-1"
 	loc := &domain.Location{Lat: 5, Lng: 6}
-	store.drones[droneID] = &domain.Drone{
+	putDrone(t, store, &domain.Drone{
 		ID:             droneID,
 		Status:         domain.DroneStatusActive,
 		CurrentOrderID: &orderID,
 		LastLocation:   loc,
 		CreatedAt:      now,
 		UpdatedAt:      now,
-	}
+	})
 	reservedAt := now
-	store.orders[orderID] = &domain.Order{
+	putOrder(t, store, &domain.Order{
 		ID:              orderID,
 		UserID:          "user-1",
 		Origin:          domain.Location{Lat: 1, Lng: 1},
@@ -374,9 +231,9 @@ func TestMarkBroken_RequeuesReservedOrder(t *testing.T) {
 		CreatedAt:       now,
 		UpdatedAt:       now,
 		HandoffOrigin:   &domain.Location{Lat: 99, Lng: 99},
-	}
+	})
 
-	_, err := svc.DroneMarkBroken(context.Background(), droneID)
+	_, err := svc.DroneMarkBroken(context.Background(), droneID, "")
 	if err != nil {
 		t.Fatalf("mark broken: %v", err)
 	}
@@ -401,3 +258,1727 @@ func TestMarkBroken_RequeuesReservedOrder(t *testing.T) {
 		t.Fatalf("expected drone current order cleared")
 	}
 }
+
+func TestAdminAssignOrder(t *testing.T) {
+	store := memory.NewStore()
+	svc := service.New(store, 10)
+	now := time.Now().UTC()
+	putDrone(t, store, &domain.Drone{ID: "drone-1", Status: domain.DroneStatusActive, CreatedAt: now, UpdatedAt: now})
+	putDrone(t, store, &domain.Drone{ID: "drone-2", Status: domain.DroneStatusBroken, CreatedAt: now, UpdatedAt: now})
+	putOrder(t, store, &domain.Order{
+		ID:          "order-1",
+		UserID:      "user-1",
+		Origin:      domain.Location{Lat: 1, Lng: 1},
+		Destination: domain.Location{Lat: 2, Lng: 2},
+		Status:      domain.OrderStatusCreated,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+
+	if _, err := svc.AdminAssignOrder(context.Background(), "order-1", "drone-2", 0); !errors.Is(err, domain.ErrPrecondition) {
+		t.Fatalf("expected precondition for broken drone, got %v", err)
+	}
+	order, err := svc.AdminAssignOrder(context.Background(), "order-1", "drone-1", 0)
+	if err != nil {
+		t.Fatalf("assign: %v", err)
+	}
+	if order.Status != domain.OrderStatusReserved || order.AssignedDroneID == nil || *order.AssignedDroneID != "drone-1" {
+		t.Fatalf("expected order reserved by drone-1")
+	}
+	drone, _ := store.GetDrone(context.Background(), "drone-1")
+	if drone.CurrentOrderID == nil || *drone.CurrentOrderID != "order-1" {
+		t.Fatalf("expected drone current order set")
+	}
+
+	order, err = svc.AdminUnassignOrder(context.Background(), "order-1", 0)
+	if err != nil {
+		t.Fatalf("unassign: %v", err)
+	}
+	if order.Status != domain.OrderStatusCreated || order.AssignedDroneID != nil || order.ReservedAt != nil {
+		t.Fatalf("expected order requeued as CREATED")
+	}
+	drone, _ = store.GetDrone(context.Background(), "drone-1")
+	if drone.CurrentOrderID != nil {
+		t.Fatalf("expected drone current order cleared")
+	}
+}
+
+func TestAdminReassignHandoffOrder(t *testing.T) {
+	store := memory.NewStore()
+	svc := service.New(store, 10)
+	now := time.Now().UTC()
+	busyOrderID := "order-2"
+	putDrone(t, store, &domain.Drone{ID: "drone-1", Status: domain.DroneStatusActive, CurrentOrderID: &busyOrderID, CreatedAt: now, UpdatedAt: now})
+	putDrone(t, store, &domain.Drone{ID: "drone-2", Status: domain.DroneStatusActive, CreatedAt: now, UpdatedAt: now})
+	putOrder(t, store, &domain.Order{
+		ID:            "order-1",
+		UserID:        "user-1",
+		Origin:        domain.Location{Lat: 1, Lng: 1},
+		Destination:   domain.Location{Lat: 2, Lng: 2},
+		Status:        domain.OrderStatusHandoffRequested,
+		HandoffOrigin: &domain.Location{Lat: 1.5, Lng: 1.5},
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+
+	if _, err := svc.AdminAssignOrder(context.Background(), "order-1", "drone-2", 0); !errors.Is(err, domain.ErrPrecondition) {
+		t.Fatalf("expected assign to reject handoff order, got %v", err)
+	}
+	if _, err := svc.AdminReassignOrder(context.Background(), "order-1", "drone-1", 0); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict for busy drone, got %v", err)
+	}
+	if _, err := svc.AdminReassignOrder(context.Background(), "order-1", "drone-2", 0); err != nil {
+		t.Fatalf("reassign: %v", err)
+	}
+	order, err := svc.AdminUnassignOrder(context.Background(), "order-1", 0)
+	if err != nil {
+		t.Fatalf("unassign: %v", err)
+	}
+	if order.Status != domain.OrderStatusHandoffRequested || order.HandoffOrigin == nil {
+		t.Fatalf("expected order back in HANDOFF_REQUESTED with handoff origin, got %s", order.Status)
+	}
+}
+
+func TestAdminForceTransitionReleasesDrone(t *testing.T) {
+	store := memory.NewStore()
+	svc := service.New(store, 10)
+	now := time.Now().UTC()
+	droneID := "drone-1"
+	orderID := "order-1"
+	putDrone(t, store, &domain.Drone{
+		ID:             droneID,
+		Status:         domain.DroneStatusActive,
+		CurrentOrderID: &orderID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	})
+	putOrder(t, store, &domain.Order{
+		ID:              orderID,
+		UserID:          "user-1",
+		Origin:          domain.Location{Lat: 1, Lng: 1},
+		Destination:     domain.Location{Lat: 2, Lng: 2},
+		Status:          domain.OrderStatusPickedUp,
+		AssignedDroneID: &droneID,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	})
+
+	if _, err := svc.AdminForceTransition(context.Background(), "admin", orderID, domain.OrderStatusDelivered, " ", 0); !errors.Is(err, domain.ErrInvalid) {
+		t.Fatalf("expected invalid without reason, got %v", err)
+	}
+	if _, err := svc.AdminForceTransition(context.Background(), "admin", orderID, domain.OrderStatusWithdrawn, "nope", 0); !errors.Is(err, domain.ErrInvalid) {
+		t.Fatalf("expected invalid target status, got %v", err)
+	}
+	order, err := svc.AdminForceTransition(context.Background(), "admin", orderID, domain.OrderStatusDelivered, "recovered by hand", 0)
+	if err != nil {
+		t.Fatalf("override: %v", err)
+	}
+	if order.Status != domain.OrderStatusDelivered || order.DeliveredAt == nil {
+		t.Fatalf("expected delivered order")
+	}
+	drone, _ := store.GetDrone(context.Background(), droneID)
+	if drone.CurrentOrderID != nil {
+		t.Fatalf("expected drone current order cleared")
+	}
+	if _, err := svc.AdminForceTransition(context.Background(), "admin", orderID, domain.OrderStatusCreated, "again", 0); !errors.Is(err, domain.ErrPrecondition) {
+		t.Fatalf("expected precondition for terminal order, got %v", err)
+	}
+}
+
+func TestListMyOrdersCursorPagination(t *testing.T) {
+	store := memory.NewStore()
+	svc := service.New(store, 10)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		id := fmt.Sprintf("order-%d", i)
+		putOrder(t, store, &domain.Order{
+			ID:        id,
+			UserID:    "user-1",
+			Status:    domain.OrderStatusCreated,
+			CreatedAt: base.Add(time.Duration(i/2) * time.Minute),
+			UpdatedAt: base,
+		})
+	}
+	putOrder(t, store, &domain.Order{ID: "other", UserID: "user-2", Status: domain.OrderStatusCreated, CreatedAt: base, UpdatedAt: base})
+
+	var seen []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatalf("pagination did not terminate")
+		}
+		page, err := svc.ListMyOrders(context.Background(), "user-1", service.OrderFilter{Limit: 2, Cursor: cursor})
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		for _, view := range page.Orders {
+			seen = append(seen, view.Order.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	want := []string{"order-0", "order-1", "order-2", "order-3", "order-4"}
+	if fmt.Sprint(seen) != fmt.Sprint(want) {
+		t.Fatalf("expected %v, got %v", want, seen)
+	}
+
+	if _, err := svc.ListMyOrders(context.Background(), "user-1", service.OrderFilter{Cursor: "not-a-cursor"}); !errors.Is(err, domain.ErrInvalid) {
+		t.Fatalf("expected invalid cursor error, got %v", err)
+	}
+}
+
+func TestAdminListOrdersFilters(t *testing.T) {
+	store := memory.NewStore()
+	svc := service.New(store, 10)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	droneID := "drone-1"
+	reason := "Low battery over water"
+	putOrder(t, store, &domain.Order{ID: "a", UserID: "u1", Origin: domain.Location{Lat: 10, Lng: 179}, Status: domain.OrderStatusCreated, CreatedAt: base, UpdatedAt: base.Add(3 * time.Hour)})
+	putOrder(t, store, &domain.Order{ID: "b", UserID: "u1", Origin: domain.Location{Lat: 10, Lng: -179}, Status: domain.OrderStatusReserved, AssignedDroneID: &droneID, CreatedAt: base.Add(time.Hour), UpdatedAt: base.Add(time.Hour)})
+	putOrder(t, store, &domain.Order{ID: "c", UserID: "u2", Origin: domain.Location{Lat: 10, Lng: 0}, Status: domain.OrderStatusFailed, FailureReason: &reason, CreatedAt: base.Add(2 * time.Hour), UpdatedAt: base.Add(2 * time.Hour)})
+
+	ids := func(page *service.OrderPage) string {
+		var out []string
+		for _, view := range page.Orders {
+			out = append(out, view.Order.ID)
+		}
+		return fmt.Sprint(out)
+	}
+
+	page, err := svc.AdminListOrders(context.Background(), service.OrderFilter{Statuses: []domain.OrderStatus{domain.OrderStatusCreated, domain.OrderStatusFailed}})
+	if err != nil || ids(page) != "[a c]" {
+		t.Fatalf("statuses: got %v err=%v", ids(page), err)
+	}
+	page, err = svc.AdminListOrders(context.Background(), service.OrderFilter{DroneID: &droneID})
+	if err != nil || ids(page) != "[b]" {
+		t.Fatalf("drone: got %v err=%v", ids(page), err)
+	}
+	page, err = svc.AdminListOrders(context.Background(), service.OrderFilter{FailureReason: "battery"})
+	if err != nil || ids(page) != "[c]" {
+		t.Fatalf("failure reason: got %v err=%v", ids(page), err)
+	}
+	// A box spanning the antimeridian includes both 179 and -179 but not 0.
+	page, err = svc.AdminListOrders(context.Background(), service.OrderFilter{OriginBox: &domain.BoundingBox{MinLat: 0, MinLng: 170, MaxLat: 20, MaxLng: -170}})
+	if err != nil || ids(page) != "[a b]" {
+		t.Fatalf("origin box: got %v err=%v", ids(page), err)
+	}
+	page, err = svc.AdminListOrders(context.Background(), service.OrderFilter{Sort: service.OrderSortUpdatedDesc, Limit: 2})
+	if err != nil || ids(page) != "[a c]" || page.NextCursor == "" {
+		t.Fatalf("sort: got %v err=%v", ids(page), err)
+	}
+	if _, err := svc.AdminListOrders(context.Background(), service.OrderFilter{Sort: service.OrderSortCreatedAsc, Cursor: page.NextCursor}); !errors.Is(err, domain.ErrInvalid) {
+		t.Fatalf("expected cursor from another sort to be rejected, got %v", err)
+	}
+	page, err = svc.AdminListOrders(context.Background(), service.OrderFilter{Sort: service.OrderSortUpdatedDesc, Limit: 2, Cursor: page.NextCursor})
+	if err != nil || ids(page) != "[b]" {
+		t.Fatalf("sort page 2: got %v err=%v", ids(page), err)
+	}
+}
+
+// countingStore counts drone and queue lookups so tests can assert view
+// building does not issue one query per order.
+type countingStore struct {
+	*memory.Store
+	getDrone       int
+	getDrones      int
+	countOrders    int
+	queuePositions int
+}
+
+func (c *countingStore) GetDrone(ctx context.Context, id string) (*domain.Drone, error) {
+	c.getDrone++
+	return c.Store.GetDrone(ctx, id)
+}
+
+func (c *countingStore) GetDrones(ctx context.Context, ids []string) ([]*domain.Drone, error) {
+	c.getDrones++
+	return c.Store.GetDrones(ctx, ids)
+}
+
+func (c *countingStore) CountOrders(ctx context.Context, filter service.OrderFilter) (int, error) {
+	c.countOrders++
+	return c.Store.CountOrders(ctx, filter)
+}
+
+func (c *countingStore) QueuePositions(ctx context.Context, statuses []domain.OrderStatus, ids []string) (map[string]int, error) {
+	c.queuePositions++
+	return c.Store.QueuePositions(ctx, statuses, ids)
+}
+
+func seedAssignedOrders(t testing.TB, store *memory.Store, n int) {
+	now := time.Now().UTC()
+	for i := 0; i < n; i++ {
+		droneID := fmt.Sprintf("drone-%d", i)
+		orderID := fmt.Sprintf("order-%03d", i)
+		putDrone(t, store, &domain.Drone{ID: droneID, Status: domain.DroneStatusActive, CurrentOrderID: &orderID, LastLocation: &domain.Location{Lat: 1.5, Lng: 1.5}, CreatedAt: now, UpdatedAt: now})
+		putOrder(t, store, &domain.Order{
+			ID:              orderID,
+			UserID:          "user-1",
+			Origin:          domain.Location{Lat: 1, Lng: 1},
+			Destination:     domain.Location{Lat: 2, Lng: 2},
+			Status:          domain.OrderStatusPickedUp,
+			AssignedDroneID: &droneID,
+			CreatedAt:       now.Add(time.Duration(i) * time.Second),
+			UpdatedAt:       now,
+		})
+	}
+}
+
+func seedQueuedOrders(t testing.TB, store *memory.Store, n int) {
+	now := time.Now().UTC()
+	for i := 0; i < n; i++ {
+		putOrder(t, store, &domain.Order{
+			ID:          fmt.Sprintf("queued-%03d", i),
+			UserID:      "user-1",
+			Origin:      domain.Location{Lat: 1, Lng: 1},
+			Destination: domain.Location{Lat: 2, Lng: 2},
+			Status:      domain.OrderStatusCreated,
+			CreatedAt:   now.Add(time.Duration(i) * time.Second),
+			UpdatedAt:   now,
+		})
+	}
+}
+
+func TestAdminListOrdersBatchesDroneLookups(t *testing.T) {
+	store := &countingStore{Store: memory.NewStore()}
+	seedAssignedOrders(t, store.Store, 50)
+	seedQueuedOrders(t, store.Store, 20)
+	svc := service.New(store, 10)
+
+	page, err := svc.AdminListOrders(context.Background(), service.OrderFilter{})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(page.Orders) != 70 {
+		t.Fatalf("expected 70 orders, got %d", len(page.Orders))
+	}
+	if store.getDrone != 0 || store.getDrones != 1 {
+		t.Fatalf("expected a single batched drone lookup, got GetDrone=%d GetDrones=%d", store.getDrone, store.getDrones)
+	}
+	if store.countOrders != 0 || store.queuePositions != 1 {
+		t.Fatalf("expected a single queue lookup, got CountOrders=%d QueuePositions=%d", store.countOrders, store.queuePositions)
+	}
+	for _, view := range page.Orders {
+		if view.ETASeconds == nil {
+			t.Fatalf("expected an ETA for %s", view.Order.ID)
+		}
+		if view.Order.Status == domain.OrderStatusPickedUp && view.CurrentLocation == nil {
+			t.Fatalf("expected drone-derived location for %s", view.Order.ID)
+		}
+	}
+}
+
+func BenchmarkAdminListOrders(b *testing.B) {
+	store := &countingStore{Store: memory.NewStore()}
+	seedAssignedOrders(b, store.Store, 250)
+	seedQueuedOrders(b, store.Store, 250)
+	svc := service.New(store, 10)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := svc.AdminListOrders(ctx, service.OrderFilter{Limit: 500}); err != nil {
+			b.Fatalf("list: %v", err)
+		}
+	}
+	b.StopTimer()
+	if store.countOrders != 0 || store.queuePositions != b.N {
+		b.Fatalf("expected one queue lookup per list, got CountOrders=%d QueuePositions=%d over %d lists", store.countOrders, store.queuePositions, b.N)
+	}
+	b.ReportMetric(float64(store.getDrone+store.getDrones)/float64(b.N), "drone-calls/op")
+	b.ReportMetric(float64(store.countOrders+store.queuePositions)/float64(b.N), "queue-calls/op")
+}
+
+func TestAdminUpdateOrderExpectedVersion(t *testing.T) {
+	store := memory.NewStore()
+	svc := service.New(store, 10)
+	now := time.Now().UTC()
+	putOrder(t, store, &domain.Order{
+		ID:          "order-1",
+		UserID:      "user-1",
+		Origin:      domain.Location{Lat: 1, Lng: 1},
+		Destination: domain.Location{Lat: 2, Lng: 2},
+		Status:      domain.OrderStatusCreated,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+
+	dest := domain.Location{Lat: 3, Lng: 3}
+	order, err := svc.AdminUpdateOrder(context.Background(), "order-1", nil, &dest, 1)
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if order.Version != 2 {
+		t.Fatalf("expected version 2, got %d", order.Version)
+	}
+	if _, err := svc.AdminUpdateOrder(context.Background(), "order-1", nil, &dest, 1); !errors.Is(err, domain.ErrVersionMismatch) {
+		t.Fatalf("expected version mismatch for stale version, got %v", err)
+	}
+	if _, err := svc.AdminUpdateOrder(context.Background(), "order-1", nil, &dest, 0); err != nil {
+		t.Fatalf("unconditional update: %v", err)
+	}
+}
+
+func TestSubmitOrderIdempotencyKey(t *testing.T) {
+	store := memory.NewStore()
+	svc := service.New(store, 10)
+	ctx := context.Background()
+	origin := domain.Location{Lat: 1, Lng: 1}
+	dest := domain.Location{Lat: 2, Lng: 2}
+
+	first, err := svc.SubmitOrder(ctx, "user-1", origin, dest, service.DeliveryWindow{}, "key-1")
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	retry, err := svc.SubmitOrder(ctx, "user-1", origin, dest, service.DeliveryWindow{}, "key-1")
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if retry.ID != first.ID {
+		t.Fatalf("expected retry to return order %s, got %s", first.ID, retry.ID)
+	}
+	other, err := svc.SubmitOrder(ctx, "user-2", origin, dest, service.DeliveryWindow{}, "key-1")
+	if err != nil {
+		t.Fatalf("submit as other user: %v", err)
+	}
+	if other.ID == first.ID {
+		t.Fatalf("expected keys to be scoped per user")
+	}
+	page, err := svc.ListMyOrders(ctx, "user-1", service.OrderFilter{})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(page.Orders) != 1 {
+		t.Fatalf("expected one order for user-1, got %d", len(page.Orders))
+	}
+	pending, err := store.FetchPending(ctx, 10)
+	if err != nil {
+		t.Fatalf("fetch pending: %v", err)
+	}
+	if len(pending) != 2 {
+		t.Fatalf("expected one created event per order, got %d", len(pending))
+	}
+
+	if _, err := svc.SubmitOrder(ctx, "user-1", origin, domain.Location{Lat: 3, Lng: 3}, service.DeliveryWindow{}, "key-1"); !errors.Is(err, domain.ErrIdempotencyKeyReused) {
+		t.Fatalf("expected key reuse with a different request to fail, got %v", err)
+	}
+
+	// A retry still gets its order once a service area leaves it outside.
+	boundary := domain.Polygon{{{Lat: 10, Lng: 10}, {Lat: 10, Lng: 11}, {Lat: 11, Lng: 11}, {Lat: 11, Lng: 10}, {Lat: 10, Lng: 10}}}
+	if _, err := svc.AdminCreateServiceArea(ctx, "Elsewhere", boundary, true); err != nil {
+		t.Fatalf("create service area: %v", err)
+	}
+	retry, err = svc.SubmitOrder(ctx, "user-1", origin, dest, service.DeliveryWindow{}, "key-1")
+	if err != nil || retry.ID != first.ID {
+		t.Fatalf("expected retry outside the service area to return order %s, got %v err=%v", first.ID, retry, err)
+	}
+
+	svc.SetIdempotencyTTL(0)
+	if _, err := svc.SubmitOrder(ctx, "user-1", domain.Location{Lat: 10.5, Lng: 10.5}, domain.Location{Lat: 10.6, Lng: 10.6}, service.DeliveryWindow{}, "key-2"); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if pruned, err := svc.PruneIdempotencyRecords(ctx); err != nil || pruned != 1 {
+		t.Fatalf("expected the expired key pruned, got %d err=%v", pruned, err)
+	}
+}
+
+func TestDroneDeliverIdempotentRetry(t *testing.T) {
+	store := memory.NewStore()
+	svc := service.New(store, 10)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	droneID := "drone-1"
+	orderID := "order-1"
+	putDrone(t, store, &domain.Drone{ID: droneID, Status: domain.DroneStatusActive, CurrentOrderID: &orderID, CreatedAt: now, UpdatedAt: now})
+	putOrder(t, store, &domain.Order{
+		ID:              orderID,
+		UserID:          "user-1",
+		Origin:          domain.Location{Lat: 1, Lng: 1},
+		Destination:     domain.Location{Lat: 2, Lng: 2},
+		Status:          domain.OrderStatusPickedUp,
+		AssignedDroneID: &droneID,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	})
+
+	first, err := svc.DroneDeliver(ctx, droneID, orderID, "deliver-1")
+	if err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	retry, err := svc.DroneDeliver(ctx, droneID, orderID, "deliver-1")
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if retry.Status != domain.OrderStatusDelivered || retry.Version != first.Version {
+		t.Fatalf("expected retry to replay the delivered order, got %s v%d", retry.Status, retry.Version)
+	}
+	if _, err := svc.DroneDeliver(ctx, droneID, orderID, ""); !errors.Is(err, domain.ErrPrecondition) {
+		t.Fatalf("expected a retry without a key to fail the precondition, got %v", err)
+	}
+	if _, err := svc.DroneFail(ctx, droneID, orderID, "lost", "deliver-1"); !errors.Is(err, domain.ErrIdempotencyKeyReused) {
+		t.Fatalf("expected key reuse for another action to fail, got %v", err)
+	}
+}
+
+func TestAdminSpatialQueries(t *testing.T) {
+	store := memory.NewStore()
+	svc := service.New(store, 10)
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	putOrder(t, store, &domain.Order{ID: "near", UserID: "u1", Origin: domain.Location{Lat: 24.7136, Lng: 46.6753}, Status: domain.OrderStatusCreated, CreatedAt: now, UpdatedAt: now})
+	putOrder(t, store, &domain.Order{ID: "far", UserID: "u1", Origin: domain.Location{Lat: 24.8, Lng: 46.6753}, Status: domain.OrderStatusCreated, CreatedAt: now, UpdatedAt: now})
+	putOrder(t, store, &domain.Order{ID: "away", UserID: "u1", Origin: domain.Location{Lat: 21.5, Lng: 39.2}, Status: domain.OrderStatusCreated, CreatedAt: now, UpdatedAt: now})
+	putDrone(t, store, &domain.Drone{ID: "drone-far", Status: domain.DroneStatusActive, LastLocation: &domain.Location{Lat: 24.8, Lng: 46.6753}, CreatedAt: now, UpdatedAt: now})
+	putDrone(t, store, &domain.Drone{ID: "drone-near", Status: domain.DroneStatusActive, LastLocation: &domain.Location{Lat: 24.714, Lng: 46.6753}, CreatedAt: now, UpdatedAt: now})
+	putDrone(t, store, &domain.Drone{ID: "drone-broken", Status: domain.DroneStatusBroken, LastLocation: &domain.Location{Lat: 24.7136, Lng: 46.6753}, CreatedAt: now, UpdatedAt: now})
+
+	center := domain.Location{Lat: 24.7136, Lng: 46.6753}
+	views, err := svc.AdminOrdersNear(ctx, service.RadiusQuery{Center: center, RadiusMeters: 20_000})
+	if err != nil {
+		t.Fatalf("orders near: %v", err)
+	}
+	var ids []string
+	for _, view := range views {
+		ids = append(ids, view.Order.ID)
+	}
+	if fmt.Sprint(ids) != "[near far]" {
+		t.Fatalf("expected [near far], got %v", ids)
+	}
+	if _, err := svc.AdminOrdersNear(ctx, service.RadiusQuery{Center: center, RadiusMeters: service.MaxRadiusMeters + 1}); !errors.Is(err, domain.ErrInvalid) {
+		t.Fatalf("expected oversized radius to be rejected, got %v", err)
+	}
+
+	drones, err := svc.AdminNearestIdleDrones(ctx, center, 0)
+	if err != nil {
+		t.Fatalf("nearest drones: %v", err)
+	}
+	if len(drones) != 2 || drones[0].ID != "drone-near" || drones[1].ID != "drone-far" {
+		t.Fatalf("unexpected nearest drones: %+v", drones)
+	}
+	if _, err := svc.AdminNearestIdleDrones(ctx, domain.Location{Lat: 91}, 1); !errors.Is(err, domain.ErrInvalid) {
+		t.Fatalf("expected invalid point to be rejected, got %v", err)
+	}
+}
+
+func TestSubmitOrderServiceAreas(t *testing.T) {
+	store := memory.NewStore()
+	svc := service.New(store, 10)
+	ctx := context.Background()
+	inside := domain.Location{Lat: 1, Lng: 1}
+	outside := domain.Location{Lat: 5, Lng: 5}
+
+	if _, err := svc.SubmitOrder(ctx, "user-1", outside, inside, service.DeliveryWindow{}, ""); err != nil {
+		t.Fatalf("expected no geofence without service areas, got %v", err)
+	}
+
+	boundary := domain.Polygon{{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 2}, {Lat: 2, Lng: 2}, {Lat: 2, Lng: 0}, {Lat: 0, Lng: 0}}}
+	area, err := svc.AdminCreateServiceArea(ctx, "Downtown", boundary, true)
+	if err != nil {
+		t.Fatalf("create service area: %v", err)
+	}
+	order, err := svc.SubmitOrder(ctx, "user-1", inside, domain.Location{Lat: 1.5, Lng: 1.5}, service.DeliveryWindow{}, "")
+	if err != nil {
+		t.Fatalf("submit inside: %v", err)
+	}
+	_, err = svc.SubmitOrder(ctx, "user-1", outside, inside, service.DeliveryWindow{}, "")
+	var outsideErr *domain.OutsideServiceAreaError
+	if !errors.As(err, &outsideErr) || outsideErr.Field != "origin" {
+		t.Fatalf("expected origin outside service area, got %v", err)
+	}
+	if !errors.Is(err, domain.ErrOutsideServiceArea) || !errors.Is(err, domain.ErrInvalid) {
+		t.Fatalf("expected error to wrap ErrOutsideServiceArea and ErrInvalid, got %v", err)
+	}
+	if _, err := svc.AdminUpdateOrder(ctx, order.ID, nil, &outside, 0); !errors.Is(err, domain.ErrOutsideServiceArea) {
+		t.Fatalf("expected admin update outside service area to fail, got %v", err)
+	}
+
+	inactive := false
+	if _, err := svc.AdminUpdateServiceArea(ctx, area.ID, service.ServiceAreaUpdate{Active: &inactive}, area.Version); err != nil {
+		t.Fatalf("deactivate: %v", err)
+	}
+	if _, err := svc.SubmitOrder(ctx, "user-1", inside, inside, service.DeliveryWindow{}, ""); !errors.Is(err, domain.ErrOutsideServiceArea) {
+		t.Fatalf("expected inactive area not to cover orders, got %v", err)
+	}
+}
+
+func TestNoFlyZoneBlocksRoutes(t *testing.T) {
+	store := memory.NewStore()
+	svc := service.New(store, 10)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	// A zone around the east point, so no route can reach it.
+	zone := domain.Polygon{{{Lat: -0.1, Lng: 0.9}, {Lat: -0.1, Lng: 1.1}, {Lat: 0.1, Lng: 1.1}, {Lat: 0.1, Lng: 0.9}, {Lat: -0.1, Lng: 0.9}}}
+	west := domain.Location{Lat: 0, Lng: 0}
+	east := domain.Location{Lat: 0, Lng: 1}
+
+	blocked := &domain.Order{
+		ID: "order-blocked", UserID: "user-1", Origin: west, Destination: east,
+		Status: domain.OrderStatusCreated, CreatedAt: now.Add(-time.Minute), UpdatedAt: now,
+	}
+	clear := &domain.Order{
+		ID: "order-clear", UserID: "user-1", Origin: west, Destination: domain.Location{Lat: 1, Lng: 0},
+		Status: domain.OrderStatusCreated, CreatedAt: now, UpdatedAt: now,
+	}
+	putOrder(t, store, blocked)
+	putOrder(t, store, clear)
+
+	later := now.Add(time.Hour)
+	if _, err := svc.AdminCreateNoFlyZone(ctx, "Air show", zone, service.NoFlyWindow{From: &later}); err != nil {
+		t.Fatalf("create scheduled zone: %v", err)
+	}
+	if _, err := svc.SubmitOrder(ctx, "user-1", west, east, service.DeliveryWindow{}, ""); err != nil {
+		t.Fatalf("expected a zone not yet in force to allow the route, got %v", err)
+	}
+
+	created, err := svc.AdminCreateNoFlyZone(ctx, "Airport", zone, service.NoFlyWindow{})
+	if err != nil {
+		t.Fatalf("create zone: %v", err)
+	}
+	_, err = svc.SubmitOrder(ctx, "user-1", west, east, service.DeliveryWindow{}, "")
+	var blockedErr *domain.RouteBlockedError
+	if !errors.As(err, &blockedErr) || blockedErr.ZoneID != created.ID || !errors.Is(err, domain.ErrInvalid) {
+		t.Fatalf("expected route blocked by %s, got %v", created.ID, err)
+	}
+
+	order, err := svc.DroneReserveJob(ctx, "drone-1", "")
+	if err != nil {
+		t.Fatalf("reserve: %v", err)
+	}
+	if order.ID != clear.ID {
+		t.Fatalf("expected the blocked older order to be passed over for %s, got %s", clear.ID, order.ID)
+	}
+	if _, err := svc.DroneReserveJob(ctx, "drone-2", ""); !errors.Is(err, domain.ErrNoJob) {
+		t.Fatalf("expected only blocked orders to remain, got %v", err)
+	}
+
+	pending, err := store.FetchPending(ctx, 100)
+	if err != nil {
+		t.Fatalf("fetch pending: %v", err)
+	}
+	blockedEvents := map[string]int{}
+	for _, evt := range pending {
+		if evt.Type == "order.route_blocked" {
+			blockedEvents[evt.AggregateID]++
+		}
+	}
+	// Each zone crosses the blocked order; the airport zone also crosses the
+	// order submitted while only the scheduled zone existed.
+	if blockedEvents[blocked.ID] != 2 || blockedEvents[clear.ID] != 0 || len(blockedEvents) != 2 {
+		t.Fatalf("unexpected route_blocked events %v", blockedEvents)
+	}
+}
+
+func TestReserveJobPlansRouteAroundNoFlyZone(t *testing.T) {
+	store := memory.NewStore()
+	svc := service.New(store, 10)
+	ctx := context.Background()
+	// A zone sitting across the equator between lng 0 and lng 1.
+	zone := domain.Polygon{{{Lat: -0.1, Lng: 0.4}, {Lat: -0.1, Lng: 0.6}, {Lat: 0.1, Lng: 0.6}, {Lat: 0.1, Lng: 0.4}, {Lat: -0.1, Lng: 0.4}}}
+	west := domain.Location{Lat: 0, Lng: 0}
+	east := domain.Location{Lat: 0, Lng: 1}
+	if _, err := svc.AdminCreateNoFlyZone(ctx, "Airport", zone, service.NoFlyWindow{}); err != nil {
+		t.Fatalf("create zone: %v", err)
+	}
+	if _, err := svc.SubmitOrder(ctx, "user-1", west, east, service.DeliveryWindow{}, ""); err != nil {
+		t.Fatalf("expected a route around the zone, got %v", err)
+	}
+
+	order, err := svc.DroneReserveJob(ctx, "drone-1", "")
+	if err != nil {
+		t.Fatalf("reserve: %v", err)
+	}
+	route := order.Route
+	if len(route) < 3 || route[0] != west || route[len(route)-1] != east {
+		t.Fatalf("expected a route from west to east bending around the zone, got %v", route)
+	}
+	for i := 1; i < len(route); i++ {
+		if zone.CrossesPath(route[i-1], route[i]) {
+			t.Fatalf("leg %v -> %v crosses the zone", route[i-1], route[i])
+		}
+	}
+
+	view, err := svc.DroneCurrentOrder(ctx, "drone-1")
+	if err != nil {
+		t.Fatalf("current order: %v", err)
+	}
+	if len(view.Order.Route) != len(route) {
+		t.Fatalf("expected the drone to fetch the stored route, got %v", view.Order.Route)
+	}
+	want := int64(domain.PathLengthMeters(route) / 10)
+	if view.ETASeconds == nil || *view.ETASeconds != want {
+		t.Fatalf("expected ETA along the route of %ds, got %v", want, view.ETASeconds)
+	}
+	if direct := int64(domain.DistanceMeters(west, east) / 10); want <= direct {
+		t.Fatalf("expected the detour to be longer than the direct %ds, got %ds", direct, want)
+	}
+}
+
+func TestComputeETALegs(t *testing.T) {
+	origin := domain.Location{Lat: 0, Lng: 0}
+	dest := domain.Location{Lat: 0, Lng: 0.1}
+	order := &domain.Order{ID: "o1", Origin: origin, Destination: dest, Status: domain.OrderStatusCreated}
+	model := service.ETAModel{SpeedMPS: 10, PickupDwell: time.Minute, DropoffDwell: 30 * time.Second}
+	delivery := int64(domain.DistanceMeters(origin, dest) / 10)
+	job := 60 + delivery + 30
+
+	legKinds := func(eta *service.ETA) string {
+		var kinds []string
+		var total int64
+		for _, leg := range eta.Legs {
+			kinds = append(kinds, string(leg.Kind))
+			total += leg.Seconds
+		}
+		if total != eta.Seconds {
+			t.Fatalf("legs sum to %d, ETA is %d", total, eta.Seconds)
+		}
+		return fmt.Sprint(kinds)
+	}
+
+	// Three orders ahead shared by two drones: one full job of waiting.
+	eta := service.ComputeETA(order, nil, model, &service.QueueState{Ahead: 3, ActiveDrones: 2})
+	if eta == nil || legKinds(eta) != "[queue pickup delivery dropoff]" || eta.Legs[0].Seconds != job {
+		t.Fatalf("unexpected queued ETA %+v", eta)
+	}
+	if eta := service.ComputeETA(order, nil, model, &service.QueueState{Ahead: 3}); eta != nil {
+		t.Fatalf("expected no ETA without active drones, got %+v", eta)
+	}
+
+	droneLoc := domain.Location{Lat: 0, Lng: -0.05}
+	drone := &domain.Drone{ID: "d1", LastLocation: &droneLoc}
+	order.Status = domain.OrderStatusReserved
+	eta = service.ComputeETA(order, drone, model, nil)
+	toPickup := int64(domain.DistanceMeters(droneLoc, origin) / 10)
+	if eta == nil || legKinds(eta) != "[to_pickup pickup delivery dropoff]" || eta.Seconds != toPickup+job {
+		t.Fatalf("unexpected reserved ETA %+v", eta)
+	}
+
+	droneLoc = domain.Location{Lat: 0, Lng: 0.05}
+	order.Status = domain.OrderStatusPickedUp
+	eta = service.ComputeETA(order, drone, model, nil)
+	if eta == nil || legKinds(eta) != "[delivery dropoff]" {
+		t.Fatalf("unexpected picked-up ETA %+v", eta)
+	}
+}
+
+func TestComputeETAObservedSpeed(t *testing.T) {
+	now := time.Now().UTC()
+	dest := domain.Location{Lat: 0, Lng: 0.1}
+	pickedUpAt := now.Add(-time.Minute)
+	order := &domain.Order{ID: "o1", Origin: domain.Location{Lat: 0, Lng: 0}, Destination: dest, Status: domain.OrderStatusPickedUp, PickedUpAt: &pickedUpAt}
+	// Fixes every 10s along the equator, 200m apart: 20 m/s toward dest.
+	step := 200 / domain.DistanceMeters(domain.Location{}, domain.Location{Lng: 1})
+	var fixes []domain.Fix
+	for i := 0; i < 6; i++ {
+		fixes = append(fixes, domain.Fix{Location: domain.Location{Lng: float64(i) * step}, At: pickedUpAt.Add(time.Duration(i) * 10 * time.Second)})
+	}
+	last := fixes[len(fixes)-1].Location
+	drone := &domain.Drone{ID: "d1", LastLocation: &last, RecentFixes: fixes}
+	model := service.ETAModel{SpeedMPS: 10}
+
+	eta := service.ComputeETA(order, drone, model, nil)
+	remaining := domain.DistanceMeters(last, dest)
+	if eta == nil || eta.Source != service.ETASourceObserved || eta.Confidence < 0.9 {
+		t.Fatalf("expected a confident observed ETA, got %+v", eta)
+	}
+	if want := int64(remaining / 20); eta.Seconds != want {
+		t.Fatalf("expected %ds at the observed 20 m/s, got %ds", want, eta.Seconds)
+	}
+
+	// Fixes from before pickup say nothing about the delivery flight.
+	later := fixes[4].At
+	order.PickedUpAt = &later
+	eta = service.ComputeETA(order, drone, model, nil)
+	if eta == nil || eta.Source != service.ETASourceNominal || eta.Seconds != int64(remaining/10) {
+		t.Fatalf("expected a nominal ETA with too few fixes since pickup, got %+v", eta)
+	}
+
+	// Flying away from the destination is not a speed toward it.
+	order.PickedUpAt = &pickedUpAt
+	order.Destination = domain.Location{Lat: 0, Lng: -0.1}
+	if eta := service.ComputeETA(order, drone, model, nil); eta == nil || eta.Source != service.ETASourceNominal {
+		t.Fatalf("expected a nominal ETA when not closing on the destination, got %+v", eta)
+	}
+
+	// Weaving 200m across the course while gaining 50m on it every 10s
+	// covers ground at over 20 m/s but closes on the destination at 5 m/s.
+	order.Destination = dest
+	fixes = nil
+	for i := 0; i < 6; i++ {
+		loc := domain.Location{Lat: float64(i%2) * step, Lng: float64(i) * step / 4}
+		fixes = append(fixes, domain.Fix{Location: loc, At: pickedUpAt.Add(time.Duration(i) * 10 * time.Second)})
+	}
+	last = fixes[len(fixes)-1].Location
+	drone = &domain.Drone{ID: "d1", LastLocation: &last, RecentFixes: fixes}
+	eta = service.ComputeETA(order, drone, model, nil)
+	remaining = domain.DistanceMeters(last, dest)
+	if eta == nil || eta.Source != service.ETASourceObserved {
+		t.Fatalf("expected an observed ETA while weaving, got %+v", eta)
+	}
+	if want := remaining / 5; math.Abs(float64(eta.Seconds)-want) > want*0.05 {
+		t.Fatalf("expected about %.0fs at the 5 m/s closing speed, got %ds", want, eta.Seconds)
+	}
+}
+
+func TestHeartbeatTelemetryTracks(t *testing.T) {
+	store := memory.NewStore()
+	svc := service.New(store, 10)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	droneID := "drone-1"
+	orderID := "order-1"
+	putDrone(t, store, &domain.Drone{ID: droneID, Status: domain.DroneStatusActive, CurrentOrderID: &orderID, CreatedAt: now, UpdatedAt: now})
+	putOrder(t, store, &domain.Order{
+		ID:              orderID,
+		UserID:          "user-1",
+		Origin:          domain.Location{Lat: 1, Lng: 1},
+		Destination:     domain.Location{Lat: 2, Lng: 2},
+		Status:          domain.OrderStatusPickedUp,
+		AssignedDroneID: &droneID,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	})
+
+	path := []domain.Location{{Lat: 1.1, Lng: 1.1}, {Lat: 1.2, Lng: 1.2}}
+	for _, loc := range path {
+		if _, err := svc.DroneHeartbeat(ctx, droneID, loc, domain.Vitals{}); err != nil {
+			t.Fatalf("heartbeat: %v", err)
+		}
+	}
+	if _, err := svc.DroneHeartbeat(ctx, "drone-2", domain.Location{Lat: 5, Lng: 5}, domain.Vitals{}); err != nil {
+		t.Fatalf("idle heartbeat: %v", err)
+	}
+
+	track, err := svc.AdminDroneTrack(ctx, droneID, service.TrackRange{})
+	if err != nil {
+		t.Fatalf("drone track: %v", err)
+	}
+	if len(track) != len(path) {
+		t.Fatalf("expected %d samples, got %d", len(path), len(track))
+	}
+	for i, sample := range track {
+		if sample.Location != path[i] || sample.OrderID == nil || *sample.OrderID != orderID {
+			t.Fatalf("sample %d: expected %v for %s, got %+v", i, path[i], orderID, sample)
+		}
+	}
+	if track, err := svc.AdminOrderTrack(ctx, orderID, service.TrackRange{}); err != nil || len(track) != len(path) {
+		t.Fatalf("order track: expected %d samples, got %d (err=%v)", len(path), len(track), err)
+	}
+	later := time.Now().Add(time.Hour)
+	if track, err := svc.AdminDroneTrack(ctx, droneID, service.TrackRange{From: &later}); err != nil || len(track) != 0 {
+		t.Fatalf("future range: expected no samples, got %d (err=%v)", len(track), err)
+	}
+	if _, err := svc.AdminDroneTrack(ctx, droneID, service.TrackRange{From: &later, To: &now}); !errors.Is(err, domain.ErrInvalid) {
+		t.Fatalf("expected an inverted range to be invalid, got %v", err)
+	}
+	if _, err := svc.AdminDroneTrack(ctx, "missing", service.TrackRange{}); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected an unknown drone to be not found, got %v", err)
+	}
+
+	if pruned, err := svc.PruneTelemetry(ctx); err != nil || pruned != 0 {
+		t.Fatalf("prune without retention: expected nothing deleted, got %d (err=%v)", pruned, err)
+	}
+	svc.SetTelemetryRetention(time.Nanosecond)
+	if pruned, err := svc.PruneTelemetry(ctx); err != nil || pruned != 3 {
+		t.Fatalf("prune: expected 3 samples deleted, got %d (err=%v)", pruned, err)
+	}
+}
+
+func TestHeartbeatVitals(t *testing.T) {
+	store := memory.NewStore()
+	svc := service.New(store, 10)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	droneID := "drone-1"
+	orderID := "order-1"
+	putDrone(t, store, &domain.Drone{ID: droneID, Status: domain.DroneStatusActive, CurrentOrderID: &orderID, CreatedAt: now, UpdatedAt: now})
+	putOrder(t, store, &domain.Order{
+		ID:              orderID,
+		UserID:          "user-1",
+		Origin:          domain.Location{Lat: 1, Lng: 1},
+		Destination:     domain.Location{Lat: 2, Lng: 2},
+		Status:          domain.OrderStatusPickedUp,
+		AssignedDroneID: &droneID,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	})
+	battery := 64.0
+	loc := domain.Location{Lat: 1.5, Lng: 1.5}
+
+	view, err := svc.DroneHeartbeat(ctx, droneID, loc, domain.Vitals{BatteryPercent: &battery, FaultCodes: []string{"LOW_SIGNAL"}})
+	if err != nil {
+		t.Fatalf("heartbeat: %v", err)
+	}
+	if view.Drone.Status != domain.DroneStatusActive || view.CurrentOrder == nil {
+		t.Fatalf("expected a non-critical fault to leave the drone flying, got %s", view.Drone.Status)
+	}
+	stored, _ := store.GetDrone(ctx, droneID)
+	if stored.Vitals.BatteryPercent == nil || *stored.Vitals.BatteryPercent != battery || len(stored.Vitals.FaultCodes) != 1 {
+		t.Fatalf("expected the latest vitals stored, got %+v", stored.Vitals)
+	}
+
+	over := 101.0
+	if _, err := svc.DroneHeartbeat(ctx, droneID, loc, domain.Vitals{BatteryPercent: &over}); !errors.Is(err, domain.ErrInvalid) {
+		t.Fatalf("expected an out-of-range battery to be invalid, got %v", err)
+	}
+
+	view, err = svc.DroneHeartbeat(ctx, droneID, loc, domain.Vitals{FaultCodes: []string{domain.FaultMotorFailure}})
+	if err != nil {
+		t.Fatalf("critical heartbeat: %v", err)
+	}
+	if view.Drone.Status != domain.DroneStatusBroken || view.CurrentOrder != nil {
+		t.Fatalf("expected a critical fault to mark the drone broken, got %s", view.Drone.Status)
+	}
+	order, _ := store.GetOrder(ctx, orderID)
+	if order.Status != domain.OrderStatusHandoffRequested || order.HandoffOrigin == nil || *order.HandoffOrigin != loc {
+		t.Fatalf("expected a handoff from the last position, got %s at %v", order.Status, order.HandoffOrigin)
+	}
+}
+
+// orderLockFailingStore fails every GetOrderForUpdate, to check what a failed
+// transaction leaves behind.
+type orderLockFailingStore struct {
+	*memory.Store
+}
+
+func (f orderLockFailingStore) BeginTx(ctx context.Context) (service.Tx, error) {
+	tx, err := f.Store.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return orderLockFailingTx{Tx: tx}, nil
+}
+
+type orderLockFailingTx struct {
+	service.Tx
+}
+
+var errOrderLock = errors.New("order lock failed")
+
+func (orderLockFailingTx) GetOrderForUpdate(ctx context.Context, id string) (*domain.Order, error) {
+	return nil, errOrderLock
+}
+
+func TestHeartbeatCriticalFaultRollsBackTogether(t *testing.T) {
+	mem := memory.NewStore()
+	svc := service.New(orderLockFailingStore{Store: mem}, 10)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	droneID := "drone-1"
+	orderID := "order-1"
+	putDrone(t, mem, &domain.Drone{ID: droneID, Status: domain.DroneStatusActive, CurrentOrderID: &orderID, CreatedAt: now, UpdatedAt: now})
+	putOrder(t, mem, &domain.Order{
+		ID:              orderID,
+		UserID:          "user-1",
+		Origin:          domain.Location{Lat: 1, Lng: 1},
+		Destination:     domain.Location{Lat: 2, Lng: 2},
+		Status:          domain.OrderStatusPickedUp,
+		AssignedDroneID: &droneID,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	})
+
+	_, err := svc.DroneHeartbeat(ctx, droneID, domain.Location{Lat: 1.5, Lng: 1.5}, domain.Vitals{FaultCodes: []string{domain.FaultMotorFailure}})
+	if !errors.Is(err, errOrderLock) {
+		t.Fatalf("expected the broken transition to fail, got %v", err)
+	}
+	stored, _ := mem.GetDrone(ctx, droneID)
+	if stored.LastHeartbeatAt != nil || len(stored.Vitals.FaultCodes) != 0 {
+		t.Fatalf("expected the heartbeat rolled back with the broken transition, got %+v", stored)
+	}
+}
+
+func TestDroneLifecycleStatuses(t *testing.T) {
+	store := memory.NewStore()
+	svc := service.New(store, 10)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	putDrone(t, store, &domain.Drone{ID: "drone-1", Status: domain.DroneStatusActive, CreatedAt: now, UpdatedAt: now})
+	putOrder(t, store, &domain.Order{
+		ID:          "order-1",
+		UserID:      "user-1",
+		Origin:      domain.Location{Lat: 1, Lng: 1},
+		Destination: domain.Location{Lat: 2, Lng: 2},
+		Status:      domain.OrderStatusCreated,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+
+	drone, err := svc.DroneSetStatus(ctx, "drone-1", domain.DroneStatusCharging)
+	if err != nil || drone.Status != domain.DroneStatusCharging {
+		t.Fatalf("expected the drone to go charging, got %v", err)
+	}
+	if _, err := svc.DroneReserveJob(ctx, "drone-1", ""); !errors.Is(err, domain.ErrPrecondition) {
+		t.Fatalf("expected a charging drone not to reserve, got %v", err)
+	}
+	if _, err := svc.DroneSetStatus(ctx, "drone-1", domain.DroneStatusMaintenance); !errors.Is(err, domain.ErrPrecondition) {
+		t.Fatalf("expected maintenance to be admin-only, got %v", err)
+	}
+	if _, err := svc.DroneSetStatus(ctx, "drone-1", "FLYING"); !errors.Is(err, domain.ErrInvalid) {
+		t.Fatalf("expected an unknown status to be invalid, got %v", err)
+	}
+	if _, err := svc.DroneSetStatus(ctx, "drone-1", domain.DroneStatusOffline); err != nil {
+		t.Fatalf("go offline: %v", err)
+	}
+	view, err := svc.DroneHeartbeat(ctx, "drone-1", domain.Location{Lat: 1, Lng: 1}, domain.Vitals{})
+	if err != nil || view.Drone.Status != domain.DroneStatusActive {
+		t.Fatalf("expected a heartbeat to bring the drone back online, got %v", err)
+	}
+
+	if _, err := svc.DroneReserveJob(ctx, "drone-1", ""); err != nil {
+		t.Fatalf("reserve: %v", err)
+	}
+	if _, err := svc.AdminRetireDrone(ctx, "drone-1", 0); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected retiring a drone with an order to conflict, got %v", err)
+	}
+	if _, err := svc.AdminUnassignOrder(ctx, "order-1", 0); err != nil {
+		t.Fatalf("unassign: %v", err)
+	}
+	if drone, err = svc.AdminSetDroneStatus(ctx, "drone-1", domain.DroneStatusMaintenance, 0); err != nil || drone.Status != domain.DroneStatusMaintenance {
+		t.Fatalf("expected maintenance, got %v", err)
+	}
+	if drone, err = svc.AdminRetireDrone(ctx, "drone-1", drone.Version); err != nil || drone.Status != domain.DroneStatusRetired {
+		t.Fatalf("expected the drone retired, got %v", err)
+	}
+	if _, err := svc.AdminMarkDroneFixed(ctx, "drone-1", domain.MaintenanceReport{}, 0); !errors.Is(err, domain.ErrPrecondition) {
+		t.Fatalf("expected a retired drone to stay retired, got %v", err)
+	}
+	if _, err := svc.DroneMarkBroken(ctx, "drone-1", ""); !errors.Is(err, domain.ErrPrecondition) {
+		t.Fatalf("expected a retired drone not to break, got %v", err)
+	}
+	if drones, err := svc.AdminNearestIdleDrones(ctx, domain.Location{Lat: 1, Lng: 1}, 10); err != nil || len(drones) != 0 {
+		t.Fatalf("expected no idle drones, got %d (%v)", len(drones), err)
+	}
+}
+
+func TestDroneMaintenanceRecords(t *testing.T) {
+	store := memory.NewStore()
+	svc := service.New(store, 10)
+	svc.SetServiceInterval(service.ServiceInterval{Meters: 1000})
+	ctx := context.Background()
+	now := time.Now().UTC()
+	putDrone(t, store, &domain.Drone{ID: "drone-1", Status: domain.DroneStatusActive, CreatedAt: now, UpdatedAt: now})
+	putOrder(t, store, &domain.Order{
+		ID:          "order-1",
+		UserID:      "user-1",
+		Origin:      domain.Location{Lat: 1, Lng: 1},
+		Destination: domain.Location{Lat: 1.01, Lng: 1.01},
+		Status:      domain.OrderStatusCreated,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	if _, err := svc.DroneHeartbeat(ctx, "drone-1", domain.Location{Lat: 1, Lng: 1}, domain.Vitals{}); err != nil {
+		t.Fatalf("heartbeat: %v", err)
+	}
+	if _, err := svc.DroneReserveJob(ctx, "drone-1", ""); err != nil {
+		t.Fatalf("reserve: %v", err)
+	}
+	if _, err := svc.DronePickup(ctx, "drone-1", "order-1", ""); err != nil {
+		t.Fatalf("pickup: %v", err)
+	}
+	if _, err := svc.DroneDeliver(ctx, "drone-1", "order-1", ""); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+
+	// The ~1.5 km route takes the drone past its 1 km service interval.
+	drone, err := store.GetDrone(ctx, "drone-1")
+	if err != nil {
+		t.Fatalf("get drone: %v", err)
+	}
+	if drone.Status != domain.DroneStatusMaintenance || drone.Usage.Meters < 1000 || drone.SinceService != drone.Usage {
+		t.Fatalf("expected the drone due for service in maintenance, got %s with usage %+v", drone.Status, drone.Usage)
+	}
+	records, err := svc.AdminDroneMaintenance(ctx, "drone-1")
+	if err != nil || len(records) != 1 || records[0].Reason != domain.MaintenanceReasonServiceInterval || !records[0].Open() {
+		t.Fatalf("expected an open service interval record, got %d (%v)", len(records), err)
+	}
+
+	if _, err := svc.AdminMarkDroneFixed(ctx, "drone-1", domain.MaintenanceReport{Parts: []string{""}}, 0); !errors.Is(err, domain.ErrInvalid) {
+		t.Fatalf("expected an empty part name to be invalid, got %v", err)
+	}
+	report := domain.MaintenanceReport{Technician: "Sam", Notes: "new propellers", Parts: []string{"propeller"}}
+	if drone, err = svc.AdminMarkDroneFixed(ctx, "drone-1", report, 0); err != nil || drone.Status != domain.DroneStatusActive {
+		t.Fatalf("expected the drone active again, got %v", err)
+	}
+	if drone.SinceService != (domain.FlightUsage{}) || drone.Usage.Meters < 1000 {
+		t.Fatalf("expected only the since-service usage reset, got %+v and %+v", drone.Usage, drone.SinceService)
+	}
+
+	vitals := domain.Vitals{FaultCodes: []string{domain.FaultMotorFailure}}
+	if _, err := svc.DroneHeartbeat(ctx, "drone-1", domain.Location{Lat: 1, Lng: 1}, vitals); err != nil {
+		t.Fatalf("heartbeat: %v", err)
+	}
+	records, err = svc.AdminDroneMaintenance(ctx, "drone-1")
+	if err != nil || len(records) != 2 {
+		t.Fatalf("expected two records, got %d (%v)", len(records), err)
+	}
+	if closed := records[0]; closed.Open() || closed.Technician == nil || *closed.Technician != "Sam" || len(closed.Parts) != 1 {
+		t.Fatalf("expected the first record closed with the report, got %+v", closed)
+	}
+	if broken := records[1]; broken.Reason != domain.MaintenanceReasonBroken || !broken.Open() || len(broken.FaultCodes) != 1 {
+		t.Fatalf("expected an open BROKEN record with the fault, got %+v", broken)
+	}
+
+	if _, err := svc.AdminDroneMaintenance(ctx, "drone-2"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for an unknown drone, got %v", err)
+	}
+}
+
+func TestDepotsDispatchAndReturnToBase(t *testing.T) {
+	store := memory.NewStore()
+	svc := service.New(store, 10)
+	ctx := context.Background()
+	northLoc := domain.Location{Lat: 1, Lng: 1}
+	southLoc := domain.Location{Lat: 2, Lng: 2}
+	north, err := svc.AdminCreateDepot(ctx, "North", northLoc, 1)
+	if err != nil {
+		t.Fatalf("create depot: %v", err)
+	}
+	south, err := svc.AdminCreateDepot(ctx, "South", southLoc, 5)
+	if err != nil {
+		t.Fatalf("create depot: %v", err)
+	}
+	for id, loc := range map[string]domain.Location{"drone-north": northLoc, "drone-south": southLoc, "drone-extra": northLoc} {
+		if _, err := svc.DroneHeartbeat(ctx, id, loc, domain.Vitals{}); err != nil {
+			t.Fatalf("heartbeat %s: %v", id, err)
+		}
+	}
+	if _, err := svc.AdminSetDroneDepot(ctx, "drone-north", &north.ID, 0); err != nil {
+		t.Fatalf("home drone-north: %v", err)
+	}
+	if _, err := svc.AdminSetDroneDepot(ctx, "drone-extra", &north.ID, 0); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected ErrConflict homing a drone at a full depot, got %v", err)
+	}
+	if _, err := svc.AdminSetDroneDepot(ctx, "drone-south", &south.ID, 0); err != nil {
+		t.Fatalf("home drone-south: %v", err)
+	}
+	if _, err := svc.AdminUpdateDepot(ctx, south.ID, service.DepotUpdate{Capacity: new(int)}, 0); !errors.Is(err, domain.ErrInvalid) {
+		t.Fatalf("expected ErrInvalid for a zero capacity, got %v", err)
+	}
+
+	// The older order is picked up near the south depot, which has an idle
+	// drone of its own, so the north drone takes the newer one.
+	southOrder, err := svc.SubmitOrder(ctx, "user-1", domain.Location{Lat: 2.001, Lng: 2.001}, domain.Location{Lat: 2.02, Lng: 2.02}, service.DeliveryWindow{}, "")
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	northOrder, err := svc.SubmitOrder(ctx, "user-1", domain.Location{Lat: 1.001, Lng: 1.001}, domain.Location{Lat: 1.02, Lng: 1.02}, service.DeliveryWindow{}, "")
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	order, err := svc.DroneReserveJob(ctx, "drone-north", "")
+	if err != nil || order.ID != northOrder.ID {
+		t.Fatalf("expected drone-north to reserve the order near its depot, got %v (err=%v)", order, err)
+	}
+	if _, err := svc.DronePickup(ctx, "drone-north", order.ID, ""); err != nil {
+		t.Fatalf("pickup: %v", err)
+	}
+	if _, err := svc.DroneDeliver(ctx, "drone-north", order.ID, ""); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+
+	// Idle at the dropoff, the drone is told to fly home.
+	view, err := svc.DroneHeartbeat(ctx, "drone-north", order.Destination, domain.Vitals{})
+	if err != nil {
+		t.Fatalf("heartbeat: %v", err)
+	}
+	rtb := view.ReturnToBase
+	if rtb == nil || rtb.DepotID != north.ID || rtb.Location != northLoc {
+		t.Fatalf("expected a return to the north depot, got %+v", rtb)
+	}
+	want := domain.DistanceMeters(order.Destination, northLoc)
+	if len(rtb.Route) != 2 || rtb.DistanceMeters != want || rtb.ETASeconds != int64(want/10) {
+		t.Fatalf("expected a direct %.0f m flight home at 10 m/s, got %+v", want, rtb)
+	}
+
+	// With nothing else waiting, the passed-over order is taken after all.
+	order, err = svc.DroneReserveJob(ctx, "drone-north", "")
+	if err != nil || order.ID != southOrder.ID {
+		t.Fatalf("expected drone-north to fall back to the south order, got %v (err=%v)", order, err)
+	}
+	if _, err := svc.AdminUnassignOrder(ctx, order.ID, 0); err != nil {
+		t.Fatalf("unassign: %v", err)
+	}
+	if view, err = svc.DroneHeartbeat(ctx, "drone-north", northLoc, domain.Vitals{}); err != nil || view.ReturnToBase != nil {
+		t.Fatalf("expected no return to base once home, got %+v (err=%v)", view.ReturnToBase, err)
+	}
+	if drones, err := store.ListDepotDrones(ctx, north.ID); err != nil || len(drones) != 1 {
+		t.Fatalf("expected one drone homed at the north depot, got %d (err=%v)", len(drones), err)
+	}
+}
+
+func TestChargingSlotsAndLowBatteryRouting(t *testing.T) {
+	store := memory.NewStore()
+	svc := service.New(store, 10)
+	ctx := context.Background()
+	nearLoc := domain.Location{Lat: 1, Lng: 1}
+	farLoc := domain.Location{Lat: 1.5, Lng: 1.5}
+	near, err := svc.AdminCreateChargingStation(ctx, "Near", nearLoc, 1)
+	if err != nil {
+		t.Fatalf("create station: %v", err)
+	}
+	far, err := svc.AdminCreateChargingStation(ctx, "Far", farLoc, 2)
+	if err != nil {
+		t.Fatalf("create station: %v", err)
+	}
+	low := 12.0
+	for _, id := range []string{"drone-1", "drone-2"} {
+		if _, err := svc.DroneHeartbeat(ctx, id, domain.Location{Lat: 1.01, Lng: 1.01}, domain.Vitals{}); err != nil {
+			t.Fatalf("heartbeat %s: %v", id, err)
+		}
+	}
+
+	// Low on battery, the drone is pointed at the nearest station.
+	view, err := svc.DroneHeartbeat(ctx, "drone-1", domain.Location{Lat: 1.01, Lng: 1.01}, domain.Vitals{BatteryPercent: &low})
+	if err != nil {
+		t.Fatalf("heartbeat: %v", err)
+	}
+	if c := view.ChargeAt; c == nil || c.StationID != near.Station.ID || c.FreeSlots != 1 {
+		t.Fatalf("expected the near station recommended, got %+v", c)
+	}
+	slot, err := svc.DroneReserveChargingSlot(ctx, "drone-1", near.Station.ID)
+	if err != nil || slot.Number != 1 || slot.DroneID == nil || *slot.DroneID != "drone-1" {
+		t.Fatalf("expected drone-1 on slot 1, got %+v (err=%v)", slot, err)
+	}
+	if again, err := svc.DroneReserveChargingSlot(ctx, "drone-1", near.Station.ID); err != nil || again.Number != 1 {
+		t.Fatalf("expected the held slot back, got %+v (err=%v)", again, err)
+	}
+	if _, err := svc.DroneReserveChargingSlot(ctx, "drone-1", far.Station.ID); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected ErrConflict for a second station, got %v", err)
+	}
+	if _, err := svc.DroneReserveChargingSlot(ctx, "drone-2", near.Station.ID); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected ErrConflict at a full station, got %v", err)
+	}
+	if view, err = svc.DroneHeartbeat(ctx, "drone-1", nearLoc, domain.Vitals{BatteryPercent: &low}); err != nil || view.ChargeAt != nil {
+		t.Fatalf("expected no recommendation while holding a slot, got %+v (err=%v)", view.ChargeAt, err)
+	}
+
+	// With the near station full, the other drone is sent to the far one.
+	view, err = svc.DroneHeartbeat(ctx, "drone-2", domain.Location{Lat: 1.01, Lng: 1.01}, domain.Vitals{BatteryPercent: &low})
+	if err != nil {
+		t.Fatalf("heartbeat: %v", err)
+	}
+	if c := view.ChargeAt; c == nil || c.StationID != far.Station.ID || c.FreeSlots != 2 {
+		t.Fatalf("expected the far station recommended, got %+v", c)
+	}
+	if _, err := svc.AdminUpdateChargingStation(ctx, near.Station.ID, service.ChargingStationUpdate{Slots: new(int)}, 0); !errors.Is(err, domain.ErrInvalid) {
+		t.Fatalf("expected ErrInvalid for zero slots, got %v", err)
+	}
+
+	released, err := svc.DroneReleaseChargingSlot(ctx, "drone-1")
+	if err != nil || !released.Free() {
+		t.Fatalf("expected the slot freed, got %+v (err=%v)", released, err)
+	}
+	if _, err := svc.DroneReleaseChargingSlot(ctx, "drone-1"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound releasing no slot, got %v", err)
+	}
+	got, err := svc.AdminGetChargingStation(ctx, near.Station.ID)
+	if err != nil || got.Occupied != 0 || len(got.Slots) != 1 {
+		t.Fatalf("expected one free slot at the near station, got %+v (err=%v)", got, err)
+	}
+
+	pending, err := store.FetchPending(ctx, 100)
+	if err != nil {
+		t.Fatalf("fetch pending: %v", err)
+	}
+	var charging []string
+	for _, evt := range pending {
+		if evt.AggregateType == "charging_station" {
+			charging = append(charging, evt.Type)
+		}
+	}
+	if fmt.Sprint(charging) != "[charging.slot_reserved charging.slot_released]" {
+		t.Fatalf("unexpected charging events %v", charging)
+	}
+}
+
+func TestRelayedOrderLegs(t *testing.T) {
+	store := memory.NewStore()
+	svc := service.New(store, 10)
+	svc.SetDroneRange(150_000)
+	ctx := context.Background()
+	origin := domain.Location{Lat: 0, Lng: 0}
+	relayLoc := domain.Location{Lat: 0, Lng: 1}
+	dest := domain.Location{Lat: 0, Lng: 2}
+	station, err := svc.AdminCreateChargingStation(ctx, "Midway", relayLoc, 1)
+	if err != nil {
+		t.Fatalf("create station: %v", err)
+	}
+	for _, id := range []string{"drone-1", "drone-2"} {
+		if _, err := svc.DroneHeartbeat(ctx, id, origin, domain.Vitals{}); err != nil {
+			t.Fatalf("heartbeat %s: %v", id, err)
+		}
+	}
+
+	// Past the range with no relay point in reach, the order is refused.
+	if _, err := svc.SubmitOrder(ctx, "user-1", origin, domain.Location{Lat: 0, Lng: -2}, service.DeliveryWindow{}, ""); !errors.Is(err, domain.ErrOutOfRange) {
+		t.Fatalf("expected ErrOutOfRange, got %v", err)
+	}
+	order, err := svc.SubmitOrder(ctx, "user-1", origin, dest, service.DeliveryWindow{}, "")
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if len(order.Legs) != 2 || order.Legs[0].RelayPointID != station.Station.ID || order.Legs[1].To != dest {
+		t.Fatalf("expected two legs via the station, got %+v", order.Legs)
+	}
+	view, err := svc.GetOrderView(ctx, "user-1", domain.RoleEndUser, order.ID)
+	if err != nil {
+		t.Fatalf("get order: %v", err)
+	}
+	// Without dwells, the ETA is the queue wait, the first leg and the relay.
+	if legs := view.ETALegs; len(legs) != 3 || legs[1].DistanceMeters != domain.DistanceMeters(origin, relayLoc) ||
+		legs[2].Kind != service.ETALegRelay || legs[2].DistanceMeters != domain.DistanceMeters(relayLoc, dest) {
+		t.Fatalf("unexpected relayed ETA legs %+v", legs)
+	}
+
+	// The first drone flies to the relay point and hands the order on.
+	reserved, err := svc.DroneReserveJob(ctx, "drone-1", "")
+	if err != nil {
+		t.Fatalf("reserve: %v", err)
+	}
+	if last := reserved.Route[len(reserved.Route)-1]; last != relayLoc {
+		t.Fatalf("expected the route to end at the relay point, got %v", last)
+	}
+	if _, err := svc.DronePickup(ctx, "drone-1", order.ID, ""); err != nil {
+		t.Fatalf("pickup: %v", err)
+	}
+	handed, err := svc.DroneDeliver(ctx, "drone-1", order.ID, "")
+	if err != nil {
+		t.Fatalf("deliver leg: %v", err)
+	}
+	if handed.Status != domain.OrderStatusHandoffRequested || handed.HandoffOrigin == nil || *handed.HandoffOrigin != relayLoc || handed.CurrentLeg() != 1 {
+		t.Fatalf("expected a handoff at the relay point on leg 1, got %s at %v leg %d", handed.Status, handed.HandoffOrigin, handed.CurrentLeg())
+	}
+	if _, err := svc.DroneReserveJob(ctx, "drone-1", ""); !errors.Is(err, domain.ErrNoJob) {
+		t.Fatalf("expected the next leg left to another drone, got %v", err)
+	}
+
+	// A second drone flies the last leg.
+	if _, err := svc.DroneReserveJob(ctx, "drone-2", ""); err != nil {
+		t.Fatalf("reserve: %v", err)
+	}
+	if _, err := svc.DronePickup(ctx, "drone-2", order.ID, ""); err != nil {
+		t.Fatalf("pickup: %v", err)
+	}
+	delivered, err := svc.DroneDeliver(ctx, "drone-2", order.ID, "")
+	if err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if delivered.Status != domain.OrderStatusDelivered || delivered.CurrentLeg() != -1 {
+		t.Fatalf("expected the order delivered with every leg flown, got %s leg %d", delivered.Status, delivered.CurrentLeg())
+	}
+	for i, want := range []string{"drone-1", "drone-2"} {
+		if leg := delivered.Legs[i]; leg.DroneID == nil || *leg.DroneID != want {
+			t.Fatalf("expected leg %d flown by %s, got %v", i, want, leg.DroneID)
+		}
+	}
+}
+
+func TestBatchedTrip(t *testing.T) {
+	store := memory.NewStore()
+	svc := service.New(store, 10)
+	svc.SetBatching(2, 1_000)
+	ctx := context.Background()
+	if _, err := svc.DroneHeartbeat(ctx, "drone-1", domain.Location{Lat: 0, Lng: 0}, domain.Vitals{}); err != nil {
+		t.Fatalf("heartbeat: %v", err)
+	}
+	submit := func(origin, dest domain.Location) *domain.Order {
+		t.Helper()
+		order, err := svc.SubmitOrder(ctx, "user-1", origin, dest, service.DeliveryWindow{}, "")
+		if err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+		return order
+	}
+	first := submit(domain.Location{Lat: 0, Lng: 0}, domain.Location{Lat: 0, Lng: 0.05})
+	submit(domain.Location{Lat: 0.5, Lng: 0.5}, domain.Location{Lat: 0.5, Lng: 0.55})
+	second := submit(domain.Location{Lat: 0, Lng: 0.001}, domain.Location{Lat: 0, Lng: 0.04})
+
+	// The far order's pickup is out of the batch radius, so it is passed over.
+	for _, want := range []string{first.ID, second.ID} {
+		reserved, err := svc.DroneReserveJob(ctx, "drone-1", "")
+		if err != nil {
+			t.Fatalf("reserve: %v", err)
+		}
+		if reserved.ID != want {
+			t.Fatalf("expected %s reserved, got %s", want, reserved.ID)
+		}
+	}
+	if _, err := svc.DroneReserveJob(ctx, "drone-1", ""); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected a full drone to get ErrConflict, got %v", err)
+	}
+	drone, err := store.GetDrone(ctx, "drone-1")
+	if err != nil {
+		t.Fatalf("get drone: %v", err)
+	}
+	want := []string{first.ID + " pickup", second.ID + " pickup", second.ID + " dropoff", first.ID + " dropoff"}
+	var got []string
+	for _, stop := range drone.Stops {
+		got = append(got, stop.OrderID+" "+string(stop.Kind))
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("expected stops %v, got %v", want, got)
+	}
+	if drone.CurrentOrderID == nil || *drone.CurrentOrderID != first.ID {
+		t.Fatalf("expected the first order current, got %v", drone.CurrentOrderID)
+	}
+
+	// The second order's ETA flies the first order's pickup on the way.
+	view, err := svc.GetOrderView(ctx, "user-1", domain.RoleEndUser, second.ID)
+	if err != nil {
+		t.Fatalf("get order: %v", err)
+	}
+	var kinds []service.ETALegKind
+	for _, leg := range view.ETALegs {
+		kinds = append(kinds, leg.Kind)
+	}
+	if len(kinds) != 3 || kinds[0] != service.ETALegStop || kinds[1] != service.ETALegToPickup || kinds[2] != service.ETALegDelivery {
+		t.Fatalf("unexpected batched ETA legs %v", kinds)
+	}
+
+	// Stops are made in turn.
+	if _, err := svc.DronePickup(ctx, "drone-1", second.ID, ""); !errors.Is(err, domain.ErrPrecondition) {
+		t.Fatalf("expected an out-of-turn pickup to fail, got %v", err)
+	}
+	for _, id := range []string{first.ID, second.ID} {
+		if _, err := svc.DronePickup(ctx, "drone-1", id, ""); err != nil {
+			t.Fatalf("pickup %s: %v", id, err)
+		}
+	}
+	if _, err := svc.DroneDeliver(ctx, "drone-1", first.ID, ""); !errors.Is(err, domain.ErrPrecondition) {
+		t.Fatalf("expected an out-of-turn delivery to fail, got %v", err)
+	}
+	for _, id := range []string{second.ID, first.ID} {
+		if _, err := svc.DroneDeliver(ctx, "drone-1", id, ""); err != nil {
+			t.Fatalf("deliver %s: %v", id, err)
+		}
+	}
+	drone, err = store.GetDrone(ctx, "drone-1")
+	if err != nil {
+		t.Fatalf("get drone: %v", err)
+	}
+	if drone.CurrentOrderID != nil || drone.Stops != nil {
+		t.Fatalf("expected the trip ended, got current %v stops %+v", drone.CurrentOrderID, drone.Stops)
+	}
+}
+
+// lockingStore records the orders its transactions lock for reservation.
+type lockingStore struct {
+	*memory.Store
+	locked []string
+}
+
+func (l *lockingStore) BeginTx(ctx context.Context) (service.Tx, error) {
+	tx, err := l.Store.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &lockingTx{Tx: tx, store: l}, nil
+}
+
+type lockingTx struct {
+	service.Tx
+	store *lockingStore
+}
+
+func (t *lockingTx) ReserveOrder(ctx context.Context, id string, allowed []domain.OrderStatus, due time.Time) (*domain.Order, error) {
+	t.store.locked = append(t.store.locked, id)
+	return t.Tx.ReserveOrder(ctx, id, allowed, due)
+}
+
+func TestReserveJobLocksOnlyTheChosenOrder(t *testing.T) {
+	store := &lockingStore{Store: memory.NewStore()}
+	svc := service.New(store, 10)
+	svc.SetBatching(2, 1_000)
+	ctx := context.Background()
+	if _, err := svc.DroneHeartbeat(ctx, "drone-1", domain.Location{Lat: 0, Lng: 0}, domain.Vitals{}); err != nil {
+		t.Fatalf("heartbeat: %v", err)
+	}
+	submit := func(origin, dest domain.Location) *domain.Order {
+		t.Helper()
+		order, err := svc.SubmitOrder(ctx, "user-1", origin, dest, service.DeliveryWindow{}, "")
+		if err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+		return order
+	}
+	first := submit(domain.Location{Lat: 0, Lng: 0}, domain.Location{Lat: 0, Lng: 0.05})
+	far := submit(domain.Location{Lat: 0.5, Lng: 0.5}, domain.Location{Lat: 0.5, Lng: 0.55})
+	second := submit(domain.Location{Lat: 0, Lng: 0.001}, domain.Location{Lat: 0, Lng: 0.04})
+
+	for range 2 {
+		if _, err := svc.DroneReserveJob(ctx, "drone-1", ""); err != nil {
+			t.Fatalf("reserve: %v", err)
+		}
+	}
+	// The far order does not batch with the first, so it is passed over
+	// without being locked.
+	if want := fmt.Sprint([]string{first.ID, second.ID}); fmt.Sprint(store.locked) != want {
+		t.Fatalf("expected only %v locked, got %v (far order %s)", want, store.locked, far.ID)
+	}
+}
+
+func TestScheduledOrders(t *testing.T) {
+	store := memory.NewStore()
+	svc := service.New(store, 10)
+	ctx := context.Background()
+	heartbeat := func(droneID string) {
+		t.Helper()
+		if _, err := svc.DroneHeartbeat(ctx, droneID, domain.Location{Lat: 0, Lng: 0}, domain.Vitals{}); err != nil {
+			t.Fatalf("heartbeat: %v", err)
+		}
+	}
+	submit := func(dest domain.Location, window service.DeliveryWindow) *domain.Order {
+		t.Helper()
+		order, err := svc.SubmitOrder(ctx, "user-1", domain.Location{Lat: 0, Lng: 0}, dest, window, "")
+		if err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+		return order
+	}
+	heartbeat("drone-1")
+	now := time.Now()
+	at := func(d time.Duration) *time.Time {
+		v := now.Add(d)
+		return &v
+	}
+	near := domain.Location{Lat: 0, Lng: 0.01}
+
+	if _, err := svc.SubmitOrder(ctx, "user-1", domain.Location{Lat: 0, Lng: 0}, near, service.DeliveryWindow{DeliverBy: at(-time.Minute)}, ""); !errors.Is(err, domain.ErrInvalid) {
+		t.Fatalf("expected a past deliver_by to be invalid, got %v", err)
+	}
+	if _, err := svc.SubmitOrder(ctx, "user-1", domain.Location{Lat: 0, Lng: 0}, near, service.DeliveryWindow{PickupNotBefore: at(2 * time.Hour), DeliverBy: at(time.Hour)}, ""); !errors.Is(err, domain.ErrInvalid) {
+		t.Fatalf("expected a deliver_by before pickup_not_before to be invalid, got %v", err)
+	}
+
+	plain := submit(near, service.DeliveryWindow{})
+	later := submit(near, service.DeliveryWindow{PickupNotBefore: at(time.Hour)})
+	urgent := submit(near, service.DeliveryWindow{DeliverBy: at(10 * time.Minute)})
+	if urgent.SLAAtRiskAt != nil {
+		t.Fatalf("expected an order on time not to be at risk")
+	}
+
+	// The urgent order jumps the queue and the one not yet due is left.
+	for i, want := range []string{urgent.ID, plain.ID} {
+		droneID := fmt.Sprintf("drone-%d", i+1)
+		heartbeat(droneID)
+		reserved, err := svc.DroneReserveJob(ctx, droneID, "")
+		if err != nil {
+			t.Fatalf("reserve: %v", err)
+		}
+		if reserved.ID != want {
+			t.Fatalf("expected %s reserved, got %s", want, reserved.ID)
+		}
+	}
+	heartbeat("drone-3")
+	if _, err := svc.DroneReserveJob(ctx, "drone-3", ""); !errors.Is(err, domain.ErrNoJob) {
+		t.Fatalf("expected the order not yet due to be left, got %v", err)
+	}
+	view, err := svc.GetOrderView(ctx, "user-1", domain.RoleEndUser, later.ID)
+	if err != nil {
+		t.Fatalf("get order: %v", err)
+	}
+	if len(view.ETALegs) == 0 || view.ETALegs[0].Kind != service.ETALegQueue || view.ETALegs[0].Seconds < int64((59*time.Minute)/time.Second) {
+		t.Fatalf("expected the queue wait to last until pickup_not_before, got %+v", view.ETALegs)
+	}
+
+	// A deadline the drone cannot make flags the order at risk.
+	late := submit(domain.Location{Lat: 0, Lng: 0.5}, service.DeliveryWindow{DeliverBy: at(time.Minute)})
+	if late.SLAAtRiskAt == nil {
+		t.Fatalf("expected an order missing its deadline to be at risk")
+	}
+}
+
+func TestSubmitOrderSLAAtRiskCountsBacklog(t *testing.T) {
+	store := memory.NewStore()
+	svc := service.New(store, 10)
+	ctx := context.Background()
+	if _, err := svc.DroneHeartbeat(ctx, "drone-1", domain.Location{Lat: 0, Lng: 0}, domain.Vitals{}); err != nil {
+		t.Fatalf("heartbeat: %v", err)
+	}
+	origin := domain.Location{Lat: 0, Lng: 0}
+	near := domain.Location{Lat: 0, Lng: 0.01}
+	deliverBy := time.Now().Add(10 * time.Minute)
+	window := service.DeliveryWindow{DeliverBy: &deliverBy}
+
+	onTime, err := svc.SubmitOrder(ctx, "user-1", origin, near, window, "")
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if onTime.SLAAtRiskAt != nil {
+		t.Fatalf("expected an order with nothing ahead of it not to be at risk")
+	}
+
+	// One drone clearing 20 orders of this length takes well past the deadline.
+	seedQueuedOrders(t, store, 20)
+	late, err := svc.SubmitOrder(ctx, "user-1", origin, near, window, "")
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if late.SLAAtRiskAt == nil {
+		t.Fatalf("expected an order behind the backlog to be at risk")
+	}
+}
+
+// lockOrderStore records the rows its transactions lock, in order.
+type lockOrderStore struct {
+	*memory.Store
+	locks []string
+}
+
+func (l *lockOrderStore) BeginTx(ctx context.Context) (service.Tx, error) {
+	tx, err := l.Store.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &lockOrderTx{Tx: tx, store: l}, nil
+}
+
+type lockOrderTx struct {
+	service.Tx
+	store *lockOrderStore
+}
+
+func (t *lockOrderTx) GetDroneForUpdate(ctx context.Context, id string) (*domain.Drone, error) {
+	t.store.locks = append(t.store.locks, "drone:"+id)
+	return t.Tx.GetDroneForUpdate(ctx, id)
+}
+
+func (t *lockOrderTx) GetOrderForUpdate(ctx context.Context, id string) (*domain.Order, error) {
+	t.store.locks = append(t.store.locks, "order:"+id)
+	return t.Tx.GetOrderForUpdate(ctx, id)
+}
+
+func TestAdminUpdateOrderLocksDroneFirst(t *testing.T) {
+	store := &lockOrderStore{Store: memory.NewStore()}
+	svc := service.New(store, 10)
+	ctx := context.Background()
+	if _, err := svc.DroneHeartbeat(ctx, "drone-1", domain.Location{Lat: 0, Lng: 0}, domain.Vitals{}); err != nil {
+		t.Fatalf("heartbeat: %v", err)
+	}
+	order, err := svc.SubmitOrder(ctx, "user-1", domain.Location{Lat: 0, Lng: 0}, domain.Location{Lat: 0, Lng: 0.01}, service.DeliveryWindow{}, "")
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if _, err := svc.DroneReserveJob(ctx, "drone-1", ""); err != nil {
+		t.Fatalf("reserve: %v", err)
+	}
+
+	store.locks = nil
+	dest := domain.Location{Lat: 0, Lng: 0.02}
+	if _, err := svc.AdminUpdateOrder(ctx, order.ID, nil, &dest, 0); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if want := []string{"drone:drone-1", "order:" + order.ID}; fmt.Sprint(store.locks) != fmt.Sprint(want) {
+		t.Fatalf("expected locks %v, got %v", want, store.locks)
+	}
+	drone, _ := store.GetDrone(ctx, "drone-1")
+	for _, stop := range drone.Stops {
+		if stop.Kind == domain.StopDropoff && stop.Location != dest {
+			t.Fatalf("expected the dropoff moved to %v, got %v", dest, stop.Location)
+		}
+	}
+}
+
+func TestBatchedTripTelemetryTracksEveryOrderOnBoard(t *testing.T) {
+	store := memory.NewStore()
+	svc := service.New(store, 10)
+	svc.SetBatching(2, 1_000)
+	ctx := context.Background()
+	heartbeat := func(loc domain.Location) {
+		t.Helper()
+		if _, err := svc.DroneHeartbeat(ctx, "drone-1", loc, domain.Vitals{}); err != nil {
+			t.Fatalf("heartbeat: %v", err)
+		}
+	}
+	heartbeat(domain.Location{Lat: 0, Lng: 0})
+	var orders []*domain.Order
+	for _, origin := range []domain.Location{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 0.001}} {
+		order, err := svc.SubmitOrder(ctx, "user-1", origin, domain.Location{Lat: 0, Lng: 0.04}, service.DeliveryWindow{}, "")
+		if err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+		if _, err := svc.DroneReserveJob(ctx, "drone-1", ""); err != nil {
+			t.Fatalf("reserve: %v", err)
+		}
+		orders = append(orders, order)
+	}
+	for _, order := range orders {
+		if _, err := svc.DronePickup(ctx, "drone-1", order.ID, ""); err != nil {
+			t.Fatalf("pickup %s: %v", order.ID, err)
+		}
+	}
+	onBoard := domain.Location{Lat: 0, Lng: 0.02}
+	heartbeat(onBoard)
+
+	for _, order := range orders {
+		track, err := svc.AdminOrderTrack(ctx, order.ID, service.TrackRange{})
+		if err != nil {
+			t.Fatalf("order track: %v", err)
+		}
+		if len(track) == 0 || track[len(track)-1].Location != onBoard {
+			t.Fatalf("expected %s's track to end on board at %v, got %d samples", order.ID, onBoard, len(track))
+		}
+	}
+	track, err := svc.AdminDroneTrack(ctx, "drone-1", service.TrackRange{})
+	if err != nil {
+		t.Fatalf("drone track: %v", err)
+	}
+	if len(track) != 2 {
+		t.Fatalf("expected one position per heartbeat, got %d", len(track))
+	}
+}
+
+func TestReserveJobPagesPastUnflyableOrders(t *testing.T) {
+	store := memory.NewStore()
+	svc := service.New(store, 10)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	zone := domain.Polygon{{{Lat: -0.1, Lng: 0.9}, {Lat: -0.1, Lng: 1.1}, {Lat: 0.1, Lng: 1.1}, {Lat: 0.1, Lng: 0.9}, {Lat: -0.1, Lng: 0.9}}}
+	if _, err := svc.AdminCreateNoFlyZone(ctx, "Airport", zone, service.NoFlyWindow{}); err != nil {
+		t.Fatalf("create zone: %v", err)
+	}
+	// More blocked orders at the head of the queue than one page holds.
+	for i := 0; i < 120; i++ {
+		putOrder(t, store, &domain.Order{
+			ID: fmt.Sprintf("blocked-%03d", i), UserID: "user-1",
+			Origin: domain.Location{Lat: 0, Lng: 0}, Destination: domain.Location{Lat: 0, Lng: 1},
+			Status: domain.OrderStatusCreated, CreatedAt: now.Add(time.Duration(i-200) * time.Second), UpdatedAt: now,
+		})
+	}
+	clear := &domain.Order{
+		ID: "order-clear", UserID: "user-1",
+		Origin: domain.Location{Lat: 0, Lng: 0}, Destination: domain.Location{Lat: 1, Lng: 0},
+		Status: domain.OrderStatusCreated, CreatedAt: now, UpdatedAt: now,
+	}
+	putOrder(t, store, clear)
+
+	order, err := svc.DroneReserveJob(ctx, "drone-1", "")
+	if err != nil {
+		t.Fatalf("reserve: %v", err)
+	}
+	if order.ID != clear.ID {
+		t.Fatalf("expected %s reserved past the blocked orders, got %s", clear.ID, order.ID)
+	}
+	if _, err := svc.DroneReserveJob(ctx, "drone-2", ""); !errors.Is(err, domain.ErrNoJob) {
+		t.Fatalf("expected only blocked orders to remain, got %v", err)
+	}
+}
+
+func TestListOrdersRejectsOffset(t *testing.T) {
+	store := memory.NewStore()
+	svc := service.New(store, 10)
+	putOrder(t, store, &domain.Order{ID: "a", UserID: "u1", Status: domain.OrderStatusCreated, CreatedAt: time.Now().UTC()})
+
+	if _, err := svc.AdminListOrders(context.Background(), service.OrderFilter{Offset: 1}); !errors.Is(err, domain.ErrInvalid) {
+		t.Fatalf("admin: expected ErrInvalid, got %v", err)
+	}
+	if _, err := svc.ListMyOrders(context.Background(), "u1", service.OrderFilter{Offset: 1}); !errors.Is(err, domain.ErrInvalid) {
+		t.Fatalf("mine: expected ErrInvalid, got %v", err)
+	}
+	page, err := svc.ListMyOrders(context.Background(), "u1", service.OrderFilter{})
+	if err != nil || len(page.Orders) != 1 {
+		t.Fatalf("zero offset: got %v err=%v", page, err)
+	}
+}
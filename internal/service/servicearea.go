@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"penny-assesment/internal/domain"
+)
+
+const maxServiceAreaNameLength = 200
+
+// ServiceAreaUpdate holds the fields of an admin service area update; nil
+// fields are left unchanged.
+type ServiceAreaUpdate struct {
+	Name     *string
+	Boundary domain.Polygon
+	Active   *bool
+}
+
+func (s *Service) AdminListServiceAreas(ctx context.Context) ([]*domain.ServiceArea, error) {
+	return s.store.ListServiceAreas(ctx)
+}
+
+func (s *Service) AdminGetServiceArea(ctx context.Context, id string) (*domain.ServiceArea, error) {
+	return s.store.GetServiceArea(ctx, id)
+}
+
+func (s *Service) AdminCreateServiceArea(ctx context.Context, name string, boundary domain.Polygon, active bool) (*domain.ServiceArea, error) {
+	name = strings.TrimSpace(name)
+	if err := validateServiceArea(name, boundary); err != nil {
+		return nil, err
+	}
+	tx, err := s.store.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	now := s.now()
+	area := &domain.ServiceArea{
+		ID:        uuidFunc(),
+		Name:      name,
+		Boundary:  boundary,
+		Active:    active,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := tx.CreateServiceArea(ctx, area); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return area, nil
+}
+
+func (s *Service) AdminUpdateServiceArea(ctx context.Context, id string, update ServiceAreaUpdate, expectedVersion int64) (*domain.ServiceArea, error) {
+	tx, err := s.store.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	area, err := tx.GetServiceAreaForUpdate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(expectedVersion, area.Version); err != nil {
+		return nil, err
+	}
+	if update.Name != nil {
+		area.Name = strings.TrimSpace(*update.Name)
+	}
+	if update.Boundary != nil {
+		area.Boundary = update.Boundary
+	}
+	if update.Active != nil {
+		area.Active = *update.Active
+	}
+	if err := validateServiceArea(area.Name, area.Boundary); err != nil {
+		return nil, err
+	}
+	area.UpdatedAt = s.now()
+	if err := tx.UpdateServiceArea(ctx, area); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return area, nil
+}
+
+func validateServiceArea(name string, boundary domain.Polygon) error {
+	if name == "" || len(name) > maxServiceAreaNameLength {
+		return fmt.Errorf("name: %w", domain.ErrInvalid)
+	}
+	if err := domain.ValidatePolygon(boundary); err != nil {
+		return fmt.Errorf("boundary: %v: %w", err, domain.ErrInvalid)
+	}
+	return nil
+}
+
+// checkServiceAreas requires the given order locations (nil ones are skipped)
+// to lie inside an active service area. Until an admin defines the first
+// area, orders are not geofenced.
+func (s *Service) checkServiceAreas(ctx context.Context, origin, dest *domain.Location) error {
+	if origin == nil && dest == nil {
+		return nil
+	}
+	areas, err := s.store.ListServiceAreas(ctx)
+	if err != nil {
+		return err
+	}
+	if len(areas) == 0 {
+		return nil
+	}
+	check := func(field string, loc *domain.Location) error {
+		if loc == nil {
+			return nil
+		}
+		for _, area := range areas {
+			if area.Active && area.Boundary.Contains(*loc) {
+				return nil
+			}
+		}
+		return &domain.OutsideServiceAreaError{Field: field, Location: *loc}
+	}
+	if err := check("origin", origin); err != nil {
+		return err
+	}
+	return check("destination", dest)
+}
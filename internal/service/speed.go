@@ -0,0 +1,98 @@
+package service
+
+import (
+	"math"
+	"time"
+
+	"penny-assesment/internal/domain"
+)
+
+// Defaults for the heartbeat window kept per drone.
+const (
+	DefaultSpeedWindowFixes = 10
+	DefaultSpeedWindow      = 2 * time.Minute
+)
+
+const (
+	// minObservedFixes and minObservedSpan are the least history an observed
+	// speed is computed from.
+	minObservedFixes = 3
+	minObservedSpan  = 10 * time.Second
+	// fullConfidenceFixes is how many fixes an observed speed needs for full
+	// confidence, if the drone held its speed between them.
+	fullConfidenceFixes = 6
+	// nominalConfidence is the confidence of an ETA flown at the configured
+	// speed. Observed speeds less certain than this are not used.
+	nominalConfidence = 0.3
+)
+
+// ObservedSpeed is a drone's speed measured from its recent fixes.
+type ObservedSpeed struct {
+	MPS float64
+	// Confidence in [0, 1] grows with the number of fixes and falls as the
+	// speed between them varies.
+	Confidence float64
+}
+
+// ObserveSpeed measures how fast a drone is closing on target from its fixes
+// taken at or after since (nil for all of them): the drop in its distance to
+// target across the window, over the time the window spans. A drone circling
+// or detouring covers more ground than it gains, and this is what an ETA to
+// target needs. It returns nil when the fixes are too few or span too short a
+// time, or when the drone did not get closer to target over them.
+func ObserveSpeed(fixes []domain.Fix, target domain.Location, since *time.Time) *ObservedSpeed {
+	var window []domain.Fix
+	for _, fix := range fixes {
+		if since == nil || !fix.At.Before(*since) {
+			window = append(window, fix)
+		}
+	}
+	if len(window) < minObservedFixes {
+		return nil
+	}
+	first, last := window[0], window[len(window)-1]
+	span := last.At.Sub(first.At)
+	if span < minObservedSpan {
+		return nil
+	}
+	progress := domain.DistanceMeters(first.Location, target) - domain.DistanceMeters(last.Location, target)
+	if progress <= 0 {
+		return nil
+	}
+	mps := progress / span.Seconds()
+
+	var speeds []float64
+	for i := 1; i < len(window); i++ {
+		dt := window[i].At.Sub(window[i-1].At).Seconds()
+		if dt <= 0 {
+			continue
+		}
+		closed := domain.DistanceMeters(window[i-1].Location, target) - domain.DistanceMeters(window[i].Location, target)
+		speeds = append(speeds, closed/dt)
+	}
+	var variance float64
+	for _, v := range speeds {
+		variance += (v - mps) * (v - mps)
+	}
+	variance /= float64(len(speeds))
+	steadiness := math.Max(0, 1-math.Sqrt(variance)/mps)
+	coverage := math.Min(1, float64(len(window)-1)/float64(fullConfidenceFixes-1))
+	return &ObservedSpeed{MPS: mps, Confidence: coverage * steadiness}
+}
+
+// recordFix appends a fix to drone's recent fixes, keeping at most maxFixes
+// and none older than maxAge.
+func recordFix(drone *domain.Drone, fix domain.Fix, maxFixes int, maxAge time.Duration) {
+	cutoff := fix.At.Add(-maxAge)
+	fixes := make([]domain.Fix, 0, len(drone.RecentFixes)+1)
+	for _, recent := range drone.RecentFixes {
+		if recent.At.After(cutoff) && recent.At.Before(fix.At) {
+			fixes = append(fixes, recent)
+		}
+	}
+	fixes = append(fixes, fix)
+	if len(fixes) > maxFixes {
+		fixes = fixes[len(fixes)-maxFixes:]
+	}
+	drone.RecentFixes = fixes
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"penny-assesment/internal/domain"
+)
+
+// MaxTrackPoints bounds how many samples one track request returns.
+const MaxTrackPoints = 10_000
+
+// TelemetryQuery selects the telemetry of one drone (DroneID) or one order
+// (OrderID) recorded in [From, To); nil ends are open.
+type TelemetryQuery struct {
+	DroneID string
+	OrderID string
+	From    *time.Time
+	To      *time.Time
+	Limit   int
+}
+
+// Matches reports whether sample falls within the query.
+func (q TelemetryQuery) Matches(sample *domain.TelemetrySample) bool {
+	if q.DroneID != "" && sample.DroneID != q.DroneID {
+		return false
+	}
+	if q.OrderID != "" && (sample.OrderID == nil || *sample.OrderID != q.OrderID) {
+		return false
+	}
+	return inRange(sample.RecordedAt, q.From, q.To)
+}
+
+// TrackRange limits a track to the samples recorded in [From, To).
+type TrackRange struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// SetTelemetryRetention sets how long heartbeat telemetry is kept; zero keeps
+// it forever.
+func (s *Service) SetTelemetryRetention(retention time.Duration) {
+	s.telemetryRetention = retention
+}
+
+// AdminDroneTrack returns the positions drone droneID reported in the range,
+// oldest first, up to MaxTrackPoints. A heartbeat on a batched trip is stored
+// once per order on board; the track keeps one position per heartbeat.
+func (s *Service) AdminDroneTrack(ctx context.Context, droneID string, rng TrackRange) ([]*domain.TelemetrySample, error) {
+	if err := rng.validate(); err != nil {
+		return nil, err
+	}
+	if _, err := s.store.GetDrone(ctx, droneID); err != nil {
+		return nil, err
+	}
+	samples, err := s.store.ListTelemetry(ctx, TelemetryQuery{DroneID: droneID, From: rng.From, To: rng.To, Limit: MaxTrackPoints})
+	if err != nil {
+		return nil, err
+	}
+	track := samples[:0]
+	for _, sample := range samples {
+		if n := len(track); n > 0 && track[n-1].RecordedAt.Equal(sample.RecordedAt) {
+			continue
+		}
+		track = append(track, sample)
+	}
+	return track, nil
+}
+
+// AdminOrderTrack returns the positions reported by the drones carrying out
+// order orderID in the range, oldest first, up to MaxTrackPoints.
+func (s *Service) AdminOrderTrack(ctx context.Context, orderID string, rng TrackRange) ([]*domain.TelemetrySample, error) {
+	if err := rng.validate(); err != nil {
+		return nil, err
+	}
+	if _, err := s.store.GetOrder(ctx, orderID); err != nil {
+		return nil, err
+	}
+	return s.store.ListTelemetry(ctx, TelemetryQuery{OrderID: orderID, From: rng.From, To: rng.To, Limit: MaxTrackPoints})
+}
+
+// PruneTelemetry deletes telemetry older than the retention period and
+// returns how many samples went. Without a retention period nothing is
+// deleted, but the store still gets to do its upkeep.
+func (s *Service) PruneTelemetry(ctx context.Context) (int64, error) {
+	var before time.Time
+	if s.telemetryRetention > 0 {
+		before = s.now().Add(-s.telemetryRetention)
+	}
+	return s.store.PruneTelemetry(ctx, before)
+}
+
+func (r TrackRange) validate() error {
+	if r.From != nil && r.To != nil && !r.From.Before(*r.To) {
+		return fmt.Errorf("time range: %w", domain.ErrInvalid)
+	}
+	return nil
+}
@@ -11,7 +11,8 @@ import (
 type Empty struct{}
 
 type ListOrdersResponse struct {
-	Orders []transport.OrderViewResponse `json:"orders"`
+	Orders     []transport.OrderViewResponse `json:"orders"`
+	NextCursor string                        `json:"next_cursor,omitempty"`
 }
 
 type ListDronesResponse struct {
@@ -26,6 +27,7 @@ type OrderService interface {
 	SubmitOrder(context.Context, *SubmitOrderRequest) (*transport.OrderResponse, error)
 	WithdrawOrder(context.Context, *OrderIDRequest) (*transport.OrderResponse, error)
 	GetOrder(context.Context, *OrderIDRequest) (*transport.OrderViewResponse, error)
+	ListMyOrders(context.Context, *ListOrdersRequest) (*ListOrdersResponse, error)
 }
 
 type DroneService interface {
@@ -34,16 +36,47 @@ type DroneService interface {
 	DeliverOrder(context.Context, *OrderIDRequest) (*transport.OrderResponse, error)
 	FailOrder(context.Context, *FailOrderRequest) (*transport.OrderResponse, error)
 	MarkDroneBroken(context.Context, *Empty) (*transport.DroneResponse, error)
+	SetStatus(context.Context, *DroneStatusRequest) (*transport.DroneResponse, error)
 	Heartbeat(context.Context, *HeartbeatRequest) (*transport.DroneStatusResponse, error)
 	CurrentOrder(context.Context, *Empty) (*transport.OrderViewResponse, error)
+	ReserveChargingSlot(context.Context, *ReserveChargingSlotRequest) (*transport.ChargingSlotResponse, error)
+	ReleaseChargingSlot(context.Context, *Empty) (*transport.ChargingSlotResponse, error)
 }
 
 type AdminService interface {
 	AdminListOrders(context.Context, *ListOrdersRequest) (*ListOrdersResponse, error)
 	AdminUpdateOrder(context.Context, *UpdateOrderRequest) (*transport.OrderResponse, error)
+	AdminAssignOrder(context.Context, *AssignOrderRequest) (*transport.OrderResponse, error)
+	AdminUnassignOrder(context.Context, *OrderIDRequest) (*transport.OrderResponse, error)
+	AdminReassignOrder(context.Context, *AssignOrderRequest) (*transport.OrderResponse, error)
+	AdminOverrideOrder(context.Context, *OverrideOrderRequest) (*transport.OrderResponse, error)
+	AdminOrdersNear(context.Context, *OrdersNearRequest) (*ListOrdersResponse, error)
 	AdminListDrones(context.Context, *Empty) (*ListDronesResponse, error)
+	AdminNearestIdleDrones(context.Context, *NearestDronesRequest) (*ListDronesResponse, error)
 	AdminMarkDroneBroken(context.Context, *DroneIDRequest) (*transport.DroneResponse, error)
-	AdminMarkDroneFixed(context.Context, *DroneIDRequest) (*transport.DroneResponse, error)
+	AdminMarkDroneFixed(context.Context, *FixDroneRequest) (*transport.DroneResponse, error)
+	AdminSetDroneStatus(context.Context, *AdminDroneStatusRequest) (*transport.DroneResponse, error)
+	AdminRetireDrone(context.Context, *DroneIDRequest) (*transport.DroneResponse, error)
+	AdminListServiceAreas(context.Context, *Empty) (*ListServiceAreasResponse, error)
+	AdminGetServiceArea(context.Context, *ServiceAreaIDRequest) (*ServiceAreaResponse, error)
+	AdminCreateServiceArea(context.Context, *CreateServiceAreaRequest) (*ServiceAreaResponse, error)
+	AdminUpdateServiceArea(context.Context, *UpdateServiceAreaRequest) (*ServiceAreaResponse, error)
+	AdminListNoFlyZones(context.Context, *Empty) (*ListNoFlyZonesResponse, error)
+	AdminGetNoFlyZone(context.Context, *NoFlyZoneIDRequest) (*NoFlyZoneResponse, error)
+	AdminCreateNoFlyZone(context.Context, *CreateNoFlyZoneRequest) (*NoFlyZoneResponse, error)
+	AdminUpdateNoFlyZone(context.Context, *UpdateNoFlyZoneRequest) (*NoFlyZoneResponse, error)
+	AdminDroneTrack(context.Context, *DroneTrackRequest) (*TrackResponse, error)
+	AdminOrderTrack(context.Context, *OrderTrackRequest) (*TrackResponse, error)
+	AdminDroneMaintenance(context.Context, *DroneIDRequest) (*DroneMaintenanceResponse, error)
+	AdminSetDroneDepot(context.Context, *SetDroneDepotRequest) (*transport.DroneResponse, error)
+	AdminListDepots(context.Context, *Empty) (*ListDepotsResponse, error)
+	AdminGetDepot(context.Context, *DepotIDRequest) (*transport.DepotResponse, error)
+	AdminCreateDepot(context.Context, *CreateDepotRequest) (*transport.DepotResponse, error)
+	AdminUpdateDepot(context.Context, *UpdateDepotRequest) (*transport.DepotResponse, error)
+	AdminListChargingStations(context.Context, *Empty) (*ListChargingStationsResponse, error)
+	AdminGetChargingStation(context.Context, *ChargingStationIDRequest) (*transport.ChargingStationResponse, error)
+	AdminCreateChargingStation(context.Context, *CreateChargingStationRequest) (*transport.ChargingStationResponse, error)
+	AdminUpdateChargingStation(context.Context, *UpdateChargingStationRequest) (*transport.ChargingStationResponse, error)
 }
 
 var authServiceDesc = grpc.ServiceDesc{
@@ -68,8 +101,11 @@ var droneServiceDesc = grpc.ServiceDesc{
 		{MethodName: "DeliverOrder", Handler: deliverOrderHandler},
 		{MethodName: "FailOrder", Handler: failOrderHandler},
 		{MethodName: "MarkBroken", Handler: markBrokenHandler},
+		{MethodName: "SetStatus", Handler: setStatusHandler},
 		{MethodName: "Heartbeat", Handler: heartbeatHandler},
 		{MethodName: "CurrentOrder", Handler: currentOrderHandler},
+		{MethodName: "ReserveChargingSlot", Handler: reserveChargingSlotHandler},
+		{MethodName: "ReleaseChargingSlot", Handler: releaseChargingSlotHandler},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "drone_delivery.proto",
@@ -82,6 +118,7 @@ var orderServiceDesc = grpc.ServiceDesc{
 		{MethodName: "SubmitOrder", Handler: submitOrderHandler},
 		{MethodName: "WithdrawOrder", Handler: withdrawOrderHandler},
 		{MethodName: "GetOrder", Handler: getOrderHandler},
+		{MethodName: "ListMyOrders", Handler: listMyOrdersHandler},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "drone_delivery.proto",
@@ -93,9 +130,37 @@ var adminServiceDesc = grpc.ServiceDesc{
 	Methods: []grpc.MethodDesc{
 		{MethodName: "ListOrders", Handler: adminListOrdersHandler},
 		{MethodName: "UpdateOrder", Handler: adminUpdateOrderHandler},
+		{MethodName: "AssignOrder", Handler: adminAssignOrderHandler},
+		{MethodName: "UnassignOrder", Handler: adminUnassignOrderHandler},
+		{MethodName: "ReassignOrder", Handler: adminReassignOrderHandler},
+		{MethodName: "OverrideOrder", Handler: adminOverrideOrderHandler},
+		{MethodName: "OrdersNear", Handler: adminOrdersNearHandler},
 		{MethodName: "ListDrones", Handler: adminListDronesHandler},
+		{MethodName: "NearestIdleDrones", Handler: adminNearestIdleDronesHandler},
 		{MethodName: "MarkDroneBroken", Handler: adminMarkDroneBrokenHandler},
 		{MethodName: "MarkDroneFixed", Handler: adminMarkDroneFixedHandler},
+		{MethodName: "SetDroneStatus", Handler: adminSetDroneStatusHandler},
+		{MethodName: "RetireDrone", Handler: adminRetireDroneHandler},
+		{MethodName: "ListServiceAreas", Handler: adminListServiceAreasHandler},
+		{MethodName: "GetServiceArea", Handler: adminGetServiceAreaHandler},
+		{MethodName: "CreateServiceArea", Handler: adminCreateServiceAreaHandler},
+		{MethodName: "UpdateServiceArea", Handler: adminUpdateServiceAreaHandler},
+		{MethodName: "ListNoFlyZones", Handler: adminListNoFlyZonesHandler},
+		{MethodName: "GetNoFlyZone", Handler: adminGetNoFlyZoneHandler},
+		{MethodName: "CreateNoFlyZone", Handler: adminCreateNoFlyZoneHandler},
+		{MethodName: "UpdateNoFlyZone", Handler: adminUpdateNoFlyZoneHandler},
+		{MethodName: "DroneTrack", Handler: adminDroneTrackHandler},
+		{MethodName: "OrderTrack", Handler: adminOrderTrackHandler},
+		{MethodName: "DroneMaintenance", Handler: adminDroneMaintenanceHandler},
+		{MethodName: "SetDroneDepot", Handler: adminSetDroneDepotHandler},
+		{MethodName: "ListDepots", Handler: adminListDepotsHandler},
+		{MethodName: "GetDepot", Handler: adminGetDepotHandler},
+		{MethodName: "CreateDepot", Handler: adminCreateDepotHandler},
+		{MethodName: "UpdateDepot", Handler: adminUpdateDepotHandler},
+		{MethodName: "ListChargingStations", Handler: adminListChargingStationsHandler},
+		{MethodName: "GetChargingStation", Handler: adminGetChargingStationHandler},
+		{MethodName: "CreateChargingStation", Handler: adminCreateChargingStationHandler},
+		{MethodName: "UpdateChargingStation", Handler: adminUpdateChargingStationHandler},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "drone_delivery.proto",
@@ -161,6 +226,21 @@ func getOrderHandler(srv any, ctx context.Context, dec func(any) error, intercep
 	return interceptor(ctx, in, info, handler)
 }
 
+func listMyOrdersHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(ListOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).ListMyOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.OrderService/ListMyOrders"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).ListMyOrders(ctx, req.(*ListOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func reserveJobHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
@@ -236,6 +316,21 @@ func markBrokenHandler(srv any, ctx context.Context, dec func(any) error, interc
 	return interceptor(ctx, in, info, handler)
 }
 
+func setStatusHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(DroneStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).SetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.DroneService/SetStatus"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).SetStatus(ctx, req.(*DroneStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func heartbeatHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
 	in := new(HeartbeatRequest)
 	if err := dec(in); err != nil {
@@ -266,6 +361,36 @@ func currentOrderHandler(srv any, ctx context.Context, dec func(any) error, inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func reserveChargingSlotHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(ReserveChargingSlotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).ReserveChargingSlot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.DroneService/ReserveChargingSlot"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).ReserveChargingSlot(ctx, req.(*ReserveChargingSlotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func releaseChargingSlotHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).ReleaseChargingSlot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.DroneService/ReleaseChargingSlot"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).ReleaseChargingSlot(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func adminListOrdersHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
 	in := new(ListOrdersRequest)
 	if err := dec(in); err != nil {
@@ -296,6 +421,81 @@ func adminUpdateOrderHandler(srv any, ctx context.Context, dec func(any) error,
 	return interceptor(ctx, in, info, handler)
 }
 
+func adminAssignOrderHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(AssignOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).AdminAssignOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/AssignOrder"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).AdminAssignOrder(ctx, req.(*AssignOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func adminUnassignOrderHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(OrderIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).AdminUnassignOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/UnassignOrder"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).AdminUnassignOrder(ctx, req.(*OrderIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func adminReassignOrderHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(AssignOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).AdminReassignOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/ReassignOrder"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).AdminReassignOrder(ctx, req.(*AssignOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func adminOverrideOrderHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(OverrideOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).AdminOverrideOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/OverrideOrder"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).AdminOverrideOrder(ctx, req.(*OverrideOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func adminOrdersNearHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(OrdersNearRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).AdminOrdersNear(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/OrdersNear"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).AdminOrdersNear(ctx, req.(*OrdersNearRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func adminListDronesHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
@@ -311,6 +511,21 @@ func adminListDronesHandler(srv any, ctx context.Context, dec func(any) error, i
 	return interceptor(ctx, in, info, handler)
 }
 
+func adminNearestIdleDronesHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(NearestDronesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).AdminNearestIdleDrones(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/NearestIdleDrones"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).AdminNearestIdleDrones(ctx, req.(*NearestDronesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func adminMarkDroneBrokenHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
 	in := new(DroneIDRequest)
 	if err := dec(in); err != nil {
@@ -327,7 +542,7 @@ func adminMarkDroneBrokenHandler(srv any, ctx context.Context, dec func(any) err
 }
 
 func adminMarkDroneFixedHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
-	in := new(DroneIDRequest)
+	in := new(FixDroneRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -336,7 +551,337 @@ func adminMarkDroneFixedHandler(srv any, ctx context.Context, dec func(any) erro
 	}
 	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/MarkDroneFixed"}
 	handler := func(ctx context.Context, req any) (any, error) {
-		return srv.(*Server).AdminMarkDroneFixed(ctx, req.(*DroneIDRequest))
+		return srv.(*Server).AdminMarkDroneFixed(ctx, req.(*FixDroneRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func adminSetDroneStatusHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(AdminDroneStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).AdminSetDroneStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/SetDroneStatus"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).AdminSetDroneStatus(ctx, req.(*AdminDroneStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func adminRetireDroneHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(DroneIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).AdminRetireDrone(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/RetireDrone"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).AdminRetireDrone(ctx, req.(*DroneIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func adminListServiceAreasHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).AdminListServiceAreas(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/ListServiceAreas"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).AdminListServiceAreas(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func adminGetServiceAreaHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(ServiceAreaIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).AdminGetServiceArea(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/GetServiceArea"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).AdminGetServiceArea(ctx, req.(*ServiceAreaIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func adminCreateServiceAreaHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(CreateServiceAreaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).AdminCreateServiceArea(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/CreateServiceArea"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).AdminCreateServiceArea(ctx, req.(*CreateServiceAreaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func adminUpdateServiceAreaHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(UpdateServiceAreaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).AdminUpdateServiceArea(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/UpdateServiceArea"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).AdminUpdateServiceArea(ctx, req.(*UpdateServiceAreaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func adminListNoFlyZonesHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).AdminListNoFlyZones(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/ListNoFlyZones"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).AdminListNoFlyZones(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func adminGetNoFlyZoneHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(NoFlyZoneIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).AdminGetNoFlyZone(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/GetNoFlyZone"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).AdminGetNoFlyZone(ctx, req.(*NoFlyZoneIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func adminCreateNoFlyZoneHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(CreateNoFlyZoneRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).AdminCreateNoFlyZone(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/CreateNoFlyZone"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).AdminCreateNoFlyZone(ctx, req.(*CreateNoFlyZoneRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func adminUpdateNoFlyZoneHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(UpdateNoFlyZoneRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).AdminUpdateNoFlyZone(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/UpdateNoFlyZone"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).AdminUpdateNoFlyZone(ctx, req.(*UpdateNoFlyZoneRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func adminDroneTrackHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(DroneTrackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).AdminDroneTrack(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/DroneTrack"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).AdminDroneTrack(ctx, req.(*DroneTrackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func adminOrderTrackHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(OrderTrackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).AdminOrderTrack(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/OrderTrack"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).AdminOrderTrack(ctx, req.(*OrderTrackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func adminDroneMaintenanceHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(DroneIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).AdminDroneMaintenance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/DroneMaintenance"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).AdminDroneMaintenance(ctx, req.(*DroneIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func adminSetDroneDepotHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(SetDroneDepotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).AdminSetDroneDepot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/SetDroneDepot"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).AdminSetDroneDepot(ctx, req.(*SetDroneDepotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func adminListDepotsHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).AdminListDepots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/ListDepots"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).AdminListDepots(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func adminGetDepotHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(DepotIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).AdminGetDepot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/GetDepot"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).AdminGetDepot(ctx, req.(*DepotIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func adminCreateDepotHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(CreateDepotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).AdminCreateDepot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/CreateDepot"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).AdminCreateDepot(ctx, req.(*CreateDepotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func adminUpdateDepotHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(UpdateDepotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).AdminUpdateDepot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/UpdateDepot"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).AdminUpdateDepot(ctx, req.(*UpdateDepotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func adminListChargingStationsHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).AdminListChargingStations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/ListChargingStations"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).AdminListChargingStations(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func adminGetChargingStationHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(ChargingStationIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).AdminGetChargingStation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/GetChargingStation"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).AdminGetChargingStation(ctx, req.(*ChargingStationIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func adminCreateChargingStationHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(CreateChargingStationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).AdminCreateChargingStation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/CreateChargingStation"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).AdminCreateChargingStation(ctx, req.(*CreateChargingStationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func adminUpdateChargingStationHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(UpdateChargingStationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(*Server).AdminUpdateChargingStation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/drone.AdminService/UpdateChargingStation"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(*Server).AdminUpdateChargingStation(ctx, req.(*UpdateChargingStationRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -3,12 +3,16 @@ package grpcapi
 import (
 	"context"
 	"errors"
+	"strings"
+	"time"
 
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	"penny-assesment/internal/auth"
 	"penny-assesment/internal/domain"
+	"penny-assesment/internal/service"
 	"penny-assesment/internal/transport"
 )
 
@@ -41,8 +45,26 @@ func mapServiceError(err error) error {
 		return status.Error(codes.NotFound, "not found")
 	case errors.Is(err, domain.ErrConflict):
 		return status.Error(codes.Aborted, "conflict")
+	case errors.Is(err, domain.ErrOutsideServiceArea):
+		var outside *domain.OutsideServiceAreaError
+		if errors.As(err, &outside) {
+			return status.Error(codes.InvalidArgument, outside.Error())
+		}
+		return status.Error(codes.InvalidArgument, "location is outside every active service area")
+	case errors.Is(err, domain.ErrRouteBlocked):
+		var blocked *domain.RouteBlockedError
+		if errors.As(err, &blocked) {
+			return status.Error(codes.InvalidArgument, blocked.Error())
+		}
+		return status.Error(codes.InvalidArgument, "route crosses a no-fly zone")
+	case errors.Is(err, domain.ErrOutOfRange):
+		return status.Error(codes.InvalidArgument, "no relay path within drone range")
+	case errors.Is(err, domain.ErrIdempotencyKeyReused):
+		return status.Error(codes.InvalidArgument, "idempotency key reused for a different request")
 	case errors.Is(err, domain.ErrInvalid):
 		return status.Error(codes.InvalidArgument, "invalid request")
+	case errors.Is(err, domain.ErrVersionMismatch):
+		return status.Error(codes.FailedPrecondition, "version mismatch")
 	case errors.Is(err, domain.ErrPrecondition):
 		return status.Error(codes.FailedPrecondition, "precondition failed")
 	case errors.Is(err, domain.ErrNoJob):
@@ -52,6 +74,146 @@ func mapServiceError(err error) error {
 	}
 }
 
+// idempotencyKey returns the "idempotency-key" metadata value, or "" if the
+// call should not be deduplicated.
+func idempotencyKey(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("idempotency-key"); len(values) > 0 {
+		return strings.TrimSpace(values[0])
+	}
+	return ""
+}
+
 func toDomainLocation(loc transport.Location) domain.Location {
 	return domain.Location{Lat: loc.Lat, Lng: loc.Lng}
 }
+
+func toOrderFilter(req *ListOrdersRequest) (service.OrderFilter, error) {
+	filter := service.OrderFilter{Limit: req.Limit, Offset: req.Offset, Cursor: req.Cursor, Sort: service.OrderSort(req.Sort)}
+	if req.Status != "" {
+		filter.Statuses = append(filter.Statuses, domain.OrderStatus(req.Status))
+	}
+	for _, status := range req.Statuses {
+		filter.Statuses = append(filter.Statuses, domain.OrderStatus(status))
+	}
+	var err error
+	if filter.CreatedFrom, err = parseTime(req.CreatedFrom); err != nil {
+		return filter, err
+	}
+	if filter.CreatedTo, err = parseTime(req.CreatedTo); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+func toAdminOrderFilter(req *ListOrdersRequest) (service.OrderFilter, error) {
+	filter, err := toOrderFilter(req)
+	if err != nil {
+		return filter, err
+	}
+	if req.UserID != "" {
+		filter.UserID = &req.UserID
+	}
+	if req.DroneID != "" {
+		filter.DroneID = &req.DroneID
+	}
+	if filter.UpdatedFrom, err = parseTime(req.UpdatedFrom); err != nil {
+		return filter, err
+	}
+	if filter.UpdatedTo, err = parseTime(req.UpdatedTo); err != nil {
+		return filter, err
+	}
+	filter.FailureReason = req.FailureReason
+	filter.OriginBox = toDomainBox(req.OriginBox)
+	filter.DestinationBox = toDomainBox(req.DestinationBox)
+	return filter, nil
+}
+
+func toDomainBox(box *BoundingBox) *domain.BoundingBox {
+	if box == nil {
+		return nil
+	}
+	return &domain.BoundingBox{MinLat: box.MinLat, MinLng: box.MinLng, MaxLat: box.MaxLat, MaxLng: box.MaxLng}
+}
+
+func toListOrdersResponse(page *service.OrderPage) *ListOrdersResponse {
+	resp := &ListOrdersResponse{
+		Orders:     make([]transport.OrderViewResponse, 0, len(page.Orders)),
+		NextCursor: page.NextCursor,
+	}
+	for _, view := range page.Orders {
+		resp.Orders = append(resp.Orders, transport.FromOrderView(view))
+	}
+	return resp
+}
+
+func toDomainPolygon(polygon Polygon) domain.Polygon {
+	rings := make(domain.Polygon, 0, len(polygon.Rings))
+	for _, ring := range polygon.Rings {
+		points := make([]domain.Location, 0, len(ring.Points))
+		for _, point := range ring.Points {
+			points = append(points, toDomainLocation(point))
+		}
+		rings = append(rings, points)
+	}
+	return rings
+}
+
+func toPolygon(polygon domain.Polygon) Polygon {
+	var resp Polygon
+	for _, ring := range polygon {
+		points := make([]transport.Location, 0, len(ring))
+		for _, loc := range ring {
+			points = append(points, transport.Location{Lat: loc.Lat, Lng: loc.Lng})
+		}
+		resp.Rings = append(resp.Rings, Ring{Points: points})
+	}
+	return resp
+}
+
+func toServiceAreaResponse(area *domain.ServiceArea) *ServiceAreaResponse {
+	return &ServiceAreaResponse{
+		ID:        area.ID,
+		Name:      area.Name,
+		Boundary:  toPolygon(area.Boundary),
+		Active:    area.Active,
+		CreatedAt: area.CreatedAt,
+		UpdatedAt: area.UpdatedAt,
+		Version:   area.Version,
+	}
+}
+
+func toNoFlyZoneResponse(zone *domain.NoFlyZone) *NoFlyZoneResponse {
+	return &NoFlyZoneResponse{
+		ID:        zone.ID,
+		Name:      zone.Name,
+		Boundary:  toPolygon(zone.Boundary),
+		Window:    NoFlyWindow{ActiveFrom: zone.ActiveFrom, ActiveUntil: zone.ActiveUntil},
+		CreatedAt: zone.CreatedAt,
+		UpdatedAt: zone.UpdatedAt,
+		Version:   zone.Version,
+	}
+}
+
+func parseTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, domain.ErrInvalid
+	}
+	return &t, nil
+}
+
+func toTrackRange(from, to string) (service.TrackRange, error) {
+	var rng service.TrackRange
+	var err error
+	if rng.From, err = parseTime(from); err != nil {
+		return rng, err
+	}
+	if rng.To, err = parseTime(to); err != nil {
+		return rng, err
+	}
+	return rng, nil
+}
@@ -71,7 +71,8 @@ func (s *Server) SubmitOrder(ctx context.Context, req *SubmitOrderRequest) (*tra
 	if err != nil {
 		return nil, err
 	}
-	order, err := s.svc.SubmitOrder(ctx, claims.Subject, toDomainLocation(req.Origin), toDomainLocation(req.Destination))
+	window := service.DeliveryWindow{PickupNotBefore: req.PickupNotBefore, DeliverBy: req.DeliverBy}
+	order, err := s.svc.SubmitOrder(ctx, claims.Subject, toDomainLocation(req.Origin), toDomainLocation(req.Destination), window, idempotencyKey(ctx))
 	if err != nil {
 		return nil, mapServiceError(err)
 	}
@@ -84,7 +85,7 @@ func (s *Server) WithdrawOrder(ctx context.Context, req *OrderIDRequest) (*trans
 	if err != nil {
 		return nil, err
 	}
-	order, err := s.svc.WithdrawOrder(ctx, claims.Subject, req.OrderID)
+	order, err := s.svc.WithdrawOrder(ctx, claims.Subject, req.OrderID, req.ExpectedVersion)
 	if err != nil {
 		return nil, mapServiceError(err)
 	}
@@ -105,12 +106,28 @@ func (s *Server) GetOrder(ctx context.Context, req *OrderIDRequest) (*transport.
 	return &resp, nil
 }
 
+func (s *Server) ListMyOrders(ctx context.Context, req *ListOrdersRequest) (*ListOrdersResponse, error) {
+	claims, err := requireRole(ctx, domain.RoleEndUser)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := toOrderFilter(req)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	page, err := s.svc.ListMyOrders(ctx, claims.Subject, filter)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	return toListOrdersResponse(page), nil
+}
+
 func (s *Server) ReserveJob(ctx context.Context, _ *Empty) (*transport.OrderResponse, error) {
 	claims, err := requireRole(ctx, domain.RoleDrone)
 	if err != nil {
 		return nil, err
 	}
-	order, err := s.svc.DroneReserveJob(ctx, claims.Subject)
+	order, err := s.svc.DroneReserveJob(ctx, claims.Subject, idempotencyKey(ctx))
 	if err != nil {
 		return nil, mapServiceError(err)
 	}
@@ -123,7 +140,7 @@ func (s *Server) PickupOrder(ctx context.Context, req *OrderIDRequest) (*transpo
 	if err != nil {
 		return nil, err
 	}
-	order, err := s.svc.DronePickup(ctx, claims.Subject, req.OrderID)
+	order, err := s.svc.DronePickup(ctx, claims.Subject, req.OrderID, idempotencyKey(ctx))
 	if err != nil {
 		return nil, mapServiceError(err)
 	}
@@ -136,7 +153,7 @@ func (s *Server) DeliverOrder(ctx context.Context, req *OrderIDRequest) (*transp
 	if err != nil {
 		return nil, err
 	}
-	order, err := s.svc.DroneDeliver(ctx, claims.Subject, req.OrderID)
+	order, err := s.svc.DroneDeliver(ctx, claims.Subject, req.OrderID, idempotencyKey(ctx))
 	if err != nil {
 		return nil, mapServiceError(err)
 	}
@@ -149,7 +166,7 @@ func (s *Server) FailOrder(ctx context.Context, req *FailOrderRequest) (*transpo
 	if err != nil {
 		return nil, err
 	}
-	order, err := s.svc.DroneFail(ctx, claims.Subject, req.OrderID, req.Reason)
+	order, err := s.svc.DroneFail(ctx, claims.Subject, req.OrderID, req.Reason, idempotencyKey(ctx))
 	if err != nil {
 		return nil, mapServiceError(err)
 	}
@@ -162,7 +179,20 @@ func (s *Server) MarkDroneBroken(ctx context.Context, _ *Empty) (*transport.Dron
 	if err != nil {
 		return nil, err
 	}
-	drone, err := s.svc.DroneMarkBroken(ctx, claims.Subject)
+	drone, err := s.svc.DroneMarkBroken(ctx, claims.Subject, idempotencyKey(ctx))
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	resp := transport.FromDrone(drone)
+	return &resp, nil
+}
+
+func (s *Server) SetStatus(ctx context.Context, req *DroneStatusRequest) (*transport.DroneResponse, error) {
+	claims, err := requireRole(ctx, domain.RoleDrone)
+	if err != nil {
+		return nil, err
+	}
+	drone, err := s.svc.DroneSetStatus(ctx, claims.Subject, domain.DroneStatus(req.Status))
 	if err != nil {
 		return nil, mapServiceError(err)
 	}
@@ -175,7 +205,8 @@ func (s *Server) Heartbeat(ctx context.Context, req *HeartbeatRequest) (*transpo
 	if err != nil {
 		return nil, err
 	}
-	view, err := s.svc.DroneHeartbeat(ctx, claims.Subject, domain.Location{Lat: req.Lat, Lng: req.Lng})
+	loc := domain.Location{Lat: req.Lat, Lng: req.Lng}
+	view, err := s.svc.DroneHeartbeat(ctx, claims.Subject, loc, transport.ToVitals(req.Vitals))
 	if err != nil {
 		return nil, mapServiceError(err)
 	}
@@ -196,24 +227,67 @@ func (s *Server) CurrentOrder(ctx context.Context, _ *Empty) (*transport.OrderVi
 	return &resp, nil
 }
 
+func (s *Server) ReserveChargingSlot(ctx context.Context, req *ReserveChargingSlotRequest) (*transport.ChargingSlotResponse, error) {
+	claims, err := requireRole(ctx, domain.RoleDrone)
+	if err != nil {
+		return nil, err
+	}
+	if req.StationID == "" {
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+	slot, err := s.svc.DroneReserveChargingSlot(ctx, claims.Subject, req.StationID)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	resp := transport.FromChargingSlot(slot)
+	return &resp, nil
+}
+
+func (s *Server) ReleaseChargingSlot(ctx context.Context, _ *Empty) (*transport.ChargingSlotResponse, error) {
+	claims, err := requireRole(ctx, domain.RoleDrone)
+	if err != nil {
+		return nil, err
+	}
+	slot, err := s.svc.DroneReleaseChargingSlot(ctx, claims.Subject)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	resp := transport.FromChargingSlot(slot)
+	return &resp, nil
+}
+
 func (s *Server) AdminListOrders(ctx context.Context, req *ListOrdersRequest) (*ListOrdersResponse, error) {
 	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
 		return nil, err
 	}
-	var status *domain.OrderStatus
-	if req.Status != "" {
-		st := domain.OrderStatus(req.Status)
-		status = &st
+	filter, err := toAdminOrderFilter(req)
+	if err != nil {
+		return nil, mapServiceError(err)
 	}
-	views, err := s.svc.AdminListOrders(ctx, service.OrderFilter{Status: status, Limit: req.Limit, Offset: req.Offset})
+	page, err := s.svc.AdminListOrders(ctx, filter)
 	if err != nil {
 		return nil, mapServiceError(err)
 	}
-	resp := &ListOrdersResponse{Orders: make([]transport.OrderViewResponse, 0, len(views))}
-	for _, view := range views {
-		resp.Orders = append(resp.Orders, transport.FromOrderView(view))
+	return toListOrdersResponse(page), nil
+}
+
+func (s *Server) AdminOrdersNear(ctx context.Context, req *OrdersNearRequest) (*ListOrdersResponse, error) {
+	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
 	}
-	return resp, nil
+	query := service.RadiusQuery{
+		Center:       domain.Location{Lat: req.Center.Lat, Lng: req.Center.Lng},
+		RadiusMeters: req.RadiusMeters,
+		Limit:        req.Limit,
+	}
+	for _, status := range req.Statuses {
+		query.Statuses = append(query.Statuses, domain.OrderStatus(status))
+	}
+	views, err := s.svc.AdminOrdersNear(ctx, query)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	return toListOrdersResponse(&service.OrderPage{Orders: views}), nil
 }
 
 func (s *Server) AdminUpdateOrder(ctx context.Context, req *UpdateOrderRequest) (*transport.OrderResponse, error) {
@@ -228,7 +302,56 @@ func (s *Server) AdminUpdateOrder(ctx context.Context, req *UpdateOrderRequest)
 	if req.Destination != nil {
 		dest = &domain.Location{Lat: req.Destination.Lat, Lng: req.Destination.Lng}
 	}
-	order, err := s.svc.AdminUpdateOrder(ctx, req.OrderID, origin, dest)
+	order, err := s.svc.AdminUpdateOrder(ctx, req.OrderID, origin, dest, req.ExpectedVersion)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	resp := transport.FromOrder(order)
+	return &resp, nil
+}
+
+func (s *Server) AdminAssignOrder(ctx context.Context, req *AssignOrderRequest) (*transport.OrderResponse, error) {
+	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	order, err := s.svc.AdminAssignOrder(ctx, req.OrderID, req.DroneID, req.ExpectedVersion)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	resp := transport.FromOrder(order)
+	return &resp, nil
+}
+
+func (s *Server) AdminUnassignOrder(ctx context.Context, req *OrderIDRequest) (*transport.OrderResponse, error) {
+	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	order, err := s.svc.AdminUnassignOrder(ctx, req.OrderID, req.ExpectedVersion)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	resp := transport.FromOrder(order)
+	return &resp, nil
+}
+
+func (s *Server) AdminReassignOrder(ctx context.Context, req *AssignOrderRequest) (*transport.OrderResponse, error) {
+	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	order, err := s.svc.AdminReassignOrder(ctx, req.OrderID, req.DroneID, req.ExpectedVersion)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	resp := transport.FromOrder(order)
+	return &resp, nil
+}
+
+func (s *Server) AdminOverrideOrder(ctx context.Context, req *OverrideOrderRequest) (*transport.OrderResponse, error) {
+	claims, err := requireRole(ctx, domain.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.svc.AdminForceTransition(ctx, claims.Subject, req.OrderID, domain.OrderStatus(req.Status), req.Reason, req.ExpectedVersion)
 	if err != nil {
 		return nil, mapServiceError(err)
 	}
@@ -251,11 +374,27 @@ func (s *Server) AdminListDrones(ctx context.Context, _ *Empty) (*ListDronesResp
 	return resp, nil
 }
 
+func (s *Server) AdminNearestIdleDrones(ctx context.Context, req *NearestDronesRequest) (*ListDronesResponse, error) {
+	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	point := domain.Location{Lat: req.Point.Lat, Lng: req.Point.Lng}
+	drones, err := s.svc.AdminNearestIdleDrones(ctx, point, req.Limit)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	resp := &ListDronesResponse{Drones: make([]transport.DroneResponse, 0, len(drones))}
+	for _, drone := range drones {
+		resp.Drones = append(resp.Drones, transport.FromDrone(drone))
+	}
+	return resp, nil
+}
+
 func (s *Server) AdminMarkDroneBroken(ctx context.Context, req *DroneIDRequest) (*transport.DroneResponse, error) {
 	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
 		return nil, err
 	}
-	drone, err := s.svc.AdminMarkDroneBroken(ctx, req.DroneID)
+	drone, err := s.svc.AdminMarkDroneBroken(ctx, req.DroneID, req.ExpectedVersion)
 	if err != nil {
 		return nil, mapServiceError(err)
 	}
@@ -263,11 +402,198 @@ func (s *Server) AdminMarkDroneBroken(ctx context.Context, req *DroneIDRequest)
 	return &resp, nil
 }
 
-func (s *Server) AdminMarkDroneFixed(ctx context.Context, req *DroneIDRequest) (*transport.DroneResponse, error) {
+func (s *Server) AdminMarkDroneFixed(ctx context.Context, req *FixDroneRequest) (*transport.DroneResponse, error) {
+	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	report := domain.MaintenanceReport{Technician: req.Technician, Notes: req.Notes, Parts: req.Parts}
+	drone, err := s.svc.AdminMarkDroneFixed(ctx, req.DroneID, report, req.ExpectedVersion)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	resp := transport.FromDrone(drone)
+	return &resp, nil
+}
+
+func (s *Server) AdminSetDroneStatus(ctx context.Context, req *AdminDroneStatusRequest) (*transport.DroneResponse, error) {
+	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	drone, err := s.svc.AdminSetDroneStatus(ctx, req.DroneID, domain.DroneStatus(req.Status), req.ExpectedVersion)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	resp := transport.FromDrone(drone)
+	return &resp, nil
+}
+
+func (s *Server) AdminRetireDrone(ctx context.Context, req *DroneIDRequest) (*transport.DroneResponse, error) {
+	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	drone, err := s.svc.AdminRetireDrone(ctx, req.DroneID, req.ExpectedVersion)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	resp := transport.FromDrone(drone)
+	return &resp, nil
+}
+
+func (s *Server) AdminListServiceAreas(ctx context.Context, _ *Empty) (*ListServiceAreasResponse, error) {
+	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	areas, err := s.svc.AdminListServiceAreas(ctx)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	resp := &ListServiceAreasResponse{ServiceAreas: make([]ServiceAreaResponse, 0, len(areas))}
+	for _, area := range areas {
+		resp.ServiceAreas = append(resp.ServiceAreas, *toServiceAreaResponse(area))
+	}
+	return resp, nil
+}
+
+func (s *Server) AdminGetServiceArea(ctx context.Context, req *ServiceAreaIDRequest) (*ServiceAreaResponse, error) {
+	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	area, err := s.svc.AdminGetServiceArea(ctx, req.ServiceAreaID)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	return toServiceAreaResponse(area), nil
+}
+
+func (s *Server) AdminCreateServiceArea(ctx context.Context, req *CreateServiceAreaRequest) (*ServiceAreaResponse, error) {
 	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
 		return nil, err
 	}
-	drone, err := s.svc.AdminMarkDroneFixed(ctx, req.DroneID)
+	active := req.Active == nil || *req.Active
+	area, err := s.svc.AdminCreateServiceArea(ctx, req.Name, toDomainPolygon(req.Boundary), active)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	return toServiceAreaResponse(area), nil
+}
+
+func (s *Server) AdminUpdateServiceArea(ctx context.Context, req *UpdateServiceAreaRequest) (*ServiceAreaResponse, error) {
+	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	update := service.ServiceAreaUpdate{Name: req.Name, Active: req.Active}
+	if req.Boundary != nil {
+		update.Boundary = toDomainPolygon(*req.Boundary)
+	}
+	area, err := s.svc.AdminUpdateServiceArea(ctx, req.ServiceAreaID, update, req.ExpectedVersion)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	return toServiceAreaResponse(area), nil
+}
+
+func (s *Server) AdminListNoFlyZones(ctx context.Context, _ *Empty) (*ListNoFlyZonesResponse, error) {
+	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	zones, err := s.svc.AdminListNoFlyZones(ctx)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	resp := &ListNoFlyZonesResponse{NoFlyZones: make([]NoFlyZoneResponse, 0, len(zones))}
+	for _, zone := range zones {
+		resp.NoFlyZones = append(resp.NoFlyZones, *toNoFlyZoneResponse(zone))
+	}
+	return resp, nil
+}
+
+func (s *Server) AdminGetNoFlyZone(ctx context.Context, req *NoFlyZoneIDRequest) (*NoFlyZoneResponse, error) {
+	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	zone, err := s.svc.AdminGetNoFlyZone(ctx, req.NoFlyZoneID)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	return toNoFlyZoneResponse(zone), nil
+}
+
+func (s *Server) AdminCreateNoFlyZone(ctx context.Context, req *CreateNoFlyZoneRequest) (*NoFlyZoneResponse, error) {
+	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	window := service.NoFlyWindow{From: req.Window.ActiveFrom, Until: req.Window.ActiveUntil}
+	zone, err := s.svc.AdminCreateNoFlyZone(ctx, req.Name, toDomainPolygon(req.Boundary), window)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	return toNoFlyZoneResponse(zone), nil
+}
+
+func (s *Server) AdminUpdateNoFlyZone(ctx context.Context, req *UpdateNoFlyZoneRequest) (*NoFlyZoneResponse, error) {
+	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	update := service.NoFlyZoneUpdate{Name: req.Name}
+	if req.Boundary != nil {
+		update.Boundary = toDomainPolygon(*req.Boundary)
+	}
+	if req.Window != nil {
+		update.Window = &service.NoFlyWindow{From: req.Window.ActiveFrom, Until: req.Window.ActiveUntil}
+	}
+	zone, err := s.svc.AdminUpdateNoFlyZone(ctx, req.NoFlyZoneID, update, req.ExpectedVersion)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	return toNoFlyZoneResponse(zone), nil
+}
+
+func (s *Server) AdminDroneTrack(ctx context.Context, req *DroneTrackRequest) (*TrackResponse, error) {
+	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	rng, err := toTrackRange(req.From, req.To)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	samples, err := s.svc.AdminDroneTrack(ctx, req.DroneID, rng)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	return &TrackResponse{Samples: transport.FromTelemetry(samples)}, nil
+}
+
+func (s *Server) AdminOrderTrack(ctx context.Context, req *OrderTrackRequest) (*TrackResponse, error) {
+	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	rng, err := toTrackRange(req.From, req.To)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	samples, err := s.svc.AdminOrderTrack(ctx, req.OrderID, rng)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	return &TrackResponse{Samples: transport.FromTelemetry(samples)}, nil
+}
+
+func (s *Server) AdminDroneMaintenance(ctx context.Context, req *DroneIDRequest) (*DroneMaintenanceResponse, error) {
+	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	records, err := s.svc.AdminDroneMaintenance(ctx, req.DroneID)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	return &DroneMaintenanceResponse{Records: transport.FromMaintenanceRecords(records)}, nil
+}
+
+func (s *Server) AdminSetDroneDepot(ctx context.Context, req *SetDroneDepotRequest) (*transport.DroneResponse, error) {
+	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	drone, err := s.svc.AdminSetDroneDepot(ctx, req.DroneID, req.DepotID, req.ExpectedVersion)
 	if err != nil {
 		return nil, mapServiceError(err)
 	}
@@ -275,3 +601,114 @@ func (s *Server) AdminMarkDroneFixed(ctx context.Context, req *DroneIDRequest) (
 	return &resp, nil
 }
 
+func (s *Server) AdminListDepots(ctx context.Context, _ *Empty) (*ListDepotsResponse, error) {
+	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	depots, err := s.svc.AdminListDepots(ctx)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	resp := &ListDepotsResponse{Depots: make([]transport.DepotResponse, 0, len(depots))}
+	for _, depot := range depots {
+		resp.Depots = append(resp.Depots, transport.FromDepot(depot))
+	}
+	return resp, nil
+}
+
+func (s *Server) AdminGetDepot(ctx context.Context, req *DepotIDRequest) (*transport.DepotResponse, error) {
+	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	depot, err := s.svc.AdminGetDepot(ctx, req.DepotID)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	resp := transport.FromDepot(depot)
+	return &resp, nil
+}
+
+func (s *Server) AdminCreateDepot(ctx context.Context, req *CreateDepotRequest) (*transport.DepotResponse, error) {
+	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	depot, err := s.svc.AdminCreateDepot(ctx, req.Name, toDomainLocation(req.Location), req.Capacity)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	resp := transport.FromDepot(depot)
+	return &resp, nil
+}
+
+func (s *Server) AdminUpdateDepot(ctx context.Context, req *UpdateDepotRequest) (*transport.DepotResponse, error) {
+	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	update := service.DepotUpdate{Name: req.Name, Capacity: req.Capacity}
+	if req.Location != nil {
+		loc := toDomainLocation(*req.Location)
+		update.Location = &loc
+	}
+	depot, err := s.svc.AdminUpdateDepot(ctx, req.DepotID, update, req.ExpectedVersion)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	resp := transport.FromDepot(depot)
+	return &resp, nil
+}
+
+func (s *Server) AdminListChargingStations(ctx context.Context, _ *Empty) (*ListChargingStationsResponse, error) {
+	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	views, err := s.svc.AdminListChargingStations(ctx)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	resp := &ListChargingStationsResponse{Stations: make([]transport.ChargingStationResponse, 0, len(views))}
+	for _, view := range views {
+		resp.Stations = append(resp.Stations, transport.FromChargingStation(view))
+	}
+	return resp, nil
+}
+
+func (s *Server) AdminGetChargingStation(ctx context.Context, req *ChargingStationIDRequest) (*transport.ChargingStationResponse, error) {
+	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	view, err := s.svc.AdminGetChargingStation(ctx, req.StationID)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	resp := transport.FromChargingStation(view)
+	return &resp, nil
+}
+
+func (s *Server) AdminCreateChargingStation(ctx context.Context, req *CreateChargingStationRequest) (*transport.ChargingStationResponse, error) {
+	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	view, err := s.svc.AdminCreateChargingStation(ctx, req.Name, toDomainLocation(req.Location), req.Slots)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	resp := transport.FromChargingStation(view)
+	return &resp, nil
+}
+
+func (s *Server) AdminUpdateChargingStation(ctx context.Context, req *UpdateChargingStationRequest) (*transport.ChargingStationResponse, error) {
+	if _, err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	update := service.ChargingStationUpdate{Name: req.Name, Slots: req.Slots}
+	if req.Location != nil {
+		loc := toDomainLocation(*req.Location)
+		update.Location = &loc
+	}
+	view, err := s.svc.AdminUpdateChargingStation(ctx, req.StationID, update, req.ExpectedVersion)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+	resp := transport.FromChargingStation(view)
+	return &resp, nil
+}
@@ -1,6 +1,10 @@
 package grpcapi
 
-import "penny-assesment/internal/transport"
+import (
+	"time"
+
+	"penny-assesment/internal/transport"
+)
 
 type TokenRequest struct {
 	Name string `json:"name"`
@@ -13,12 +17,17 @@ type TokenResponse struct {
 }
 
 type SubmitOrderRequest struct {
-	Origin      transport.Location `json:"origin"`
-	Destination transport.Location `json:"destination"`
+	Origin          transport.Location `json:"origin"`
+	Destination     transport.Location `json:"destination"`
+	PickupNotBefore *time.Time         `json:"pickup_not_before"`
+	DeliverBy       *time.Time         `json:"deliver_by"`
 }
 
+// OrderIDRequest is shared by reads and mutations; ExpectedVersion is only
+// honoured by withdraw and the admin order mutations (0 = unconditional).
 type OrderIDRequest struct {
-	OrderID string `json:"order_id"`
+	OrderID         string `json:"order_id"`
+	ExpectedVersion int64  `json:"expected_version"`
 }
 
 type FailOrderRequest struct {
@@ -26,24 +35,251 @@ type FailOrderRequest struct {
 	Reason  string `json:"reason"`
 }
 
+// HeartbeatRequest's vitals are optional; older drones send only lat/lng.
 type HeartbeatRequest struct {
 	Lat float64 `json:"lat"`
 	Lng float64 `json:"lng"`
+	transport.Vitals
+}
+
+type BoundingBox struct {
+	MinLat float64 `json:"min_lat"`
+	MinLng float64 `json:"min_lng"`
+	MaxLat float64 `json:"max_lat"`
+	MaxLng float64 `json:"max_lng"`
 }
 
 type ListOrdersRequest struct {
-	Status string `json:"status"`
-	Limit  int    `json:"limit"`
-	Offset int    `json:"offset"`
+	Status         string       `json:"status"`
+	Statuses       []string     `json:"statuses"`
+	Limit          int          `json:"limit"`
+	Offset         int          `json:"offset"`
+	Cursor         string       `json:"cursor"`
+	CreatedFrom    string       `json:"created_from"`
+	CreatedTo      string       `json:"created_to"`
+	Sort           string       `json:"sort"`
+	UserID         string       `json:"user_id"`
+	DroneID        string       `json:"drone_id"`
+	UpdatedFrom    string       `json:"updated_from"`
+	UpdatedTo      string       `json:"updated_to"`
+	FailureReason  string       `json:"failure_reason"`
+	OriginBox      *BoundingBox `json:"origin_bbox"`
+	DestinationBox *BoundingBox `json:"destination_bbox"`
 }
 
 type UpdateOrderRequest struct {
-	OrderID     string               `json:"order_id"`
-	Origin      *transport.Location `json:"origin"`
-	Destination *transport.Location `json:"destination"`
+	OrderID         string              `json:"order_id"`
+	Origin          *transport.Location `json:"origin"`
+	Destination     *transport.Location `json:"destination"`
+	ExpectedVersion int64               `json:"expected_version"`
+}
+
+type AssignOrderRequest struct {
+	OrderID         string `json:"order_id"`
+	DroneID         string `json:"drone_id"`
+	ExpectedVersion int64  `json:"expected_version"`
+}
+
+type OverrideOrderRequest struct {
+	OrderID         string `json:"order_id"`
+	Status          string `json:"status"`
+	Reason          string `json:"reason"`
+	ExpectedVersion int64  `json:"expected_version"`
 }
 
 type DroneIDRequest struct {
+	DroneID         string `json:"drone_id"`
+	ExpectedVersion int64  `json:"expected_version"`
+}
+
+// FixDroneRequest is a DroneIDRequest with the optional maintenance report.
+type FixDroneRequest struct {
+	DroneID         string   `json:"drone_id"`
+	ExpectedVersion int64    `json:"expected_version"`
+	Technician      string   `json:"technician"`
+	Notes           string   `json:"notes"`
+	Parts           []string `json:"parts"`
+}
+
+type DroneStatusRequest struct {
+	Status string `json:"status"`
+}
+
+type AdminDroneStatusRequest struct {
+	DroneID         string `json:"drone_id"`
+	Status          string `json:"status"`
+	ExpectedVersion int64  `json:"expected_version"`
+}
+
+type OrdersNearRequest struct {
+	Center       transport.Location `json:"center"`
+	RadiusMeters float64            `json:"radius_m"`
+	Statuses     []string           `json:"statuses"`
+	Limit        int                `json:"limit"`
+}
+
+type NearestDronesRequest struct {
+	Point transport.Location `json:"point"`
+	Limit int                `json:"limit"`
+}
+
+// Polygon mirrors domain.Polygon: the first ring is the exterior, any others
+// are holes, and each ring is closed.
+type Polygon struct {
+	Rings []Ring `json:"rings"`
+}
+
+type Ring struct {
+	Points []transport.Location `json:"points"`
+}
+
+type ServiceAreaIDRequest struct {
+	ServiceAreaID string `json:"service_area_id"`
+}
+
+type CreateServiceAreaRequest struct {
+	Name     string  `json:"name"`
+	Boundary Polygon `json:"boundary"`
+	// Active defaults to true when omitted.
+	Active *bool `json:"active"`
+}
+
+type UpdateServiceAreaRequest struct {
+	ServiceAreaID   string   `json:"service_area_id"`
+	Name            *string  `json:"name"`
+	Boundary        *Polygon `json:"boundary"`
+	Active          *bool    `json:"active"`
+	ExpectedVersion int64    `json:"expected_version"`
+}
+
+type ServiceAreaResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Boundary  Polygon   `json:"boundary"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Version   int64     `json:"version"`
+}
+
+type ListServiceAreasResponse struct {
+	ServiceAreas []ServiceAreaResponse `json:"service_areas"`
+}
+
+// NoFlyWindow is the period a no-fly zone is in force; a nil end is open.
+type NoFlyWindow struct {
+	ActiveFrom  *time.Time `json:"active_from"`
+	ActiveUntil *time.Time `json:"active_until"`
+}
+
+type NoFlyZoneIDRequest struct {
+	NoFlyZoneID string `json:"no_fly_zone_id"`
+}
+
+type CreateNoFlyZoneRequest struct {
+	Name     string      `json:"name"`
+	Boundary Polygon     `json:"boundary"`
+	Window   NoFlyWindow `json:"window"`
+}
+
+type UpdateNoFlyZoneRequest struct {
+	NoFlyZoneID string   `json:"no_fly_zone_id"`
+	Name        *string  `json:"name"`
+	Boundary    *Polygon `json:"boundary"`
+	// Window, when set, replaces both ends of the zone's window.
+	Window          *NoFlyWindow `json:"window"`
+	ExpectedVersion int64        `json:"expected_version"`
+}
+
+type NoFlyZoneResponse struct {
+	ID        string      `json:"id"`
+	Name      string      `json:"name"`
+	Boundary  Polygon     `json:"boundary"`
+	Window    NoFlyWindow `json:"window"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	Version   int64       `json:"version"`
+}
+
+type ListNoFlyZonesResponse struct {
+	NoFlyZones []NoFlyZoneResponse `json:"no_fly_zones"`
+}
+
+// DroneTrackRequest and OrderTrackRequest bound the track by RFC 3339 times;
+// an empty end is open.
+type DroneTrackRequest struct {
 	DroneID string `json:"drone_id"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+}
+
+type OrderTrackRequest struct {
+	OrderID string `json:"order_id"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+}
+
+type TrackResponse struct {
+	Samples []transport.TelemetrySampleResponse `json:"samples"`
+}
+
+type DroneMaintenanceResponse struct {
+	Records []transport.MaintenanceRecordResponse `json:"records"`
+}
+
+// SetDroneDepotRequest homes the drone at DepotID, or clears its home depot
+// when DepotID is nil.
+type SetDroneDepotRequest struct {
+	DroneID         string  `json:"drone_id"`
+	DepotID         *string `json:"depot_id"`
+	ExpectedVersion int64   `json:"expected_version"`
+}
+
+type DepotIDRequest struct {
+	DepotID string `json:"depot_id"`
+}
+
+type CreateDepotRequest struct {
+	Name     string             `json:"name"`
+	Location transport.Location `json:"location"`
+	Capacity int                `json:"capacity"`
+}
+
+type UpdateDepotRequest struct {
+	DepotID         string              `json:"depot_id"`
+	Name            *string             `json:"name"`
+	Location        *transport.Location `json:"location"`
+	Capacity        *int                `json:"capacity"`
+	ExpectedVersion int64               `json:"expected_version"`
+}
+
+type ListDepotsResponse struct {
+	Depots []transport.DepotResponse `json:"depots"`
+}
+
+type ChargingStationIDRequest struct {
+	StationID string `json:"station_id"`
+}
+
+type CreateChargingStationRequest struct {
+	Name     string             `json:"name"`
+	Location transport.Location `json:"location"`
+	Slots    int                `json:"slots"`
+}
+
+type UpdateChargingStationRequest struct {
+	StationID       string              `json:"station_id"`
+	Name            *string             `json:"name"`
+	Location        *transport.Location `json:"location"`
+	Slots           *int                `json:"slots"`
+	ExpectedVersion int64               `json:"expected_version"`
+}
+
+type ListChargingStationsResponse struct {
+	Stations []transport.ChargingStationResponse `json:"stations"`
+}
+
+type ReserveChargingSlotRequest struct {
+	StationID string `json:"station_id"`
 }
 
@@ -0,0 +1,66 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"penny-assesment/internal/domain"
+	"penny-assesment/internal/service"
+	"penny-assesment/internal/transport"
+)
+
+// setETag exposes a resource version as a strong ETag, e.g. "3".
+func setETag(w http.ResponseWriter, version int64) {
+	w.Header().Set("ETag", strconv.Quote(strconv.FormatInt(version, 10)))
+}
+
+// ifMatchVersion returns the version named by the If-Match header. A missing
+// header or "*" returns 0, which the service treats as unconditional. Only a
+// single strong ETag is supported.
+func ifMatchVersion(r *http.Request) (int64, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+	unquoted, err := strconv.Unquote(value)
+	if err != nil {
+		return 0, fmt.Errorf("If-Match: %w", domain.ErrInvalid)
+	}
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("If-Match: %w", domain.ErrInvalid)
+	}
+	return version, nil
+}
+
+func respondOrder(w http.ResponseWriter, status int, order *domain.Order) {
+	setETag(w, order.Version)
+	respondJSON(w, status, transport.FromOrder(order))
+}
+
+func respondDrone(w http.ResponseWriter, status int, drone *domain.Drone) {
+	setETag(w, drone.Version)
+	respondJSON(w, status, transport.FromDrone(drone))
+}
+
+func respondServiceArea(w http.ResponseWriter, status int, area *domain.ServiceArea) {
+	setETag(w, area.Version)
+	respondJSON(w, status, transport.FromServiceArea(area))
+}
+
+func respondNoFlyZone(w http.ResponseWriter, status int, zone *domain.NoFlyZone) {
+	setETag(w, zone.Version)
+	respondJSON(w, status, transport.FromNoFlyZone(zone))
+}
+
+func respondDepot(w http.ResponseWriter, status int, depot *domain.Depot) {
+	setETag(w, depot.Version)
+	respondJSON(w, status, transport.FromDepot(depot))
+}
+
+func respondChargingStation(w http.ResponseWriter, status int, view *service.ChargingStationView) {
+	setETag(w, view.Station.Version)
+	respondJSON(w, status, transport.FromChargingStation(view))
+}
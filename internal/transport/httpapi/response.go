@@ -41,10 +41,38 @@ func writeError(w http.ResponseWriter, err error) {
 		status = http.StatusConflict
 		code = "conflict"
 		message = "conflict"
+	case errors.Is(err, domain.ErrOutsideServiceArea):
+		status = http.StatusUnprocessableEntity
+		code = "outside_service_area"
+		message = "location is outside every active service area"
+		var outside *domain.OutsideServiceAreaError
+		if errors.As(err, &outside) {
+			message = outside.Error()
+		}
+	case errors.Is(err, domain.ErrRouteBlocked):
+		status = http.StatusUnprocessableEntity
+		code = "route_blocked"
+		message = "route crosses a no-fly zone"
+		var blocked *domain.RouteBlockedError
+		if errors.As(err, &blocked) {
+			message = blocked.Error()
+		}
+	case errors.Is(err, domain.ErrOutOfRange):
+		status = http.StatusUnprocessableEntity
+		code = "out_of_range"
+		message = "no relay path within drone range"
+	case errors.Is(err, domain.ErrIdempotencyKeyReused):
+		status = http.StatusUnprocessableEntity
+		code = "idempotency_key_reused"
+		message = "idempotency key reused for a different request"
 	case errors.Is(err, domain.ErrInvalid):
 		status = http.StatusUnprocessableEntity
 		code = "invalid"
 		message = "invalid request"
+	case errors.Is(err, domain.ErrVersionMismatch):
+		status = http.StatusPreconditionFailed
+		code = "version_mismatch"
+		message = "version mismatch"
 	case errors.Is(err, domain.ErrPrecondition):
 		status = http.StatusConflict
 		code = "precondition_failed"
@@ -2,8 +2,12 @@ package httpapi
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -37,13 +41,17 @@ func NewServer(svc *service.Service, authenticator *auth.Authenticator) http.Han
 		r.Post("/orders/{id}/deliver", s.handleDroneDeliver)
 		r.Post("/orders/{id}/fail", s.handleDroneFail)
 		r.Post("/broken", s.handleDroneBroken)
+		r.Post("/status", s.handleDroneStatus)
 		r.Post("/heartbeat", s.handleDroneHeartbeat)
 		r.Get("/orders/current", s.handleDroneCurrentOrder)
+		r.Post("/charging/reserve", s.handleDroneReserveCharging)
+		r.Post("/charging/release", s.handleDroneReleaseCharging)
 	})
 
 	r.Route("/orders", func(r chi.Router) {
 		r.Use(s.requireRole(domain.RoleEndUser))
 		r.Post("/", s.handleSubmitOrder)
+		r.Get("/", s.handleListMyOrders)
 		r.Post("/{id}/withdraw", s.handleWithdrawOrder)
 		r.Get("/{id}", s.handleGetOrder)
 	})
@@ -51,10 +59,39 @@ func NewServer(svc *service.Service, authenticator *auth.Authenticator) http.Han
 	r.Route("/admin", func(r chi.Router) {
 		r.Use(s.requireRole(domain.RoleAdmin))
 		r.Get("/orders", s.handleAdminListOrders)
+		r.Get("/orders/page", s.handleAdminListOrdersPage)
+		r.Get("/orders/nearby", s.handleAdminOrdersNearby)
 		r.Patch("/orders/{id}", s.handleAdminUpdateOrder)
+		r.Post("/orders/{id}/assign", s.handleAdminAssignOrder)
+		r.Post("/orders/{id}/unassign", s.handleAdminUnassignOrder)
+		r.Post("/orders/{id}/reassign", s.handleAdminReassignOrder)
+		r.Post("/orders/{id}/override", s.handleAdminOverrideOrder)
+		r.Get("/orders/{id}/track", s.handleAdminOrderTrack)
 		r.Get("/drones", s.handleAdminListDrones)
+		r.Get("/drones/nearest", s.handleAdminNearestDrones)
 		r.Post("/drones/{id}/broken", s.handleAdminDroneBroken)
 		r.Post("/drones/{id}/fixed", s.handleAdminDroneFixed)
+		r.Post("/drones/{id}/status", s.handleAdminDroneStatus)
+		r.Post("/drones/{id}/retire", s.handleAdminRetireDrone)
+		r.Get("/drones/{id}/track", s.handleAdminDroneTrack)
+		r.Get("/drones/{id}/maintenance", s.handleAdminDroneMaintenance)
+		r.Post("/drones/{id}/depot", s.handleAdminDroneDepot)
+		r.Get("/service-areas", s.handleAdminListServiceAreas)
+		r.Post("/service-areas", s.handleAdminCreateServiceArea)
+		r.Get("/service-areas/{id}", s.handleAdminGetServiceArea)
+		r.Patch("/service-areas/{id}", s.handleAdminUpdateServiceArea)
+		r.Get("/no-fly-zones", s.handleAdminListNoFlyZones)
+		r.Post("/no-fly-zones", s.handleAdminCreateNoFlyZone)
+		r.Get("/no-fly-zones/{id}", s.handleAdminGetNoFlyZone)
+		r.Patch("/no-fly-zones/{id}", s.handleAdminUpdateNoFlyZone)
+		r.Get("/depots", s.handleAdminListDepots)
+		r.Post("/depots", s.handleAdminCreateDepot)
+		r.Get("/depots/{id}", s.handleAdminGetDepot)
+		r.Patch("/depots/{id}", s.handleAdminUpdateDepot)
+		r.Get("/charging-stations", s.handleAdminListChargingStations)
+		r.Post("/charging-stations", s.handleAdminCreateChargingStation)
+		r.Get("/charging-stations/{id}", s.handleAdminGetChargingStation)
+		r.Patch("/charging-stations/{id}", s.handleAdminUpdateChargingStation)
 	})
 
 	return r
@@ -110,30 +147,38 @@ func (s *Server) handleIssueToken(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleSubmitOrder(w http.ResponseWriter, r *http.Request) {
 	claims := mustClaims(r)
 	var req struct {
-		Origin      transport.Location `json:"origin"`
-		Destination transport.Location `json:"destination"`
+		Origin          transport.Location `json:"origin"`
+		Destination     transport.Location `json:"destination"`
+		PickupNotBefore *time.Time         `json:"pickup_not_before"`
+		DeliverBy       *time.Time         `json:"deliver_by"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, domain.ErrInvalid)
 		return
 	}
-	order, err := s.svc.SubmitOrder(r.Context(), claims.Subject, toDomainLocation(req.Origin), toDomainLocation(req.Destination))
+	window := service.DeliveryWindow{PickupNotBefore: req.PickupNotBefore, DeliverBy: req.DeliverBy}
+	order, err := s.svc.SubmitOrder(r.Context(), claims.Subject, toDomainLocation(req.Origin), toDomainLocation(req.Destination), window, idempotencyKey(r))
 	if err != nil {
 		writeError(w, err)
 		return
 	}
-	respondJSON(w, http.StatusCreated, transport.FromOrder(order))
+	respondOrder(w, http.StatusCreated, order)
 }
 
 func (s *Server) handleWithdrawOrder(w http.ResponseWriter, r *http.Request) {
 	claims := mustClaims(r)
 	orderID := chi.URLParam(r, "id")
-	order, err := s.svc.WithdrawOrder(r.Context(), claims.Subject, orderID)
+	expectedVersion, err := ifMatchVersion(r)
 	if err != nil {
 		writeError(w, err)
 		return
 	}
-	respondJSON(w, http.StatusOK, transport.FromOrder(order))
+	order, err := s.svc.WithdrawOrder(r.Context(), claims.Subject, orderID, expectedVersion)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondOrder(w, http.StatusOK, order)
 }
 
 func (s *Server) handleGetOrder(w http.ResponseWriter, r *http.Request) {
@@ -144,39 +189,55 @@ func (s *Server) handleGetOrder(w http.ResponseWriter, r *http.Request) {
 		writeError(w, err)
 		return
 	}
+	setETag(w, view.Order.Version)
 	respondJSON(w, http.StatusOK, transport.FromOrderView(view))
 }
 
+func (s *Server) handleListMyOrders(w http.ResponseWriter, r *http.Request) {
+	claims := mustClaims(r)
+	filter, err := parseOrderFilter(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	page, err := s.svc.ListMyOrders(r.Context(), claims.Subject, filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, transport.FromOrderPage(page))
+}
+
 func (s *Server) handleDroneReserve(w http.ResponseWriter, r *http.Request) {
 	claims := mustClaims(r)
-	order, err := s.svc.DroneReserveJob(r.Context(), claims.Subject)
+	order, err := s.svc.DroneReserveJob(r.Context(), claims.Subject, idempotencyKey(r))
 	if err != nil {
 		writeError(w, err)
 		return
 	}
-	respondJSON(w, http.StatusOK, transport.FromOrder(order))
+	respondOrder(w, http.StatusOK, order)
 }
 
 func (s *Server) handleDronePickup(w http.ResponseWriter, r *http.Request) {
 	claims := mustClaims(r)
 	orderID := chi.URLParam(r, "id")
-	order, err := s.svc.DronePickup(r.Context(), claims.Subject, orderID)
+	order, err := s.svc.DronePickup(r.Context(), claims.Subject, orderID, idempotencyKey(r))
 	if err != nil {
 		writeError(w, err)
 		return
 	}
-	respondJSON(w, http.StatusOK, transport.FromOrder(order))
+	respondOrder(w, http.StatusOK, order)
 }
 
 func (s *Server) handleDroneDeliver(w http.ResponseWriter, r *http.Request) {
 	claims := mustClaims(r)
 	orderID := chi.URLParam(r, "id")
-	order, err := s.svc.DroneDeliver(r.Context(), claims.Subject, orderID)
+	order, err := s.svc.DroneDeliver(r.Context(), claims.Subject, orderID, idempotencyKey(r))
 	if err != nil {
 		writeError(w, err)
 		return
 	}
-	respondJSON(w, http.StatusOK, transport.FromOrder(order))
+	respondOrder(w, http.StatusOK, order)
 }
 
 func (s *Server) handleDroneFail(w http.ResponseWriter, r *http.Request) {
@@ -189,22 +250,39 @@ func (s *Server) handleDroneFail(w http.ResponseWriter, r *http.Request) {
 		writeError(w, domain.ErrInvalid)
 		return
 	}
-	order, err := s.svc.DroneFail(r.Context(), claims.Subject, orderID, req.Reason)
+	order, err := s.svc.DroneFail(r.Context(), claims.Subject, orderID, req.Reason, idempotencyKey(r))
 	if err != nil {
 		writeError(w, err)
 		return
 	}
-	respondJSON(w, http.StatusOK, transport.FromOrder(order))
+	respondOrder(w, http.StatusOK, order)
 }
 
 func (s *Server) handleDroneBroken(w http.ResponseWriter, r *http.Request) {
 	claims := mustClaims(r)
-	drone, err := s.svc.DroneMarkBroken(r.Context(), claims.Subject)
+	drone, err := s.svc.DroneMarkBroken(r.Context(), claims.Subject, idempotencyKey(r))
 	if err != nil {
 		writeError(w, err)
 		return
 	}
-	respondJSON(w, http.StatusOK, transport.FromDrone(drone))
+	respondDrone(w, http.StatusOK, drone)
+}
+
+func (s *Server) handleDroneStatus(w http.ResponseWriter, r *http.Request) {
+	claims := mustClaims(r)
+	var req struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, domain.ErrInvalid)
+		return
+	}
+	drone, err := s.svc.DroneSetStatus(r.Context(), claims.Subject, domain.DroneStatus(req.Status))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondDrone(w, http.StatusOK, drone)
 }
 
 func (s *Server) handleDroneHeartbeat(w http.ResponseWriter, r *http.Request) {
@@ -212,12 +290,14 @@ func (s *Server) handleDroneHeartbeat(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Lat float64 `json:"lat"`
 		Lng float64 `json:"lng"`
+		transport.Vitals
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, domain.ErrInvalid)
 		return
 	}
-	view, err := s.svc.DroneHeartbeat(r.Context(), claims.Subject, domain.Location{Lat: req.Lat, Lng: req.Lng})
+	loc := domain.Location{Lat: req.Lat, Lng: req.Lng}
+	view, err := s.svc.DroneHeartbeat(r.Context(), claims.Subject, loc, transport.ToVitals(req.Vitals))
 	if err != nil {
 		writeError(w, err)
 		return
@@ -235,29 +315,61 @@ func (s *Server) handleDroneCurrentOrder(w http.ResponseWriter, r *http.Request)
 	respondJSON(w, http.StatusOK, transport.FromOrderView(view))
 }
 
+// handleAdminListOrders keeps the original array response and so returns
+// only the first page; handleAdminListOrdersPage also returns the cursor.
 func (s *Server) handleAdminListOrders(w http.ResponseWriter, r *http.Request) {
-	statusParam := r.URL.Query().Get("status")
-	var status *domain.OrderStatus
-	if statusParam != "" {
-		st := domain.OrderStatus(statusParam)
-		status = &st
+	filter, err := parseAdminOrderFilter(r)
+	if err != nil {
+		writeError(w, err)
+		return
 	}
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
-	views, err := s.svc.AdminListOrders(r.Context(), service.OrderFilter{Status: status, Limit: limit, Offset: offset})
+	page, err := s.svc.AdminListOrders(r.Context(), filter)
 	if err != nil {
 		writeError(w, err)
 		return
 	}
-	resp := make([]transport.OrderViewResponse, 0, len(views))
-	for _, view := range views {
+	resp := make([]transport.OrderViewResponse, 0, len(page.Orders))
+	for _, view := range page.Orders {
 		resp = append(resp, transport.FromOrderView(view))
 	}
 	respondJSON(w, http.StatusOK, resp)
 }
 
+func (s *Server) handleAdminListOrdersPage(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAdminOrderFilter(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	page, err := s.svc.AdminListOrders(r.Context(), filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, transport.FromOrderPage(page))
+}
+
+func (s *Server) handleAdminOrdersNearby(w http.ResponseWriter, r *http.Request) {
+	query, err := parseRadiusQuery(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	views, err := s.svc.AdminOrdersNear(r.Context(), query)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, transport.FromOrderPage(&service.OrderPage{Orders: views}))
+}
+
 func (s *Server) handleAdminUpdateOrder(w http.ResponseWriter, r *http.Request) {
 	orderID := chi.URLParam(r, "id")
+	expectedVersion, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
 	var req struct {
 		Origin      *transport.Location `json:"origin"`
 		Destination *transport.Location `json:"destination"`
@@ -274,12 +386,95 @@ func (s *Server) handleAdminUpdateOrder(w http.ResponseWriter, r *http.Request)
 	if req.Destination != nil {
 		dest = &domain.Location{Lat: req.Destination.Lat, Lng: req.Destination.Lng}
 	}
-	order, err := s.svc.AdminUpdateOrder(r.Context(), orderID, origin, dest)
+	order, err := s.svc.AdminUpdateOrder(r.Context(), orderID, origin, dest, expectedVersion)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondOrder(w, http.StatusOK, order)
+}
+
+func (s *Server) handleAdminAssignOrder(w http.ResponseWriter, r *http.Request) {
+	orderID := chi.URLParam(r, "id")
+	expectedVersion, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var req struct {
+		DroneID string `json:"drone_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, domain.ErrInvalid)
+		return
+	}
+	order, err := s.svc.AdminAssignOrder(r.Context(), orderID, req.DroneID, expectedVersion)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondOrder(w, http.StatusOK, order)
+}
+
+func (s *Server) handleAdminUnassignOrder(w http.ResponseWriter, r *http.Request) {
+	orderID := chi.URLParam(r, "id")
+	expectedVersion, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	order, err := s.svc.AdminUnassignOrder(r.Context(), orderID, expectedVersion)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondOrder(w, http.StatusOK, order)
+}
+
+func (s *Server) handleAdminReassignOrder(w http.ResponseWriter, r *http.Request) {
+	orderID := chi.URLParam(r, "id")
+	expectedVersion, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var req struct {
+		DroneID string `json:"drone_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, domain.ErrInvalid)
+		return
+	}
+	order, err := s.svc.AdminReassignOrder(r.Context(), orderID, req.DroneID, expectedVersion)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondOrder(w, http.StatusOK, order)
+}
+
+func (s *Server) handleAdminOverrideOrder(w http.ResponseWriter, r *http.Request) {
+	claims := mustClaims(r)
+	orderID := chi.URLParam(r, "id")
+	expectedVersion, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var req struct {
+		Status string `json:"status"`
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, domain.ErrInvalid)
+		return
+	}
+	order, err := s.svc.AdminForceTransition(r.Context(), claims.Subject, orderID, domain.OrderStatus(req.Status), req.Reason, expectedVersion)
 	if err != nil {
 		writeError(w, err)
 		return
 	}
-	respondJSON(w, http.StatusOK, transport.FromOrder(order))
+	respondOrder(w, http.StatusOK, order)
 }
 
 func (s *Server) handleAdminListDrones(w http.ResponseWriter, r *http.Request) {
@@ -295,24 +490,508 @@ func (s *Server) handleAdminListDrones(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, resp)
 }
 
+func (s *Server) handleAdminNearestDrones(w http.ResponseWriter, r *http.Request) {
+	point, err := parsePointParams(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	drones, err := s.svc.AdminNearestIdleDrones(r.Context(), point, limit)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	resp := make([]transport.DroneResponse, 0, len(drones))
+	for _, drone := range drones {
+		resp = append(resp, transport.FromDrone(drone))
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
 func (s *Server) handleAdminDroneBroken(w http.ResponseWriter, r *http.Request) {
 	droneID := chi.URLParam(r, "id")
-	drone, err := s.svc.AdminMarkDroneBroken(r.Context(), droneID)
+	expectedVersion, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	drone, err := s.svc.AdminMarkDroneBroken(r.Context(), droneID, expectedVersion)
 	if err != nil {
 		writeError(w, err)
 		return
 	}
-	respondJSON(w, http.StatusOK, transport.FromDrone(drone))
+	respondDrone(w, http.StatusOK, drone)
 }
 
 func (s *Server) handleAdminDroneFixed(w http.ResponseWriter, r *http.Request) {
 	droneID := chi.URLParam(r, "id")
-	drone, err := s.svc.AdminMarkDroneFixed(r.Context(), droneID)
+	expectedVersion, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	// The maintenance report is optional: an empty body marks the drone
+	// fixed without one.
+	var req transport.MaintenanceReport
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, domain.ErrInvalid)
+		return
+	}
+	drone, err := s.svc.AdminMarkDroneFixed(r.Context(), droneID, transport.ToMaintenanceReport(req), expectedVersion)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondDrone(w, http.StatusOK, drone)
+}
+
+func (s *Server) handleAdminDroneStatus(w http.ResponseWriter, r *http.Request) {
+	droneID := chi.URLParam(r, "id")
+	expectedVersion, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var req struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, domain.ErrInvalid)
+		return
+	}
+	drone, err := s.svc.AdminSetDroneStatus(r.Context(), droneID, domain.DroneStatus(req.Status), expectedVersion)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondDrone(w, http.StatusOK, drone)
+}
+
+// handleAdminDroneDepot homes the drone at the depot in the body, or clears
+// its home depot when depot_id is null.
+func (s *Server) handleAdminDroneDepot(w http.ResponseWriter, r *http.Request) {
+	droneID := chi.URLParam(r, "id")
+	expectedVersion, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var req struct {
+		DepotID *string `json:"depot_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, domain.ErrInvalid)
+		return
+	}
+	drone, err := s.svc.AdminSetDroneDepot(r.Context(), droneID, req.DepotID, expectedVersion)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondDrone(w, http.StatusOK, drone)
+}
+
+func (s *Server) handleAdminRetireDrone(w http.ResponseWriter, r *http.Request) {
+	droneID := chi.URLParam(r, "id")
+	expectedVersion, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	drone, err := s.svc.AdminRetireDrone(r.Context(), droneID, expectedVersion)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondDrone(w, http.StatusOK, drone)
+}
+
+func (s *Server) handleAdminDroneMaintenance(w http.ResponseWriter, r *http.Request) {
+	records, err := s.svc.AdminDroneMaintenance(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, transport.FromMaintenanceRecords(records))
+}
+
+func (s *Server) handleAdminDroneTrack(w http.ResponseWriter, r *http.Request) {
+	droneID := chi.URLParam(r, "id")
+	rng, err := parseTrackRange(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	format, err := parseTrackFormat(r)
 	if err != nil {
 		writeError(w, err)
 		return
 	}
-	respondJSON(w, http.StatusOK, transport.FromDrone(drone))
+	samples, err := s.svc.AdminDroneTrack(r.Context(), droneID, rng)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondTrack(w, format, "drone "+droneID, samples)
+}
+
+func (s *Server) handleAdminOrderTrack(w http.ResponseWriter, r *http.Request) {
+	orderID := chi.URLParam(r, "id")
+	rng, err := parseTrackRange(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	format, err := parseTrackFormat(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	samples, err := s.svc.AdminOrderTrack(r.Context(), orderID, rng)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondTrack(w, format, "order "+orderID, samples)
+}
+
+func (s *Server) handleAdminListServiceAreas(w http.ResponseWriter, r *http.Request) {
+	areas, err := s.svc.AdminListServiceAreas(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	resp := make([]transport.ServiceAreaResponse, 0, len(areas))
+	for _, area := range areas {
+		resp = append(resp, transport.FromServiceArea(area))
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleAdminCreateServiceArea(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name     string                   `json:"name"`
+		Boundary transport.GeoJSONPolygon `json:"boundary"`
+		Active   *bool                    `json:"active"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, domain.ErrInvalid)
+		return
+	}
+	boundary, err := transport.ToPolygon(req.Boundary)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	active := req.Active == nil || *req.Active
+	area, err := s.svc.AdminCreateServiceArea(r.Context(), req.Name, boundary, active)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondServiceArea(w, http.StatusCreated, area)
+}
+
+func (s *Server) handleAdminGetServiceArea(w http.ResponseWriter, r *http.Request) {
+	area, err := s.svc.AdminGetServiceArea(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondServiceArea(w, http.StatusOK, area)
+}
+
+func (s *Server) handleAdminUpdateServiceArea(w http.ResponseWriter, r *http.Request) {
+	areaID := chi.URLParam(r, "id")
+	expectedVersion, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var req struct {
+		Name     *string                   `json:"name"`
+		Boundary *transport.GeoJSONPolygon `json:"boundary"`
+		Active   *bool                     `json:"active"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, domain.ErrInvalid)
+		return
+	}
+	update := service.ServiceAreaUpdate{Name: req.Name, Active: req.Active}
+	if req.Boundary != nil {
+		if update.Boundary, err = transport.ToPolygon(*req.Boundary); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+	area, err := s.svc.AdminUpdateServiceArea(r.Context(), areaID, update, expectedVersion)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondServiceArea(w, http.StatusOK, area)
+}
+
+func (s *Server) handleAdminListNoFlyZones(w http.ResponseWriter, r *http.Request) {
+	zones, err := s.svc.AdminListNoFlyZones(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	resp := make([]transport.NoFlyZoneResponse, 0, len(zones))
+	for _, zone := range zones {
+		resp = append(resp, transport.FromNoFlyZone(zone))
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleAdminCreateNoFlyZone(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name        string                   `json:"name"`
+		Boundary    transport.GeoJSONPolygon `json:"boundary"`
+		ActiveFrom  *time.Time               `json:"active_from"`
+		ActiveUntil *time.Time               `json:"active_until"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, domain.ErrInvalid)
+		return
+	}
+	boundary, err := transport.ToPolygon(req.Boundary)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	window := service.NoFlyWindow{From: req.ActiveFrom, Until: req.ActiveUntil}
+	zone, err := s.svc.AdminCreateNoFlyZone(r.Context(), req.Name, boundary, window)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondNoFlyZone(w, http.StatusCreated, zone)
+}
+
+func (s *Server) handleAdminGetNoFlyZone(w http.ResponseWriter, r *http.Request) {
+	zone, err := s.svc.AdminGetNoFlyZone(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondNoFlyZone(w, http.StatusOK, zone)
+}
+
+// handleAdminUpdateNoFlyZone replaces the whole window when either
+// active_from or active_until is present; an omitted or null end is open.
+func (s *Server) handleAdminUpdateNoFlyZone(w http.ResponseWriter, r *http.Request) {
+	zoneID := chi.URLParam(r, "id")
+	expectedVersion, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var req struct {
+		Name        *string                   `json:"name"`
+		Boundary    *transport.GeoJSONPolygon `json:"boundary"`
+		ActiveFrom  optionalTime              `json:"active_from"`
+		ActiveUntil optionalTime              `json:"active_until"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, domain.ErrInvalid)
+		return
+	}
+	update := service.NoFlyZoneUpdate{Name: req.Name}
+	if req.Boundary != nil {
+		if update.Boundary, err = transport.ToPolygon(*req.Boundary); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+	if req.ActiveFrom.Set || req.ActiveUntil.Set {
+		update.Window = &service.NoFlyWindow{From: req.ActiveFrom.Time, Until: req.ActiveUntil.Time}
+	}
+	zone, err := s.svc.AdminUpdateNoFlyZone(r.Context(), zoneID, update, expectedVersion)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondNoFlyZone(w, http.StatusOK, zone)
+}
+
+func (s *Server) handleAdminListDepots(w http.ResponseWriter, r *http.Request) {
+	depots, err := s.svc.AdminListDepots(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	resp := make([]transport.DepotResponse, 0, len(depots))
+	for _, depot := range depots {
+		resp = append(resp, transport.FromDepot(depot))
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleAdminCreateDepot(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name     string             `json:"name"`
+		Location transport.Location `json:"location"`
+		Capacity int                `json:"capacity"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, domain.ErrInvalid)
+		return
+	}
+	depot, err := s.svc.AdminCreateDepot(r.Context(), req.Name, toDomainLocation(req.Location), req.Capacity)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondDepot(w, http.StatusCreated, depot)
+}
+
+func (s *Server) handleAdminGetDepot(w http.ResponseWriter, r *http.Request) {
+	depot, err := s.svc.AdminGetDepot(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondDepot(w, http.StatusOK, depot)
+}
+
+func (s *Server) handleAdminUpdateDepot(w http.ResponseWriter, r *http.Request) {
+	depotID := chi.URLParam(r, "id")
+	expectedVersion, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var req struct {
+		Name     *string             `json:"name"`
+		Location *transport.Location `json:"location"`
+		Capacity *int                `json:"capacity"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, domain.ErrInvalid)
+		return
+	}
+	update := service.DepotUpdate{Name: req.Name, Capacity: req.Capacity}
+	if req.Location != nil {
+		loc := toDomainLocation(*req.Location)
+		update.Location = &loc
+	}
+	depot, err := s.svc.AdminUpdateDepot(r.Context(), depotID, update, expectedVersion)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondDepot(w, http.StatusOK, depot)
+}
+
+func (s *Server) handleAdminListChargingStations(w http.ResponseWriter, r *http.Request) {
+	views, err := s.svc.AdminListChargingStations(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	resp := make([]transport.ChargingStationResponse, 0, len(views))
+	for _, view := range views {
+		resp = append(resp, transport.FromChargingStation(view))
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleAdminCreateChargingStation(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name     string             `json:"name"`
+		Location transport.Location `json:"location"`
+		Slots    int                `json:"slots"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, domain.ErrInvalid)
+		return
+	}
+	view, err := s.svc.AdminCreateChargingStation(r.Context(), req.Name, toDomainLocation(req.Location), req.Slots)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondChargingStation(w, http.StatusCreated, view)
+}
+
+func (s *Server) handleAdminGetChargingStation(w http.ResponseWriter, r *http.Request) {
+	view, err := s.svc.AdminGetChargingStation(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondChargingStation(w, http.StatusOK, view)
+}
+
+func (s *Server) handleAdminUpdateChargingStation(w http.ResponseWriter, r *http.Request) {
+	stationID := chi.URLParam(r, "id")
+	expectedVersion, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var req struct {
+		Name     *string             `json:"name"`
+		Location *transport.Location `json:"location"`
+		Slots    *int                `json:"slots"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, domain.ErrInvalid)
+		return
+	}
+	update := service.ChargingStationUpdate{Name: req.Name, Slots: req.Slots}
+	if req.Location != nil {
+		loc := toDomainLocation(*req.Location)
+		update.Location = &loc
+	}
+	view, err := s.svc.AdminUpdateChargingStation(r.Context(), stationID, update, expectedVersion)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondChargingStation(w, http.StatusOK, view)
+}
+
+func (s *Server) handleDroneReserveCharging(w http.ResponseWriter, r *http.Request) {
+	claims := mustClaims(r)
+	var req struct {
+		StationID string `json:"station_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.StationID == "" {
+		writeError(w, domain.ErrInvalid)
+		return
+	}
+	slot, err := s.svc.DroneReserveChargingSlot(r.Context(), claims.Subject, req.StationID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, transport.FromChargingSlot(slot))
+}
+
+func (s *Server) handleDroneReleaseCharging(w http.ResponseWriter, r *http.Request) {
+	claims := mustClaims(r)
+	slot, err := s.svc.DroneReleaseChargingSlot(r.Context(), claims.Subject)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, transport.FromChargingSlot(slot))
+}
+
+// optionalTime tells a JSON field that is absent (Set is false) from one that
+// is null (Set, with a nil Time).
+type optionalTime struct {
+	Set  bool
+	Time *time.Time
+}
+
+func (o *optionalTime) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	return json.Unmarshal(data, &o.Time)
 }
 
 func mustClaims(r *http.Request) *auth.Claims {
@@ -320,7 +999,139 @@ func mustClaims(r *http.Request) *auth.Claims {
 	return claims
 }
 
+// idempotencyKey returns the client's Idempotency-Key header, or "" if the
+// request should not be deduplicated.
+func idempotencyKey(r *http.Request) string {
+	return strings.TrimSpace(r.Header.Get("Idempotency-Key"))
+}
+
+func parseOrderFilter(r *http.Request) (service.OrderFilter, error) {
+	query := r.URL.Query()
+	var filter service.OrderFilter
+	for _, param := range query["status"] {
+		for _, status := range strings.Split(param, ",") {
+			if status = strings.TrimSpace(status); status != "" {
+				filter.Statuses = append(filter.Statuses, domain.OrderStatus(status))
+			}
+		}
+	}
+	from, err := parseTimeParam(query.Get("created_from"))
+	if err != nil {
+		return filter, err
+	}
+	to, err := parseTimeParam(query.Get("created_to"))
+	if err != nil {
+		return filter, err
+	}
+	filter.CreatedFrom = from
+	filter.CreatedTo = to
+	filter.Sort = service.OrderSort(query.Get("sort"))
+	filter.Limit, _ = strconv.Atoi(query.Get("limit"))
+	filter.Cursor = query.Get("cursor")
+	if offset := query.Get("offset"); offset != "" {
+		if filter.Offset, err = strconv.Atoi(offset); err != nil {
+			return filter, domain.ErrInvalid
+		}
+	}
+	return filter, nil
+}
+
+func parseAdminOrderFilter(r *http.Request) (service.OrderFilter, error) {
+	filter, err := parseOrderFilter(r)
+	if err != nil {
+		return filter, err
+	}
+	query := r.URL.Query()
+	if userID := query.Get("user_id"); userID != "" {
+		filter.UserID = &userID
+	}
+	if droneID := query.Get("drone_id"); droneID != "" {
+		filter.DroneID = &droneID
+	}
+	if filter.UpdatedFrom, err = parseTimeParam(query.Get("updated_from")); err != nil {
+		return filter, err
+	}
+	if filter.UpdatedTo, err = parseTimeParam(query.Get("updated_to")); err != nil {
+		return filter, err
+	}
+	filter.FailureReason = query.Get("failure_reason")
+	if filter.OriginBox, err = parseBoxParam(query.Get("origin_bbox")); err != nil {
+		return filter, err
+	}
+	if filter.DestinationBox, err = parseBoxParam(query.Get("destination_bbox")); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+// parseRadiusQuery parses lat, lng, radius_m, status and limit.
+func parseRadiusQuery(r *http.Request) (service.RadiusQuery, error) {
+	var query service.RadiusQuery
+	center, err := parsePointParams(r)
+	if err != nil {
+		return query, err
+	}
+	query.Center = center
+	params := r.URL.Query()
+	if query.RadiusMeters, err = strconv.ParseFloat(params.Get("radius_m"), 64); err != nil {
+		return query, domain.ErrInvalid
+	}
+	for _, param := range params["status"] {
+		for _, status := range strings.Split(param, ",") {
+			if status = strings.TrimSpace(status); status != "" {
+				query.Statuses = append(query.Statuses, domain.OrderStatus(status))
+			}
+		}
+	}
+	query.Limit, _ = strconv.Atoi(params.Get("limit"))
+	return query, nil
+}
+
+// parsePointParams parses the required lat and lng query parameters.
+func parsePointParams(r *http.Request) (domain.Location, error) {
+	params := r.URL.Query()
+	lat, err := strconv.ParseFloat(params.Get("lat"), 64)
+	if err != nil {
+		return domain.Location{}, domain.ErrInvalid
+	}
+	lng, err := strconv.ParseFloat(params.Get("lng"), 64)
+	if err != nil {
+		return domain.Location{}, domain.ErrInvalid
+	}
+	return domain.Location{Lat: lat, Lng: lng}, nil
+}
+
+// parseBoxParam parses "minLat,minLng,maxLat,maxLng".
+func parseBoxParam(value string) (*domain.BoundingBox, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return nil, domain.ErrInvalid
+	}
+	var vals [4]float64
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, domain.ErrInvalid
+		}
+		vals[i] = v
+	}
+	return &domain.BoundingBox{MinLat: vals[0], MinLng: vals[1], MaxLat: vals[2], MaxLng: vals[3]}, nil
+}
+
+func parseTimeParam(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, domain.ErrInvalid
+	}
+	return &t, nil
+}
+
 func toDomainLocation(loc transport.Location) domain.Location {
 	return domain.Location{Lat: loc.Lat, Lng: loc.Lng}
 }
-
@@ -0,0 +1,163 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"time"
+
+	"penny-assesment/internal/domain"
+	"penny-assesment/internal/service"
+	"penny-assesment/internal/transport"
+)
+
+// Track export formats, chosen with the format query parameter.
+const (
+	trackFormatJSON    = "json"
+	trackFormatGeoJSON = "geojson"
+	trackFormatGPX     = "gpx"
+)
+
+// parseTrackRange parses the optional from and to query parameters.
+func parseTrackRange(r *http.Request) (service.TrackRange, error) {
+	var rng service.TrackRange
+	var err error
+	query := r.URL.Query()
+	if rng.From, err = parseTimeParam(query.Get("from")); err != nil {
+		return rng, err
+	}
+	if rng.To, err = parseTimeParam(query.Get("to")); err != nil {
+		return rng, err
+	}
+	return rng, nil
+}
+
+func parseTrackFormat(r *http.Request) (string, error) {
+	switch format := r.URL.Query().Get("format"); format {
+	case "", trackFormatJSON:
+		return trackFormatJSON, nil
+	case trackFormatGeoJSON, trackFormatGPX:
+		return format, nil
+	default:
+		return "", fmt.Errorf("track format %q: %w", format, domain.ErrInvalid)
+	}
+}
+
+// respondTrack writes samples in format. name titles the GPX track.
+func respondTrack(w http.ResponseWriter, format, name string, samples []*domain.TelemetrySample) {
+	switch format {
+	case trackFormatGeoJSON:
+		w.Header().Set("Content-Type", "application/geo+json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(trackGeoJSON(samples))
+	case trackFormatGPX:
+		w.Header().Set("Content-Type", "application/gpx+xml")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(xml.Header))
+		enc := xml.NewEncoder(w)
+		enc.Indent("", "  ")
+		_ = enc.Encode(trackGPX(name, samples))
+	default:
+		respondJSON(w, http.StatusOK, transport.FromTelemetry(samples))
+	}
+}
+
+// trackSegments splits samples into runs reported by the same drone, so an
+// order handed off between drones is not drawn as one jump between them.
+func trackSegments(samples []*domain.TelemetrySample) [][]*domain.TelemetrySample {
+	var segments [][]*domain.TelemetrySample
+	for i, sample := range samples {
+		if i == 0 || sample.DroneID != samples[i-1].DroneID {
+			segments = append(segments, nil)
+		}
+		last := len(segments) - 1
+		segments[last] = append(segments[last], sample)
+	}
+	return segments
+}
+
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string             `json:"type"`
+	Geometry   geoJSONGeometry    `json:"geometry"`
+	Properties geoJSONTrackFields `json:"properties"`
+}
+
+// geoJSONGeometry is a LineString, or a Point for a single-sample segment;
+// positions are [lng, lat].
+type geoJSONGeometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+// geoJSONTrackFields follows the coordinateTimes convention: one timestamp
+// per position.
+type geoJSONTrackFields struct {
+	DroneID         string      `json:"drone_id"`
+	CoordinateTimes []time.Time `json:"coordinateTimes"`
+}
+
+// trackGeoJSON renders samples as a FeatureCollection with one LineString
+// feature per drone segment.
+func trackGeoJSON(samples []*domain.TelemetrySample) geoJSONFeatureCollection {
+	fc := geoJSONFeatureCollection{Type: "FeatureCollection", Features: []geoJSONFeature{}}
+	for _, segment := range trackSegments(samples) {
+		coords := make([][]float64, 0, len(segment))
+		times := make([]time.Time, 0, len(segment))
+		for _, sample := range segment {
+			coords = append(coords, []float64{sample.Location.Lng, sample.Location.Lat})
+			times = append(times, sample.RecordedAt)
+		}
+		geometry := geoJSONGeometry{Type: "LineString", Coordinates: coords}
+		if len(coords) == 1 {
+			geometry = geoJSONGeometry{Type: "Point", Coordinates: coords[0]}
+		}
+		fc.Features = append(fc.Features, geoJSONFeature{
+			Type:       "Feature",
+			Geometry:   geometry,
+			Properties: geoJSONTrackFields{DroneID: segment[0].DroneID, CoordinateTimes: times},
+		})
+	}
+	return fc
+}
+
+type gpxDocument struct {
+	XMLName xml.Name `xml:"http://www.topografix.com/GPX/1/1 gpx"`
+	Version string   `xml:"version,attr"`
+	Creator string   `xml:"creator,attr"`
+	Track   gpxTrack `xml:"trk"`
+}
+
+type gpxTrack struct {
+	Name     string       `xml:"name"`
+	Segments []gpxSegment `xml:"trkseg"`
+}
+
+type gpxSegment struct {
+	Points []gpxPoint `xml:"trkpt"`
+}
+
+type gpxPoint struct {
+	Lat  float64   `xml:"lat,attr"`
+	Lon  float64   `xml:"lon,attr"`
+	Time time.Time `xml:"time"`
+}
+
+// trackGPX renders samples as a GPX 1.1 track with one segment per drone
+// segment.
+func trackGPX(name string, samples []*domain.TelemetrySample) gpxDocument {
+	doc := gpxDocument{Version: "1.1", Creator: "penny-assesment", Track: gpxTrack{Name: name}}
+	for _, segment := range trackSegments(samples) {
+		points := make([]gpxPoint, 0, len(segment))
+		for _, sample := range segment {
+			points = append(points, gpxPoint{Lat: sample.Location.Lat, Lon: sample.Location.Lng, Time: sample.RecordedAt.UTC()})
+		}
+		doc.Track.Segments = append(doc.Track.Segments, gpxSegment{Points: points})
+	}
+	return doc
+}
@@ -1,6 +1,7 @@
 package transport
 
 import (
+	"fmt"
 	"time"
 
 	"penny-assesment/internal/domain"
@@ -27,12 +28,46 @@ type OrderResponse struct {
 	DeliveredAt     *time.Time `json:"delivered_at,omitempty"`
 	FailedAt        *time.Time `json:"failed_at,omitempty"`
 	FailureReason   *string    `json:"failure_reason,omitempty"`
+	Route           []Location `json:"route,omitempty"`
+	// Legs and CurrentLeg are set on orders relayed between drones;
+	// CurrentLeg indexes Legs and is omitted once they are all flown.
+	Legs       []RelayLegResponse `json:"legs,omitempty"`
+	CurrentLeg *int               `json:"current_leg,omitempty"`
+	// PickupNotBefore and DeliverBy are the order's delivery window, if
+	// scheduled; SLAAtRiskAt is when its ETA was first found to miss
+	// DeliverBy.
+	PickupNotBefore *time.Time `json:"pickup_not_before,omitempty"`
+	DeliverBy       *time.Time `json:"deliver_by,omitempty"`
+	SLAAtRiskAt     *time.Time `json:"sla_at_risk_at,omitempty"`
+	Version         int64      `json:"version"`
+}
+
+// RelayLegResponse is one hop of a relayed order, ending at To.
+type RelayLegResponse struct {
+	To           Location   `json:"to"`
+	RelayPointID string     `json:"relay_point_id,omitempty"`
+	DroneID      *string    `json:"drone_id,omitempty"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
 }
 
 type OrderViewResponse struct {
 	Order           OrderResponse `json:"order"`
 	CurrentLocation *Location     `json:"current_location,omitempty"`
 	ETASeconds      *int64        `json:"eta_seconds,omitempty"`
+	ETALegs         []ETALeg      `json:"eta_legs,omitempty"`
+	ETASource       string        `json:"eta_source,omitempty"`
+	ETAConfidence   *float64      `json:"eta_confidence,omitempty"`
+}
+
+type ETALeg struct {
+	Kind           string  `json:"kind"`
+	DistanceMeters float64 `json:"distance_meters,omitempty"`
+	Seconds        int64   `json:"seconds"`
+}
+
+type OrderPageResponse struct {
+	Orders     []OrderViewResponse `json:"orders"`
+	NextCursor string              `json:"next_cursor,omitempty"`
 }
 
 type DroneResponse struct {
@@ -41,13 +76,70 @@ type DroneResponse struct {
 	LastLocation    *Location  `json:"last_location,omitempty"`
 	LastHeartbeatAt *time.Time `json:"last_heartbeat_at,omitempty"`
 	CurrentOrderID  *string    `json:"current_order_id,omitempty"`
-	CreatedAt       time.Time  `json:"created_at"`
-	UpdatedAt       time.Time  `json:"updated_at"`
+	HomeDepotID     *string    `json:"home_depot_id,omitempty"`
+	// Stops are the pickups and dropoffs of the drone's trip, in flight
+	// order.
+	Stops     []StopResponse `json:"stops,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	Version   int64          `json:"version"`
+	// Vitals are the readings of the drone's latest heartbeat.
+	Vitals
+	// FlightUsage is the flying the drone has done on completed orders.
+	FlightUsage
+}
+
+// StopResponse is a pickup or dropoff of one order on a drone's trip.
+type StopResponse struct {
+	OrderID     string     `json:"order_id"`
+	Kind        string     `json:"kind"`
+	Location    Location   `json:"location"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// FlightUsage is a drone's flying in total and since it was last serviced;
+// it is flattened into DroneResponse.
+type FlightUsage struct {
+	FlightSeconds       float64 `json:"flight_seconds"`
+	FlightMeters        float64 `json:"flight_meters"`
+	SinceServiceSeconds float64 `json:"since_service_seconds"`
+	SinceServiceMeters  float64 `json:"since_service_meters"`
+}
+
+// Vitals are the optional readings a drone sends with a heartbeat besides its
+// position; they are flattened into the heartbeat request and DroneResponse.
+type Vitals struct {
+	AltitudeMeters *float64 `json:"altitude_m,omitempty"`
+	HeadingDegrees *float64 `json:"heading_deg,omitempty"`
+	GroundSpeedMPS *float64 `json:"ground_speed_mps,omitempty"`
+	BatteryPercent *float64 `json:"battery_pct,omitempty"`
+	FaultCodes     []string `json:"fault_codes,omitempty"`
 }
 
 type DroneStatusResponse struct {
-	Drone        DroneResponse     `json:"drone"`
-	CurrentOrder *OrderViewResponse `json:"current_order,omitempty"`
+	Drone        DroneResponse         `json:"drone"`
+	CurrentOrder *OrderViewResponse    `json:"current_order,omitempty"`
+	ReturnToBase *ReturnToBaseResponse `json:"return_to_base,omitempty"`
+	ChargeAt     *ChargeAtResponse     `json:"charge_at,omitempty"`
+}
+
+// ReturnToBaseResponse tells an idle drone to fly back to its home depot.
+type ReturnToBaseResponse struct {
+	DepotID        string     `json:"depot_id"`
+	Location       Location   `json:"location"`
+	Route          []Location `json:"route"`
+	DistanceMeters float64    `json:"distance_m"`
+	ETASeconds     int64      `json:"eta_seconds"`
+}
+
+// ChargeAtResponse points a drone low on battery at the nearest charging
+// station with a free slot.
+type ChargeAtResponse struct {
+	StationID      string   `json:"station_id"`
+	Name           string   `json:"name"`
+	Location       Location `json:"location"`
+	DistanceMeters float64  `json:"distance_m"`
+	FreeSlots      int      `json:"free_slots"`
 }
 
 func FromOrder(order *domain.Order) OrderResponse {
@@ -65,10 +157,28 @@ func FromOrder(order *domain.Order) OrderResponse {
 		DeliveredAt:     order.DeliveredAt,
 		FailedAt:        order.FailedAt,
 		FailureReason:   order.FailureReason,
+		PickupNotBefore: order.PickupNotBefore,
+		DeliverBy:       order.DeliverBy,
+		SLAAtRiskAt:     order.SLAAtRiskAt,
+		Version:         order.Version,
 	}
 	if order.HandoffOrigin != nil {
 		resp.HandoffOrigin = &Location{Lat: order.HandoffOrigin.Lat, Lng: order.HandoffOrigin.Lng}
 	}
+	for _, loc := range order.Route {
+		resp.Route = append(resp.Route, Location{Lat: loc.Lat, Lng: loc.Lng})
+	}
+	for _, leg := range order.Legs {
+		resp.Legs = append(resp.Legs, RelayLegResponse{
+			To:           Location{Lat: leg.To.Lat, Lng: leg.To.Lng},
+			RelayPointID: leg.RelayPointID,
+			DroneID:      leg.DroneID,
+			CompletedAt:  leg.CompletedAt,
+		})
+	}
+	if cur := order.CurrentLeg(); cur >= 0 {
+		resp.CurrentLeg = &cur
+	}
 	return resp
 }
 
@@ -80,6 +190,25 @@ func FromOrderView(view *service.OrderView) OrderViewResponse {
 	if view.CurrentLocation != nil {
 		resp.CurrentLocation = &Location{Lat: view.CurrentLocation.Lat, Lng: view.CurrentLocation.Lng}
 	}
+	if view.ETASeconds != nil {
+		resp.ETASource = string(view.ETASource)
+		confidence := view.ETAConfidence
+		resp.ETAConfidence = &confidence
+	}
+	for _, leg := range view.ETALegs {
+		resp.ETALegs = append(resp.ETALegs, ETALeg{Kind: string(leg.Kind), DistanceMeters: leg.DistanceMeters, Seconds: leg.Seconds})
+	}
+	return resp
+}
+
+func FromOrderPage(page *service.OrderPage) OrderPageResponse {
+	resp := OrderPageResponse{
+		Orders:     make([]OrderViewResponse, 0, len(page.Orders)),
+		NextCursor: page.NextCursor,
+	}
+	for _, view := range page.Orders {
+		resp.Orders = append(resp.Orders, FromOrderView(view))
+	}
 	return resp
 }
 
@@ -88,16 +217,53 @@ func FromDrone(drone *domain.Drone) DroneResponse {
 		ID:              drone.ID,
 		Status:          string(drone.Status),
 		CurrentOrderID:  drone.CurrentOrderID,
+		HomeDepotID:     drone.HomeDepotID,
 		CreatedAt:       drone.CreatedAt,
 		UpdatedAt:       drone.UpdatedAt,
 		LastHeartbeatAt: drone.LastHeartbeatAt,
+		Vitals:          FromVitals(drone.Vitals),
+		FlightUsage: FlightUsage{
+			FlightSeconds:       drone.Usage.Seconds,
+			FlightMeters:        drone.Usage.Meters,
+			SinceServiceSeconds: drone.SinceService.Seconds,
+			SinceServiceMeters:  drone.SinceService.Meters,
+		},
+		Version: drone.Version,
 	}
 	if drone.LastLocation != nil {
 		resp.LastLocation = &Location{Lat: drone.LastLocation.Lat, Lng: drone.LastLocation.Lng}
 	}
+	for _, stop := range drone.Stops {
+		resp.Stops = append(resp.Stops, StopResponse{
+			OrderID:     stop.OrderID,
+			Kind:        string(stop.Kind),
+			Location:    Location{Lat: stop.Location.Lat, Lng: stop.Location.Lng},
+			CompletedAt: stop.CompletedAt,
+		})
+	}
 	return resp
 }
 
+func FromVitals(v domain.Vitals) Vitals {
+	return Vitals{
+		AltitudeMeters: v.AltitudeMeters,
+		HeadingDegrees: v.HeadingDegrees,
+		GroundSpeedMPS: v.GroundSpeedMPS,
+		BatteryPercent: v.BatteryPercent,
+		FaultCodes:     v.FaultCodes,
+	}
+}
+
+func ToVitals(v Vitals) domain.Vitals {
+	return domain.Vitals{
+		AltitudeMeters: v.AltitudeMeters,
+		HeadingDegrees: v.HeadingDegrees,
+		GroundSpeedMPS: v.GroundSpeedMPS,
+		BatteryPercent: v.BatteryPercent,
+		FaultCodes:     v.FaultCodes,
+	}
+}
+
 func FromDroneStatus(view *service.DroneStatusView) DroneStatusResponse {
 	resp := DroneStatusResponse{
 		Drone: FromDrone(view.Drone),
@@ -106,5 +272,233 @@ func FromDroneStatus(view *service.DroneStatusView) DroneStatusResponse {
 		orderView := FromOrderView(view.CurrentOrder)
 		resp.CurrentOrder = &orderView
 	}
+	if rtb := view.ReturnToBase; rtb != nil {
+		resp.ReturnToBase = &ReturnToBaseResponse{
+			DepotID:        rtb.DepotID,
+			Location:       Location{Lat: rtb.Location.Lat, Lng: rtb.Location.Lng},
+			DistanceMeters: rtb.DistanceMeters,
+			ETASeconds:     rtb.ETASeconds,
+		}
+		for _, loc := range rtb.Route {
+			resp.ReturnToBase.Route = append(resp.ReturnToBase.Route, Location{Lat: loc.Lat, Lng: loc.Lng})
+		}
+	}
+	if c := view.ChargeAt; c != nil {
+		resp.ChargeAt = &ChargeAtResponse{
+			StationID:      c.StationID,
+			Name:           c.Name,
+			Location:       Location{Lat: c.Location.Lat, Lng: c.Location.Lng},
+			DistanceMeters: c.DistanceMeters,
+			FreeSlots:      c.FreeSlots,
+		}
+	}
+	return resp
+}
+
+// GeoJSONPolygon is a GeoJSON Polygon geometry; positions are [lng, lat].
+type GeoJSONPolygon struct {
+	Type        string        `json:"type"`
+	Coordinates [][][]float64 `json:"coordinates"`
+}
+
+type ServiceAreaResponse struct {
+	ID        string         `json:"id"`
+	Name      string         `json:"name"`
+	Boundary  GeoJSONPolygon `json:"boundary"`
+	Active    bool           `json:"active"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	Version   int64          `json:"version"`
+}
+
+// ToPolygon converts a GeoJSON Polygon geometry. Any other geometry type, or
+// a position without both coordinates, is domain.ErrInvalid.
+func ToPolygon(geometry GeoJSONPolygon) (domain.Polygon, error) {
+	if geometry.Type != "Polygon" {
+		return nil, fmt.Errorf("geometry type %q: %w", geometry.Type, domain.ErrInvalid)
+	}
+	polygon, err := domain.PolygonFromGeoJSON(geometry.Coordinates)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, domain.ErrInvalid)
+	}
+	return polygon, nil
+}
+
+func FromPolygon(polygon domain.Polygon) GeoJSONPolygon {
+	return GeoJSONPolygon{Type: "Polygon", Coordinates: polygon.GeoJSONCoordinates()}
+}
+
+func FromServiceArea(area *domain.ServiceArea) ServiceAreaResponse {
+	return ServiceAreaResponse{
+		ID:        area.ID,
+		Name:      area.Name,
+		Boundary:  FromPolygon(area.Boundary),
+		Active:    area.Active,
+		CreatedAt: area.CreatedAt,
+		UpdatedAt: area.UpdatedAt,
+		Version:   area.Version,
+	}
+}
+
+// NoFlyZoneResponse omits an open end of the zone's window.
+type NoFlyZoneResponse struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Boundary    GeoJSONPolygon `json:"boundary"`
+	ActiveFrom  *time.Time     `json:"active_from,omitempty"`
+	ActiveUntil *time.Time     `json:"active_until,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	Version     int64          `json:"version"`
+}
+
+func FromNoFlyZone(zone *domain.NoFlyZone) NoFlyZoneResponse {
+	return NoFlyZoneResponse{
+		ID:          zone.ID,
+		Name:        zone.Name,
+		Boundary:    FromPolygon(zone.Boundary),
+		ActiveFrom:  zone.ActiveFrom,
+		ActiveUntil: zone.ActiveUntil,
+		CreatedAt:   zone.CreatedAt,
+		UpdatedAt:   zone.UpdatedAt,
+		Version:     zone.Version,
+	}
+}
+
+type DepotResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Location  Location  `json:"location"`
+	Capacity  int       `json:"capacity"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Version   int64     `json:"version"`
+}
+
+func FromDepot(depot *domain.Depot) DepotResponse {
+	return DepotResponse{
+		ID:        depot.ID,
+		Name:      depot.Name,
+		Location:  Location{Lat: depot.Location.Lat, Lng: depot.Location.Lng},
+		Capacity:  depot.Capacity,
+		CreatedAt: depot.CreatedAt,
+		UpdatedAt: depot.UpdatedAt,
+		Version:   depot.Version,
+	}
+}
+
+// ChargingStationResponse is a charging station with its occupancy. Slots is
+// only filled in for a single station.
+type ChargingStationResponse struct {
+	ID        string                 `json:"id"`
+	Name      string                 `json:"name"`
+	Location  Location               `json:"location"`
+	Slots     int                    `json:"slots"`
+	Occupied  int                    `json:"occupied"`
+	SlotList  []ChargingSlotResponse `json:"slot_list,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at"`
+	Version   int64                  `json:"version"`
+}
+
+type ChargingSlotResponse struct {
+	StationID  string     `json:"station_id"`
+	Slot       int        `json:"slot"`
+	DroneID    *string    `json:"drone_id,omitempty"`
+	ReservedAt *time.Time `json:"reserved_at,omitempty"`
+}
+
+func FromChargingStation(view *service.ChargingStationView) ChargingStationResponse {
+	station := view.Station
+	resp := ChargingStationResponse{
+		ID:        station.ID,
+		Name:      station.Name,
+		Location:  Location{Lat: station.Location.Lat, Lng: station.Location.Lng},
+		Slots:     station.Slots,
+		Occupied:  view.Occupied,
+		CreatedAt: station.CreatedAt,
+		UpdatedAt: station.UpdatedAt,
+		Version:   station.Version,
+	}
+	for _, slot := range view.Slots {
+		resp.SlotList = append(resp.SlotList, FromChargingSlot(slot))
+	}
+	return resp
+}
+
+func FromChargingSlot(slot *domain.ChargingSlot) ChargingSlotResponse {
+	return ChargingSlotResponse{
+		StationID:  slot.StationID,
+		Slot:       slot.Number,
+		DroneID:    slot.DroneID,
+		ReservedAt: slot.ReservedAt,
+	}
+}
+
+// TelemetrySampleResponse is one point of a drone or order track.
+type TelemetrySampleResponse struct {
+	DroneID    string    `json:"drone_id"`
+	OrderID    *string   `json:"order_id,omitempty"`
+	Location   Location  `json:"location"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+func FromTelemetry(samples []*domain.TelemetrySample) []TelemetrySampleResponse {
+	resp := make([]TelemetrySampleResponse, 0, len(samples))
+	for _, sample := range samples {
+		resp = append(resp, TelemetrySampleResponse{
+			DroneID:    sample.DroneID,
+			OrderID:    sample.OrderID,
+			Location:   Location{Lat: sample.Location.Lat, Lng: sample.Location.Lng},
+			RecordedAt: sample.RecordedAt,
+		})
+	}
 	return resp
 }
+
+// MaintenanceRecordResponse omits closed_at while the record is open.
+type MaintenanceRecordResponse struct {
+	ID            string     `json:"id"`
+	DroneID       string     `json:"drone_id"`
+	Reason        string     `json:"reason"`
+	FaultCodes    []string   `json:"fault_codes,omitempty"`
+	FlightSeconds float64    `json:"flight_seconds"`
+	FlightMeters  float64    `json:"flight_meters"`
+	OpenedAt      time.Time  `json:"opened_at"`
+	ClosedAt      *time.Time `json:"closed_at,omitempty"`
+	Technician    *string    `json:"technician,omitempty"`
+	Notes         *string    `json:"notes,omitempty"`
+	Parts         []string   `json:"parts,omitempty"`
+}
+
+func FromMaintenanceRecords(records []*domain.MaintenanceRecord) []MaintenanceRecordResponse {
+	resp := make([]MaintenanceRecordResponse, 0, len(records))
+	for _, record := range records {
+		resp = append(resp, MaintenanceRecordResponse{
+			ID:            record.ID,
+			DroneID:       record.DroneID,
+			Reason:        string(record.Reason),
+			FaultCodes:    record.FaultCodes,
+			FlightSeconds: record.Usage.Seconds,
+			FlightMeters:  record.Usage.Meters,
+			OpenedAt:      record.OpenedAt,
+			ClosedAt:      record.ClosedAt,
+			Technician:    record.Technician,
+			Notes:         record.Notes,
+			Parts:         record.Parts,
+		})
+	}
+	return resp
+}
+
+// MaintenanceReport is the optional account of a repair sent when a drone is
+// marked fixed.
+type MaintenanceReport struct {
+	Technician string   `json:"technician,omitempty"`
+	Notes      string   `json:"notes,omitempty"`
+	Parts      []string `json:"parts,omitempty"`
+}
+
+func ToMaintenanceReport(r MaintenanceReport) domain.MaintenanceReport {
+	return domain.MaintenanceReport{Technician: r.Technician, Notes: r.Notes, Parts: r.Parts}
+}
@@ -31,22 +31,55 @@ func (p processorFunc) Process(ctx context.Context, seqID int32, in, out thrift.
 func NewProcessor(svc *service.Service, authenticator *auth.Authenticator) *Processor {
 	p := &Processor{svc: svc, auth: authenticator}
 	p.processorMap = map[string]thrift.TProcessorFunction{
-		"IssueToken":      processorFunc{fn: p.handleIssueToken},
-		"SubmitOrder":     processorFunc{fn: p.handleSubmitOrder},
-		"WithdrawOrder":   processorFunc{fn: p.handleWithdrawOrder},
-		"GetOrder":        processorFunc{fn: p.handleGetOrder},
-		"ReserveJob":      processorFunc{fn: p.handleReserveJob},
-		"PickupOrder":     processorFunc{fn: p.handlePickupOrder},
-		"DeliverOrder":    processorFunc{fn: p.handleDeliverOrder},
-		"FailOrder":       processorFunc{fn: p.handleFailOrder},
-		"MarkBroken":      processorFunc{fn: p.handleMarkBroken},
-		"Heartbeat":       processorFunc{fn: p.handleHeartbeat},
-		"CurrentOrder":    processorFunc{fn: p.handleCurrentOrder},
-		"ListOrders":      processorFunc{fn: p.handleAdminListOrders},
-		"UpdateOrder":     processorFunc{fn: p.handleAdminUpdateOrder},
-		"ListDrones":      processorFunc{fn: p.handleAdminListDrones},
-		"MarkDroneBroken": processorFunc{fn: p.handleAdminMarkDroneBroken},
-		"MarkDroneFixed":  processorFunc{fn: p.handleAdminMarkDroneFixed},
+		"IssueToken":            processorFunc{fn: p.handleIssueToken},
+		"SubmitOrder":           processorFunc{fn: p.handleSubmitOrder},
+		"WithdrawOrder":         processorFunc{fn: p.handleWithdrawOrder},
+		"GetOrder":              processorFunc{fn: p.handleGetOrder},
+		"ListMyOrders":          processorFunc{fn: p.handleListMyOrders},
+		"ReserveJob":            processorFunc{fn: p.handleReserveJob},
+		"PickupOrder":           processorFunc{fn: p.handlePickupOrder},
+		"DeliverOrder":          processorFunc{fn: p.handleDeliverOrder},
+		"FailOrder":             processorFunc{fn: p.handleFailOrder},
+		"MarkBroken":            processorFunc{fn: p.handleMarkBroken},
+		"SetStatus":             processorFunc{fn: p.handleSetStatus},
+		"Heartbeat":             processorFunc{fn: p.handleHeartbeat},
+		"CurrentOrder":          processorFunc{fn: p.handleCurrentOrder},
+		"ReserveChargingSlot":   processorFunc{fn: p.handleReserveChargingSlot},
+		"ReleaseChargingSlot":   processorFunc{fn: p.handleReleaseChargingSlot},
+		"ListOrders":            processorFunc{fn: p.handleAdminListOrders},
+		"ListOrdersPage":        processorFunc{fn: p.handleAdminListOrdersPage},
+		"UpdateOrder":           processorFunc{fn: p.handleAdminUpdateOrder},
+		"AssignOrder":           processorFunc{fn: p.handleAdminAssignOrder},
+		"UnassignOrder":         processorFunc{fn: p.handleAdminUnassignOrder},
+		"ReassignOrder":         processorFunc{fn: p.handleAdminReassignOrder},
+		"OverrideOrder":         processorFunc{fn: p.handleAdminOverrideOrder},
+		"OrdersNear":            processorFunc{fn: p.handleAdminOrdersNear},
+		"ListDrones":            processorFunc{fn: p.handleAdminListDrones},
+		"NearestIdleDrones":     processorFunc{fn: p.handleAdminNearestIdleDrones},
+		"MarkDroneBroken":       processorFunc{fn: p.handleAdminMarkDroneBroken},
+		"MarkDroneFixed":        processorFunc{fn: p.handleAdminMarkDroneFixed},
+		"SetDroneStatus":        processorFunc{fn: p.handleAdminSetDroneStatus},
+		"RetireDrone":           processorFunc{fn: p.handleAdminRetireDrone},
+		"ListServiceAreas":      processorFunc{fn: p.handleAdminListServiceAreas},
+		"GetServiceArea":        processorFunc{fn: p.handleAdminGetServiceArea},
+		"CreateServiceArea":     processorFunc{fn: p.handleAdminCreateServiceArea},
+		"UpdateServiceArea":     processorFunc{fn: p.handleAdminUpdateServiceArea},
+		"ListNoFlyZones":        processorFunc{fn: p.handleAdminListNoFlyZones},
+		"GetNoFlyZone":          processorFunc{fn: p.handleAdminGetNoFlyZone},
+		"CreateNoFlyZone":       processorFunc{fn: p.handleAdminCreateNoFlyZone},
+		"UpdateNoFlyZone":       processorFunc{fn: p.handleAdminUpdateNoFlyZone},
+		"DroneTrack":            processorFunc{fn: p.handleAdminDroneTrack},
+		"OrderTrack":            processorFunc{fn: p.handleAdminOrderTrack},
+		"DroneMaintenance":      processorFunc{fn: p.handleAdminDroneMaintenance},
+		"SetDroneDepot":         processorFunc{fn: p.handleAdminSetDroneDepot},
+		"ListDepots":            processorFunc{fn: p.handleAdminListDepots},
+		"GetDepot":              processorFunc{fn: p.handleAdminGetDepot},
+		"CreateDepot":           processorFunc{fn: p.handleAdminCreateDepot},
+		"UpdateDepot":           processorFunc{fn: p.handleAdminUpdateDepot},
+		"ListChargingStations":  processorFunc{fn: p.handleAdminListChargingStations},
+		"GetChargingStation":    processorFunc{fn: p.handleAdminGetChargingStation},
+		"CreateChargingStation": processorFunc{fn: p.handleAdminCreateChargingStation},
+		"UpdateChargingStation": processorFunc{fn: p.handleAdminUpdateChargingStation},
 	}
 	return p
 }
@@ -97,7 +130,7 @@ func (p *Processor) handleIssueToken(ctx context.Context, seqID int32, in, out t
 }
 
 func (p *Processor) handleSubmitOrder(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
-	authToken, origin, dest, err := readSubmitOrderRequest(ctx, in)
+	authToken, origin, dest, window, key, err := readSubmitOrderRequest(ctx, in)
 	if err != nil {
 		return p.writeException(ctx, out, "SubmitOrder", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
 	}
@@ -105,7 +138,7 @@ func (p *Processor) handleSubmitOrder(ctx context.Context, seqID int32, in, out
 	if appErr != nil {
 		return p.writeException(ctx, out, "SubmitOrder", seqID, appErr)
 	}
-	order, err := p.svc.SubmitOrder(ctx, claims.Subject, origin, dest)
+	order, err := p.svc.SubmitOrder(ctx, claims.Subject, origin, dest, window, key)
 	if err != nil {
 		return p.writeException(ctx, out, "SubmitOrder", seqID, mapError(err))
 	}
@@ -118,7 +151,7 @@ func (p *Processor) handleSubmitOrder(ctx context.Context, seqID int32, in, out
 }
 
 func (p *Processor) handleWithdrawOrder(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
-	authToken, orderID, err := readOrderIDRequest(ctx, in)
+	authToken, orderID, expectedVersion, err := readVersionedIDRequest(ctx, in)
 	if err != nil {
 		return p.writeException(ctx, out, "WithdrawOrder", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
 	}
@@ -126,7 +159,7 @@ func (p *Processor) handleWithdrawOrder(ctx context.Context, seqID int32, in, ou
 	if appErr != nil {
 		return p.writeException(ctx, out, "WithdrawOrder", seqID, appErr)
 	}
-	order, err := p.svc.WithdrawOrder(ctx, claims.Subject, orderID)
+	order, err := p.svc.WithdrawOrder(ctx, claims.Subject, orderID, expectedVersion)
 	if err != nil {
 		return p.writeException(ctx, out, "WithdrawOrder", seqID, mapError(err))
 	}
@@ -159,8 +192,29 @@ func (p *Processor) handleGetOrder(ctx context.Context, seqID int32, in, out thr
 	})
 }
 
+func (p *Processor) handleListMyOrders(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, filter, err := readListOrdersRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "ListMyOrders", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	claims, appErr := p.authorize(authToken, domain.RoleEndUser)
+	if appErr != nil {
+		return p.writeException(ctx, out, "ListMyOrders", seqID, appErr)
+	}
+	page, err := p.svc.ListMyOrders(ctx, claims.Subject, filter)
+	if err != nil {
+		return p.writeException(ctx, out, "ListMyOrders", seqID, mapError(err))
+	}
+	return p.writeReply(ctx, out, "ListMyOrders", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
+			return err
+		}
+		return writeOrderPage(ctx, out, page)
+	})
+}
+
 func (p *Processor) handleReserveJob(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
-	authToken, err := readAuthRequest(ctx, in)
+	authToken, key, err := readIdempotentAuthRequest(ctx, in)
 	if err != nil {
 		return p.writeException(ctx, out, "ReserveJob", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
 	}
@@ -168,7 +222,7 @@ func (p *Processor) handleReserveJob(ctx context.Context, seqID int32, in, out t
 	if appErr != nil {
 		return p.writeException(ctx, out, "ReserveJob", seqID, appErr)
 	}
-	order, err := p.svc.DroneReserveJob(ctx, claims.Subject)
+	order, err := p.svc.DroneReserveJob(ctx, claims.Subject, key)
 	if err != nil {
 		return p.writeException(ctx, out, "ReserveJob", seqID, mapError(err))
 	}
@@ -181,7 +235,7 @@ func (p *Processor) handleReserveJob(ctx context.Context, seqID int32, in, out t
 }
 
 func (p *Processor) handlePickupOrder(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
-	authToken, orderID, err := readOrderIDRequest(ctx, in)
+	authToken, orderID, key, err := readIdempotentIDRequest(ctx, in)
 	if err != nil {
 		return p.writeException(ctx, out, "PickupOrder", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
 	}
@@ -189,7 +243,7 @@ func (p *Processor) handlePickupOrder(ctx context.Context, seqID int32, in, out
 	if appErr != nil {
 		return p.writeException(ctx, out, "PickupOrder", seqID, appErr)
 	}
-	order, err := p.svc.DronePickup(ctx, claims.Subject, orderID)
+	order, err := p.svc.DronePickup(ctx, claims.Subject, orderID, key)
 	if err != nil {
 		return p.writeException(ctx, out, "PickupOrder", seqID, mapError(err))
 	}
@@ -202,7 +256,7 @@ func (p *Processor) handlePickupOrder(ctx context.Context, seqID int32, in, out
 }
 
 func (p *Processor) handleDeliverOrder(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
-	authToken, orderID, err := readOrderIDRequest(ctx, in)
+	authToken, orderID, key, err := readIdempotentIDRequest(ctx, in)
 	if err != nil {
 		return p.writeException(ctx, out, "DeliverOrder", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
 	}
@@ -210,7 +264,7 @@ func (p *Processor) handleDeliverOrder(ctx context.Context, seqID int32, in, out
 	if appErr != nil {
 		return p.writeException(ctx, out, "DeliverOrder", seqID, appErr)
 	}
-	order, err := p.svc.DroneDeliver(ctx, claims.Subject, orderID)
+	order, err := p.svc.DroneDeliver(ctx, claims.Subject, orderID, key)
 	if err != nil {
 		return p.writeException(ctx, out, "DeliverOrder", seqID, mapError(err))
 	}
@@ -223,7 +277,7 @@ func (p *Processor) handleDeliverOrder(ctx context.Context, seqID int32, in, out
 }
 
 func (p *Processor) handleFailOrder(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
-	authToken, orderID, reason, err := readFailOrderRequest(ctx, in)
+	authToken, orderID, reason, key, err := readFailOrderRequest(ctx, in)
 	if err != nil {
 		return p.writeException(ctx, out, "FailOrder", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
 	}
@@ -231,7 +285,7 @@ func (p *Processor) handleFailOrder(ctx context.Context, seqID int32, in, out th
 	if appErr != nil {
 		return p.writeException(ctx, out, "FailOrder", seqID, appErr)
 	}
-	order, err := p.svc.DroneFail(ctx, claims.Subject, orderID, reason)
+	order, err := p.svc.DroneFail(ctx, claims.Subject, orderID, reason, key)
 	if err != nil {
 		return p.writeException(ctx, out, "FailOrder", seqID, mapError(err))
 	}
@@ -244,7 +298,7 @@ func (p *Processor) handleFailOrder(ctx context.Context, seqID int32, in, out th
 }
 
 func (p *Processor) handleMarkBroken(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
-	authToken, err := readAuthRequest(ctx, in)
+	authToken, key, err := readIdempotentAuthRequest(ctx, in)
 	if err != nil {
 		return p.writeException(ctx, out, "MarkBroken", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
 	}
@@ -252,7 +306,7 @@ func (p *Processor) handleMarkBroken(ctx context.Context, seqID int32, in, out t
 	if appErr != nil {
 		return p.writeException(ctx, out, "MarkBroken", seqID, appErr)
 	}
-	drone, err := p.svc.DroneMarkBroken(ctx, claims.Subject)
+	drone, err := p.svc.DroneMarkBroken(ctx, claims.Subject, key)
 	if err != nil {
 		return p.writeException(ctx, out, "MarkBroken", seqID, mapError(err))
 	}
@@ -264,8 +318,29 @@ func (p *Processor) handleMarkBroken(ctx context.Context, seqID int32, in, out t
 	})
 }
 
+func (p *Processor) handleSetStatus(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, status, err := readDroneStatusRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "SetStatus", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	claims, appErr := p.authorize(authToken, domain.RoleDrone)
+	if appErr != nil {
+		return p.writeException(ctx, out, "SetStatus", seqID, appErr)
+	}
+	drone, err := p.svc.DroneSetStatus(ctx, claims.Subject, domain.DroneStatus(status))
+	if err != nil {
+		return p.writeException(ctx, out, "SetStatus", seqID, mapError(err))
+	}
+	return p.writeReply(ctx, out, "SetStatus", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
+			return err
+		}
+		return writeDrone(ctx, out, drone)
+	})
+}
+
 func (p *Processor) handleHeartbeat(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
-	authToken, loc, err := readHeartbeatRequest(ctx, in)
+	authToken, loc, vitals, err := readHeartbeatRequest(ctx, in)
 	if err != nil {
 		return p.writeException(ctx, out, "Heartbeat", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
 	}
@@ -273,7 +348,7 @@ func (p *Processor) handleHeartbeat(ctx context.Context, seqID int32, in, out th
 	if appErr != nil {
 		return p.writeException(ctx, out, "Heartbeat", seqID, appErr)
 	}
-	view, err := p.svc.DroneHeartbeat(ctx, claims.Subject, loc)
+	view, err := p.svc.DroneHeartbeat(ctx, claims.Subject, loc, vitals)
 	if err != nil {
 		return p.writeException(ctx, out, "Heartbeat", seqID, mapError(err))
 	}
@@ -306,20 +381,57 @@ func (p *Processor) handleCurrentOrder(ctx context.Context, seqID int32, in, out
 	})
 }
 
+func (p *Processor) handleReserveChargingSlot(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, stationID, _, err := readVersionedIDRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "ReserveChargingSlot", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	claims, appErr := p.authorize(authToken, domain.RoleDrone)
+	if appErr != nil {
+		return p.writeException(ctx, out, "ReserveChargingSlot", seqID, appErr)
+	}
+	slot, err := p.svc.DroneReserveChargingSlot(ctx, claims.Subject, stationID)
+	if err != nil {
+		return p.writeException(ctx, out, "ReserveChargingSlot", seqID, mapError(err))
+	}
+	return p.writeReply(ctx, out, "ReserveChargingSlot", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
+			return err
+		}
+		return writeChargingSlot(ctx, out, slot)
+	})
+}
+
+func (p *Processor) handleReleaseChargingSlot(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, err := readAuthRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "ReleaseChargingSlot", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	claims, appErr := p.authorize(authToken, domain.RoleDrone)
+	if appErr != nil {
+		return p.writeException(ctx, out, "ReleaseChargingSlot", seqID, appErr)
+	}
+	slot, err := p.svc.DroneReleaseChargingSlot(ctx, claims.Subject)
+	if err != nil {
+		return p.writeException(ctx, out, "ReleaseChargingSlot", seqID, mapError(err))
+	}
+	return p.writeReply(ctx, out, "ReleaseChargingSlot", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
+			return err
+		}
+		return writeChargingSlot(ctx, out, slot)
+	})
+}
+
 func (p *Processor) handleAdminListOrders(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
-	authToken, status, limit, offset, err := readListOrdersRequest(ctx, in)
+	authToken, filter, err := readListOrdersRequest(ctx, in)
 	if err != nil {
 		return p.writeException(ctx, out, "ListOrders", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
 	}
 	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
 		return p.writeException(ctx, out, "ListOrders", seqID, appErr)
 	}
-	var st *domain.OrderStatus
-	if status != "" {
-		statusVal := domain.OrderStatus(status)
-		st = &statusVal
-	}
-	views, err := p.svc.AdminListOrders(ctx, service.OrderFilter{Status: st, Limit: limit, Offset: offset})
+	page, err := p.svc.AdminListOrders(ctx, filter)
 	if err != nil {
 		return p.writeException(ctx, out, "ListOrders", seqID, mapError(err))
 	}
@@ -327,19 +439,59 @@ func (p *Processor) handleAdminListOrders(ctx context.Context, seqID int32, in,
 		if err := out.WriteFieldBegin(ctx, "success", thrift.LIST, 0); err != nil {
 			return err
 		}
-		return writeOrderViewList(ctx, out, views)
+		return writeOrderViewList(ctx, out, page.Orders)
+	})
+}
+
+func (p *Processor) handleAdminListOrdersPage(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, filter, err := readListOrdersRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "ListOrdersPage", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
+		return p.writeException(ctx, out, "ListOrdersPage", seqID, appErr)
+	}
+	page, err := p.svc.AdminListOrders(ctx, filter)
+	if err != nil {
+		return p.writeException(ctx, out, "ListOrdersPage", seqID, mapError(err))
+	}
+	return p.writeReply(ctx, out, "ListOrdersPage", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
+			return err
+		}
+		return writeOrderPage(ctx, out, page)
+	})
+}
+
+func (p *Processor) handleAdminOrdersNear(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, query, err := readOrdersNearRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "OrdersNear", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
+		return p.writeException(ctx, out, "OrdersNear", seqID, appErr)
+	}
+	views, err := p.svc.AdminOrdersNear(ctx, query)
+	if err != nil {
+		return p.writeException(ctx, out, "OrdersNear", seqID, mapError(err))
+	}
+	return p.writeReply(ctx, out, "OrdersNear", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
+			return err
+		}
+		return writeOrderPage(ctx, out, &service.OrderPage{Orders: views})
 	})
 }
 
 func (p *Processor) handleAdminUpdateOrder(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
-	authToken, orderID, origin, dest, err := readUpdateOrderRequest(ctx, in)
+	authToken, orderID, origin, dest, expectedVersion, err := readUpdateOrderRequest(ctx, in)
 	if err != nil {
 		return p.writeException(ctx, out, "UpdateOrder", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
 	}
 	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
 		return p.writeException(ctx, out, "UpdateOrder", seqID, appErr)
 	}
-	order, err := p.svc.AdminUpdateOrder(ctx, orderID, origin, dest)
+	order, err := p.svc.AdminUpdateOrder(ctx, orderID, origin, dest, expectedVersion)
 	if err != nil {
 		return p.writeException(ctx, out, "UpdateOrder", seqID, mapError(err))
 	}
@@ -351,6 +503,87 @@ func (p *Processor) handleAdminUpdateOrder(ctx context.Context, seqID int32, in,
 	})
 }
 
+func (p *Processor) handleAdminAssignOrder(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, orderID, droneID, expectedVersion, err := readAssignOrderRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "AssignOrder", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
+		return p.writeException(ctx, out, "AssignOrder", seqID, appErr)
+	}
+	order, err := p.svc.AdminAssignOrder(ctx, orderID, droneID, expectedVersion)
+	if err != nil {
+		return p.writeException(ctx, out, "AssignOrder", seqID, mapError(err))
+	}
+	return p.writeReply(ctx, out, "AssignOrder", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
+			return err
+		}
+		return writeOrder(ctx, out, order)
+	})
+}
+
+func (p *Processor) handleAdminUnassignOrder(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, orderID, expectedVersion, err := readVersionedIDRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "UnassignOrder", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
+		return p.writeException(ctx, out, "UnassignOrder", seqID, appErr)
+	}
+	order, err := p.svc.AdminUnassignOrder(ctx, orderID, expectedVersion)
+	if err != nil {
+		return p.writeException(ctx, out, "UnassignOrder", seqID, mapError(err))
+	}
+	return p.writeReply(ctx, out, "UnassignOrder", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
+			return err
+		}
+		return writeOrder(ctx, out, order)
+	})
+}
+
+func (p *Processor) handleAdminReassignOrder(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, orderID, droneID, expectedVersion, err := readAssignOrderRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "ReassignOrder", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
+		return p.writeException(ctx, out, "ReassignOrder", seqID, appErr)
+	}
+	order, err := p.svc.AdminReassignOrder(ctx, orderID, droneID, expectedVersion)
+	if err != nil {
+		return p.writeException(ctx, out, "ReassignOrder", seqID, mapError(err))
+	}
+	return p.writeReply(ctx, out, "ReassignOrder", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
+			return err
+		}
+		return writeOrder(ctx, out, order)
+	})
+}
+
+func (p *Processor) handleAdminOverrideOrder(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, orderID, status, reason, expectedVersion, err := readOverrideOrderRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "OverrideOrder", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	claims, appErr := p.authorize(authToken, domain.RoleAdmin)
+	if appErr != nil {
+		return p.writeException(ctx, out, "OverrideOrder", seqID, appErr)
+	}
+	order, err := p.svc.AdminForceTransition(ctx, claims.Subject, orderID, domain.OrderStatus(status), reason, expectedVersion)
+	if err != nil {
+		return p.writeException(ctx, out, "OverrideOrder", seqID, mapError(err))
+	}
+	return p.writeReply(ctx, out, "OverrideOrder", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
+			return err
+		}
+		return writeOrder(ctx, out, order)
+	})
+}
+
 func (p *Processor) handleAdminListDrones(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
 	authToken, err := readAuthRequest(ctx, in)
 	if err != nil {
@@ -371,15 +604,35 @@ func (p *Processor) handleAdminListDrones(ctx context.Context, seqID int32, in,
 	})
 }
 
+func (p *Processor) handleAdminNearestIdleDrones(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, point, limit, err := readNearestDronesRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "NearestIdleDrones", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
+		return p.writeException(ctx, out, "NearestIdleDrones", seqID, appErr)
+	}
+	drones, err := p.svc.AdminNearestIdleDrones(ctx, point, limit)
+	if err != nil {
+		return p.writeException(ctx, out, "NearestIdleDrones", seqID, mapError(err))
+	}
+	return p.writeReply(ctx, out, "NearestIdleDrones", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.LIST, 0); err != nil {
+			return err
+		}
+		return writeDroneList(ctx, out, drones)
+	})
+}
+
 func (p *Processor) handleAdminMarkDroneBroken(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
-	authToken, droneID, err := readDroneIDRequest(ctx, in)
+	authToken, droneID, expectedVersion, err := readDroneIDRequest(ctx, in)
 	if err != nil {
 		return p.writeException(ctx, out, "MarkDroneBroken", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
 	}
 	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
 		return p.writeException(ctx, out, "MarkDroneBroken", seqID, appErr)
 	}
-	drone, err := p.svc.AdminMarkDroneBroken(ctx, droneID)
+	drone, err := p.svc.AdminMarkDroneBroken(ctx, droneID, expectedVersion)
 	if err != nil {
 		return p.writeException(ctx, out, "MarkDroneBroken", seqID, mapError(err))
 	}
@@ -392,14 +645,14 @@ func (p *Processor) handleAdminMarkDroneBroken(ctx context.Context, seqID int32,
 }
 
 func (p *Processor) handleAdminMarkDroneFixed(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
-	authToken, droneID, err := readDroneIDRequest(ctx, in)
+	authToken, droneID, report, expectedVersion, err := readFixDroneRequest(ctx, in)
 	if err != nil {
 		return p.writeException(ctx, out, "MarkDroneFixed", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
 	}
 	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
 		return p.writeException(ctx, out, "MarkDroneFixed", seqID, appErr)
 	}
-	drone, err := p.svc.AdminMarkDroneFixed(ctx, droneID)
+	drone, err := p.svc.AdminMarkDroneFixed(ctx, droneID, report, expectedVersion)
 	if err != nil {
 		return p.writeException(ctx, out, "MarkDroneFixed", seqID, mapError(err))
 	}
@@ -411,99 +664,1450 @@ func (p *Processor) handleAdminMarkDroneFixed(ctx context.Context, seqID int32,
 	})
 }
 
-func (p *Processor) authorize(token, role string) (*auth.Claims, thrift.TApplicationException) {
-	claims, err := p.auth.ParseToken(token)
+func (p *Processor) handleAdminSetDroneStatus(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, droneID, status, expectedVersion, err := readAdminDroneStatusRequest(ctx, in)
 	if err != nil {
-		return nil, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, "unauthorized")
+		return p.writeException(ctx, out, "SetDroneStatus", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
 	}
-	if claims.Role != role {
-		return nil, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, "forbidden")
+	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
+		return p.writeException(ctx, out, "SetDroneStatus", seqID, appErr)
 	}
-	return claims, nil
-}
-
-func (p *Processor) authorizeAny(token string) (*auth.Claims, thrift.TApplicationException) {
-	claims, err := p.auth.ParseToken(token)
+	drone, err := p.svc.AdminSetDroneStatus(ctx, droneID, domain.DroneStatus(status), expectedVersion)
 	if err != nil {
-		return nil, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, "unauthorized")
+		return p.writeException(ctx, out, "SetDroneStatus", seqID, mapError(err))
 	}
-	return claims, nil
+	return p.writeReply(ctx, out, "SetDroneStatus", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
+			return err
+		}
+		return writeDrone(ctx, out, drone)
+	})
 }
 
-func (p *Processor) writeReply(ctx context.Context, out thrift.TProtocol, method string, seqID int32, writeSuccess func(out thrift.TProtocol) error) (bool, thrift.TException) {
-	if err := out.WriteMessageBegin(ctx, method, thrift.REPLY, seqID); err != nil {
-		return false, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-	}
-	if err := out.WriteStructBegin(ctx, method+"_result"); err != nil {
-		return false, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-	}
-	if err := writeSuccess(out); err != nil {
-		return false, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+func (p *Processor) handleAdminRetireDrone(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, droneID, expectedVersion, err := readDroneIDRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "RetireDrone", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
 	}
-	if err := out.WriteFieldEnd(ctx); err != nil {
-		return false, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
+		return p.writeException(ctx, out, "RetireDrone", seqID, appErr)
 	}
-	if err := out.WriteFieldStop(ctx); err != nil {
-		return false, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+	drone, err := p.svc.AdminRetireDrone(ctx, droneID, expectedVersion)
+	if err != nil {
+		return p.writeException(ctx, out, "RetireDrone", seqID, mapError(err))
 	}
-	if err := out.WriteStructEnd(ctx); err != nil {
-		return false, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+	return p.writeReply(ctx, out, "RetireDrone", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
+			return err
+		}
+		return writeDrone(ctx, out, drone)
+	})
+}
+
+func (p *Processor) handleAdminListServiceAreas(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, err := readAuthRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "ListServiceAreas", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
 	}
-	if err := out.WriteMessageEnd(ctx); err != nil {
-		return false, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
+		return p.writeException(ctx, out, "ListServiceAreas", seqID, appErr)
 	}
-	if err := out.Flush(ctx); err != nil {
-		return false, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+	areas, err := p.svc.AdminListServiceAreas(ctx)
+	if err != nil {
+		return p.writeException(ctx, out, "ListServiceAreas", seqID, mapError(err))
 	}
-	return true, nil
-}
-
-func (p *Processor) writeException(ctx context.Context, out thrift.TProtocol, method string, seqID int32, appErr thrift.TApplicationException) (bool, thrift.TException) {
-	_ = out.WriteMessageBegin(ctx, method, thrift.EXCEPTION, seqID)
-	_ = appErr.Write(ctx, out)
-	_ = out.WriteMessageEnd(ctx)
-	_ = out.Flush(ctx)
-	return false, appErr
+	return p.writeReply(ctx, out, "ListServiceAreas", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.LIST, 0); err != nil {
+			return err
+		}
+		if err := out.WriteListBegin(ctx, thrift.STRUCT, len(areas)); err != nil {
+			return err
+		}
+		for _, area := range areas {
+			if err := writeServiceArea(ctx, out, area); err != nil {
+				return err
+			}
+		}
+		return out.WriteListEnd(ctx)
+	})
 }
 
-func mapError(err error) thrift.TApplicationException {
-	switch {
-	case errors.Is(err, domain.ErrUnauthorized):
-		return thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, "unauthorized")
-	case errors.Is(err, domain.ErrForbidden):
-		return thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, "forbidden")
-	case errors.Is(err, domain.ErrNotFound):
-		return thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, "not found")
-	case errors.Is(err, domain.ErrConflict):
-		return thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, "conflict")
-	case errors.Is(err, domain.ErrInvalid):
-		return thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, "invalid request")
-	case errors.Is(err, domain.ErrPrecondition):
-		return thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, "precondition failed")
-	case errors.Is(err, domain.ErrNoJob):
-		return thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, "no job")
-	default:
-		return thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "internal error")
+func (p *Processor) handleAdminGetServiceArea(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, areaID, _, err := readVersionedIDRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "GetServiceArea", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
+		return p.writeException(ctx, out, "GetServiceArea", seqID, appErr)
+	}
+	area, err := p.svc.AdminGetServiceArea(ctx, areaID)
+	if err != nil {
+		return p.writeException(ctx, out, "GetServiceArea", seqID, mapError(err))
 	}
+	return p.writeReply(ctx, out, "GetServiceArea", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
+			return err
+		}
+		return writeServiceArea(ctx, out, area)
+	})
 }
 
-func writeTokenResponse(ctx context.Context, out thrift.TProtocol, token string, exp time.Time) error {
-	if err := out.WriteStructBegin(ctx, "TokenResponse"); err != nil {
-		return err
+func (p *Processor) handleAdminCreateServiceArea(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, name, boundary, active, err := readCreateServiceAreaRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "CreateServiceArea", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
 	}
-	if err := out.WriteFieldBegin(ctx, "token", thrift.STRING, 1); err != nil {
-		return err
+	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
+		return p.writeException(ctx, out, "CreateServiceArea", seqID, appErr)
 	}
-	if err := out.WriteString(ctx, token); err != nil {
-		return err
+	area, err := p.svc.AdminCreateServiceArea(ctx, name, boundary, active)
+	if err != nil {
+		return p.writeException(ctx, out, "CreateServiceArea", seqID, mapError(err))
 	}
-	if err := out.WriteFieldEnd(ctx); err != nil {
-		return err
+	return p.writeReply(ctx, out, "CreateServiceArea", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
+			return err
+		}
+		return writeServiceArea(ctx, out, area)
+	})
+}
+
+func (p *Processor) handleAdminUpdateServiceArea(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, areaID, update, expectedVersion, err := readUpdateServiceAreaRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "UpdateServiceArea", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
+		return p.writeException(ctx, out, "UpdateServiceArea", seqID, appErr)
+	}
+	area, err := p.svc.AdminUpdateServiceArea(ctx, areaID, update, expectedVersion)
+	if err != nil {
+		return p.writeException(ctx, out, "UpdateServiceArea", seqID, mapError(err))
+	}
+	return p.writeReply(ctx, out, "UpdateServiceArea", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
+			return err
+		}
+		return writeServiceArea(ctx, out, area)
+	})
+}
+
+func (p *Processor) handleAdminListNoFlyZones(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, err := readAuthRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "ListNoFlyZones", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
+		return p.writeException(ctx, out, "ListNoFlyZones", seqID, appErr)
+	}
+	zones, err := p.svc.AdminListNoFlyZones(ctx)
+	if err != nil {
+		return p.writeException(ctx, out, "ListNoFlyZones", seqID, mapError(err))
+	}
+	return p.writeReply(ctx, out, "ListNoFlyZones", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.LIST, 0); err != nil {
+			return err
+		}
+		if err := out.WriteListBegin(ctx, thrift.STRUCT, len(zones)); err != nil {
+			return err
+		}
+		for _, zone := range zones {
+			if err := writeNoFlyZone(ctx, out, zone); err != nil {
+				return err
+			}
+		}
+		return out.WriteListEnd(ctx)
+	})
+}
+
+func (p *Processor) handleAdminGetNoFlyZone(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, zoneID, _, err := readVersionedIDRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "GetNoFlyZone", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
+		return p.writeException(ctx, out, "GetNoFlyZone", seqID, appErr)
+	}
+	zone, err := p.svc.AdminGetNoFlyZone(ctx, zoneID)
+	if err != nil {
+		return p.writeException(ctx, out, "GetNoFlyZone", seqID, mapError(err))
+	}
+	return p.writeReply(ctx, out, "GetNoFlyZone", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
+			return err
+		}
+		return writeNoFlyZone(ctx, out, zone)
+	})
+}
+
+func (p *Processor) handleAdminCreateNoFlyZone(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, name, boundary, window, err := readCreateNoFlyZoneRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "CreateNoFlyZone", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
+		return p.writeException(ctx, out, "CreateNoFlyZone", seqID, appErr)
+	}
+	zone, err := p.svc.AdminCreateNoFlyZone(ctx, name, boundary, window)
+	if err != nil {
+		return p.writeException(ctx, out, "CreateNoFlyZone", seqID, mapError(err))
+	}
+	return p.writeReply(ctx, out, "CreateNoFlyZone", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
+			return err
+		}
+		return writeNoFlyZone(ctx, out, zone)
+	})
+}
+
+func (p *Processor) handleAdminUpdateNoFlyZone(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, zoneID, update, expectedVersion, err := readUpdateNoFlyZoneRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "UpdateNoFlyZone", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
+		return p.writeException(ctx, out, "UpdateNoFlyZone", seqID, appErr)
+	}
+	zone, err := p.svc.AdminUpdateNoFlyZone(ctx, zoneID, update, expectedVersion)
+	if err != nil {
+		return p.writeException(ctx, out, "UpdateNoFlyZone", seqID, mapError(err))
+	}
+	return p.writeReply(ctx, out, "UpdateNoFlyZone", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
+			return err
+		}
+		return writeNoFlyZone(ctx, out, zone)
+	})
+}
+
+func (p *Processor) authorize(token, role string) (*auth.Claims, thrift.TApplicationException) {
+	claims, err := p.auth.ParseToken(token)
+	if err != nil {
+		return nil, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, "unauthorized")
+	}
+	if claims.Role != role {
+		return nil, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, "forbidden")
+	}
+	return claims, nil
+}
+
+func (p *Processor) authorizeAny(token string) (*auth.Claims, thrift.TApplicationException) {
+	claims, err := p.auth.ParseToken(token)
+	if err != nil {
+		return nil, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, "unauthorized")
+	}
+	return claims, nil
+}
+
+func (p *Processor) writeReply(ctx context.Context, out thrift.TProtocol, method string, seqID int32, writeSuccess func(out thrift.TProtocol) error) (bool, thrift.TException) {
+	if err := out.WriteMessageBegin(ctx, method, thrift.REPLY, seqID); err != nil {
+		return false, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+	}
+	if err := out.WriteStructBegin(ctx, method+"_result"); err != nil {
+		return false, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+	}
+	if err := writeSuccess(out); err != nil {
+		return false, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return false, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+	}
+	if err := out.WriteFieldStop(ctx); err != nil {
+		return false, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+	}
+	if err := out.WriteStructEnd(ctx); err != nil {
+		return false, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+	}
+	if err := out.WriteMessageEnd(ctx); err != nil {
+		return false, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+	}
+	if err := out.Flush(ctx); err != nil {
+		return false, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+	}
+	return true, nil
+}
+
+func (p *Processor) writeException(ctx context.Context, out thrift.TProtocol, method string, seqID int32, appErr thrift.TApplicationException) (bool, thrift.TException) {
+	_ = out.WriteMessageBegin(ctx, method, thrift.EXCEPTION, seqID)
+	_ = appErr.Write(ctx, out)
+	_ = out.WriteMessageEnd(ctx)
+	_ = out.Flush(ctx)
+	return false, appErr
+}
+
+func mapError(err error) thrift.TApplicationException {
+	switch {
+	case errors.Is(err, domain.ErrUnauthorized):
+		return thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, "unauthorized")
+	case errors.Is(err, domain.ErrForbidden):
+		return thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, "forbidden")
+	case errors.Is(err, domain.ErrNotFound):
+		return thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, "not found")
+	case errors.Is(err, domain.ErrConflict):
+		return thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, "conflict")
+	case errors.Is(err, domain.ErrOutsideServiceArea):
+		var outside *domain.OutsideServiceAreaError
+		if errors.As(err, &outside) {
+			return thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, outside.Error())
+		}
+		return thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, "location is outside every active service area")
+	case errors.Is(err, domain.ErrRouteBlocked):
+		var blocked *domain.RouteBlockedError
+		if errors.As(err, &blocked) {
+			return thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, blocked.Error())
+		}
+		return thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, "route crosses a no-fly zone")
+	case errors.Is(err, domain.ErrOutOfRange):
+		return thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, "no relay path within drone range")
+	case errors.Is(err, domain.ErrIdempotencyKeyReused):
+		return thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, "idempotency key reused for a different request")
+	case errors.Is(err, domain.ErrInvalid):
+		return thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, "invalid request")
+	case errors.Is(err, domain.ErrVersionMismatch):
+		return thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, "version mismatch")
+	case errors.Is(err, domain.ErrPrecondition):
+		return thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, "precondition failed")
+	case errors.Is(err, domain.ErrNoJob):
+		return thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, "no job")
+	default:
+		return thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "internal error")
+	}
+}
+
+func (p *Processor) handleAdminDroneTrack(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, droneID, rng, err := readTrackRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "DroneTrack", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
+		return p.writeException(ctx, out, "DroneTrack", seqID, appErr)
+	}
+	samples, err := p.svc.AdminDroneTrack(ctx, droneID, rng)
+	if err != nil {
+		return p.writeException(ctx, out, "DroneTrack", seqID, mapError(err))
+	}
+	return p.writeReply(ctx, out, "DroneTrack", seqID, func(out thrift.TProtocol) error {
+		return writeTelemetryList(ctx, out, samples)
+	})
+}
+
+func (p *Processor) handleAdminOrderTrack(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, orderID, rng, err := readTrackRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "OrderTrack", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
+		return p.writeException(ctx, out, "OrderTrack", seqID, appErr)
+	}
+	samples, err := p.svc.AdminOrderTrack(ctx, orderID, rng)
+	if err != nil {
+		return p.writeException(ctx, out, "OrderTrack", seqID, mapError(err))
+	}
+	return p.writeReply(ctx, out, "OrderTrack", seqID, func(out thrift.TProtocol) error {
+		return writeTelemetryList(ctx, out, samples)
+	})
+}
+
+func (p *Processor) handleAdminDroneMaintenance(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, droneID, _, err := readDroneIDRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "DroneMaintenance", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
+		return p.writeException(ctx, out, "DroneMaintenance", seqID, appErr)
+	}
+	records, err := p.svc.AdminDroneMaintenance(ctx, droneID)
+	if err != nil {
+		return p.writeException(ctx, out, "DroneMaintenance", seqID, mapError(err))
+	}
+	return p.writeReply(ctx, out, "DroneMaintenance", seqID, func(out thrift.TProtocol) error {
+		return writeMaintenanceList(ctx, out, records)
+	})
+}
+
+func (p *Processor) handleAdminSetDroneDepot(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, droneID, depotID, expectedVersion, err := readSetDroneDepotRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "SetDroneDepot", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
+		return p.writeException(ctx, out, "SetDroneDepot", seqID, appErr)
+	}
+	drone, err := p.svc.AdminSetDroneDepot(ctx, droneID, depotID, expectedVersion)
+	if err != nil {
+		return p.writeException(ctx, out, "SetDroneDepot", seqID, mapError(err))
+	}
+	return p.writeReply(ctx, out, "SetDroneDepot", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
+			return err
+		}
+		return writeDrone(ctx, out, drone)
+	})
+}
+
+func (p *Processor) handleAdminListDepots(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, err := readAuthRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "ListDepots", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
+		return p.writeException(ctx, out, "ListDepots", seqID, appErr)
+	}
+	depots, err := p.svc.AdminListDepots(ctx)
+	if err != nil {
+		return p.writeException(ctx, out, "ListDepots", seqID, mapError(err))
+	}
+	return p.writeReply(ctx, out, "ListDepots", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.LIST, 0); err != nil {
+			return err
+		}
+		if err := out.WriteListBegin(ctx, thrift.STRUCT, len(depots)); err != nil {
+			return err
+		}
+		for _, depot := range depots {
+			if err := writeDepot(ctx, out, depot); err != nil {
+				return err
+			}
+		}
+		return out.WriteListEnd(ctx)
+	})
+}
+
+func (p *Processor) handleAdminGetDepot(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, depotID, _, err := readVersionedIDRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "GetDepot", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
+		return p.writeException(ctx, out, "GetDepot", seqID, appErr)
+	}
+	depot, err := p.svc.AdminGetDepot(ctx, depotID)
+	if err != nil {
+		return p.writeException(ctx, out, "GetDepot", seqID, mapError(err))
+	}
+	return p.writeReply(ctx, out, "GetDepot", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
+			return err
+		}
+		return writeDepot(ctx, out, depot)
+	})
+}
+
+func (p *Processor) handleAdminCreateDepot(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, name, location, capacity, err := readCreateDepotRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "CreateDepot", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
+		return p.writeException(ctx, out, "CreateDepot", seqID, appErr)
+	}
+	depot, err := p.svc.AdminCreateDepot(ctx, name, location, capacity)
+	if err != nil {
+		return p.writeException(ctx, out, "CreateDepot", seqID, mapError(err))
+	}
+	return p.writeReply(ctx, out, "CreateDepot", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
+			return err
+		}
+		return writeDepot(ctx, out, depot)
+	})
+}
+
+func (p *Processor) handleAdminUpdateDepot(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, depotID, update, expectedVersion, err := readUpdateDepotRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "UpdateDepot", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
+		return p.writeException(ctx, out, "UpdateDepot", seqID, appErr)
+	}
+	depot, err := p.svc.AdminUpdateDepot(ctx, depotID, update, expectedVersion)
+	if err != nil {
+		return p.writeException(ctx, out, "UpdateDepot", seqID, mapError(err))
+	}
+	return p.writeReply(ctx, out, "UpdateDepot", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
+			return err
+		}
+		return writeDepot(ctx, out, depot)
+	})
+}
+
+func (p *Processor) handleAdminListChargingStations(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, err := readAuthRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "ListChargingStations", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
+		return p.writeException(ctx, out, "ListChargingStations", seqID, appErr)
+	}
+	views, err := p.svc.AdminListChargingStations(ctx)
+	if err != nil {
+		return p.writeException(ctx, out, "ListChargingStations", seqID, mapError(err))
+	}
+	return p.writeReply(ctx, out, "ListChargingStations", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.LIST, 0); err != nil {
+			return err
+		}
+		if err := out.WriteListBegin(ctx, thrift.STRUCT, len(views)); err != nil {
+			return err
+		}
+		for _, view := range views {
+			if err := writeChargingStation(ctx, out, view); err != nil {
+				return err
+			}
+		}
+		return out.WriteListEnd(ctx)
+	})
+}
+
+func (p *Processor) handleAdminGetChargingStation(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, stationID, _, err := readVersionedIDRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "GetChargingStation", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
+		return p.writeException(ctx, out, "GetChargingStation", seqID, appErr)
+	}
+	view, err := p.svc.AdminGetChargingStation(ctx, stationID)
+	if err != nil {
+		return p.writeException(ctx, out, "GetChargingStation", seqID, mapError(err))
+	}
+	return p.writeReply(ctx, out, "GetChargingStation", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
+			return err
+		}
+		return writeChargingStation(ctx, out, view)
+	})
+}
+
+func (p *Processor) handleAdminCreateChargingStation(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, name, location, slots, err := readCreateChargingStationRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "CreateChargingStation", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
+		return p.writeException(ctx, out, "CreateChargingStation", seqID, appErr)
+	}
+	view, err := p.svc.AdminCreateChargingStation(ctx, name, location, slots)
+	if err != nil {
+		return p.writeException(ctx, out, "CreateChargingStation", seqID, mapError(err))
+	}
+	return p.writeReply(ctx, out, "CreateChargingStation", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
+			return err
+		}
+		return writeChargingStation(ctx, out, view)
+	})
+}
+
+func (p *Processor) handleAdminUpdateChargingStation(ctx context.Context, seqID int32, in, out thrift.TProtocol) (bool, thrift.TException) {
+	authToken, stationID, update, expectedVersion, err := readUpdateChargingStationRequest(ctx, in)
+	if err != nil {
+		return p.writeException(ctx, out, "UpdateChargingStation", seqID, thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error()))
+	}
+	if _, appErr := p.authorize(authToken, domain.RoleAdmin); appErr != nil {
+		return p.writeException(ctx, out, "UpdateChargingStation", seqID, appErr)
+	}
+	view, err := p.svc.AdminUpdateChargingStation(ctx, stationID, update, expectedVersion)
+	if err != nil {
+		return p.writeException(ctx, out, "UpdateChargingStation", seqID, mapError(err))
+	}
+	return p.writeReply(ctx, out, "UpdateChargingStation", seqID, func(out thrift.TProtocol) error {
+		if err := out.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
+			return err
+		}
+		return writeChargingStation(ctx, out, view)
+	})
+}
+
+func writeTokenResponse(ctx context.Context, out thrift.TProtocol, token string, exp time.Time) error {
+	if err := out.WriteStructBegin(ctx, "TokenResponse"); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "token", thrift.STRING, 1); err != nil {
+		return err
+	}
+	if err := out.WriteString(ctx, token); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "expiresAt", thrift.I64, 2); err != nil {
+		return err
+	}
+	if err := out.WriteI64(ctx, exp.Unix()); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldStop(ctx); err != nil {
+		return err
+	}
+	return out.WriteStructEnd(ctx)
+}
+
+func writeOrder(ctx context.Context, out thrift.TProtocol, order *domain.Order) error {
+	if err := out.WriteStructBegin(ctx, "Order"); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "id", thrift.STRING, 1); err != nil {
+		return err
+	}
+	if err := out.WriteString(ctx, order.ID); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "userId", thrift.STRING, 2); err != nil {
+		return err
+	}
+	if err := out.WriteString(ctx, order.UserID); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "origin", thrift.STRUCT, 3); err != nil {
+		return err
+	}
+	if err := writeLocation(ctx, out, order.Origin); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "destination", thrift.STRUCT, 4); err != nil {
+		return err
+	}
+	if err := writeLocation(ctx, out, order.Destination); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "status", thrift.STRING, 5); err != nil {
+		return err
+	}
+	if err := out.WriteString(ctx, string(order.Status)); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if order.AssignedDroneID != nil {
+		if err := out.WriteFieldBegin(ctx, "assignedDroneId", thrift.STRING, 6); err != nil {
+			return err
+		}
+		if err := out.WriteString(ctx, *order.AssignedDroneID); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if order.HandoffOrigin != nil {
+		if err := out.WriteFieldBegin(ctx, "handoffOrigin", thrift.STRUCT, 7); err != nil {
+			return err
+		}
+		if err := writeLocation(ctx, out, *order.HandoffOrigin); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if err := out.WriteFieldBegin(ctx, "createdAt", thrift.I64, 8); err != nil {
+		return err
+	}
+	if err := out.WriteI64(ctx, order.CreatedAt.Unix()); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "updatedAt", thrift.I64, 9); err != nil {
+		return err
+	}
+	if err := out.WriteI64(ctx, order.UpdatedAt.Unix()); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if order.ReservedAt != nil {
+		if err := out.WriteFieldBegin(ctx, "reservedAt", thrift.I64, 10); err != nil {
+			return err
+		}
+		if err := out.WriteI64(ctx, order.ReservedAt.Unix()); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if order.PickedUpAt != nil {
+		if err := out.WriteFieldBegin(ctx, "pickedUpAt", thrift.I64, 11); err != nil {
+			return err
+		}
+		if err := out.WriteI64(ctx, order.PickedUpAt.Unix()); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if order.DeliveredAt != nil {
+		if err := out.WriteFieldBegin(ctx, "deliveredAt", thrift.I64, 12); err != nil {
+			return err
+		}
+		if err := out.WriteI64(ctx, order.DeliveredAt.Unix()); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if order.FailedAt != nil {
+		if err := out.WriteFieldBegin(ctx, "failedAt", thrift.I64, 13); err != nil {
+			return err
+		}
+		if err := out.WriteI64(ctx, order.FailedAt.Unix()); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if order.FailureReason != nil {
+		if err := out.WriteFieldBegin(ctx, "failureReason", thrift.STRING, 14); err != nil {
+			return err
+		}
+		if err := out.WriteString(ctx, *order.FailureReason); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if err := out.WriteFieldBegin(ctx, "version", thrift.I64, 15); err != nil {
+		return err
+	}
+	if err := out.WriteI64(ctx, order.Version); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if order.Route != nil {
+		if err := out.WriteFieldBegin(ctx, "route", thrift.LIST, 16); err != nil {
+			return err
+		}
+		if err := out.WriteListBegin(ctx, thrift.STRUCT, len(order.Route)); err != nil {
+			return err
+		}
+		for _, loc := range order.Route {
+			if err := writeLocation(ctx, out, loc); err != nil {
+				return err
+			}
+		}
+		if err := out.WriteListEnd(ctx); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if order.Legs != nil {
+		if err := out.WriteFieldBegin(ctx, "legs", thrift.LIST, 17); err != nil {
+			return err
+		}
+		if err := out.WriteListBegin(ctx, thrift.STRUCT, len(order.Legs)); err != nil {
+			return err
+		}
+		for _, leg := range order.Legs {
+			if err := writeRelayLeg(ctx, out, leg); err != nil {
+				return err
+			}
+		}
+		if err := out.WriteListEnd(ctx); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if cur := order.CurrentLeg(); cur >= 0 {
+		if err := out.WriteFieldBegin(ctx, "currentLeg", thrift.I32, 18); err != nil {
+			return err
+		}
+		if err := out.WriteI32(ctx, int32(cur)); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	for _, field := range []struct {
+		name string
+		id   int16
+		t    *time.Time
+	}{
+		{"pickupNotBefore", 19, order.PickupNotBefore},
+		{"deliverBy", 20, order.DeliverBy},
+		{"slaAtRiskAt", 21, order.SLAAtRiskAt},
+	} {
+		if field.t == nil {
+			continue
+		}
+		if err := out.WriteFieldBegin(ctx, field.name, thrift.I64, field.id); err != nil {
+			return err
+		}
+		if err := out.WriteI64(ctx, field.t.Unix()); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	return out.WriteStructEnd(ctx)
+}
+
+func writeRelayLeg(ctx context.Context, out thrift.TProtocol, leg domain.RelayLeg) error {
+	if err := out.WriteStructBegin(ctx, "RelayLeg"); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "to", thrift.STRUCT, 1); err != nil {
+		return err
+	}
+	if err := writeLocation(ctx, out, leg.To); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if leg.RelayPointID != "" {
+		if err := out.WriteFieldBegin(ctx, "relayPointId", thrift.STRING, 2); err != nil {
+			return err
+		}
+		if err := out.WriteString(ctx, leg.RelayPointID); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if leg.DroneID != nil {
+		if err := out.WriteFieldBegin(ctx, "droneId", thrift.STRING, 3); err != nil {
+			return err
+		}
+		if err := out.WriteString(ctx, *leg.DroneID); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if leg.CompletedAt != nil {
+		if err := out.WriteFieldBegin(ctx, "completedAt", thrift.I64, 4); err != nil {
+			return err
+		}
+		if err := out.WriteI64(ctx, leg.CompletedAt.Unix()); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if err := out.WriteFieldStop(ctx); err != nil {
+		return err
+	}
+	return out.WriteStructEnd(ctx)
+}
+
+func writeOrderView(ctx context.Context, out thrift.TProtocol, view *service.OrderView) error {
+	if err := out.WriteStructBegin(ctx, "OrderView"); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "order", thrift.STRUCT, 1); err != nil {
+		return err
+	}
+	if err := writeOrder(ctx, out, view.Order); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if view.CurrentLocation != nil {
+		if err := out.WriteFieldBegin(ctx, "currentLocation", thrift.STRUCT, 2); err != nil {
+			return err
+		}
+		if err := writeLocation(ctx, out, *view.CurrentLocation); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if view.ETASeconds != nil {
+		if err := out.WriteFieldBegin(ctx, "etaSeconds", thrift.I64, 3); err != nil {
+			return err
+		}
+		if err := out.WriteI64(ctx, *view.ETASeconds); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if view.ETALegs != nil {
+		if err := out.WriteFieldBegin(ctx, "etaLegs", thrift.LIST, 4); err != nil {
+			return err
+		}
+		if err := out.WriteListBegin(ctx, thrift.STRUCT, len(view.ETALegs)); err != nil {
+			return err
+		}
+		for _, leg := range view.ETALegs {
+			if err := writeETALeg(ctx, out, leg); err != nil {
+				return err
+			}
+		}
+		if err := out.WriteListEnd(ctx); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if view.ETASeconds != nil {
+		if err := out.WriteFieldBegin(ctx, "etaSource", thrift.STRING, 5); err != nil {
+			return err
+		}
+		if err := out.WriteString(ctx, string(view.ETASource)); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+		if err := out.WriteFieldBegin(ctx, "etaConfidence", thrift.DOUBLE, 6); err != nil {
+			return err
+		}
+		if err := out.WriteDouble(ctx, view.ETAConfidence); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	return out.WriteStructEnd(ctx)
+}
+
+func writeETALeg(ctx context.Context, out thrift.TProtocol, leg service.ETALeg) error {
+	if err := out.WriteStructBegin(ctx, "ETALeg"); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "kind", thrift.STRING, 1); err != nil {
+		return err
+	}
+	if err := out.WriteString(ctx, string(leg.Kind)); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "distanceMeters", thrift.DOUBLE, 2); err != nil {
+		return err
+	}
+	if err := out.WriteDouble(ctx, leg.DistanceMeters); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "seconds", thrift.I64, 3); err != nil {
+		return err
+	}
+	if err := out.WriteI64(ctx, leg.Seconds); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	return out.WriteStructEnd(ctx)
+}
+
+func writeDrone(ctx context.Context, out thrift.TProtocol, drone *domain.Drone) error {
+	if err := out.WriteStructBegin(ctx, "Drone"); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "id", thrift.STRING, 1); err != nil {
+		return err
+	}
+	if err := out.WriteString(ctx, drone.ID); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "status", thrift.STRING, 2); err != nil {
+		return err
+	}
+	if err := out.WriteString(ctx, string(drone.Status)); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if drone.LastLocation != nil {
+		if err := out.WriteFieldBegin(ctx, "lastLocation", thrift.STRUCT, 3); err != nil {
+			return err
+		}
+		if err := writeLocation(ctx, out, *drone.LastLocation); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if drone.LastHeartbeatAt != nil {
+		if err := out.WriteFieldBegin(ctx, "lastHeartbeatAt", thrift.I64, 4); err != nil {
+			return err
+		}
+		if err := out.WriteI64(ctx, drone.LastHeartbeatAt.Unix()); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if drone.CurrentOrderID != nil {
+		if err := out.WriteFieldBegin(ctx, "currentOrderId", thrift.STRING, 5); err != nil {
+			return err
+		}
+		if err := out.WriteString(ctx, *drone.CurrentOrderID); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if err := out.WriteFieldBegin(ctx, "createdAt", thrift.I64, 6); err != nil {
+		return err
+	}
+	if err := out.WriteI64(ctx, drone.CreatedAt.Unix()); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "updatedAt", thrift.I64, 7); err != nil {
+		return err
+	}
+	if err := out.WriteI64(ctx, drone.UpdatedAt.Unix()); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "version", thrift.I64, 8); err != nil {
+		return err
+	}
+	if err := out.WriteI64(ctx, drone.Version); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := writeVitals(ctx, out, drone.Vitals); err != nil {
+		return err
+	}
+	if err := writeFlightUsage(ctx, out, drone); err != nil {
+		return err
+	}
+	if drone.HomeDepotID != nil {
+		if err := out.WriteFieldBegin(ctx, "homeDepotId", thrift.STRING, 18); err != nil {
+			return err
+		}
+		if err := out.WriteString(ctx, *drone.HomeDepotID); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if drone.Stops != nil {
+		if err := out.WriteFieldBegin(ctx, "stops", thrift.LIST, 19); err != nil {
+			return err
+		}
+		if err := out.WriteListBegin(ctx, thrift.STRUCT, len(drone.Stops)); err != nil {
+			return err
+		}
+		for _, stop := range drone.Stops {
+			if err := writeStop(ctx, out, stop); err != nil {
+				return err
+			}
+		}
+		if err := out.WriteListEnd(ctx); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	return out.WriteStructEnd(ctx)
+}
+
+func writeStop(ctx context.Context, out thrift.TProtocol, stop domain.Stop) error {
+	if err := out.WriteStructBegin(ctx, "Stop"); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "orderId", thrift.STRING, 1); err != nil {
+		return err
+	}
+	if err := out.WriteString(ctx, stop.OrderID); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "kind", thrift.STRING, 2); err != nil {
+		return err
+	}
+	if err := out.WriteString(ctx, string(stop.Kind)); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "location", thrift.STRUCT, 3); err != nil {
+		return err
+	}
+	if err := writeLocation(ctx, out, stop.Location); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if stop.CompletedAt != nil {
+		if err := out.WriteFieldBegin(ctx, "completedAt", thrift.I64, 4); err != nil {
+			return err
+		}
+		if err := out.WriteI64(ctx, stop.CompletedAt.Unix()); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if err := out.WriteFieldStop(ctx); err != nil {
+		return err
+	}
+	return out.WriteStructEnd(ctx)
+}
+
+// writeFlightUsage writes the drone's usage as Drone fields 14-17.
+func writeFlightUsage(ctx context.Context, out thrift.TProtocol, drone *domain.Drone) error {
+	usage := []struct {
+		name  string
+		id    int16
+		value float64
+	}{
+		{"flightSeconds", 14, drone.Usage.Seconds},
+		{"flightMeters", 15, drone.Usage.Meters},
+		{"sinceServiceSeconds", 16, drone.SinceService.Seconds},
+		{"sinceServiceMeters", 17, drone.SinceService.Meters},
+	}
+	for _, u := range usage {
+		if err := out.WriteFieldBegin(ctx, u.name, thrift.DOUBLE, u.id); err != nil {
+			return err
+		}
+		if err := out.WriteDouble(ctx, u.value); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeVitals writes the reported readings as Drone fields 9-13.
+func writeVitals(ctx context.Context, out thrift.TProtocol, vitals domain.Vitals) error {
+	readings := []struct {
+		name  string
+		id    int16
+		value *float64
+	}{
+		{"altitudeMeters", 9, vitals.AltitudeMeters},
+		{"headingDegrees", 10, vitals.HeadingDegrees},
+		{"groundSpeedMps", 11, vitals.GroundSpeedMPS},
+		{"batteryPercent", 12, vitals.BatteryPercent},
+	}
+	for _, r := range readings {
+		if r.value == nil {
+			continue
+		}
+		if err := out.WriteFieldBegin(ctx, r.name, thrift.DOUBLE, r.id); err != nil {
+			return err
+		}
+		if err := out.WriteDouble(ctx, *r.value); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if len(vitals.FaultCodes) == 0 {
+		return nil
+	}
+	if err := out.WriteFieldBegin(ctx, "faultCodes", thrift.LIST, 13); err != nil {
+		return err
+	}
+	if err := out.WriteListBegin(ctx, thrift.STRING, len(vitals.FaultCodes)); err != nil {
+		return err
+	}
+	for _, code := range vitals.FaultCodes {
+		if err := out.WriteString(ctx, code); err != nil {
+			return err
+		}
+	}
+	if err := out.WriteListEnd(ctx); err != nil {
+		return err
+	}
+	return out.WriteFieldEnd(ctx)
+}
+
+func writeDroneStatus(ctx context.Context, out thrift.TProtocol, view *service.DroneStatusView) error {
+	if err := out.WriteStructBegin(ctx, "DroneStatus"); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "drone", thrift.STRUCT, 1); err != nil {
+		return err
+	}
+	if err := writeDrone(ctx, out, view.Drone); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if view.CurrentOrder != nil {
+		if err := out.WriteFieldBegin(ctx, "currentOrder", thrift.STRUCT, 2); err != nil {
+			return err
+		}
+		if err := writeOrderView(ctx, out, view.CurrentOrder); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if view.ReturnToBase != nil {
+		if err := out.WriteFieldBegin(ctx, "returnToBase", thrift.STRUCT, 3); err != nil {
+			return err
+		}
+		if err := writeReturnToBase(ctx, out, view.ReturnToBase); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if view.ChargeAt != nil {
+		if err := out.WriteFieldBegin(ctx, "chargeAt", thrift.STRUCT, 4); err != nil {
+			return err
+		}
+		if err := writeChargeAt(ctx, out, view.ChargeAt); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	return out.WriteStructEnd(ctx)
+}
+
+func writeReturnToBase(ctx context.Context, out thrift.TProtocol, rtb *service.ReturnToBase) error {
+	if err := out.WriteStructBegin(ctx, "ReturnToBase"); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "depotId", thrift.STRING, 1); err != nil {
+		return err
+	}
+	if err := out.WriteString(ctx, rtb.DepotID); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "location", thrift.STRUCT, 2); err != nil {
+		return err
+	}
+	if err := writeLocation(ctx, out, rtb.Location); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "route", thrift.LIST, 3); err != nil {
+		return err
+	}
+	if err := out.WriteListBegin(ctx, thrift.STRUCT, len(rtb.Route)); err != nil {
+		return err
+	}
+	for _, loc := range rtb.Route {
+		if err := writeLocation(ctx, out, loc); err != nil {
+			return err
+		}
+	}
+	if err := out.WriteListEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "distanceMeters", thrift.DOUBLE, 4); err != nil {
+		return err
+	}
+	if err := out.WriteDouble(ctx, rtb.DistanceMeters); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "etaSeconds", thrift.I64, 5); err != nil {
+		return err
+	}
+	if err := out.WriteI64(ctx, rtb.ETASeconds); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldStop(ctx); err != nil {
+		return err
+	}
+	return out.WriteStructEnd(ctx)
+}
+
+func writeOrderPage(ctx context.Context, out thrift.TProtocol, page *service.OrderPage) error {
+	if err := out.WriteStructBegin(ctx, "OrderPage"); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "orders", thrift.LIST, 1); err != nil {
+		return err
+	}
+	if err := writeOrderViewList(ctx, out, page.Orders); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if page.NextCursor != "" {
+		if err := out.WriteFieldBegin(ctx, "nextCursor", thrift.STRING, 2); err != nil {
+			return err
+		}
+		if err := out.WriteString(ctx, page.NextCursor); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if err := out.WriteFieldStop(ctx); err != nil {
+		return err
+	}
+	return out.WriteStructEnd(ctx)
+}
+
+func writeOrderViewList(ctx context.Context, out thrift.TProtocol, views []*service.OrderView) error {
+	if err := out.WriteListBegin(ctx, thrift.STRUCT, len(views)); err != nil {
+		return err
+	}
+	for _, view := range views {
+		if err := writeOrderView(ctx, out, view); err != nil {
+			return err
+		}
+	}
+	return out.WriteListEnd(ctx)
+}
+
+func writeDroneList(ctx context.Context, out thrift.TProtocol, drones []*domain.Drone) error {
+	if err := out.WriteListBegin(ctx, thrift.STRUCT, len(drones)); err != nil {
+		return err
+	}
+	for _, drone := range drones {
+		if err := writeDrone(ctx, out, drone); err != nil {
+			return err
+		}
+	}
+	return out.WriteListEnd(ctx)
+}
+
+func writeServiceArea(ctx context.Context, out thrift.TProtocol, area *domain.ServiceArea) error {
+	if err := out.WriteStructBegin(ctx, "ServiceArea"); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "id", thrift.STRING, 1); err != nil {
+		return err
+	}
+	if err := out.WriteString(ctx, area.ID); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "name", thrift.STRING, 2); err != nil {
+		return err
+	}
+	if err := out.WriteString(ctx, area.Name); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "boundary", thrift.LIST, 3); err != nil {
+		return err
+	}
+	if err := writePolygon(ctx, out, area.Boundary); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "active", thrift.BOOL, 4); err != nil {
+		return err
+	}
+	if err := out.WriteBool(ctx, area.Active); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "createdAt", thrift.I64, 5); err != nil {
+		return err
+	}
+	if err := out.WriteI64(ctx, area.CreatedAt.Unix()); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
 	}
-	if err := out.WriteFieldBegin(ctx, "expiresAt", thrift.I64, 2); err != nil {
+	if err := out.WriteFieldBegin(ctx, "updatedAt", thrift.I64, 6); err != nil {
 		return err
 	}
-	if err := out.WriteI64(ctx, exp.Unix()); err != nil {
+	if err := out.WriteI64(ctx, area.UpdatedAt.Unix()); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "version", thrift.I64, 7); err != nil {
+		return err
+	}
+	if err := out.WriteI64(ctx, area.Version); err != nil {
 		return err
 	}
 	if err := out.WriteFieldEnd(ctx); err != nil {
@@ -515,312 +2119,565 @@ func writeTokenResponse(ctx context.Context, out thrift.TProtocol, token string,
 	return out.WriteStructEnd(ctx)
 }
 
-func writeOrder(ctx context.Context, out thrift.TProtocol, order *domain.Order) error {
-	if err := out.WriteStructBegin(ctx, "Order"); err != nil {
+func writeDepot(ctx context.Context, out thrift.TProtocol, depot *domain.Depot) error {
+	if err := out.WriteStructBegin(ctx, "Depot"); err != nil {
 		return err
 	}
 	if err := out.WriteFieldBegin(ctx, "id", thrift.STRING, 1); err != nil {
 		return err
 	}
-	if err := out.WriteString(ctx, order.ID); err != nil {
+	if err := out.WriteString(ctx, depot.ID); err != nil {
 		return err
 	}
 	if err := out.WriteFieldEnd(ctx); err != nil {
 		return err
 	}
-	if err := out.WriteFieldBegin(ctx, "userId", thrift.STRING, 2); err != nil {
+	if err := out.WriteFieldBegin(ctx, "name", thrift.STRING, 2); err != nil {
 		return err
 	}
-	if err := out.WriteString(ctx, order.UserID); err != nil {
+	if err := out.WriteString(ctx, depot.Name); err != nil {
 		return err
 	}
 	if err := out.WriteFieldEnd(ctx); err != nil {
 		return err
 	}
-	if err := out.WriteFieldBegin(ctx, "origin", thrift.STRUCT, 3); err != nil {
+	if err := out.WriteFieldBegin(ctx, "location", thrift.STRUCT, 3); err != nil {
 		return err
 	}
-	if err := writeLocation(ctx, out, order.Origin); err != nil {
+	if err := writeLocation(ctx, out, depot.Location); err != nil {
 		return err
 	}
 	if err := out.WriteFieldEnd(ctx); err != nil {
 		return err
 	}
-	if err := out.WriteFieldBegin(ctx, "destination", thrift.STRUCT, 4); err != nil {
+	if err := out.WriteFieldBegin(ctx, "capacity", thrift.I32, 4); err != nil {
 		return err
 	}
-	if err := writeLocation(ctx, out, order.Destination); err != nil {
+	if err := out.WriteI32(ctx, int32(depot.Capacity)); err != nil {
 		return err
 	}
 	if err := out.WriteFieldEnd(ctx); err != nil {
 		return err
 	}
-	if err := out.WriteFieldBegin(ctx, "status", thrift.STRING, 5); err != nil {
+	if err := out.WriteFieldBegin(ctx, "createdAt", thrift.I64, 5); err != nil {
 		return err
 	}
-	if err := out.WriteString(ctx, string(order.Status)); err != nil {
+	if err := out.WriteI64(ctx, depot.CreatedAt.Unix()); err != nil {
 		return err
 	}
 	if err := out.WriteFieldEnd(ctx); err != nil {
 		return err
 	}
-	if order.AssignedDroneID != nil {
-		if err := out.WriteFieldBegin(ctx, "assignedDroneId", thrift.STRING, 6); err != nil {
-			return err
-		}
-		if err := out.WriteString(ctx, *order.AssignedDroneID); err != nil {
-			return err
-		}
-		if err := out.WriteFieldEnd(ctx); err != nil {
-			return err
-		}
+	if err := out.WriteFieldBegin(ctx, "updatedAt", thrift.I64, 6); err != nil {
+		return err
 	}
-	if order.HandoffOrigin != nil {
-		if err := out.WriteFieldBegin(ctx, "handoffOrigin", thrift.STRUCT, 7); err != nil {
-			return err
-		}
-		if err := writeLocation(ctx, out, *order.HandoffOrigin); err != nil {
-			return err
-		}
-		if err := out.WriteFieldEnd(ctx); err != nil {
-			return err
-		}
+	if err := out.WriteI64(ctx, depot.UpdatedAt.Unix()); err != nil {
+		return err
 	}
-	if err := out.WriteFieldBegin(ctx, "createdAt", thrift.I64, 8); err != nil {
+	if err := out.WriteFieldEnd(ctx); err != nil {
 		return err
 	}
-	if err := out.WriteI64(ctx, order.CreatedAt.Unix()); err != nil {
+	if err := out.WriteFieldBegin(ctx, "version", thrift.I64, 7); err != nil {
+		return err
+	}
+	if err := out.WriteI64(ctx, depot.Version); err != nil {
 		return err
 	}
 	if err := out.WriteFieldEnd(ctx); err != nil {
 		return err
 	}
-	if err := out.WriteFieldBegin(ctx, "updatedAt", thrift.I64, 9); err != nil {
+	if err := out.WriteFieldStop(ctx); err != nil {
 		return err
 	}
-	if err := out.WriteI64(ctx, order.UpdatedAt.Unix()); err != nil {
+	return out.WriteStructEnd(ctx)
+}
+
+func writeChargeAt(ctx context.Context, out thrift.TProtocol, charge *service.ChargingRecommendation) error {
+	if err := out.WriteStructBegin(ctx, "ChargeAt"); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "stationId", thrift.STRING, 1); err != nil {
+		return err
+	}
+	if err := out.WriteString(ctx, charge.StationID); err != nil {
 		return err
 	}
 	if err := out.WriteFieldEnd(ctx); err != nil {
 		return err
 	}
-	if order.ReservedAt != nil {
-		if err := out.WriteFieldBegin(ctx, "reservedAt", thrift.I64, 10); err != nil {
+	if err := out.WriteFieldBegin(ctx, "name", thrift.STRING, 2); err != nil {
+		return err
+	}
+	if err := out.WriteString(ctx, charge.Name); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "location", thrift.STRUCT, 3); err != nil {
+		return err
+	}
+	if err := writeLocation(ctx, out, charge.Location); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "distanceMeters", thrift.DOUBLE, 4); err != nil {
+		return err
+	}
+	if err := out.WriteDouble(ctx, charge.DistanceMeters); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "freeSlots", thrift.I32, 5); err != nil {
+		return err
+	}
+	if err := out.WriteI32(ctx, int32(charge.FreeSlots)); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldStop(ctx); err != nil {
+		return err
+	}
+	return out.WriteStructEnd(ctx)
+}
+
+// writeChargingStation writes a station with its occupancy; slotList is only
+// written when the view carries the slots.
+func writeChargingStation(ctx context.Context, out thrift.TProtocol, view *service.ChargingStationView) error {
+	station := view.Station
+	if err := out.WriteStructBegin(ctx, "ChargingStation"); err != nil {
+		return err
+	}
+	for _, f := range []struct {
+		name  string
+		id    int16
+		value string
+	}{
+		{"id", 1, station.ID},
+		{"name", 2, station.Name},
+	} {
+		if err := out.WriteFieldBegin(ctx, f.name, thrift.STRING, f.id); err != nil {
 			return err
 		}
-		if err := out.WriteI64(ctx, order.ReservedAt.Unix()); err != nil {
+		if err := out.WriteString(ctx, f.value); err != nil {
 			return err
 		}
 		if err := out.WriteFieldEnd(ctx); err != nil {
 			return err
 		}
 	}
-	if order.PickedUpAt != nil {
-		if err := out.WriteFieldBegin(ctx, "pickedUpAt", thrift.I64, 11); err != nil {
+	if err := out.WriteFieldBegin(ctx, "location", thrift.STRUCT, 3); err != nil {
+		return err
+	}
+	if err := writeLocation(ctx, out, station.Location); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	for _, f := range []struct {
+		name  string
+		id    int16
+		value int
+	}{
+		{"slots", 4, station.Slots},
+		{"occupied", 5, view.Occupied},
+	} {
+		if err := out.WriteFieldBegin(ctx, f.name, thrift.I32, f.id); err != nil {
 			return err
 		}
-		if err := out.WriteI64(ctx, order.PickedUpAt.Unix()); err != nil {
+		if err := out.WriteI32(ctx, int32(f.value)); err != nil {
 			return err
 		}
 		if err := out.WriteFieldEnd(ctx); err != nil {
 			return err
 		}
 	}
-	if order.DeliveredAt != nil {
-		if err := out.WriteFieldBegin(ctx, "deliveredAt", thrift.I64, 12); err != nil {
+	if view.Slots != nil {
+		if err := out.WriteFieldBegin(ctx, "slotList", thrift.LIST, 6); err != nil {
 			return err
 		}
-		if err := out.WriteI64(ctx, order.DeliveredAt.Unix()); err != nil {
-			return err
-		}
-		if err := out.WriteFieldEnd(ctx); err != nil {
+		if err := out.WriteListBegin(ctx, thrift.STRUCT, len(view.Slots)); err != nil {
 			return err
 		}
-	}
-	if order.FailedAt != nil {
-		if err := out.WriteFieldBegin(ctx, "failedAt", thrift.I64, 13); err != nil {
-			return err
+		for _, slot := range view.Slots {
+			if err := writeChargingSlot(ctx, out, slot); err != nil {
+				return err
+			}
 		}
-		if err := out.WriteI64(ctx, order.FailedAt.Unix()); err != nil {
+		if err := out.WriteListEnd(ctx); err != nil {
 			return err
 		}
 		if err := out.WriteFieldEnd(ctx); err != nil {
 			return err
 		}
 	}
-	if order.FailureReason != nil {
-		if err := out.WriteFieldBegin(ctx, "failureReason", thrift.STRING, 14); err != nil {
+	for _, f := range []struct {
+		name  string
+		id    int16
+		value int64
+	}{
+		{"createdAt", 7, station.CreatedAt.Unix()},
+		{"updatedAt", 8, station.UpdatedAt.Unix()},
+		{"version", 9, station.Version},
+	} {
+		if err := out.WriteFieldBegin(ctx, f.name, thrift.I64, f.id); err != nil {
 			return err
 		}
-		if err := out.WriteString(ctx, *order.FailureReason); err != nil {
+		if err := out.WriteI64(ctx, f.value); err != nil {
 			return err
 		}
 		if err := out.WriteFieldEnd(ctx); err != nil {
 			return err
 		}
 	}
+	if err := out.WriteFieldStop(ctx); err != nil {
+		return err
+	}
 	return out.WriteStructEnd(ctx)
 }
 
-func writeOrderView(ctx context.Context, out thrift.TProtocol, view *service.OrderView) error {
-	if err := out.WriteStructBegin(ctx, "OrderView"); err != nil {
+func writeChargingSlot(ctx context.Context, out thrift.TProtocol, slot *domain.ChargingSlot) error {
+	if err := out.WriteStructBegin(ctx, "ChargingSlot"); err != nil {
 		return err
 	}
-	if err := out.WriteFieldBegin(ctx, "order", thrift.STRUCT, 1); err != nil {
+	if err := out.WriteFieldBegin(ctx, "stationId", thrift.STRING, 1); err != nil {
 		return err
 	}
-	if err := writeOrder(ctx, out, view.Order); err != nil {
+	if err := out.WriteString(ctx, slot.StationID); err != nil {
 		return err
 	}
 	if err := out.WriteFieldEnd(ctx); err != nil {
 		return err
 	}
-	if view.CurrentLocation != nil {
-		if err := out.WriteFieldBegin(ctx, "currentLocation", thrift.STRUCT, 2); err != nil {
+	if err := out.WriteFieldBegin(ctx, "slot", thrift.I32, 2); err != nil {
+		return err
+	}
+	if err := out.WriteI32(ctx, int32(slot.Number)); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if slot.DroneID != nil {
+		if err := out.WriteFieldBegin(ctx, "droneId", thrift.STRING, 3); err != nil {
 			return err
 		}
-		if err := writeLocation(ctx, out, *view.CurrentLocation); err != nil {
+		if err := out.WriteString(ctx, *slot.DroneID); err != nil {
 			return err
 		}
 		if err := out.WriteFieldEnd(ctx); err != nil {
 			return err
 		}
 	}
-	if view.ETASeconds != nil {
-		if err := out.WriteFieldBegin(ctx, "etaSeconds", thrift.I64, 3); err != nil {
+	if slot.ReservedAt != nil {
+		if err := out.WriteFieldBegin(ctx, "reservedAt", thrift.I64, 4); err != nil {
 			return err
 		}
-		if err := out.WriteI64(ctx, *view.ETASeconds); err != nil {
+		if err := out.WriteI64(ctx, slot.ReservedAt.Unix()); err != nil {
 			return err
 		}
 		if err := out.WriteFieldEnd(ctx); err != nil {
 			return err
 		}
 	}
+	if err := out.WriteFieldStop(ctx); err != nil {
+		return err
+	}
 	return out.WriteStructEnd(ctx)
 }
 
-func writeDrone(ctx context.Context, out thrift.TProtocol, drone *domain.Drone) error {
-	if err := out.WriteStructBegin(ctx, "Drone"); err != nil {
+func writeNoFlyZone(ctx context.Context, out thrift.TProtocol, zone *domain.NoFlyZone) error {
+	if err := out.WriteStructBegin(ctx, "NoFlyZone"); err != nil {
 		return err
 	}
 	if err := out.WriteFieldBegin(ctx, "id", thrift.STRING, 1); err != nil {
 		return err
 	}
-	if err := out.WriteString(ctx, drone.ID); err != nil {
+	if err := out.WriteString(ctx, zone.ID); err != nil {
 		return err
 	}
 	if err := out.WriteFieldEnd(ctx); err != nil {
 		return err
 	}
-	if err := out.WriteFieldBegin(ctx, "status", thrift.STRING, 2); err != nil {
+	if err := out.WriteFieldBegin(ctx, "name", thrift.STRING, 2); err != nil {
 		return err
 	}
-	if err := out.WriteString(ctx, string(drone.Status)); err != nil {
+	if err := out.WriteString(ctx, zone.Name); err != nil {
 		return err
 	}
 	if err := out.WriteFieldEnd(ctx); err != nil {
 		return err
 	}
-	if drone.LastLocation != nil {
-		if err := out.WriteFieldBegin(ctx, "lastLocation", thrift.STRUCT, 3); err != nil {
+	if err := out.WriteFieldBegin(ctx, "boundary", thrift.LIST, 3); err != nil {
+		return err
+	}
+	if err := writePolygon(ctx, out, zone.Boundary); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if zone.ActiveFrom != nil {
+		if err := out.WriteFieldBegin(ctx, "activeFrom", thrift.I64, 4); err != nil {
 			return err
 		}
-		if err := writeLocation(ctx, out, *drone.LastLocation); err != nil {
+		if err := out.WriteI64(ctx, zone.ActiveFrom.Unix()); err != nil {
 			return err
 		}
 		if err := out.WriteFieldEnd(ctx); err != nil {
 			return err
 		}
 	}
-	if drone.LastHeartbeatAt != nil {
-		if err := out.WriteFieldBegin(ctx, "lastHeartbeatAt", thrift.I64, 4); err != nil {
+	if zone.ActiveUntil != nil {
+		if err := out.WriteFieldBegin(ctx, "activeUntil", thrift.I64, 5); err != nil {
 			return err
 		}
-		if err := out.WriteI64(ctx, drone.LastHeartbeatAt.Unix()); err != nil {
+		if err := out.WriteI64(ctx, zone.ActiveUntil.Unix()); err != nil {
 			return err
 		}
 		if err := out.WriteFieldEnd(ctx); err != nil {
 			return err
 		}
 	}
-	if drone.CurrentOrderID != nil {
-		if err := out.WriteFieldBegin(ctx, "currentOrderId", thrift.STRING, 5); err != nil {
+	if err := out.WriteFieldBegin(ctx, "createdAt", thrift.I64, 6); err != nil {
+		return err
+	}
+	if err := out.WriteI64(ctx, zone.CreatedAt.Unix()); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "updatedAt", thrift.I64, 7); err != nil {
+		return err
+	}
+	if err := out.WriteI64(ctx, zone.UpdatedAt.Unix()); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "version", thrift.I64, 8); err != nil {
+		return err
+	}
+	if err := out.WriteI64(ctx, zone.Version); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if err := out.WriteFieldStop(ctx); err != nil {
+		return err
+	}
+	return out.WriteStructEnd(ctx)
+}
+
+// writeTelemetryList writes the success field of a track reply.
+func writeTelemetryList(ctx context.Context, out thrift.TProtocol, samples []*domain.TelemetrySample) error {
+	if err := out.WriteFieldBegin(ctx, "success", thrift.LIST, 0); err != nil {
+		return err
+	}
+	if err := out.WriteListBegin(ctx, thrift.STRUCT, len(samples)); err != nil {
+		return err
+	}
+	for _, sample := range samples {
+		if err := writeTelemetrySample(ctx, out, sample); err != nil {
 			return err
 		}
-		if err := out.WriteString(ctx, *drone.CurrentOrderID); err != nil {
+	}
+	return out.WriteListEnd(ctx)
+}
+
+func writeTelemetrySample(ctx context.Context, out thrift.TProtocol, sample *domain.TelemetrySample) error {
+	if err := out.WriteStructBegin(ctx, "TelemetrySample"); err != nil {
+		return err
+	}
+	if err := out.WriteFieldBegin(ctx, "droneId", thrift.STRING, 1); err != nil {
+		return err
+	}
+	if err := out.WriteString(ctx, sample.DroneID); err != nil {
+		return err
+	}
+	if err := out.WriteFieldEnd(ctx); err != nil {
+		return err
+	}
+	if sample.OrderID != nil {
+		if err := out.WriteFieldBegin(ctx, "orderId", thrift.STRING, 2); err != nil {
+			return err
+		}
+		if err := out.WriteString(ctx, *sample.OrderID); err != nil {
 			return err
 		}
 		if err := out.WriteFieldEnd(ctx); err != nil {
 			return err
 		}
 	}
-	if err := out.WriteFieldBegin(ctx, "createdAt", thrift.I64, 6); err != nil {
+	if err := out.WriteFieldBegin(ctx, "location", thrift.STRUCT, 3); err != nil {
 		return err
 	}
-	if err := out.WriteI64(ctx, drone.CreatedAt.Unix()); err != nil {
+	if err := writeLocation(ctx, out, sample.Location); err != nil {
 		return err
 	}
 	if err := out.WriteFieldEnd(ctx); err != nil {
 		return err
 	}
-	if err := out.WriteFieldBegin(ctx, "updatedAt", thrift.I64, 7); err != nil {
+	if err := out.WriteFieldBegin(ctx, "recordedAt", thrift.I64, 4); err != nil {
 		return err
 	}
-	if err := out.WriteI64(ctx, drone.UpdatedAt.Unix()); err != nil {
+	if err := out.WriteI64(ctx, sample.RecordedAt.Unix()); err != nil {
 		return err
 	}
 	if err := out.WriteFieldEnd(ctx); err != nil {
 		return err
 	}
+	if err := out.WriteFieldStop(ctx); err != nil {
+		return err
+	}
 	return out.WriteStructEnd(ctx)
 }
 
-func writeDroneStatus(ctx context.Context, out thrift.TProtocol, view *service.DroneStatusView) error {
-	if err := out.WriteStructBegin(ctx, "DroneStatus"); err != nil {
+// writeMaintenanceList writes the success field of a DroneMaintenance reply.
+func writeMaintenanceList(ctx context.Context, out thrift.TProtocol, records []*domain.MaintenanceRecord) error {
+	if err := out.WriteFieldBegin(ctx, "success", thrift.LIST, 0); err != nil {
 		return err
 	}
-	if err := out.WriteFieldBegin(ctx, "drone", thrift.STRUCT, 1); err != nil {
+	if err := out.WriteListBegin(ctx, thrift.STRUCT, len(records)); err != nil {
 		return err
 	}
-	if err := writeDrone(ctx, out, view.Drone); err != nil {
+	for _, record := range records {
+		if err := writeMaintenanceRecord(ctx, out, record); err != nil {
+			return err
+		}
+	}
+	return out.WriteListEnd(ctx)
+}
+
+func writeMaintenanceRecord(ctx context.Context, out thrift.TProtocol, record *domain.MaintenanceRecord) error {
+	if err := out.WriteStructBegin(ctx, "MaintenanceRecord"); err != nil {
 		return err
 	}
-	if err := out.WriteFieldEnd(ctx); err != nil {
+	texts := []struct {
+		name  string
+		id    int16
+		value *string
+	}{
+		{"id", 1, &record.ID},
+		{"droneId", 2, &record.DroneID},
+		{"reason", 3, (*string)(&record.Reason)},
+		{"technician", 9, record.Technician},
+		{"notes", 10, record.Notes},
+	}
+	for _, f := range texts {
+		if f.value == nil {
+			continue
+		}
+		if err := out.WriteFieldBegin(ctx, f.name, thrift.STRING, f.id); err != nil {
+			return err
+		}
+		if err := out.WriteString(ctx, *f.value); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if err := writeStringListField(ctx, out, "faultCodes", 4, record.FaultCodes); err != nil {
 		return err
 	}
-	if view.CurrentOrder != nil {
-		if err := out.WriteFieldBegin(ctx, "currentOrder", thrift.STRUCT, 2); err != nil {
+	if err := writeStringListField(ctx, out, "parts", 11, record.Parts); err != nil {
+		return err
+	}
+	for _, f := range []struct {
+		name  string
+		id    int16
+		value float64
+	}{
+		{"flightSeconds", 5, record.Usage.Seconds},
+		{"flightMeters", 6, record.Usage.Meters},
+	} {
+		if err := out.WriteFieldBegin(ctx, f.name, thrift.DOUBLE, f.id); err != nil {
 			return err
 		}
-		if err := writeOrderView(ctx, out, view.CurrentOrder); err != nil {
+		if err := out.WriteDouble(ctx, f.value); err != nil {
+			return err
+		}
+		if err := out.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	times := []struct {
+		name  string
+		id    int16
+		value *time.Time
+	}{
+		{"openedAt", 7, &record.OpenedAt},
+		{"closedAt", 8, record.ClosedAt},
+	}
+	for _, f := range times {
+		if f.value == nil {
+			continue
+		}
+		if err := out.WriteFieldBegin(ctx, f.name, thrift.I64, f.id); err != nil {
+			return err
+		}
+		if err := out.WriteI64(ctx, f.value.Unix()); err != nil {
 			return err
 		}
 		if err := out.WriteFieldEnd(ctx); err != nil {
 			return err
 		}
 	}
+	if err := out.WriteFieldStop(ctx); err != nil {
+		return err
+	}
 	return out.WriteStructEnd(ctx)
 }
 
-func writeOrderViewList(ctx context.Context, out thrift.TProtocol, views []*service.OrderView) error {
-	if err := out.WriteListBegin(ctx, thrift.STRUCT, len(views)); err != nil {
+// writeStringListField writes values as a list<string> field, or nothing if
+// there are none.
+func writeStringListField(ctx context.Context, out thrift.TProtocol, name string, id int16, values []string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	if err := out.WriteFieldBegin(ctx, name, thrift.LIST, id); err != nil {
 		return err
 	}
-	for _, view := range views {
-		if err := writeOrderView(ctx, out, view); err != nil {
+	if err := out.WriteListBegin(ctx, thrift.STRING, len(values)); err != nil {
+		return err
+	}
+	for _, v := range values {
+		if err := out.WriteString(ctx, v); err != nil {
 			return err
 		}
 	}
-	return out.WriteListEnd(ctx)
+	if err := out.WriteListEnd(ctx); err != nil {
+		return err
+	}
+	return out.WriteFieldEnd(ctx)
 }
 
-func writeDroneList(ctx context.Context, out thrift.TProtocol, drones []*domain.Drone) error {
-	if err := out.WriteListBegin(ctx, thrift.STRUCT, len(drones)); err != nil {
+// writePolygon writes a list<list<Location>>, exterior ring first.
+func writePolygon(ctx context.Context, out thrift.TProtocol, polygon domain.Polygon) error {
+	if err := out.WriteListBegin(ctx, thrift.LIST, len(polygon)); err != nil {
 		return err
 	}
-	for _, drone := range drones {
-		if err := writeDrone(ctx, out, drone); err != nil {
+	for _, ring := range polygon {
+		if err := out.WriteListBegin(ctx, thrift.STRUCT, len(ring)); err != nil {
+			return err
+		}
+		for _, loc := range ring {
+			if err := writeLocation(ctx, out, loc); err != nil {
+				return err
+			}
+		}
+		if err := out.WriteListEnd(ctx); err != nil {
 			return err
 		}
 	}
@@ -974,6 +2831,29 @@ func readAuthRequest(ctx context.Context, in thrift.TProtocol) (string, error) {
 	return token, nil
 }
 
+// readIdempotentAuthRequest reads an AuthRequest together with its optional
+// idempotencyKey (field 2).
+func readIdempotentAuthRequest(ctx context.Context, in thrift.TProtocol) (string, string, error) {
+	// Expected args struct: <Method>_args { 1: AuthRequest request }
+	var token, idempotencyKey string
+	err := readRequest(ctx, in, func(fieldID int16, fieldType thrift.TType) error {
+		var err error
+		switch fieldID {
+		case 1:
+			token, err = in.ReadString(ctx)
+		case 2:
+			idempotencyKey, err = in.ReadString(ctx)
+		default:
+			err = in.Skip(ctx, fieldType)
+		}
+		return err
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return token, idempotencyKey, nil
+}
+
 func readOrderIDRequest(ctx context.Context, in thrift.TProtocol) (string, string, error) {
 	// Expected args struct: <Method>_args { 1: OrderIDRequest request }
 	if _, err := in.ReadStructBegin(ctx); err != nil {
@@ -1036,32 +2916,217 @@ func readOrderIDRequest(ctx context.Context, in thrift.TProtocol) (string, strin
 	return token, orderID, nil
 }
 
-func readDroneIDRequest(ctx context.Context, in thrift.TProtocol) (string, string, error) {
-	return readOrderIDRequest(ctx, in)
+// readVersionedIDRequest reads an OrderIDRequest or DroneIDRequest together
+// with its optional expectedVersion (field 3; 0 when absent).
+func readVersionedIDRequest(ctx context.Context, in thrift.TProtocol) (string, string, int64, error) {
+	// Expected args struct: <Method>_args { 1: OrderIDRequest|DroneIDRequest request }
+	var token, id string
+	var expectedVersion int64
+	err := readRequest(ctx, in, func(fieldID int16, fieldType thrift.TType) error {
+		var err error
+		switch fieldID {
+		case 1:
+			token, err = in.ReadString(ctx)
+		case 2:
+			id, err = in.ReadString(ctx)
+		case 3:
+			expectedVersion, err = in.ReadI64(ctx)
+		default:
+			err = in.Skip(ctx, fieldType)
+		}
+		return err
+	})
+	if err != nil {
+		return "", "", 0, err
+	}
+	return token, id, expectedVersion, nil
+}
+
+// readIdempotentIDRequest reads an OrderIDRequest together with its optional
+// idempotencyKey (field 4).
+func readIdempotentIDRequest(ctx context.Context, in thrift.TProtocol) (string, string, string, error) {
+	// Expected args struct: <Method>_args { 1: OrderIDRequest request }
+	var token, orderID, idempotencyKey string
+	err := readRequest(ctx, in, func(fieldID int16, fieldType thrift.TType) error {
+		var err error
+		switch fieldID {
+		case 1:
+			token, err = in.ReadString(ctx)
+		case 2:
+			orderID, err = in.ReadString(ctx)
+		case 4:
+			idempotencyKey, err = in.ReadString(ctx)
+		default:
+			err = in.Skip(ctx, fieldType)
+		}
+		return err
+	})
+	if err != nil {
+		return "", "", "", err
+	}
+	return token, orderID, idempotencyKey, nil
+}
+
+func readDroneIDRequest(ctx context.Context, in thrift.TProtocol) (string, string, int64, error) {
+	return readVersionedIDRequest(ctx, in)
+}
+
+// readFixDroneRequest reads a FixDroneRequest: a DroneIDRequest (fields 1-3)
+// with the optional maintenance report in fields 4-6.
+func readFixDroneRequest(ctx context.Context, in thrift.TProtocol) (string, string, domain.MaintenanceReport, int64, error) {
+	// Expected args struct: MarkDroneFixed_args { 1: FixDroneRequest request }
+	var token, droneID string
+	var report domain.MaintenanceReport
+	var expectedVersion int64
+	err := readRequest(ctx, in, func(fieldID int16, fieldType thrift.TType) error {
+		var err error
+		switch fieldID {
+		case 1:
+			token, err = in.ReadString(ctx)
+		case 2:
+			droneID, err = in.ReadString(ctx)
+		case 3:
+			expectedVersion, err = in.ReadI64(ctx)
+		case 4:
+			report.Technician, err = in.ReadString(ctx)
+		case 5:
+			report.Notes, err = in.ReadString(ctx)
+		case 6:
+			report.Parts, err = readStringList(ctx, in)
+		default:
+			err = in.Skip(ctx, fieldType)
+		}
+		return err
+	})
+	if err != nil {
+		return "", "", domain.MaintenanceReport{}, 0, err
+	}
+	return token, droneID, report, expectedVersion, nil
+}
+
+func readDroneStatusRequest(ctx context.Context, in thrift.TProtocol) (string, string, error) {
+	// Expected args struct: SetStatus_args { 1: DroneStatusRequest request }
+	var token, status string
+	err := readRequest(ctx, in, func(fieldID int16, fieldType thrift.TType) error {
+		var err error
+		switch fieldID {
+		case 1:
+			token, err = in.ReadString(ctx)
+		case 2:
+			status, err = in.ReadString(ctx)
+		default:
+			err = in.Skip(ctx, fieldType)
+		}
+		return err
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return token, status, nil
+}
+
+func readAdminDroneStatusRequest(ctx context.Context, in thrift.TProtocol) (string, string, string, int64, error) {
+	// Expected args struct: SetDroneStatus_args { 1: AdminDroneStatusRequest request }
+	var token, droneID, status string
+	var expectedVersion int64
+	err := readRequest(ctx, in, func(fieldID int16, fieldType thrift.TType) error {
+		var err error
+		switch fieldID {
+		case 1:
+			token, err = in.ReadString(ctx)
+		case 2:
+			droneID, err = in.ReadString(ctx)
+		case 3:
+			status, err = in.ReadString(ctx)
+		case 4:
+			expectedVersion, err = in.ReadI64(ctx)
+		default:
+			err = in.Skip(ctx, fieldType)
+		}
+		return err
+	})
+	if err != nil {
+		return "", "", "", 0, err
+	}
+	return token, droneID, status, expectedVersion, nil
+}
+
+func readAssignOrderRequest(ctx context.Context, in thrift.TProtocol) (string, string, string, int64, error) {
+	// Expected args struct: <Method>_args { 1: AssignOrderRequest request }
+	var token, orderID, droneID string
+	var expectedVersion int64
+	err := readRequest(ctx, in, func(fieldID int16, fieldType thrift.TType) error {
+		var err error
+		switch fieldID {
+		case 1:
+			token, err = in.ReadString(ctx)
+		case 2:
+			orderID, err = in.ReadString(ctx)
+		case 3:
+			droneID, err = in.ReadString(ctx)
+		case 4:
+			expectedVersion, err = in.ReadI64(ctx)
+		default:
+			err = in.Skip(ctx, fieldType)
+		}
+		return err
+	})
+	if err != nil {
+		return "", "", "", 0, err
+	}
+	return token, orderID, droneID, expectedVersion, nil
+}
+
+func readOverrideOrderRequest(ctx context.Context, in thrift.TProtocol) (string, string, string, string, int64, error) {
+	// Expected args struct: OverrideOrder_args { 1: OverrideOrderRequest request }
+	var token, orderID, status, reason string
+	var expectedVersion int64
+	err := readRequest(ctx, in, func(fieldID int16, fieldType thrift.TType) error {
+		var err error
+		switch fieldID {
+		case 1:
+			token, err = in.ReadString(ctx)
+		case 2:
+			orderID, err = in.ReadString(ctx)
+		case 3:
+			status, err = in.ReadString(ctx)
+		case 4:
+			reason, err = in.ReadString(ctx)
+		case 5:
+			expectedVersion, err = in.ReadI64(ctx)
+		default:
+			err = in.Skip(ctx, fieldType)
+		}
+		return err
+	})
+	if err != nil {
+		return "", "", "", "", 0, err
+	}
+	return token, orderID, status, reason, expectedVersion, nil
 }
 
-func readFailOrderRequest(ctx context.Context, in thrift.TProtocol) (string, string, string, error) {
+func readFailOrderRequest(ctx context.Context, in thrift.TProtocol) (string, string, string, string, error) {
 	// Expected args struct: FailOrder_args { 1: FailOrderRequest request }
 	if _, err := in.ReadStructBegin(ctx); err != nil {
-		return "", "", "", err
+		return "", "", "", "", err
 	}
-	var token, orderID, reason string
+	var token, orderID, reason, idempotencyKey string
 	for {
 		_, fieldType, fieldID, err := in.ReadFieldBegin(ctx)
 		if err != nil {
-			return "", "", "", err
+			return "", "", "", "", err
 		}
 		if fieldType == thrift.STOP {
 			break
 		}
 		if fieldID == 1 && fieldType == thrift.STRUCT {
 			if _, err := in.ReadStructBegin(ctx); err != nil {
-				return "", "", "", err
+				return "", "", "", "", err
 			}
 			for {
 				_, ft, fid, err := in.ReadFieldBegin(ctx)
 				if err != nil {
-					return "", "", "", err
+					return "", "", "", "", err
 				}
 				if ft == thrift.STOP {
 					break
@@ -1073,117 +3138,281 @@ func readFailOrderRequest(ctx context.Context, in thrift.TProtocol) (string, str
 					orderID, err = in.ReadString(ctx)
 				case 3:
 					reason, err = in.ReadString(ctx)
+				case 4:
+					idempotencyKey, err = in.ReadString(ctx)
 				default:
 					err = in.Skip(ctx, ft)
 				}
 				if err != nil {
-					return "", "", "", err
+					return "", "", "", "", err
 				}
 				if err := in.ReadFieldEnd(ctx); err != nil {
-					return "", "", "", err
+					return "", "", "", "", err
 				}
 			}
 			if err := in.ReadStructEnd(ctx); err != nil {
-				return "", "", "", err
+				return "", "", "", "", err
 			}
 		} else {
 			if err := in.Skip(ctx, fieldType); err != nil {
-				return "", "", "", err
+				return "", "", "", "", err
 			}
 		}
 		if err := in.ReadFieldEnd(ctx); err != nil {
-			return "", "", "", err
+			return "", "", "", "", err
 		}
 	}
 	if err := in.ReadStructEnd(ctx); err != nil {
-		return "", "", "", err
+		return "", "", "", "", err
 	}
 	if err := in.ReadMessageEnd(ctx); err != nil {
-		return "", "", "", err
+		return "", "", "", "", err
 	}
-	return token, orderID, reason, nil
+	return token, orderID, reason, idempotencyKey, nil
 }
 
-func readHeartbeatRequest(ctx context.Context, in thrift.TProtocol) (string, domain.Location, error) {
+func readHeartbeatRequest(ctx context.Context, in thrift.TProtocol) (string, domain.Location, domain.Vitals, error) {
 	// Expected args struct: Heartbeat_args { 1: HeartbeatRequest request }
-	if _, err := in.ReadStructBegin(ctx); err != nil {
-		return "", domain.Location{}, err
-	}
 	var token string
 	var loc domain.Location
+	var vitals domain.Vitals
+	readDouble := func() (*float64, error) {
+		v, err := in.ReadDouble(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &v, nil
+	}
+	err := readRequest(ctx, in, func(fieldID int16, fieldType thrift.TType) error {
+		var err error
+		switch fieldID {
+		case 1:
+			token, err = in.ReadString(ctx)
+		case 2:
+			loc, err = readLocation(ctx, in)
+		case 3:
+			vitals.AltitudeMeters, err = readDouble()
+		case 4:
+			vitals.HeadingDegrees, err = readDouble()
+		case 5:
+			vitals.GroundSpeedMPS, err = readDouble()
+		case 6:
+			vitals.BatteryPercent, err = readDouble()
+		case 7:
+			vitals.FaultCodes, err = readStringList(ctx, in)
+		default:
+			err = in.Skip(ctx, fieldType)
+		}
+		return err
+	})
+	if err != nil {
+		return "", domain.Location{}, domain.Vitals{}, err
+	}
+	return token, loc, vitals, nil
+}
+
+func readSubmitOrderRequest(ctx context.Context, in thrift.TProtocol) (string, domain.Location, domain.Location, service.DeliveryWindow, string, error) {
+	if _, err := in.ReadStructBegin(ctx); err != nil {
+		return "", domain.Location{}, domain.Location{}, service.DeliveryWindow{}, "", err
+	}
+	var token, idempotencyKey string
+	var origin, dest domain.Location
+	var window service.DeliveryWindow
 	for {
 		_, fieldType, fieldID, err := in.ReadFieldBegin(ctx)
 		if err != nil {
-			return "", domain.Location{}, err
+			return "", domain.Location{}, domain.Location{}, service.DeliveryWindow{}, "", err
 		}
 		if fieldType == thrift.STOP {
 			break
 		}
-		if fieldID == 1 && fieldType == thrift.STRUCT {
-			if _, err := in.ReadStructBegin(ctx); err != nil {
-				return "", domain.Location{}, err
-			}
-			for {
-				_, ft, fid, err := in.ReadFieldBegin(ctx)
-				if err != nil {
-					return "", domain.Location{}, err
-				}
-				if ft == thrift.STOP {
-					break
-				}
-				switch fid {
-				case 1:
-					token, err = in.ReadString(ctx)
-				case 2:
-					// location struct
-					if ft != thrift.STRUCT {
-						err = in.Skip(ctx, ft)
-					} else {
-						var l domain.Location
-						l, err = readLocation(ctx, in)
-						loc = l
-					}
-				default:
-					err = in.Skip(ctx, ft)
-				}
-				if err != nil {
-					return "", domain.Location{}, err
-				}
-				if err := in.ReadFieldEnd(ctx); err != nil {
-					return "", domain.Location{}, err
-				}
+		switch fieldID {
+		case 1:
+			token, err = in.ReadString(ctx)
+		case 2:
+			origin, err = readLocation(ctx, in)
+		case 3:
+			dest, err = readLocation(ctx, in)
+		case 4:
+			idempotencyKey, err = in.ReadString(ctx)
+		case 5:
+			window.PickupNotBefore, err = readUnixTime(ctx, in)
+		case 6:
+			window.DeliverBy, err = readUnixTime(ctx, in)
+		default:
+			err = in.Skip(ctx, fieldType)
+		}
+		if err != nil {
+			return "", domain.Location{}, domain.Location{}, service.DeliveryWindow{}, "", err
+		}
+		if err := in.ReadFieldEnd(ctx); err != nil {
+			return "", domain.Location{}, domain.Location{}, service.DeliveryWindow{}, "", err
+		}
+	}
+	if err := in.ReadStructEnd(ctx); err != nil {
+		return "", domain.Location{}, domain.Location{}, service.DeliveryWindow{}, "", err
+	}
+	if err := in.ReadMessageEnd(ctx); err != nil {
+		return "", domain.Location{}, domain.Location{}, service.DeliveryWindow{}, "", err
+	}
+	return token, origin, dest, window, idempotencyKey, nil
+}
+
+func readListOrdersRequest(ctx context.Context, in thrift.TProtocol) (string, service.OrderFilter, error) {
+	// Expected args struct: <Method>_args { 1: ListOrdersRequest request }
+	var token string
+	var filter service.OrderFilter
+	err := readRequest(ctx, in, func(fieldID int16, fieldType thrift.TType) error {
+		var err error
+		switch fieldID {
+		case 1:
+			token, err = in.ReadString(ctx)
+		case 2:
+			var status string
+			status, err = in.ReadString(ctx)
+			if status != "" {
+				filter.Statuses = append(filter.Statuses, domain.OrderStatus(status))
 			}
-			if err := in.ReadStructEnd(ctx); err != nil {
-				return "", domain.Location{}, err
+		case 3:
+			var limit int32
+			limit, err = in.ReadI32(ctx)
+			filter.Limit = int(limit)
+		case 4:
+			var offset int32
+			offset, err = in.ReadI32(ctx)
+			filter.Offset = int(offset)
+		case 5:
+			filter.Cursor, err = in.ReadString(ctx)
+		case 6:
+			var from int64
+			from, err = in.ReadI64(ctx)
+			t := time.Unix(from, 0).UTC()
+			filter.CreatedFrom = &t
+		case 7:
+			var to int64
+			to, err = in.ReadI64(ctx)
+			t := time.Unix(to, 0).UTC()
+			filter.CreatedTo = &t
+		case 8:
+			var statuses []string
+			statuses, err = readStringList(ctx, in)
+			for _, status := range statuses {
+				filter.Statuses = append(filter.Statuses, domain.OrderStatus(status))
 			}
-		} else {
-			if err := in.Skip(ctx, fieldType); err != nil {
-				return "", domain.Location{}, err
+		case 9:
+			var sort string
+			sort, err = in.ReadString(ctx)
+			filter.Sort = service.OrderSort(sort)
+		case 10:
+			var userID string
+			userID, err = in.ReadString(ctx)
+			filter.UserID = &userID
+		case 11:
+			var droneID string
+			droneID, err = in.ReadString(ctx)
+			filter.DroneID = &droneID
+		case 12:
+			var from int64
+			from, err = in.ReadI64(ctx)
+			t := time.Unix(from, 0).UTC()
+			filter.UpdatedFrom = &t
+		case 13:
+			var to int64
+			to, err = in.ReadI64(ctx)
+			t := time.Unix(to, 0).UTC()
+			filter.UpdatedTo = &t
+		case 14:
+			filter.FailureReason, err = in.ReadString(ctx)
+		case 15:
+			var box domain.BoundingBox
+			box, err = readBoundingBox(ctx, in)
+			filter.OriginBox = &box
+		case 16:
+			var box domain.BoundingBox
+			box, err = readBoundingBox(ctx, in)
+			filter.DestinationBox = &box
+		default:
+			err = in.Skip(ctx, fieldType)
+		}
+		return err
+	})
+	if err != nil {
+		return "", service.OrderFilter{}, err
+	}
+	return token, filter, nil
+}
+
+func readOrdersNearRequest(ctx context.Context, in thrift.TProtocol) (string, service.RadiusQuery, error) {
+	// Expected args struct: OrdersNear_args { 1: OrdersNearRequest request }
+	var token string
+	var query service.RadiusQuery
+	err := readRequest(ctx, in, func(fieldID int16, fieldType thrift.TType) error {
+		var err error
+		switch fieldID {
+		case 1:
+			token, err = in.ReadString(ctx)
+		case 2:
+			query.Center, err = readLocation(ctx, in)
+		case 3:
+			query.RadiusMeters, err = in.ReadDouble(ctx)
+		case 4:
+			var statuses []string
+			statuses, err = readStringList(ctx, in)
+			for _, status := range statuses {
+				query.Statuses = append(query.Statuses, domain.OrderStatus(status))
 			}
+		case 5:
+			var limit int32
+			limit, err = in.ReadI32(ctx)
+			query.Limit = int(limit)
+		default:
+			err = in.Skip(ctx, fieldType)
 		}
-		if err := in.ReadFieldEnd(ctx); err != nil {
-			return "", domain.Location{}, err
+		return err
+	})
+	if err != nil {
+		return "", service.RadiusQuery{}, err
+	}
+	return token, query, nil
+}
+
+func readNearestDronesRequest(ctx context.Context, in thrift.TProtocol) (string, domain.Location, int, error) {
+	// Expected args struct: NearestIdleDrones_args { 1: NearestDronesRequest request }
+	var token string
+	var point domain.Location
+	var limit int32
+	err := readRequest(ctx, in, func(fieldID int16, fieldType thrift.TType) error {
+		var err error
+		switch fieldID {
+		case 1:
+			token, err = in.ReadString(ctx)
+		case 2:
+			point, err = readLocation(ctx, in)
+		case 3:
+			limit, err = in.ReadI32(ctx)
+		default:
+			err = in.Skip(ctx, fieldType)
 		}
+		return err
+	})
+	if err != nil {
+		return "", domain.Location{}, 0, err
 	}
-	if err := in.ReadStructEnd(ctx); err != nil {
-		return "", domain.Location{}, err
-	}
-	if err := in.ReadMessageEnd(ctx); err != nil {
-		return "", domain.Location{}, err
-	}
-	return token, loc, nil
+	return token, point, int(limit), nil
 }
 
-func readSubmitOrderRequest(ctx context.Context, in thrift.TProtocol) (string, domain.Location, domain.Location, error) {
+func readUpdateOrderRequest(ctx context.Context, in thrift.TProtocol) (string, string, *domain.Location, *domain.Location, int64, error) {
 	if _, err := in.ReadStructBegin(ctx); err != nil {
-		return "", domain.Location{}, domain.Location{}, err
+		return "", "", nil, nil, 0, err
 	}
-	var token string
-	var origin, dest domain.Location
+	var token, orderID string
+	var origin *domain.Location
+	var dest *domain.Location
+	var expectedVersion int64
 	for {
 		_, fieldType, fieldID, err := in.ReadFieldBegin(ctx)
 		if err != nil {
-			return "", domain.Location{}, domain.Location{}, err
+			return "", "", nil, nil, 0, err
 		}
 		if fieldType == thrift.STOP {
 			break
@@ -1192,121 +3421,463 @@ func readSubmitOrderRequest(ctx context.Context, in thrift.TProtocol) (string, d
 		case 1:
 			token, err = in.ReadString(ctx)
 		case 2:
-			origin, err = readLocation(ctx, in)
+			orderID, err = in.ReadString(ctx)
 		case 3:
-			dest, err = readLocation(ctx, in)
+			loc, err := readLocation(ctx, in)
+			if err != nil {
+				return "", "", nil, nil, 0, err
+			}
+			origin = &loc
+		case 4:
+			loc, err := readLocation(ctx, in)
+			if err != nil {
+				return "", "", nil, nil, 0, err
+			}
+			dest = &loc
+		case 5:
+			expectedVersion, err = in.ReadI64(ctx)
 		default:
 			err = in.Skip(ctx, fieldType)
 		}
 		if err != nil {
-			return "", domain.Location{}, domain.Location{}, err
+			return "", "", nil, nil, 0, err
 		}
 		if err := in.ReadFieldEnd(ctx); err != nil {
-			return "", domain.Location{}, domain.Location{}, err
+			return "", "", nil, nil, 0, err
 		}
 	}
 	if err := in.ReadStructEnd(ctx); err != nil {
-		return "", domain.Location{}, domain.Location{}, err
+		return "", "", nil, nil, 0, err
 	}
 	if err := in.ReadMessageEnd(ctx); err != nil {
-		return "", domain.Location{}, domain.Location{}, err
+		return "", "", nil, nil, 0, err
 	}
-	return token, origin, dest, nil
+	return token, orderID, origin, dest, expectedVersion, nil
 }
 
-func readListOrdersRequest(ctx context.Context, in thrift.TProtocol) (string, string, int, int, error) {
-	if _, err := in.ReadStructBegin(ctx); err != nil {
-		return "", "", 0, 0, err
+// readCreateServiceAreaRequest reads a CreateServiceAreaRequest; active
+// defaults to true when the optional field is unset.
+func readCreateServiceAreaRequest(ctx context.Context, in thrift.TProtocol) (string, string, domain.Polygon, bool, error) {
+	// Expected args struct: CreateServiceArea_args { 1: CreateServiceAreaRequest request }
+	var token, name string
+	var boundary domain.Polygon
+	active := true
+	err := readRequest(ctx, in, func(fieldID int16, fieldType thrift.TType) error {
+		var err error
+		switch fieldID {
+		case 1:
+			token, err = in.ReadString(ctx)
+		case 2:
+			name, err = in.ReadString(ctx)
+		case 3:
+			boundary, err = readPolygon(ctx, in)
+		case 4:
+			active, err = in.ReadBool(ctx)
+		default:
+			err = in.Skip(ctx, fieldType)
+		}
+		return err
+	})
+	if err != nil {
+		return "", "", nil, false, err
 	}
-	var token string
-	var status string
-	var limit int32
-	var offset int32
-	for {
-		_, fieldType, fieldID, err := in.ReadFieldBegin(ctx)
-		if err != nil {
-			return "", "", 0, 0, err
+	return token, name, boundary, active, nil
+}
+
+func readUpdateServiceAreaRequest(ctx context.Context, in thrift.TProtocol) (string, string, service.ServiceAreaUpdate, int64, error) {
+	// Expected args struct: UpdateServiceArea_args { 1: UpdateServiceAreaRequest request }
+	var token, areaID string
+	var update service.ServiceAreaUpdate
+	var expectedVersion int64
+	err := readRequest(ctx, in, func(fieldID int16, fieldType thrift.TType) error {
+		var err error
+		switch fieldID {
+		case 1:
+			token, err = in.ReadString(ctx)
+		case 2:
+			areaID, err = in.ReadString(ctx)
+		case 3:
+			var name string
+			name, err = in.ReadString(ctx)
+			update.Name = &name
+		case 4:
+			update.Boundary, err = readPolygon(ctx, in)
+		case 5:
+			var active bool
+			active, err = in.ReadBool(ctx)
+			update.Active = &active
+		case 6:
+			expectedVersion, err = in.ReadI64(ctx)
+		default:
+			err = in.Skip(ctx, fieldType)
 		}
-		if fieldType == thrift.STOP {
-			break
+		return err
+	})
+	if err != nil {
+		return "", "", service.ServiceAreaUpdate{}, 0, err
+	}
+	return token, areaID, update, expectedVersion, nil
+}
+
+// readSetDroneDepotRequest reads a SetDroneDepotRequest; an absent depotId
+// (field 3) clears the drone's home depot.
+func readSetDroneDepotRequest(ctx context.Context, in thrift.TProtocol) (string, string, *string, int64, error) {
+	// Expected args struct: SetDroneDepot_args { 1: SetDroneDepotRequest request }
+	var token, droneID string
+	var depotID *string
+	var expectedVersion int64
+	err := readRequest(ctx, in, func(fieldID int16, fieldType thrift.TType) error {
+		var err error
+		switch fieldID {
+		case 1:
+			token, err = in.ReadString(ctx)
+		case 2:
+			droneID, err = in.ReadString(ctx)
+		case 3:
+			var id string
+			id, err = in.ReadString(ctx)
+			depotID = &id
+		case 4:
+			expectedVersion, err = in.ReadI64(ctx)
+		default:
+			err = in.Skip(ctx, fieldType)
 		}
+		return err
+	})
+	if err != nil {
+		return "", "", nil, 0, err
+	}
+	return token, droneID, depotID, expectedVersion, nil
+}
+
+func readCreateDepotRequest(ctx context.Context, in thrift.TProtocol) (string, string, domain.Location, int, error) {
+	// Expected args struct: CreateDepot_args { 1: CreateDepotRequest request }
+	var token, name string
+	var location domain.Location
+	var capacity int32
+	err := readRequest(ctx, in, func(fieldID int16, fieldType thrift.TType) error {
+		var err error
 		switch fieldID {
 		case 1:
 			token, err = in.ReadString(ctx)
 		case 2:
-			status, err = in.ReadString(ctx)
+			name, err = in.ReadString(ctx)
 		case 3:
-			limit, err = in.ReadI32(ctx)
+			location, err = readLocation(ctx, in)
 		case 4:
-			offset, err = in.ReadI32(ctx)
+			capacity, err = in.ReadI32(ctx)
 		default:
 			err = in.Skip(ctx, fieldType)
 		}
-		if err != nil {
-			return "", "", 0, 0, err
+		return err
+	})
+	if err != nil {
+		return "", "", domain.Location{}, 0, err
+	}
+	return token, name, location, int(capacity), nil
+}
+
+func readUpdateDepotRequest(ctx context.Context, in thrift.TProtocol) (string, string, service.DepotUpdate, int64, error) {
+	// Expected args struct: UpdateDepot_args { 1: UpdateDepotRequest request }
+	var token, depotID string
+	var update service.DepotUpdate
+	var expectedVersion int64
+	err := readRequest(ctx, in, func(fieldID int16, fieldType thrift.TType) error {
+		var err error
+		switch fieldID {
+		case 1:
+			token, err = in.ReadString(ctx)
+		case 2:
+			depotID, err = in.ReadString(ctx)
+		case 3:
+			var name string
+			name, err = in.ReadString(ctx)
+			update.Name = &name
+		case 4:
+			var location domain.Location
+			location, err = readLocation(ctx, in)
+			update.Location = &location
+		case 5:
+			var capacity int32
+			capacity, err = in.ReadI32(ctx)
+			c := int(capacity)
+			update.Capacity = &c
+		case 6:
+			expectedVersion, err = in.ReadI64(ctx)
+		default:
+			err = in.Skip(ctx, fieldType)
 		}
-		if err := in.ReadFieldEnd(ctx); err != nil {
-			return "", "", 0, 0, err
+		return err
+	})
+	if err != nil {
+		return "", "", service.DepotUpdate{}, 0, err
+	}
+	return token, depotID, update, expectedVersion, nil
+}
+
+func readCreateChargingStationRequest(ctx context.Context, in thrift.TProtocol) (string, string, domain.Location, int, error) {
+	// Expected args struct: CreateChargingStation_args { 1: CreateChargingStationRequest request }
+	var token, name string
+	var location domain.Location
+	var slots int32
+	err := readRequest(ctx, in, func(fieldID int16, fieldType thrift.TType) error {
+		var err error
+		switch fieldID {
+		case 1:
+			token, err = in.ReadString(ctx)
+		case 2:
+			name, err = in.ReadString(ctx)
+		case 3:
+			location, err = readLocation(ctx, in)
+		case 4:
+			slots, err = in.ReadI32(ctx)
+		default:
+			err = in.Skip(ctx, fieldType)
 		}
+		return err
+	})
+	if err != nil {
+		return "", "", domain.Location{}, 0, err
 	}
-	if err := in.ReadStructEnd(ctx); err != nil {
-		return "", "", 0, 0, err
+	return token, name, location, int(slots), nil
+}
+
+func readUpdateChargingStationRequest(ctx context.Context, in thrift.TProtocol) (string, string, service.ChargingStationUpdate, int64, error) {
+	// Expected args struct: UpdateChargingStation_args { 1: UpdateChargingStationRequest request }
+	var token, stationID string
+	var update service.ChargingStationUpdate
+	var expectedVersion int64
+	err := readRequest(ctx, in, func(fieldID int16, fieldType thrift.TType) error {
+		var err error
+		switch fieldID {
+		case 1:
+			token, err = in.ReadString(ctx)
+		case 2:
+			stationID, err = in.ReadString(ctx)
+		case 3:
+			var name string
+			name, err = in.ReadString(ctx)
+			update.Name = &name
+		case 4:
+			var location domain.Location
+			location, err = readLocation(ctx, in)
+			update.Location = &location
+		case 5:
+			var slots int32
+			slots, err = in.ReadI32(ctx)
+			n := int(slots)
+			update.Slots = &n
+		case 6:
+			expectedVersion, err = in.ReadI64(ctx)
+		default:
+			err = in.Skip(ctx, fieldType)
+		}
+		return err
+	})
+	if err != nil {
+		return "", "", service.ChargingStationUpdate{}, 0, err
 	}
-	if err := in.ReadMessageEnd(ctx); err != nil {
-		return "", "", 0, 0, err
+	return token, stationID, update, expectedVersion, nil
+}
+
+func readCreateNoFlyZoneRequest(ctx context.Context, in thrift.TProtocol) (string, string, domain.Polygon, service.NoFlyWindow, error) {
+	// Expected args struct: CreateNoFlyZone_args { 1: CreateNoFlyZoneRequest request }
+	var token, name string
+	var boundary domain.Polygon
+	var window service.NoFlyWindow
+	err := readRequest(ctx, in, func(fieldID int16, fieldType thrift.TType) error {
+		var err error
+		switch fieldID {
+		case 1:
+			token, err = in.ReadString(ctx)
+		case 2:
+			name, err = in.ReadString(ctx)
+		case 3:
+			boundary, err = readPolygon(ctx, in)
+		case 4:
+			window.From, err = readUnixTime(ctx, in)
+		case 5:
+			window.Until, err = readUnixTime(ctx, in)
+		default:
+			err = in.Skip(ctx, fieldType)
+		}
+		return err
+	})
+	if err != nil {
+		return "", "", nil, service.NoFlyWindow{}, err
 	}
-	return token, status, int(limit), int(offset), nil
+	return token, name, boundary, window, nil
 }
 
-func readUpdateOrderRequest(ctx context.Context, in thrift.TProtocol) (string, string, *domain.Location, *domain.Location, error) {
-	if _, err := in.ReadStructBegin(ctx); err != nil {
-		return "", "", nil, nil, err
+func readUpdateNoFlyZoneRequest(ctx context.Context, in thrift.TProtocol) (string, string, service.NoFlyZoneUpdate, int64, error) {
+	// Expected args struct: UpdateNoFlyZone_args { 1: UpdateNoFlyZoneRequest request }
+	var token, zoneID string
+	var update service.NoFlyZoneUpdate
+	var replaceWindow bool
+	var window service.NoFlyWindow
+	var expectedVersion int64
+	err := readRequest(ctx, in, func(fieldID int16, fieldType thrift.TType) error {
+		var err error
+		switch fieldID {
+		case 1:
+			token, err = in.ReadString(ctx)
+		case 2:
+			zoneID, err = in.ReadString(ctx)
+		case 3:
+			var name string
+			name, err = in.ReadString(ctx)
+			update.Name = &name
+		case 4:
+			update.Boundary, err = readPolygon(ctx, in)
+		case 5:
+			replaceWindow, err = in.ReadBool(ctx)
+		case 6:
+			window.From, err = readUnixTime(ctx, in)
+		case 7:
+			window.Until, err = readUnixTime(ctx, in)
+		case 8:
+			expectedVersion, err = in.ReadI64(ctx)
+		default:
+			err = in.Skip(ctx, fieldType)
+		}
+		return err
+	})
+	if err != nil {
+		return "", "", service.NoFlyZoneUpdate{}, 0, err
 	}
-	var token, orderID string
-	var origin *domain.Location
-	var dest *domain.Location
-	for {
-		_, fieldType, fieldID, err := in.ReadFieldBegin(ctx)
-		if err != nil {
-			return "", "", nil, nil, err
+	if replaceWindow {
+		update.Window = &window
+	}
+	return token, zoneID, update, expectedVersion, nil
+}
+
+// readUnixTime reads an i64 of Unix seconds.
+func readTrackRequest(ctx context.Context, in thrift.TProtocol) (string, string, service.TrackRange, error) {
+	// Expected args struct: DroneTrack_args / OrderTrack_args { 1: TrackRequest request }
+	var token, id string
+	var rng service.TrackRange
+	err := readRequest(ctx, in, func(fieldID int16, fieldType thrift.TType) error {
+		var err error
+		switch fieldID {
+		case 1:
+			token, err = in.ReadString(ctx)
+		case 2:
+			id, err = in.ReadString(ctx)
+		case 3:
+			rng.From, err = readUnixTime(ctx, in)
+		case 4:
+			rng.To, err = readUnixTime(ctx, in)
+		default:
+			err = in.Skip(ctx, fieldType)
 		}
-		if fieldType == thrift.STOP {
-			break
+		return err
+	})
+	if err != nil {
+		return "", "", service.TrackRange{}, err
+	}
+	return token, id, rng, nil
+}
+
+func readUnixTime(ctx context.Context, in thrift.TProtocol) (*time.Time, error) {
+	seconds, err := in.ReadI64(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t := time.Unix(seconds, 0).UTC()
+	return &t, nil
+}
+
+func readStringList(ctx context.Context, in thrift.TProtocol) ([]string, error) {
+	_, size, err := in.ReadListBegin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, 0, size)
+	for i := 0; i < size; i++ {
+		v, err := in.ReadString(ctx)
+		if err != nil {
+			return nil, err
 		}
+		values = append(values, v)
+	}
+	return values, in.ReadListEnd(ctx)
+}
+
+func readBoundingBox(ctx context.Context, in thrift.TProtocol) (domain.BoundingBox, error) {
+	var box domain.BoundingBox
+	err := readStruct(ctx, in, func(fieldID int16, fieldType thrift.TType) error {
+		var err error
 		switch fieldID {
 		case 1:
-			token, err = in.ReadString(ctx)
+			box.MinLat, err = in.ReadDouble(ctx)
 		case 2:
-			orderID, err = in.ReadString(ctx)
+			box.MinLng, err = in.ReadDouble(ctx)
 		case 3:
-			loc, err := readLocation(ctx, in)
-			if err != nil {
-				return "", "", nil, nil, err
-			}
-			origin = &loc
+			box.MaxLat, err = in.ReadDouble(ctx)
 		case 4:
-			loc, err := readLocation(ctx, in)
-			if err != nil {
-				return "", "", nil, nil, err
-			}
-			dest = &loc
+			box.MaxLng, err = in.ReadDouble(ctx)
 		default:
 			err = in.Skip(ctx, fieldType)
 		}
+		return err
+	})
+	return box, err
+}
+
+// readRequest decodes a <Method>_args { 1: <Request> request } envelope and
+// hands each field of the inner request struct to readField, which must
+// consume (or skip) the field value.
+func readRequest(ctx context.Context, in thrift.TProtocol, readField func(fieldID int16, fieldType thrift.TType) error) error {
+	if _, err := in.ReadStructBegin(ctx); err != nil {
+		return err
+	}
+	for {
+		_, fieldType, fieldID, err := in.ReadFieldBegin(ctx)
 		if err != nil {
-			return "", "", nil, nil, err
+			return err
+		}
+		if fieldType == thrift.STOP {
+			break
+		}
+		if fieldID == 1 && fieldType == thrift.STRUCT {
+			if err := readStruct(ctx, in, readField); err != nil {
+				return err
+			}
+		} else {
+			if err := in.Skip(ctx, fieldType); err != nil {
+				return err
+			}
 		}
 		if err := in.ReadFieldEnd(ctx); err != nil {
-			return "", "", nil, nil, err
+			return err
 		}
 	}
 	if err := in.ReadStructEnd(ctx); err != nil {
-		return "", "", nil, nil, err
+		return err
 	}
-	if err := in.ReadMessageEnd(ctx); err != nil {
-		return "", "", nil, nil, err
+	return in.ReadMessageEnd(ctx)
+}
+
+func readStruct(ctx context.Context, in thrift.TProtocol, readField func(fieldID int16, fieldType thrift.TType) error) error {
+	if _, err := in.ReadStructBegin(ctx); err != nil {
+		return err
+	}
+	for {
+		_, fieldType, fieldID, err := in.ReadFieldBegin(ctx)
+		if err != nil {
+			return err
+		}
+		if fieldType == thrift.STOP {
+			break
+		}
+		if err := readField(fieldID, fieldType); err != nil {
+			return err
+		}
+		if err := in.ReadFieldEnd(ctx); err != nil {
+			return err
+		}
 	}
-	return token, orderID, origin, dest, nil
+	return in.ReadStructEnd(ctx)
 }
 
 func readLocation(ctx context.Context, in thrift.TProtocol) (domain.Location, error) {
@@ -1342,3 +3913,31 @@ func readLocation(ctx context.Context, in thrift.TProtocol) (domain.Location, er
 	}
 	return domain.Location{Lat: lat, Lng: lng}, nil
 }
+
+// readPolygon reads a list<list<Location>>, exterior ring first.
+func readPolygon(ctx context.Context, in thrift.TProtocol) (domain.Polygon, error) {
+	_, rings, err := in.ReadListBegin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	polygon := make(domain.Polygon, 0, rings)
+	for i := 0; i < rings; i++ {
+		_, size, err := in.ReadListBegin(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ring := make([]domain.Location, 0, size)
+		for j := 0; j < size; j++ {
+			loc, err := readLocation(ctx, in)
+			if err != nil {
+				return nil, err
+			}
+			ring = append(ring, loc)
+		}
+		if err := in.ReadListEnd(ctx); err != nil {
+			return nil, err
+		}
+		polygon = append(polygon, ring)
+	}
+	return polygon, in.ReadListEnd(ctx)
+}
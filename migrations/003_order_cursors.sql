@@ -0,0 +1,5 @@
+-- Keyset pagination walks orders by (created_at, id); the per-user index is
+-- widened so "my orders" listings are served from it.
+DROP INDEX IF EXISTS idx_orders_user;
+CREATE INDEX IF NOT EXISTS idx_orders_user ON orders (user_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_orders_created_id ON orders (created_at, id);
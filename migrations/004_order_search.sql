@@ -0,0 +1,6 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_orders_updated_id ON orders (updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_orders_origin ON orders (origin_lat, origin_lng);
+CREATE INDEX IF NOT EXISTS idx_orders_dest ON orders (dest_lat, dest_lng);
+CREATE INDEX IF NOT EXISTS idx_orders_failure_reason_trgm ON orders USING gin (failure_reason gin_trgm_ops);
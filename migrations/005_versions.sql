@@ -0,0 +1,4 @@
+-- Optimistic concurrency: every update bumps version; callers may send the
+-- version they read (If-Match) and get a precondition error if it moved.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1;
+ALTER TABLE drones ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1;
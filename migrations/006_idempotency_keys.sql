@@ -0,0 +1,11 @@
+-- Results of mutations made under a client-supplied Idempotency-Key, scoped to
+-- the caller. Expired rows are overwritten when their key is reused.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+  scope text NOT NULL,
+  idempotency_key text NOT NULL,
+  request_hash text NOT NULL,
+  response bytea NOT NULL,
+  created_at timestamptz NOT NULL,
+  expires_at timestamptz NOT NULL,
+  PRIMARY KEY (scope, idempotency_key)
+);
@@ -0,0 +1,10 @@
+-- boundary holds GeoJSON Polygon coordinates ([lng, lat] positions).
+CREATE TABLE IF NOT EXISTS service_areas (
+  id uuid PRIMARY KEY,
+  name text NOT NULL,
+  boundary jsonb NOT NULL,
+  active boolean NOT NULL,
+  created_at timestamptz NOT NULL,
+  updated_at timestamptz NOT NULL,
+  version bigint NOT NULL DEFAULT 1
+);
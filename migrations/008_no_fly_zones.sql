@@ -0,0 +1,12 @@
+-- boundary holds GeoJSON Polygon coordinates ([lng, lat] positions). A NULL
+-- window end is open.
+CREATE TABLE IF NOT EXISTS no_fly_zones (
+  id uuid PRIMARY KEY,
+  name text NOT NULL,
+  boundary jsonb NOT NULL,
+  active_from timestamptz NULL,
+  active_until timestamptz NULL,
+  created_at timestamptz NOT NULL,
+  updated_at timestamptz NOT NULL,
+  version bigint NOT NULL DEFAULT 1
+);
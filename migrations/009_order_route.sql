@@ -0,0 +1,3 @@
+-- route holds the path planned at the last reservation as GeoJSON LineString
+-- coordinates ([lng, lat] positions); NULL until the order is reserved.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS route jsonb NULL;
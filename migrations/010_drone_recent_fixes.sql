@@ -0,0 +1,3 @@
+-- recent_fixes holds the drone's latest heartbeat positions as a JSON array of
+-- {"lat", "lng", "at"} objects, oldest first.
+ALTER TABLE drones ADD COLUMN IF NOT EXISTS recent_fixes jsonb NULL;
@@ -0,0 +1,35 @@
+-- drone_telemetry keeps every heartbeat position for track exports. order_id
+-- is the order the drone was carrying out at the time, if any.
+--
+-- The table is partitioned by month so expired telemetry can be dropped a
+-- partition at a time. The store creates each month's partition ahead of time
+-- when it prunes; the default partition only catches rows that arrive before
+-- then.
+CREATE TABLE IF NOT EXISTS drone_telemetry (
+  drone_id text NOT NULL,
+  order_id uuid NULL,
+  lat double precision NOT NULL,
+  lng double precision NOT NULL,
+  recorded_at timestamptz NOT NULL
+) PARTITION BY RANGE (recorded_at);
+
+CREATE TABLE IF NOT EXISTS drone_telemetry_default PARTITION OF drone_telemetry DEFAULT;
+
+CREATE INDEX IF NOT EXISTS drone_telemetry_drone_idx ON drone_telemetry (drone_id, recorded_at);
+CREATE INDEX IF NOT EXISTS drone_telemetry_order_idx ON drone_telemetry (order_id, recorded_at) WHERE order_id IS NOT NULL;
+
+DO $$
+DECLARE
+  month date;
+BEGIN
+  FOR i IN 0..1 LOOP
+    month := (date_trunc('month', now() AT TIME ZONE 'UTC') + make_interval(months => i))::date;
+    EXECUTE format(
+      'CREATE TABLE IF NOT EXISTS %I PARTITION OF drone_telemetry FOR VALUES FROM (%L) TO (%L)',
+      'drone_telemetry_' || to_char(month, 'YYYY_MM'),
+      month::timestamp AT TIME ZONE 'UTC',
+      (month + interval '1 month')::timestamp AT TIME ZONE 'UTC'
+    );
+  END LOOP;
+END
+$$;
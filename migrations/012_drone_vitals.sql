@@ -0,0 +1,7 @@
+-- The readings of each drone's latest heartbeat; NULL when not reported.
+ALTER TABLE drones
+  ADD COLUMN IF NOT EXISTS altitude_m double precision NULL,
+  ADD COLUMN IF NOT EXISTS heading_deg double precision NULL,
+  ADD COLUMN IF NOT EXISTS ground_speed_mps double precision NULL,
+  ADD COLUMN IF NOT EXISTS battery_pct double precision NULL,
+  ADD COLUMN IF NOT EXISTS fault_codes text[] NULL;
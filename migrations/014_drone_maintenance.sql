@@ -0,0 +1,26 @@
+-- The flying each drone has done on its orders, in total and since it was
+-- last serviced.
+ALTER TABLE drones
+  ADD COLUMN IF NOT EXISTS flight_seconds double precision NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS flight_meters double precision NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS service_flight_seconds double precision NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS service_flight_meters double precision NOT NULL DEFAULT 0;
+
+-- One row per spell a drone spends BROKEN or in MAINTENANCE; closed_at is
+-- NULL while the spell lasts.
+CREATE TABLE IF NOT EXISTS maintenance_records (
+  id uuid PRIMARY KEY,
+  drone_id text NOT NULL,
+  reason text NOT NULL,
+  fault_codes text[] NULL,
+  flight_seconds double precision NOT NULL,
+  flight_meters double precision NOT NULL,
+  opened_at timestamptz NOT NULL,
+  closed_at timestamptz NULL,
+  technician text NULL,
+  notes text NULL,
+  parts text[] NULL
+);
+
+CREATE INDEX IF NOT EXISTS maintenance_records_drone_idx ON maintenance_records (drone_id, opened_at);
+CREATE UNIQUE INDEX IF NOT EXISTS maintenance_records_open_idx ON maintenance_records (drone_id) WHERE closed_at IS NULL;
@@ -0,0 +1,15 @@
+-- Depots drones are based at; a drone's home_depot_id is NULL if it has none.
+CREATE TABLE IF NOT EXISTS depots (
+  id uuid PRIMARY KEY,
+  name text NOT NULL,
+  lat double precision NOT NULL,
+  lng double precision NOT NULL,
+  capacity integer NOT NULL,
+  created_at timestamptz NOT NULL,
+  updated_at timestamptz NOT NULL,
+  version bigint NOT NULL DEFAULT 1
+);
+
+ALTER TABLE drones ADD COLUMN IF NOT EXISTS home_depot_id uuid NULL;
+
+CREATE INDEX IF NOT EXISTS drones_home_depot_idx ON drones (home_depot_id);
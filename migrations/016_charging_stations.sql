@@ -0,0 +1,23 @@
+-- Charging stations and their slots, numbered from 1. A slot's drone_id is
+-- set while a drone holds it; a drone holds at most one slot.
+CREATE TABLE IF NOT EXISTS charging_stations (
+  id uuid PRIMARY KEY,
+  name text NOT NULL,
+  lat double precision NOT NULL,
+  lng double precision NOT NULL,
+  slots integer NOT NULL,
+  created_at timestamptz NOT NULL,
+  updated_at timestamptz NOT NULL,
+  version bigint NOT NULL DEFAULT 1
+);
+
+CREATE TABLE IF NOT EXISTS charging_slots (
+  station_id uuid NOT NULL REFERENCES charging_stations (id),
+  slot integer NOT NULL,
+  drone_id text NULL,
+  reserved_at timestamptz NULL,
+  PRIMARY KEY (station_id, slot)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS charging_slots_drone_idx ON charging_slots (drone_id) WHERE drone_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS charging_slots_free_idx ON charging_slots (station_id, slot) WHERE drone_id IS NULL;
@@ -0,0 +1,5 @@
+-- pickup_not_before and deliver_by are an order's optional delivery window;
+-- sla_at_risk_at records when its ETA was first found to miss deliver_by.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS pickup_not_before timestamptz NULL;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS deliver_by timestamptz NULL;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS sla_at_risk_at timestamptz NULL;
@@ -0,0 +1,41 @@
+-- ensure_drone_telemetry_partition creates the monthly drone_telemetry
+-- partition starting at month, unless it exists. A partition cannot be created
+-- over rows the default partition already holds for its range, as happens
+-- when the store's upkeep misses a month boundary; those rows are moved into
+-- the new partition while the default is detached.
+CREATE OR REPLACE FUNCTION ensure_drone_telemetry_partition(month date) RETURNS void
+LANGUAGE plpgsql AS $$
+DECLARE
+  part text := 'drone_telemetry_' || to_char(month, 'YYYY_MM');
+  lower_bound timestamptz := month::timestamp AT TIME ZONE 'UTC';
+  upper_bound timestamptz := (month + interval '1 month')::timestamp AT TIME ZONE 'UTC';
+BEGIN
+  IF to_regclass(part) IS NOT NULL THEN
+    RETURN;
+  END IF;
+  IF NOT EXISTS (
+    SELECT 1 FROM drone_telemetry_default
+    WHERE recorded_at >= lower_bound AND recorded_at < upper_bound
+  ) THEN
+    EXECUTE format('CREATE TABLE %I PARTITION OF drone_telemetry FOR VALUES FROM (%L) TO (%L)',
+      part, lower_bound, upper_bound);
+    RETURN;
+  END IF;
+  ALTER TABLE drone_telemetry DETACH PARTITION drone_telemetry_default;
+  EXECUTE format('CREATE TABLE %I PARTITION OF drone_telemetry FOR VALUES FROM (%L) TO (%L)',
+    part, lower_bound, upper_bound);
+  WITH moved AS (
+    DELETE FROM drone_telemetry_default
+    WHERE recorded_at >= lower_bound AND recorded_at < upper_bound
+    RETURNING drone_id, order_id, lat, lng, recorded_at
+  )
+  INSERT INTO drone_telemetry (drone_id, order_id, lat, lng, recorded_at)
+  SELECT drone_id, order_id, lat, lng, recorded_at FROM moved;
+  ALTER TABLE drone_telemetry ATTACH PARTITION drone_telemetry_default DEFAULT;
+END
+$$;
+
+-- This month's partition and the next three, so heartbeats keep landing in
+-- monthly partitions even if prune runs are missed for a while.
+SELECT ensure_drone_telemetry_partition((date_trunc('month', now() AT TIME ZONE 'UTC') + make_interval(months => i))::date)
+FROM generate_series(0, 3) AS i;
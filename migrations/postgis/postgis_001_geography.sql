@@ -0,0 +1,21 @@
+-- Optional PostGIS schema, applied only when the extension is available (see
+-- postgres.SetupPostGIS). The geography columns are generated from the
+-- lat/lng columns the store writes, so every write keeps them in sync.
+CREATE EXTENSION IF NOT EXISTS postgis;
+
+ALTER TABLE orders
+  ADD COLUMN IF NOT EXISTS origin_geog geography(Point, 4326)
+    GENERATED ALWAYS AS (ST_SetSRID(ST_MakePoint(origin_lng, origin_lat), 4326)::geography) STORED,
+  ADD COLUMN IF NOT EXISTS dest_geog geography(Point, 4326)
+    GENERATED ALWAYS AS (ST_SetSRID(ST_MakePoint(dest_lng, dest_lat), 4326)::geography) STORED,
+  ADD COLUMN IF NOT EXISTS handoff_origin_geog geography(Point, 4326)
+    GENERATED ALWAYS AS (ST_SetSRID(ST_MakePoint(handoff_origin_lng, handoff_origin_lat), 4326)::geography) STORED;
+
+ALTER TABLE drones
+  ADD COLUMN IF NOT EXISTS last_geog geography(Point, 4326)
+    GENERATED ALWAYS AS (ST_SetSRID(ST_MakePoint(last_lng, last_lat), 4326)::geography) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_orders_origin_geog ON orders USING gist (origin_geog);
+CREATE INDEX IF NOT EXISTS idx_orders_dest_geog ON orders USING gist (dest_geog);
+CREATE INDEX IF NOT EXISTS idx_orders_handoff_origin_geog ON orders USING gist (handoff_origin_geog);
+CREATE INDEX IF NOT EXISTS idx_drones_last_geog ON drones USING gist (last_geog);
@@ -20,27 +20,102 @@ struct Order {
   12: optional i64 deliveredAt
   13: optional i64 failedAt
   14: optional string failureReason
+  15: i64 version
+  16: optional list<Location> route
+  // Set on orders relayed between drones; currentLeg indexes legs and is
+  // unset once they are all flown.
+  17: optional list<RelayLeg> legs
+  18: optional i32 currentLeg
+  // Set on scheduled orders; slaAtRiskAt is when the ETA was first found to
+  // miss deliverBy.
+  19: optional i64 pickupNotBefore
+  20: optional i64 deliverBy
+  21: optional i64 slaAtRiskAt
+}
+
+// One hop of a relayed order; relayPointId is unset on the last leg.
+struct RelayLeg {
+  1: Location to
+  2: optional string relayPointId
+  3: optional string droneId
+  4: optional i64 completedAt
+}
+
+// kind is queue, to_pickup, pickup, delivery, dropoff, relay or stop.
+struct ETALeg {
+  1: string kind
+  2: double distanceMeters
+  3: i64 seconds
 }
 
 struct OrderView {
   1: Order order
   2: optional Location currentLocation
   3: optional i64 etaSeconds
+  4: optional list<ETALeg> etaLegs
+  // observed (drone's measured speed) or nominal (configured speed).
+  5: optional string etaSource
+  6: optional double etaConfidence
 }
 
 struct Drone {
   1: string id
+  // ACTIVE, CHARGING, MAINTENANCE, OFFLINE, BROKEN or RETIRED.
   2: string status
   3: optional Location lastLocation
   4: optional i64 lastHeartbeatAt
   5: optional string currentOrderId
   6: i64 createdAt
   7: i64 updatedAt
+  8: i64 version
+  // The readings of the drone's latest heartbeat.
+  9: optional double altitudeMeters
+  10: optional double headingDegrees
+  11: optional double groundSpeedMps
+  12: optional double batteryPercent
+  13: optional list<string> faultCodes
+  // Flying done on completed orders, in total and since the drone was last
+  // serviced.
+  14: double flightSeconds
+  15: double flightMeters
+  16: double sinceServiceSeconds
+  17: double sinceServiceMeters
+  18: optional string homeDepotId
+  // The pickups and dropoffs of the drone's trip, in flight order.
+  19: optional list<Stop> stops
+}
+
+// kind is pickup or dropoff.
+struct Stop {
+  1: string orderId
+  2: string kind
+  3: Location location
+  4: optional i64 completedAt
+}
+
+// Sent to a drone idle away from its home depot: fly back along route.
+struct ReturnToBase {
+  1: string depotId
+  2: Location location
+  3: list<Location> route
+  4: double distanceMeters
+  5: i64 etaSeconds
+}
+
+// Sent to a drone reporting low battery: the nearest station with a free slot.
+struct ChargeAt {
+  1: string stationId
+  2: string name
+  3: Location location
+  4: double distanceMeters
+  5: i32 freeSlots
 }
 
 struct DroneStatus {
   1: Drone drone
   2: optional OrderView currentOrder
+  3: optional ReturnToBase returnToBase
+  4: optional ChargeAt chargeAt
 }
 
 struct TokenRequest {
@@ -57,33 +132,94 @@ struct SubmitOrderRequest {
   1: string authToken
   2: Location origin
   3: Location destination
+  // Retries with the same key return the original order.
+  4: optional string idempotencyKey
+  // Optional delivery window: the order is not picked up before
+  // pickupNotBefore and is due by deliverBy.
+  5: optional i64 pickupNotBefore
+  6: optional i64 deliverBy
 }
 
 struct OrderIDRequest {
   1: string authToken
   2: string orderId
+  // Honoured by WithdrawOrder and the admin order mutations; 0 = unconditional.
+  3: optional i64 expectedVersion
+  // Honoured by PickupOrder and DeliverOrder.
+  4: optional string idempotencyKey
 }
 
 struct AuthRequest {
   1: string authToken
+  // Honoured by ReserveJob and MarkBroken.
+  2: optional string idempotencyKey
 }
 
 struct FailOrderRequest {
   1: string authToken
   2: string orderId
   3: string reason
+  4: optional string idempotencyKey
 }
 
+// Every reading besides location is optional. Reporting a critical fault
+// code (MOTOR_FAILURE, BATTERY_CRITICAL, FLIGHT_CONTROLLER_FAILURE, GPS_LOST,
+// STRUCTURAL_DAMAGE) marks the drone broken.
 struct HeartbeatRequest {
   1: string authToken
   2: Location location
+  3: optional double altitudeMeters
+  4: optional double headingDegrees
+  5: optional double groundSpeedMps
+  6: optional double batteryPercent
+  7: optional list<string> faultCodes
+}
+
+struct BoundingBox {
+  1: double minLat
+  2: double minLng
+  3: double maxLat
+  4: double maxLng
 }
 
 struct ListOrdersRequest {
   1: string authToken
   2: optional string status
   3: optional i32 limit
+  // No longer supported; a non-zero offset is rejected, page with cursor.
   4: optional i32 offset
+  5: optional string cursor
+  6: optional i64 createdFrom
+  7: optional i64 createdTo
+  8: optional list<string> statuses
+  9: optional string sort
+  // Admin-only filters; ignored by ListMyOrders.
+  10: optional string userId
+  11: optional string droneId
+  12: optional i64 updatedFrom
+  13: optional i64 updatedTo
+  14: optional string failureReason
+  15: optional BoundingBox originBox
+  16: optional BoundingBox destinationBox
+}
+
+struct OrderPage {
+  1: list<OrderView> orders
+  2: optional string nextCursor
+}
+
+struct OrdersNearRequest {
+  1: string authToken
+  2: Location center
+  3: double radiusMeters
+  4: optional list<string> statuses
+  5: optional i32 limit
+}
+
+struct NearestDronesRequest {
+  1: string authToken
+  2: Location point
+  3: optional i32 limit
 }
 
 struct UpdateOrderRequest {
@@ -91,11 +227,244 @@ struct UpdateOrderRequest {
   2: string orderId
   3: optional Location origin
   4: optional Location destination
+  5: optional i64 expectedVersion
+}
+
+struct AssignOrderRequest {
+  1: string authToken
+  2: string orderId
+  3: string droneId
+  4: optional i64 expectedVersion
+}
+
+struct OverrideOrderRequest {
+  1: string authToken
+  2: string orderId
+  3: string status
+  4: string reason
+  5: optional i64 expectedVersion
 }
 
 struct DroneIDRequest {
   1: string authToken
   2: string droneId
+  3: optional i64 expectedVersion
+}
+
+// A DroneIDRequest with the optional report of the repair, recorded on the
+// drone's maintenance record.
+struct FixDroneRequest {
+  1: string authToken
+  2: string droneId
+  3: optional i64 expectedVersion
+  4: optional string technician
+  5: optional string notes
+  6: optional list<string> parts
+}
+
+// status is one of ACTIVE, CHARGING, OFFLINE or BROKEN.
+struct DroneStatusRequest {
+  1: string authToken
+  2: string status
+}
+
+// status is any drone status; see docs_api.md for the allowed transitions.
+struct AdminDroneStatusRequest {
+  1: string authToken
+  2: string droneId
+  3: string status
+  4: optional i64 expectedVersion
+}
+
+// First ring is the exterior, any others are holes; rings are closed.
+typedef list<list<Location>> Polygon
+
+struct ServiceArea {
+  1: string id
+  2: string name
+  3: Polygon boundary
+  4: bool active
+  5: i64 createdAt
+  6: i64 updatedAt
+  7: i64 version
+}
+
+struct ServiceAreaIDRequest {
+  1: string authToken
+  2: string serviceAreaId
+}
+
+struct CreateServiceAreaRequest {
+  1: string authToken
+  2: string name
+  3: Polygon boundary
+  // Defaults to true.
+  4: optional bool active
+}
+
+struct UpdateServiceAreaRequest {
+  1: string authToken
+  2: string serviceAreaId
+  3: optional string name
+  4: optional Polygon boundary
+  5: optional bool active
+  6: optional i64 expectedVersion
+}
+
+struct Depot {
+  1: string id
+  2: string name
+  3: Location location
+  // How many drones may be homed at the depot.
+  4: i32 capacity
+  5: i64 createdAt
+  6: i64 updatedAt
+  7: i64 version
+}
+
+struct DepotIDRequest {
+  1: string authToken
+  2: string depotId
+}
+
+struct CreateDepotRequest {
+  1: string authToken
+  2: string name
+  3: Location location
+  4: i32 capacity
+}
+
+struct UpdateDepotRequest {
+  1: string authToken
+  2: string depotId
+  3: optional string name
+  4: optional Location location
+  5: optional i32 capacity
+  6: optional i64 expectedVersion
+}
+
+// An unset depotId clears the drone's home depot.
+struct SetDroneDepotRequest {
+  1: string authToken
+  2: string droneId
+  3: optional string depotId
+  4: optional i64 expectedVersion
+}
+
+// Slots are numbered from 1; droneId and reservedAt are set while a drone
+// holds the slot.
+struct ChargingSlot {
+  1: string stationId
+  2: i32 slot
+  3: optional string droneId
+  4: optional i64 reservedAt
+}
+
+// slotList is only set by GetChargingStation and the create/update calls.
+struct ChargingStation {
+  1: string id
+  2: string name
+  3: Location location
+  4: i32 slots
+  5: i32 occupied
+  6: optional list<ChargingSlot> slotList
+  7: i64 createdAt
+  8: i64 updatedAt
+  9: i64 version
+}
+
+struct ChargingStationIDRequest {
+  1: string authToken
+  2: string stationId
+}
+
+struct CreateChargingStationRequest {
+  1: string authToken
+  2: string name
+  3: Location location
+  4: i32 slots
+}
+
+struct UpdateChargingStationRequest {
+  1: string authToken
+  2: string stationId
+  3: optional string name
+  4: optional Location location
+  5: optional i32 slots
+  6: optional i64 expectedVersion
+}
+
+// Unix seconds; an unset end is open.
+struct NoFlyZone {
+  1: string id
+  2: string name
+  3: Polygon boundary
+  4: optional i64 activeFrom
+  5: optional i64 activeUntil
+  6: i64 createdAt
+  7: i64 updatedAt
+  8: i64 version
+}
+
+struct NoFlyZoneIDRequest {
+  1: string authToken
+  2: string noFlyZoneId
+}
+
+struct CreateNoFlyZoneRequest {
+  1: string authToken
+  2: string name
+  3: Polygon boundary
+  4: optional i64 activeFrom
+  5: optional i64 activeUntil
+}
+
+struct UpdateNoFlyZoneRequest {
+  1: string authToken
+  2: string noFlyZoneId
+  3: optional string name
+  4: optional Polygon boundary
+  // When replaceWindow is true, activeFrom and activeUntil replace both ends
+  // of the window; either left unset is open.
+  5: optional bool replaceWindow
+  6: optional i64 activeFrom
+  7: optional i64 activeUntil
+  8: optional i64 expectedVersion
+}
+
+// Used by DroneTrack (id is a drone ID) and OrderTrack (id is an order ID).
+// from and to are Unix seconds bounding the track to [from, to); an unset end
+// is open.
+struct TrackRequest {
+  1: string authToken
+  2: string id
+  3: optional i64 from
+  4: optional i64 to
+}
+
+// recordedAt is Unix seconds.
+struct TelemetrySample {
+  1: string droneId
+  2: optional string orderId
+  3: Location location
+  4: i64 recordedAt
+}
+
+// reason is BROKEN, SCHEDULED or SERVICE_INTERVAL. flightSeconds and
+// flightMeters are the drone's usage when the record was opened; openedAt and
+// closedAt are Unix seconds, closedAt unset while the record is open.
+struct MaintenanceRecord {
+  1: string id
+  2: string droneId
+  3: string reason
+  4: optional list<string> faultCodes
+  5: double flightSeconds
+  6: double flightMeters
+  7: i64 openedAt
+  8: optional i64 closedAt
+  9: optional string technician
+  10: optional string notes
+  11: optional list<string> parts
 }
 
 service AuthService {
@@ -106,6 +475,7 @@ service OrderService {
   Order SubmitOrder(1: SubmitOrderRequest request)
   Order WithdrawOrder(1: OrderIDRequest request)
   OrderView GetOrder(1: OrderIDRequest request)
+  OrderPage ListMyOrders(1: ListOrdersRequest request)
 }
 
 service DroneService {
@@ -114,14 +484,52 @@ service DroneService {
   Order DeliverOrder(1: OrderIDRequest request)
   Order FailOrder(1: FailOrderRequest request)
   Drone MarkBroken(1: AuthRequest request)
+  Drone SetStatus(1: DroneStatusRequest request)
   DroneStatus Heartbeat(1: HeartbeatRequest request)
   OrderView CurrentOrder(1: AuthRequest request)
+  // Fails when the station is full or the drone holds a slot elsewhere.
+  ChargingSlot ReserveChargingSlot(1: ChargingStationIDRequest request)
+  ChargingSlot ReleaseChargingSlot(1: AuthRequest request)
 }
 
 service AdminService {
+  // Returns the first page only; ListOrdersPage returns the cursor too.
   list<OrderView> ListOrders(1: ListOrdersRequest request)
+  OrderPage ListOrdersPage(1: ListOrdersRequest request)
   Order UpdateOrder(1: UpdateOrderRequest request)
+  Order AssignOrder(1: AssignOrderRequest request)
+  Order UnassignOrder(1: OrderIDRequest request)
+  Order ReassignOrder(1: AssignOrderRequest request)
+  Order OverrideOrder(1: OverrideOrderRequest request)
+  OrderPage OrdersNear(1: OrdersNearRequest request)
   list<Drone> ListDrones(1: AuthRequest request)
+  list<Drone> NearestIdleDrones(1: NearestDronesRequest request)
   Drone MarkDroneBroken(1: DroneIDRequest request)
-  Drone MarkDroneFixed(1: DroneIDRequest request)
+  Drone MarkDroneFixed(1: FixDroneRequest request)
+  Drone SetDroneStatus(1: AdminDroneStatusRequest request)
+  // Fails while the drone holds an order.
+  Drone RetireDrone(1: DroneIDRequest request)
+  list<ServiceArea> ListServiceAreas(1: AuthRequest request)
+  ServiceArea GetServiceArea(1: ServiceAreaIDRequest request)
+  ServiceArea CreateServiceArea(1: CreateServiceAreaRequest request)
+  ServiceArea UpdateServiceArea(1: UpdateServiceAreaRequest request)
+  list<NoFlyZone> ListNoFlyZones(1: AuthRequest request)
+  NoFlyZone GetNoFlyZone(1: NoFlyZoneIDRequest request)
+  NoFlyZone CreateNoFlyZone(1: CreateNoFlyZoneRequest request)
+  NoFlyZone UpdateNoFlyZone(1: UpdateNoFlyZoneRequest request)
+  list<TelemetrySample> DroneTrack(1: TrackRequest request)
+  list<TelemetrySample> OrderTrack(1: TrackRequest request)
+  // Oldest first.
+  list<MaintenanceRecord> DroneMaintenance(1: DroneIDRequest request)
+  // Fails when the depot is at capacity.
+  Drone SetDroneDepot(1: SetDroneDepotRequest request)
+  list<Depot> ListDepots(1: AuthRequest request)
+  Depot GetDepot(1: DepotIDRequest request)
+  Depot CreateDepot(1: CreateDepotRequest request)
+  Depot UpdateDepot(1: UpdateDepotRequest request)
+  list<ChargingStation> ListChargingStations(1: AuthRequest request)
+  ChargingStation GetChargingStation(1: ChargingStationIDRequest request)
+  ChargingStation CreateChargingStation(1: CreateChargingStationRequest request)
+  // Fails when removing a slot a drone holds.
+  ChargingStation UpdateChargingStation(1: UpdateChargingStationRequest request)
 }